@@ -136,6 +136,28 @@ CREATE TABLE identity.user_organization (
 
 
 
+--
+-- Name: approval; Type: TABLE; Schema: workflow; Owner: -
+--
+
+CREATE TABLE workflow.approval (
+    id character varying(36) NOT NULL,
+    "executionId" integer NOT NULL,
+    "workflowId" character varying(36) NOT NULL,
+    "nodeName" character varying NOT NULL,
+    "runIndex" integer NOT NULL,
+    message text DEFAULT ''::text NOT NULL,
+    "requireComment" boolean DEFAULT false NOT NULL,
+    status character varying NOT NULL,
+    "expiresAt" timestamp(3) with time zone NOT NULL,
+    "decidedAt" timestamp(3) with time zone,
+    comment text,
+    "clientIp" character varying,
+    "userAgent" character varying,
+    "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL
+);
+
+
 --
 -- Name: auth_identity; Type: TABLE; Schema: workflow; Owner: -
 --
@@ -199,7 +221,8 @@ CREATE TABLE workflow.credentials_entity (
     "nodesAccess" json NOT NULL,
     "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
     "updatedAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
-    id character varying(36) NOT NULL
+    id character varying(36) NOT NULL,
+    "sugerOrgId" character varying(36) DEFAULT ''::character varying NOT NULL
 );
 
 
@@ -241,7 +264,9 @@ CREATE TABLE workflow.execution_entity (
     "waitTill" timestamp(3) with time zone,
     status character varying,
     "workflowId" character varying(36) NOT NULL,
-    "deletedAt" timestamp(3) with time zone
+    "deletedAt" timestamp(3) with time zone,
+    "workflowVersionId" character varying(36),
+    "startedBy" character varying
 );
 
 
@@ -405,9 +430,11 @@ CREATE TABLE workflow.settings (
 CREATE TABLE workflow.shared_credentials (
     "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
     "updatedAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
-    "roleId" integer NOT NULL,
-    "userId" uuid NOT NULL,
-    "credentialsId" character varying(36) NOT NULL
+    "credentialsId" character varying(36) NOT NULL,
+    "sugerOrgId" character varying(36) NOT NULL,
+    "granteeType" character varying(16) NOT NULL,
+    "granteeId" character varying(36) NOT NULL,
+    role character varying(32) NOT NULL
 );
 
 
@@ -418,9 +445,11 @@ CREATE TABLE workflow.shared_credentials (
 CREATE TABLE workflow.shared_workflow (
     "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
     "updatedAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
-    "roleId" integer NOT NULL,
-    "userId" uuid NOT NULL,
-    "workflowId" character varying(36) NOT NULL
+    "workflowId" character varying(36) NOT NULL,
+    "sugerOrgId" character varying(36) NOT NULL,
+    "granteeType" character varying(16) NOT NULL,
+    "granteeId" character varying(36) NOT NULL,
+    role character varying(32) NOT NULL
 );
 
 
@@ -432,7 +461,8 @@ CREATE TABLE workflow.tag_entity (
     name character varying(24) NOT NULL,
     "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
     "updatedAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
-    id character varying(36) NOT NULL
+    id character varying(36) NOT NULL,
+    "sugerOrgId" character varying(36) DEFAULT ''::character varying NOT NULL
 );
 
 
@@ -467,7 +497,8 @@ CREATE TABLE workflow.variables (
     key character varying(50) NOT NULL,
     type character varying(50) DEFAULT 'string'::character varying NOT NULL,
     value character varying(255),
-    id character varying(36) NOT NULL
+    id character varying(36) NOT NULL,
+    "sugerOrgId" character varying(36) DEFAULT ''::character varying NOT NULL
 );
 
 
@@ -485,6 +516,76 @@ CREATE TABLE workflow.webhook_entity (
 );
 
 
+--
+-- Name: webhook_idempotency_key; Type: TABLE; Schema: workflow; Owner: -
+--
+
+CREATE TABLE workflow.webhook_idempotency_key (
+    "workflowId" character varying(36) NOT NULL,
+    "nodeId" character varying NOT NULL,
+    key character varying NOT NULL,
+    "executionId" character varying,
+    "responseStatusCode" integer,
+    "responseContentType" character varying,
+    "responseBody" bytea,
+    "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
+    "expiresAt" timestamp(3) with time zone NOT NULL
+);
+
+
+--
+-- Name: webhook_queue; Type: TABLE; Schema: workflow; Owner: -
+--
+
+CREATE TABLE workflow.webhook_queue (
+    id integer NOT NULL,
+    "workflowId" character varying(36) NOT NULL,
+    "nodeId" character varying NOT NULL,
+    "sugerOrgId" character varying NOT NULL,
+    request bytea NOT NULL,
+    "clientIp" character varying NOT NULL,
+    params json NOT NULL,
+    "idempotencyKey" character varying,
+    status character varying NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL,
+    "heartbeatAt" timestamp(3) with time zone
+);
+
+
+--
+-- Name: webhook_queue_id_seq; Type: SEQUENCE; Schema: workflow; Owner: -
+--
+
+CREATE SEQUENCE workflow.webhook_queue_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: webhook_queue_id_seq; Type: SEQUENCE OWNED BY; Schema: workflow; Owner: -
+--
+
+ALTER SEQUENCE workflow.webhook_queue_id_seq OWNED BY workflow.webhook_queue.id;
+
+
+--
+-- Name: webhook_response; Type: TABLE; Schema: workflow; Owner: -
+--
+
+CREATE TABLE workflow.webhook_response (
+    "executionId" integer NOT NULL,
+    "statusCode" integer NOT NULL,
+    headers json NOT NULL,
+    body bytea NOT NULL,
+    "createdAt" timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL
+);
+
+
 --
 -- Name: workflow_entity; Type: TABLE; Schema: workflow; Owner: -
 --
@@ -533,6 +634,19 @@ CREATE TABLE workflow.workflow_statistics (
     "workflowId" character varying(36) NOT NULL
 );
 
+--
+-- Name: workflow_statistics_hourly; Type: TABLE; Schema: workflow; Owner: -
+--
+
+CREATE TABLE workflow.workflow_statistics_hourly (
+    "workflowId" character varying(36) NOT NULL,
+    hour timestamp(3) with time zone NOT NULL,
+    status character varying(16) NOT NULL,
+    "durationBucket" smallint NOT NULL,
+    count integer DEFAULT 0 NOT NULL
+);
+
+
 --
 -- Name: workflows_tags; Type: TABLE; Schema: workflow; Owner: -
 --
@@ -577,6 +691,13 @@ ALTER TABLE ONLY workflow.migrations ALTER COLUMN id SET DEFAULT nextval('workfl
 
 ALTER TABLE ONLY workflow.role ALTER COLUMN id SET DEFAULT nextval('workflow.role_id_seq'::regclass);
 
+
+--
+-- Name: webhook_queue id; Type: DEFAULT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_queue ALTER COLUMN id SET DEFAULT nextval('workflow.webhook_queue_id_seq'::regclass);
+
 --
 -- Name: integration integration_pkey; Type: CONSTRAINT; Schema: identity; Owner: -
 --
@@ -720,6 +841,14 @@ ALTER TABLE ONLY workflow."user"
     ADD CONSTRAINT "UQ_e12875dfb3b1d92d7d7c5377e2" UNIQUE (email);
 
 
+--
+-- Name: approval approval_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.approval
+    ADD CONSTRAINT approval_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: auth_identity auth_identity_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -781,7 +910,7 @@ ALTER TABLE ONLY workflow.execution_entity
 --
 
 ALTER TABLE ONLY workflow.shared_credentials
-    ADD CONSTRAINT pk_shared_credentials_id PRIMARY KEY ("userId", "credentialsId");
+    ADD CONSTRAINT pk_shared_credentials_id PRIMARY KEY ("credentialsId", "sugerOrgId", "granteeType", "granteeId");
 
 
 --
@@ -789,7 +918,7 @@ ALTER TABLE ONLY workflow.shared_credentials
 --
 
 ALTER TABLE ONLY workflow.shared_workflow
-    ADD CONSTRAINT pk_shared_workflow_id PRIMARY KEY ("userId", "workflowId");
+    ADD CONSTRAINT pk_shared_workflow_id PRIMARY KEY ("workflowId", "granteeType", "granteeId");
 
 
 --
@@ -800,6 +929,14 @@ ALTER TABLE ONLY workflow.workflow_statistics
     ADD CONSTRAINT pk_workflow_statistics PRIMARY KEY ("workflowId", name);
 
 
+--
+-- Name: workflow_statistics_hourly pk_workflow_statistics_hourly; Type: CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.workflow_statistics_hourly
+    ADD CONSTRAINT pk_workflow_statistics_hourly PRIMARY KEY ("workflowId", hour, status, "durationBucket");
+
+
 --
 -- Name: workflows_tags pk_workflows_tags; Type: CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -821,7 +958,7 @@ ALTER TABLE ONLY workflow.tag_entity
 --
 
 ALTER TABLE ONLY workflow.variables
-    ADD CONSTRAINT variables_key_key UNIQUE (key);
+    ADD CONSTRAINT variables_key_key UNIQUE ("sugerOrgId", key);
 
 
 --
@@ -832,6 +969,30 @@ ALTER TABLE ONLY workflow.variables
     ADD CONSTRAINT variables_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: webhook_idempotency_key webhook_idempotency_key_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_idempotency_key
+    ADD CONSTRAINT webhook_idempotency_key_pkey PRIMARY KEY ("workflowId", "nodeId", key);
+
+
+--
+-- Name: webhook_queue webhook_queue_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_queue
+    ADD CONSTRAINT webhook_queue_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_response webhook_response_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_response
+    ADD CONSTRAINT webhook_response_pkey PRIMARY KEY ("executionId");
+
+
 --
 -- Name: workflow_entity workflow_entity_pkey; Type: CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -907,7 +1068,21 @@ CREATE INDEX idx_16f4436789e804e3e1c9eeb240 ON workflow.webhook_entity USING btr
 -- Name: idx_812eb05f7451ca757fb98444ce; Type: INDEX; Schema: workflow; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_812eb05f7451ca757fb98444ce ON workflow.tag_entity USING btree (name);
+CREATE UNIQUE INDEX idx_812eb05f7451ca757fb98444ce ON workflow.tag_entity USING btree ("sugerOrgId", name);
+
+
+--
+-- Name: idx_approval_execution_id_node_name_run_index; Type: INDEX; Schema: workflow; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_approval_execution_id_node_name_run_index ON workflow.approval USING btree ("executionId", "nodeName", "runIndex");
+
+
+--
+-- Name: idx_execution_entity_status_wait_till; Type: INDEX; Schema: workflow; Owner: -
+--
+
+CREATE INDEX idx_execution_entity_status_wait_till ON workflow.execution_entity USING btree (status, "waitTill");
 
 
 --
@@ -918,17 +1093,31 @@ CREATE INDEX idx_execution_entity_workflow_id_id ON workflow.execution_entity US
 
 
 --
--- Name: idx_shared_credentials_credentials_id; Type: INDEX; Schema: workflow; Owner: -
+-- Name: idx_execution_metadata_execution_id_key; Type: INDEX; Schema: workflow; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_execution_metadata_execution_id_key ON workflow.execution_metadata USING btree ("executionId", key);
+
+
+--
+-- Name: idx_execution_metadata_key_value; Type: INDEX; Schema: workflow; Owner: -
 --
 
-CREATE INDEX idx_shared_credentials_credentials_id ON workflow.shared_credentials USING btree ("credentialsId");
+CREATE INDEX idx_execution_metadata_key_value ON workflow.execution_metadata USING btree (key, value);
 
 
 --
--- Name: idx_shared_workflow_workflow_id; Type: INDEX; Schema: workflow; Owner: -
+-- Name: idx_webhook_queue_org_id_workflow_id_status; Type: INDEX; Schema: workflow; Owner: -
 --
 
-CREATE INDEX idx_shared_workflow_workflow_id ON workflow.shared_workflow USING btree ("workflowId");
+CREATE INDEX idx_webhook_queue_org_id_workflow_id_status ON workflow.webhook_queue USING btree ("sugerOrgId", "workflowId", status);
+
+
+--
+-- Name: idx_webhook_queue_status_id; Type: INDEX; Schema: workflow; Owner: -
+--
+
+CREATE INDEX idx_webhook_queue_status_id ON workflow.webhook_queue USING btree (status, id);
 
 
 --
@@ -938,6 +1127,13 @@ CREATE INDEX idx_shared_workflow_workflow_id ON workflow.shared_workflow USING b
 CREATE INDEX idx_workflows_tags_workflow_id ON workflow.workflows_tags USING btree ("workflowId");
 
 
+--
+-- Name: idx_workflows_tags_tag_id; Type: INDEX; Schema: workflow; Owner: -
+--
+
+CREATE INDEX idx_workflows_tags_tag_id ON workflow.workflows_tags USING btree ("tagId");
+
+
 --
 -- Name: pk_credentials_entity_id; Type: INDEX; Schema: workflow; Owner: -
 --
@@ -982,22 +1178,6 @@ ALTER TABLE ONLY workflow.workflow_history
     ADD CONSTRAINT "FK_1e31657f5fe46816c34be7c1b4b" FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
 
 
---
--- Name: shared_workflow FK_3540da03964527aa24ae014b780; Type: FK CONSTRAINT; Schema: workflow; Owner: -
---
-
-ALTER TABLE ONLY workflow.shared_workflow
-    ADD CONSTRAINT "FK_3540da03964527aa24ae014b780" FOREIGN KEY ("roleId") REFERENCES workflow.role(id);
-
-
---
--- Name: shared_credentials FK_484f0327e778648dd04f1d70493; Type: FK CONSTRAINT; Schema: workflow; Owner: -
---
-
-ALTER TABLE ONLY workflow.shared_credentials
-    ADD CONSTRAINT "FK_484f0327e778648dd04f1d70493" FOREIGN KEY ("userId") REFERENCES workflow."user"(id) ON DELETE CASCADE;
-
-
 --
 -- Name: installed_nodes FK_73f857fc5dce682cef8a99c11dbddbc969618951; Type: FK CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -1006,22 +1186,6 @@ ALTER TABLE ONLY workflow.installed_nodes
     ADD CONSTRAINT "FK_73f857fc5dce682cef8a99c11dbddbc969618951" FOREIGN KEY (package) REFERENCES workflow.installed_packages("packageName") ON UPDATE CASCADE ON DELETE CASCADE;
 
 
---
--- Name: shared_workflow FK_82b2fd9ec4e3e24209af8160282; Type: FK CONSTRAINT; Schema: workflow; Owner: -
---
-
-ALTER TABLE ONLY workflow.shared_workflow
-    ADD CONSTRAINT "FK_82b2fd9ec4e3e24209af8160282" FOREIGN KEY ("userId") REFERENCES workflow."user"(id) ON DELETE CASCADE;
-
-
---
--- Name: shared_credentials FK_c68e056637562000b68f480815a; Type: FK CONSTRAINT; Schema: workflow; Owner: -
---
-
-ALTER TABLE ONLY workflow.shared_credentials
-    ADD CONSTRAINT "FK_c68e056637562000b68f480815a" FOREIGN KEY ("roleId") REFERENCES workflow.role(id);
-
-
 --
 -- Name: user FK_f0609be844f9200ff4365b1bb3d; Type: FK CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -1054,6 +1218,14 @@ ALTER TABLE ONLY workflow.execution_metadata
     ADD CONSTRAINT execution_metadata_fk FOREIGN KEY ("executionId") REFERENCES workflow.execution_entity(id) ON DELETE CASCADE;
 
 
+--
+-- Name: approval fk_approval_execution_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.approval
+    ADD CONSTRAINT fk_approval_execution_id FOREIGN KEY ("executionId") REFERENCES workflow.execution_entity(id) ON DELETE CASCADE;
+
+
 --
 -- Name: execution_entity fk_execution_entity_workflow_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -1086,6 +1258,30 @@ ALTER TABLE ONLY workflow.webhook_entity
     ADD CONSTRAINT fk_webhook_entity_workflow_id FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
 
 
+--
+-- Name: webhook_idempotency_key fk_webhook_idempotency_key_workflow_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_idempotency_key
+    ADD CONSTRAINT fk_webhook_idempotency_key_workflow_id FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_queue fk_webhook_queue_workflow_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_queue
+    ADD CONSTRAINT fk_webhook_queue_workflow_id FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_response fk_webhook_response_execution_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.webhook_response
+    ADD CONSTRAINT fk_webhook_response_execution_id FOREIGN KEY ("executionId") REFERENCES workflow.execution_entity(id) ON DELETE CASCADE;
+
+
 --
 -- Name: workflow_statistics fk_workflow_statistics_workflow_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
 --
@@ -1094,6 +1290,14 @@ ALTER TABLE ONLY workflow.workflow_statistics
     ADD CONSTRAINT fk_workflow_statistics_workflow_id FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
 
 
+--
+-- Name: workflow_statistics_hourly fk_workflow_statistics_hourly_workflow_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
+--
+
+ALTER TABLE ONLY workflow.workflow_statistics_hourly
+    ADD CONSTRAINT fk_workflow_statistics_hourly_workflow_id FOREIGN KEY ("workflowId") REFERENCES workflow.workflow_entity(id) ON DELETE CASCADE;
+
+
 --
 -- Name: workflows_tags fk_workflows_tags_tag_id; Type: FK CONSTRAINT; Schema: workflow; Owner: -
 --
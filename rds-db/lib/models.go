@@ -80,6 +80,23 @@ type IdentityUserOrganization struct {
 	LastUpdateTime     time.Time `db:"last_update_time" json:"lastUpdateTime"`
 }
 
+type WorkflowApproval struct {
+	ID             string         `db:"id" json:"id"`
+	ExecutionId    int32          `db:"executionId" json:"executionId"`
+	WorkflowId     string         `db:"workflowId" json:"workflowId"`
+	NodeName       string         `db:"nodeName" json:"nodeName"`
+	RunIndex       int32          `db:"runIndex" json:"runIndex"`
+	Message        string         `db:"message" json:"message"`
+	RequireComment bool           `db:"requireComment" json:"requireComment"`
+	Status         string         `db:"status" json:"status"`
+	ExpiresAt      time.Time      `db:"expiresAt" json:"expiresAt"`
+	DecidedAt      sql.NullTime   `db:"decidedAt" json:"decidedAt"`
+	Comment        sql.NullString `db:"comment" json:"comment"`
+	ClientIp       sql.NullString `db:"clientIp" json:"clientIp"`
+	UserAgent      sql.NullString `db:"userAgent" json:"userAgent"`
+	CreatedAt      time.Time      `db:"createdAt" json:"createdAt"`
+}
+
 type WorkflowAuthIdentity struct {
 	UserId       uuid.NullUUID `db:"userId" json:"userId"`
 	ProviderId   string        `db:"providerId" json:"providerId"`
@@ -110,6 +127,7 @@ type WorkflowCredentialsEntity struct {
 	CreatedAt   time.Time       `db:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time       `db:"updatedAt" json:"updatedAt"`
 	ID          string          `db:"id" json:"id"`
+	SugerOrgId  string          `db:"sugerOrgId" json:"sugerOrgId"`
 }
 
 type WorkflowEventDestination struct {
@@ -126,17 +144,19 @@ type WorkflowExecutionDatum struct {
 }
 
 type WorkflowExecutionEntity struct {
-	ID             int32          `db:"id" json:"id"`
-	Finished       bool           `db:"finished" json:"finished"`
-	Mode           string         `db:"mode" json:"mode"`
-	RetryOf        sql.NullString `db:"retryOf" json:"retryOf"`
-	RetrySuccessId sql.NullString `db:"retrySuccessId" json:"retrySuccessId"`
-	StartedAt      time.Time      `db:"startedAt" json:"startedAt"`
-	StoppedAt      sql.NullTime   `db:"stoppedAt" json:"stoppedAt"`
-	WaitTill       sql.NullTime   `db:"waitTill" json:"waitTill"`
-	Status         sql.NullString `db:"status" json:"status"`
-	WorkflowId     string         `db:"workflowId" json:"workflowId"`
-	DeletedAt      sql.NullTime   `db:"deletedAt" json:"deletedAt"`
+	ID                int32          `db:"id" json:"id"`
+	Finished          bool           `db:"finished" json:"finished"`
+	Mode              string         `db:"mode" json:"mode"`
+	RetryOf           sql.NullString `db:"retryOf" json:"retryOf"`
+	RetrySuccessId    sql.NullString `db:"retrySuccessId" json:"retrySuccessId"`
+	StartedAt         time.Time      `db:"startedAt" json:"startedAt"`
+	StoppedAt         sql.NullTime   `db:"stoppedAt" json:"stoppedAt"`
+	WaitTill          sql.NullTime   `db:"waitTill" json:"waitTill"`
+	Status            sql.NullString `db:"status" json:"status"`
+	WorkflowId        string         `db:"workflowId" json:"workflowId"`
+	DeletedAt         sql.NullTime   `db:"deletedAt" json:"deletedAt"`
+	WorkflowVersionId sql.NullString `db:"workflowVersionId" json:"workflowVersionId"`
+	StartedBy         sql.NullString `db:"startedBy" json:"startedBy"`
 }
 
 type WorkflowExecutionMetadatum struct {
@@ -185,24 +205,29 @@ type WorkflowSetting struct {
 type WorkflowSharedCredential struct {
 	CreatedAt     time.Time `db:"createdAt" json:"createdAt"`
 	UpdatedAt     time.Time `db:"updatedAt" json:"updatedAt"`
-	RoleId        int32     `db:"roleId" json:"roleId"`
-	UserId        uuid.UUID `db:"userId" json:"userId"`
 	CredentialsId string    `db:"credentialsId" json:"credentialsId"`
+	SugerOrgId    string    `db:"sugerOrgId" json:"sugerOrgId"`
+	GranteeType   string    `db:"granteeType" json:"granteeType"`
+	GranteeId     string    `db:"granteeId" json:"granteeId"`
+	Role          string    `db:"role" json:"role"`
 }
 
 type WorkflowSharedWorkflow struct {
-	CreatedAt  time.Time `db:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time `db:"updatedAt" json:"updatedAt"`
-	RoleId     int32     `db:"roleId" json:"roleId"`
-	UserId     uuid.UUID `db:"userId" json:"userId"`
-	WorkflowId string    `db:"workflowId" json:"workflowId"`
+	CreatedAt   time.Time `db:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time `db:"updatedAt" json:"updatedAt"`
+	WorkflowId  string    `db:"workflowId" json:"workflowId"`
+	SugerOrgId  string    `db:"sugerOrgId" json:"sugerOrgId"`
+	GranteeType string    `db:"granteeType" json:"granteeType"`
+	GranteeId   string    `db:"granteeId" json:"granteeId"`
+	Role        string    `db:"role" json:"role"`
 }
 
 type WorkflowTagEntity struct {
-	Name      string    `db:"name" json:"name"`
-	CreatedAt time.Time `db:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time `db:"updatedAt" json:"updatedAt"`
-	ID        string    `db:"id" json:"id"`
+	Name       string    `db:"name" json:"name"`
+	CreatedAt  time.Time `db:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time `db:"updatedAt" json:"updatedAt"`
+	ID         string    `db:"id" json:"id"`
+	SugerOrgId string    `db:"sugerOrgId" json:"sugerOrgId"`
 }
 
 type WorkflowUser struct {
@@ -224,10 +249,11 @@ type WorkflowUser struct {
 }
 
 type WorkflowVariable struct {
-	Key   string         `db:"key" json:"key"`
-	Type  string         `db:"type" json:"type"`
-	Value sql.NullString `db:"value" json:"value"`
-	ID    string         `db:"id" json:"id"`
+	Key        string         `db:"key" json:"key"`
+	Type       string         `db:"type" json:"type"`
+	Value      sql.NullString `db:"value" json:"value"`
+	ID         string         `db:"id" json:"id"`
+	SugerOrgId string         `db:"sugerOrgId" json:"sugerOrgId"`
 }
 
 type WorkflowWebhookEntity struct {
@@ -239,6 +265,41 @@ type WorkflowWebhookEntity struct {
 	WorkflowId  string         `db:"workflowId" json:"workflowId"`
 }
 
+type WorkflowWebhookIdempotencyKey struct {
+	WorkflowId          string         `db:"workflowId" json:"workflowId"`
+	NodeId              string         `db:"nodeId" json:"nodeId"`
+	Key                 string         `db:"key" json:"key"`
+	ExecutionId         sql.NullString `db:"executionId" json:"executionId"`
+	ResponseStatusCode  sql.NullInt32  `db:"responseStatusCode" json:"responseStatusCode"`
+	ResponseContentType sql.NullString `db:"responseContentType" json:"responseContentType"`
+	ResponseBody        []byte         `db:"responseBody" json:"responseBody"`
+	CreatedAt           time.Time      `db:"createdAt" json:"createdAt"`
+	ExpiresAt           time.Time      `db:"expiresAt" json:"expiresAt"`
+}
+
+type WorkflowWebhookQueue struct {
+	ID             int32           `db:"id" json:"id"`
+	WorkflowId     string          `db:"workflowId" json:"workflowId"`
+	NodeId         string          `db:"nodeId" json:"nodeId"`
+	SugerOrgId     string          `db:"sugerOrgId" json:"sugerOrgId"`
+	Request        []byte          `db:"request" json:"request"`
+	ClientIp       string          `db:"clientIp" json:"clientIp"`
+	Params         json.RawMessage `db:"params" json:"params"`
+	IdempotencyKey sql.NullString  `db:"idempotencyKey" json:"idempotencyKey"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int32           `db:"attempts" json:"attempts"`
+	CreatedAt      time.Time       `db:"createdAt" json:"createdAt"`
+	HeartbeatAt    sql.NullTime    `db:"heartbeatAt" json:"heartbeatAt"`
+}
+
+type WorkflowWebhookResponse struct {
+	ExecutionId int32           `db:"executionId" json:"executionId"`
+	StatusCode  int32           `db:"statusCode" json:"statusCode"`
+	Headers     json.RawMessage `db:"headers" json:"headers"`
+	Body        []byte          `db:"body" json:"body"`
+	CreatedAt   time.Time       `db:"createdAt" json:"createdAt"`
+}
+
 type WorkflowWorkflowEntity struct {
 	Name         string                `db:"name" json:"name"`
 	Active       bool                  `db:"active" json:"active"`
@@ -273,6 +334,14 @@ type WorkflowWorkflowStatistic struct {
 	WorkflowId  string        `db:"workflowId" json:"workflowId"`
 }
 
+type WorkflowWorkflowStatisticsHourly struct {
+	WorkflowId     string    `db:"workflowId" json:"workflowId"`
+	Hour           time.Time `db:"hour" json:"hour"`
+	Status         string    `db:"status" json:"status"`
+	DurationBucket int16     `db:"durationBucket" json:"durationBucket"`
+	Count          int32     `db:"count" json:"count"`
+}
+
 type WorkflowWorkflowsTag struct {
 	WorkflowId string `db:"workflowId" json:"workflowId"`
 	TagId      string `db:"tagId" json:"tagId"`
@@ -10,9 +10,39 @@ import (
 	"database/sql"
 	"encoding/json"
 
+	"github.com/lib/pq"
 	"github.com/sqlc-dev/pqtype"
 )
 
+const CountWorkflowEntitiesWithFilter = `-- name: CountWorkflowEntitiesWithFilter :one
+SELECT COUNT(*) FROM workflow.workflow_entity
+    WHERE "sugerOrgId" = $1
+    AND ($2::text = '' OR name ILIKE '%' || $2::text || '%')
+    AND ($3::boolean IS NULL OR active = $3::boolean)
+    AND (cardinality($4::text[]) = 0 OR id IN (
+        SELECT "workflowId" FROM workflow.workflows_tags WHERE "tagId" = ANY($4::text[])
+        GROUP BY "workflowId" HAVING COUNT(*) = cardinality($4::text[])))
+`
+
+type CountWorkflowEntitiesWithFilterParams struct {
+	SugerOrgId string       `db:"suger_org_id" json:"sugerOrgId"`
+	Name       string       `db:"name" json:"name"`
+	Active     sql.NullBool `db:"active" json:"active"`
+	TagIds     []string     `db:"tag_ids" json:"tagIds"`
+}
+
+func (q *Queries) CountWorkflowEntitiesWithFilter(ctx context.Context, arg CountWorkflowEntitiesWithFilterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountWorkflowEntitiesWithFilter,
+		arg.SugerOrgId,
+		arg.Name,
+		arg.Active,
+		pq.Array(arg.TagIds),
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const CreateWorkflowEntity = `-- name: CreateWorkflowEntity :one
 INSERT INTO workflow.workflow_entity(name, active, nodes, connections, settings, "staticData", "pinData", "versionId", "triggerCount", id, meta, "sugerOrgId")
     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING name, active, nodes, connections, "createdAt", "updatedAt", settings, "staticData", "pinData", "versionId", "triggerCount", id, meta, "sugerOrgId"
@@ -282,6 +312,72 @@ func (q *Queries) ListWorkflowEntities(ctx context.Context, sugerorgid string) (
 	return items, nil
 }
 
+const ListWorkflowEntitiesWithFilter = `-- name: ListWorkflowEntitiesWithFilter :many
+SELECT name, active, nodes, connections, "createdAt", "updatedAt", settings, "staticData", "pinData", "versionId", "triggerCount", id, meta, "sugerOrgId" FROM workflow.workflow_entity
+    WHERE "sugerOrgId" = $1
+    AND ($2::text = '' OR name ILIKE '%' || $2::text || '%')
+    AND ($3::boolean IS NULL OR active = $3::boolean)
+    AND (cardinality($4::text[]) = 0 OR id IN (
+        SELECT "workflowId" FROM workflow.workflows_tags WHERE "tagId" = ANY($4::text[])
+        GROUP BY "workflowId" HAVING COUNT(*) = cardinality($4::text[])))
+    ORDER BY "updatedAt" DESC, id
+    LIMIT $5 OFFSET $6
+`
+
+type ListWorkflowEntitiesWithFilterParams struct {
+	SugerOrgId string       `db:"suger_org_id" json:"sugerOrgId"`
+	Name       string       `db:"name" json:"name"`
+	Active     sql.NullBool `db:"active" json:"active"`
+	TagIds     []string     `db:"tag_ids" json:"tagIds"`
+	PageLimit  int32        `db:"page_limit" json:"pageLimit"`
+	PageOffset int32        `db:"page_offset" json:"pageOffset"`
+}
+
+func (q *Queries) ListWorkflowEntitiesWithFilter(ctx context.Context, arg ListWorkflowEntitiesWithFilterParams) ([]WorkflowWorkflowEntity, error) {
+	rows, err := q.db.QueryContext(ctx, ListWorkflowEntitiesWithFilter,
+		arg.SugerOrgId,
+		arg.Name,
+		arg.Active,
+		pq.Array(arg.TagIds),
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WorkflowWorkflowEntity{}
+	for rows.Next() {
+		var i WorkflowWorkflowEntity
+		if err := rows.Scan(
+			&i.Name,
+			&i.Active,
+			&i.Nodes,
+			&i.Connections,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Settings,
+			&i.StaticData,
+			&i.PinData,
+			&i.VersionId,
+			&i.TriggerCount,
+			&i.ID,
+			&i.Meta,
+			&i.SugerOrgId,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const UpdateWorkflowEntity = `-- name: UpdateWorkflowEntity :one
 UPDATE workflow.workflow_entity SET name = $3, active = $4, nodes = $5, connections = $6, settings = $7, "staticData" = $8, "pinData" = $9, "versionId" = $10, "triggerCount" = $11, meta = $12, "updatedAt" = CURRENT_TIMESTAMP
     WHERE "sugerOrgId" = $1 and id = $2 RETURNING name, active, nodes, connections, "createdAt", "updatedAt", settings, "staticData", "pinData", "versionId", "triggerCount", id, meta, "sugerOrgId"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
 const BatchDeleteWorkflowExecutionEntities = `-- name: BatchDeleteWorkflowExecutionEntities :exec
@@ -27,6 +28,20 @@ func (q *Queries) BatchDeleteWorkflowExecutionEntities(ctx context.Context, arg
 	return err
 }
 
+const ClaimWaitingWorkflowExecutionEntity = `-- name: ClaimWaitingWorkflowExecutionEntity :one
+UPDATE workflow.execution_entity e SET status = 'running', "waitTill" = NULL
+    FROM (SELECT id, "waitTill" FROM workflow.execution_entity WHERE id = $1 FOR UPDATE) claimed
+    WHERE e.id = claimed.id AND e.status = 'waiting'
+    RETURNING claimed."waitTill"
+`
+
+func (q *Queries) ClaimWaitingWorkflowExecutionEntity(ctx context.Context, id int32) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, ClaimWaitingWorkflowExecutionEntity, id)
+	var waitTill sql.NullTime
+	err := row.Scan(&waitTill)
+	return waitTill, err
+}
+
 const CountWorkflowExecutionEntitiesByWorkflowId = `-- name: CountWorkflowExecutionEntitiesByWorkflowId :one
 SELECT count(*) FROM workflow.execution_entity WHERE "workflowId" = $1
 `
@@ -38,22 +53,83 @@ func (q *Queries) CountWorkflowExecutionEntitiesByWorkflowId(ctx context.Context
 	return count, err
 }
 
+const CountWorkflowExecutionEntitiesWithFilter = `-- name: CountWorkflowExecutionEntitiesWithFilter :one
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM workflow.execution_entity e
+        JOIN workflow.workflow_entity w ON w.id = e."workflowId"
+        WHERE w."sugerOrgId" = $1
+        AND ($2::text = '' OR e."workflowId" = $2::text)
+        AND ($3::integer = 0 OR e.id = $3::integer)
+        AND (cardinality($4::text[]) = 0 OR e.status = ANY($4::text[]))
+        AND ($5::text = '' OR e.mode = $5::text)
+        AND ($6::text = '' OR e."retryOf" = $6::text)
+        AND ($7::text = '' OR e."retrySuccessId" = $7::text)
+        AND (NOT $8::boolean OR e.finished)
+        AND ($9::timestamptz IS NULL OR e."startedAt" >= $9::timestamptz)
+        AND ($10::timestamptz IS NULL OR e."startedAt" <= $10::timestamptz)
+        AND (cardinality($11::text[]) = 0 OR e.id IN (
+            SELECT m."executionId" FROM workflow.execution_metadata m
+                JOIN unnest($11::text[], $12::text[]) AS f(key, value) ON m.key = f.key AND m.value = f.value
+                GROUP BY m."executionId" HAVING COUNT(*) = cardinality($11::text[])))
+        LIMIT $13
+) c
+`
+
+type CountWorkflowExecutionEntitiesWithFilterParams struct {
+	SugerOrgId     string       `db:"suger_org_id" json:"sugerOrgId"`
+	WorkflowID     string       `db:"workflow_id" json:"workflowID"`
+	ExecutionID    int32        `db:"execution_id" json:"executionID"`
+	Statuses       []string     `db:"statuses" json:"statuses"`
+	Mode           string       `db:"mode" json:"mode"`
+	RetryOf        string       `db:"retry_of" json:"retryOf"`
+	RetrySuccessId string       `db:"retry_success_id" json:"retrySuccessId"`
+	Finished       bool         `db:"finished" json:"finished"`
+	StartedAfter   sql.NullTime `db:"started_after" json:"startedAfter"`
+	StartedBefore  sql.NullTime `db:"started_before" json:"startedBefore"`
+	MetadataKeys   []string     `db:"metadata_keys" json:"metadataKeys"`
+	MetadataValues []string     `db:"metadata_values" json:"metadataValues"`
+	CountLimit     int32        `db:"count_limit" json:"countLimit"`
+}
+
+func (q *Queries) CountWorkflowExecutionEntitiesWithFilter(ctx context.Context, arg CountWorkflowExecutionEntitiesWithFilterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountWorkflowExecutionEntitiesWithFilter,
+		arg.SugerOrgId,
+		arg.WorkflowID,
+		arg.ExecutionID,
+		pq.Array(arg.Statuses),
+		arg.Mode,
+		arg.RetryOf,
+		arg.RetrySuccessId,
+		arg.Finished,
+		arg.StartedAfter,
+		arg.StartedBefore,
+		pq.Array(arg.MetadataKeys),
+		pq.Array(arg.MetadataValues),
+		arg.CountLimit,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const CreateWorkflowExecutionEntity = `-- name: CreateWorkflowExecutionEntity :one
-INSERT INTO workflow.execution_entity(finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt")
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt"
+INSERT INTO workflow.execution_entity(finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy")
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy"
 `
 
 type CreateWorkflowExecutionEntityParams struct {
-	Finished       bool           `db:"finished" json:"finished"`
-	Mode           string         `db:"mode" json:"mode"`
-	RetryOf        sql.NullString `db:"retryOf" json:"retryOf"`
-	RetrySuccessId sql.NullString `db:"retrySuccessId" json:"retrySuccessId"`
-	StartedAt      time.Time      `db:"startedAt" json:"startedAt"`
-	StoppedAt      sql.NullTime   `db:"stoppedAt" json:"stoppedAt"`
-	WaitTill       sql.NullTime   `db:"waitTill" json:"waitTill"`
-	Status         sql.NullString `db:"status" json:"status"`
-	WorkflowId     string         `db:"workflowId" json:"workflowId"`
-	DeletedAt      sql.NullTime   `db:"deletedAt" json:"deletedAt"`
+	Finished          bool           `db:"finished" json:"finished"`
+	Mode              string         `db:"mode" json:"mode"`
+	RetryOf           sql.NullString `db:"retryOf" json:"retryOf"`
+	RetrySuccessId    sql.NullString `db:"retrySuccessId" json:"retrySuccessId"`
+	StartedAt         time.Time      `db:"startedAt" json:"startedAt"`
+	StoppedAt         sql.NullTime   `db:"stoppedAt" json:"stoppedAt"`
+	WaitTill          sql.NullTime   `db:"waitTill" json:"waitTill"`
+	Status            sql.NullString `db:"status" json:"status"`
+	WorkflowId        string         `db:"workflowId" json:"workflowId"`
+	DeletedAt         sql.NullTime   `db:"deletedAt" json:"deletedAt"`
+	WorkflowVersionId sql.NullString `db:"workflowVersionId" json:"workflowVersionId"`
+	StartedBy         sql.NullString `db:"startedBy" json:"startedBy"`
 }
 
 func (q *Queries) CreateWorkflowExecutionEntity(ctx context.Context, arg CreateWorkflowExecutionEntityParams) (WorkflowExecutionEntity, error) {
@@ -68,6 +144,8 @@ func (q *Queries) CreateWorkflowExecutionEntity(ctx context.Context, arg CreateW
 		arg.Status,
 		arg.WorkflowId,
 		arg.DeletedAt,
+		arg.WorkflowVersionId,
+		arg.StartedBy,
 	)
 	var i WorkflowExecutionEntity
 	err := row.Scan(
@@ -82,6 +160,8 @@ func (q *Queries) CreateWorkflowExecutionEntity(ctx context.Context, arg CreateW
 		&i.Status,
 		&i.WorkflowId,
 		&i.DeletedAt,
+		&i.WorkflowVersionId,
+		&i.StartedBy,
 	)
 	return i, err
 }
@@ -101,7 +181,7 @@ func (q *Queries) DeleteWorkflowExecutionEntity(ctx context.Context, arg DeleteW
 }
 
 const GetWorkflowExecutionEntity = `-- name: GetWorkflowExecutionEntity :one
-SELECT id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt" FROM workflow.execution_entity WHERE id = $1
+SELECT id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy" FROM workflow.execution_entity WHERE id = $1
 `
 
 func (q *Queries) GetWorkflowExecutionEntity(ctx context.Context, id int32) (WorkflowExecutionEntity, error) {
@@ -119,12 +199,46 @@ func (q *Queries) GetWorkflowExecutionEntity(ctx context.Context, id int32) (Wor
 		&i.Status,
 		&i.WorkflowId,
 		&i.DeletedAt,
+		&i.WorkflowVersionId,
+		&i.StartedBy,
 	)
 	return i, err
 }
 
+const ListWaitingWorkflowExecutionEntityIds = `-- name: ListWaitingWorkflowExecutionEntityIds :many
+SELECT id FROM workflow.execution_entity WHERE status = 'waiting' AND "waitTill" <= $1 ORDER BY "waitTill" LIMIT $2
+`
+
+type ListWaitingWorkflowExecutionEntityIdsParams struct {
+	WaitTill sql.NullTime `db:"waitTill" json:"waitTill"`
+	Limit    int32        `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListWaitingWorkflowExecutionEntityIds(ctx context.Context, arg ListWaitingWorkflowExecutionEntityIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, ListWaitingWorkflowExecutionEntityIds, arg.WaitTill, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListWorkflowExecutionEntitiesByWorkflowId = `-- name: ListWorkflowExecutionEntitiesByWorkflowId :many
-SELECT id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt" FROM workflow.execution_entity WHERE "workflowId" = $1 ORDER BY "startedAt" DESC LIMIT $2 OFFSET $3
+SELECT id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy" FROM workflow.execution_entity WHERE "workflowId" = $1 ORDER BY "startedAt" DESC LIMIT $2 OFFSET $3
 `
 
 type ListWorkflowExecutionEntitiesByWorkflowIdParams struct {
@@ -154,6 +268,8 @@ func (q *Queries) ListWorkflowExecutionEntitiesByWorkflowId(ctx context.Context,
 			&i.Status,
 			&i.WorkflowId,
 			&i.DeletedAt,
+			&i.WorkflowVersionId,
+			&i.StartedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -168,9 +284,151 @@ func (q *Queries) ListWorkflowExecutionEntitiesByWorkflowId(ctx context.Context,
 	return items, nil
 }
 
+const ListWorkflowExecutionEntitiesWithFilter = `-- name: ListWorkflowExecutionEntitiesWithFilter :many
+SELECT e.id, e.finished, e.mode, e."retryOf", e."retrySuccessId", e."startedAt", e."stoppedAt", e."waitTill", e.status, e."workflowId", e."deletedAt", e."workflowVersionId", e."startedBy", e."workflowName",
+    r.data ->> 'lastNodeExecuted' AS "lastNodeExecuted",
+    r.data ->> 'error' AS error,
+    r.data -> 'runData' -> (r.data ->> 'lastNodeExecuted') -> -1 -> 'error' AS "executionError"
+FROM (
+    SELECT e.id, e.finished, e.mode, e."retryOf", e."retrySuccessId", e."startedAt", e."stoppedAt", e."waitTill", e.status, e."workflowId", e."deletedAt", e."workflowVersionId", e."startedBy", w.name AS "workflowName" FROM workflow.execution_entity e
+        JOIN workflow.workflow_entity w ON w.id = e."workflowId"
+        WHERE w."sugerOrgId" = $1
+        AND ($2::text = '' OR e."workflowId" = $2::text)
+        AND ($3::integer = 0 OR e.id = $3::integer)
+        AND (cardinality($4::text[]) = 0 OR e.status = ANY($4::text[]))
+        AND ($5::text = '' OR e.mode = $5::text)
+        AND ($6::text = '' OR e."retryOf" = $6::text)
+        AND ($7::text = '' OR e."retrySuccessId" = $7::text)
+        AND (NOT $8::boolean OR e.finished)
+        AND ($9::timestamptz IS NULL OR e."startedAt" >= $9::timestamptz)
+        AND ($10::timestamptz IS NULL OR e."startedAt" <= $10::timestamptz)
+        AND (cardinality($11::text[]) = 0 OR e.id IN (
+            SELECT m."executionId" FROM workflow.execution_metadata m
+                JOIN unnest($11::text[], $12::text[]) AS f(key, value) ON m.key = f.key AND m.value = f.value
+                GROUP BY m."executionId" HAVING COUNT(*) = cardinality($11::text[])))
+        AND ($13::integer = 0 OR e.id < $13::integer)
+        AND ($14::integer = 0 OR e.id > $14::integer)
+        ORDER BY e.id DESC
+        LIMIT $15
+) e
+    LEFT JOIN workflow.execution_data d ON d."executionId" = e.id
+    LEFT JOIN LATERAL (SELECT CASE WHEN left(d.data, 1) = '{' THEN d.data::json -> 'resultData' END AS data) r ON true
+    ORDER BY e.id DESC
+`
+
+type ListWorkflowExecutionEntitiesWithFilterParams struct {
+	SugerOrgId     string       `db:"suger_org_id" json:"sugerOrgId"`
+	WorkflowID     string       `db:"workflow_id" json:"workflowID"`
+	ExecutionID    int32        `db:"execution_id" json:"executionID"`
+	Statuses       []string     `db:"statuses" json:"statuses"`
+	Mode           string       `db:"mode" json:"mode"`
+	RetryOf        string       `db:"retry_of" json:"retryOf"`
+	RetrySuccessId string       `db:"retry_success_id" json:"retrySuccessId"`
+	Finished       bool         `db:"finished" json:"finished"`
+	StartedAfter   sql.NullTime `db:"started_after" json:"startedAfter"`
+	StartedBefore  sql.NullTime `db:"started_before" json:"startedBefore"`
+	MetadataKeys   []string     `db:"metadata_keys" json:"metadataKeys"`
+	MetadataValues []string     `db:"metadata_values" json:"metadataValues"`
+	LastID         int32        `db:"last_id" json:"lastID"`
+	FirstID        int32        `db:"first_id" json:"firstID"`
+	PageLimit      int32        `db:"page_limit" json:"pageLimit"`
+}
+
+type ListWorkflowExecutionEntitiesWithFilterRow struct {
+	ID                int32                 `db:"id" json:"id"`
+	Finished          bool                  `db:"finished" json:"finished"`
+	Mode              string                `db:"mode" json:"mode"`
+	RetryOf           sql.NullString        `db:"retryOf" json:"retryOf"`
+	RetrySuccessId    sql.NullString        `db:"retrySuccessId" json:"retrySuccessId"`
+	StartedAt         time.Time             `db:"startedAt" json:"startedAt"`
+	StoppedAt         sql.NullTime          `db:"stoppedAt" json:"stoppedAt"`
+	WaitTill          sql.NullTime          `db:"waitTill" json:"waitTill"`
+	Status            sql.NullString        `db:"status" json:"status"`
+	WorkflowId        string                `db:"workflowId" json:"workflowId"`
+	DeletedAt         sql.NullTime          `db:"deletedAt" json:"deletedAt"`
+	WorkflowVersionId sql.NullString        `db:"workflowVersionId" json:"workflowVersionId"`
+	StartedBy         sql.NullString        `db:"startedBy" json:"startedBy"`
+	WorkflowName      string                `db:"workflowName" json:"workflowName"`
+	LastNodeExecuted  sql.NullString        `db:"lastNodeExecuted" json:"lastNodeExecuted"`
+	Error             sql.NullString        `db:"error" json:"error"`
+	ExecutionError    pqtype.NullRawMessage `db:"executionError" json:"executionError"`
+}
+
+func (q *Queries) ListWorkflowExecutionEntitiesWithFilter(ctx context.Context, arg ListWorkflowExecutionEntitiesWithFilterParams) ([]ListWorkflowExecutionEntitiesWithFilterRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListWorkflowExecutionEntitiesWithFilter,
+		arg.SugerOrgId,
+		arg.WorkflowID,
+		arg.ExecutionID,
+		pq.Array(arg.Statuses),
+		arg.Mode,
+		arg.RetryOf,
+		arg.RetrySuccessId,
+		arg.Finished,
+		arg.StartedAfter,
+		arg.StartedBefore,
+		pq.Array(arg.MetadataKeys),
+		pq.Array(arg.MetadataValues),
+		arg.LastID,
+		arg.FirstID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWorkflowExecutionEntitiesWithFilterRow{}
+	for rows.Next() {
+		var i ListWorkflowExecutionEntitiesWithFilterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Finished,
+			&i.Mode,
+			&i.RetryOf,
+			&i.RetrySuccessId,
+			&i.StartedAt,
+			&i.StoppedAt,
+			&i.WaitTill,
+			&i.Status,
+			&i.WorkflowId,
+			&i.DeletedAt,
+			&i.WorkflowVersionId,
+			&i.StartedBy,
+			&i.WorkflowName,
+			&i.LastNodeExecuted,
+			&i.Error,
+			&i.ExecutionError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ReleaseClaimedWorkflowExecutionEntity = `-- name: ReleaseClaimedWorkflowExecutionEntity :exec
+UPDATE workflow.execution_entity SET status = 'waiting', "waitTill" = $2
+    WHERE id = $1 AND status = 'running'
+`
+
+type ReleaseClaimedWorkflowExecutionEntityParams struct {
+	ID       int32        `db:"id" json:"id"`
+	WaitTill sql.NullTime `db:"waitTill" json:"waitTill"`
+}
+
+func (q *Queries) ReleaseClaimedWorkflowExecutionEntity(ctx context.Context, arg ReleaseClaimedWorkflowExecutionEntityParams) error {
+	_, err := q.db.ExecContext(ctx, ReleaseClaimedWorkflowExecutionEntity, arg.ID, arg.WaitTill)
+	return err
+}
+
 const UpdateWorkflowExecutionEntity = `-- name: UpdateWorkflowExecutionEntity :one
 UPDATE workflow.execution_entity SET finished = $2, mode = $3, "retryOf" = $4, "retrySuccessId" = $5, "stoppedAt" = $6, "waitTill" = $7, status = $8
-    WHERE id = $1 RETURNING id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt"
+    WHERE id = $1 RETURNING id, finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy"
 `
 
 type UpdateWorkflowExecutionEntityParams struct {
@@ -208,6 +466,8 @@ func (q *Queries) UpdateWorkflowExecutionEntity(ctx context.Context, arg UpdateW
 		&i.Status,
 		&i.WorkflowId,
 		&i.DeletedAt,
+		&i.WorkflowVersionId,
+		&i.StartedBy,
 	)
 	return i, err
 }
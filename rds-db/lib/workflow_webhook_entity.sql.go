@@ -143,3 +143,44 @@ func (q *Queries) ListWebhookEntities(ctx context.Context, arg ListWebhookEntiti
 	}
 	return items, nil
 }
+
+const ListWebhookEntitiesByOrgId_Method_PathLength = `-- name: ListWebhookEntitiesByOrgId_Method_PathLength :many
+SELECT "webhookPath", method, node, "webhookId", "pathLength", "workflowId" FROM workflow.webhook_entity
+    WHERE split_part("webhookPath", '/', 1) = $1::text AND method = $2 AND "pathLength" = $3
+`
+
+type ListWebhookEntitiesByOrgId_Method_PathLengthParams struct {
+	OrgID      string        `db:"org_id" json:"orgID"`
+	Method     string        `db:"method" json:"method"`
+	PathLength sql.NullInt32 `db:"path_length" json:"pathLength"`
+}
+
+func (q *Queries) ListWebhookEntitiesByOrgId_Method_PathLength(ctx context.Context, arg ListWebhookEntitiesByOrgId_Method_PathLengthParams) ([]WorkflowWebhookEntity, error) {
+	rows, err := q.db.QueryContext(ctx, ListWebhookEntitiesByOrgId_Method_PathLength, arg.OrgID, arg.Method, arg.PathLength)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WorkflowWebhookEntity{}
+	for rows.Next() {
+		var i WorkflowWebhookEntity
+		if err := rows.Scan(
+			&i.WebhookPath,
+			&i.Method,
+			&i.Node,
+			&i.WebhookId,
+			&i.PathLength,
+			&i.WorkflowId,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
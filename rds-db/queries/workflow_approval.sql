@@ -0,0 +1,23 @@
+-- name: CreateApproval :one
+INSERT INTO workflow.approval(id, "executionId", "workflowId", "nodeName", "runIndex", message, "requireComment", status, "expiresAt")
+    VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending', $8)
+    RETURNING *;
+
+-- name: GetApproval :one
+SELECT * FROM workflow.approval WHERE id = $1;
+
+-- name: GetApprovalByNode :one
+SELECT * FROM workflow.approval WHERE "executionId" = $1 AND "nodeName" = $2 AND "runIndex" = $3;
+
+-- name: ListApprovalsByExecutionId :many
+SELECT * FROM workflow.approval WHERE "executionId" = $1 ORDER BY "createdAt";
+
+-- name: DecideApproval :one
+UPDATE workflow.approval SET status = $2, "decidedAt" = CURRENT_TIMESTAMP(3), comment = $3, "clientIp" = $4, "userAgent" = $5
+    WHERE id = $1 AND status = 'pending' AND "expiresAt" > CURRENT_TIMESTAMP(3)
+    RETURNING *;
+
+-- name: ExpireApproval :one
+UPDATE workflow.approval SET status = 'timeout', "decidedAt" = CURRENT_TIMESTAMP(3)
+    WHERE id = $1 AND status = 'pending' AND "expiresAt" <= CURRENT_TIMESTAMP(3)
+    RETURNING *;
@@ -0,0 +1,6 @@
+-- name: GetCredentialsEntity :one
+SELECT * FROM workflow.credentials_entity WHERE "sugerOrgId" = $1 AND id = $2;
+
+-- name: CreateCredentialsEntity :one
+INSERT INTO workflow.credentials_entity(name, data, type, "nodesAccess", id, "sugerOrgId")
+    VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
@@ -56,3 +56,23 @@ UPDATE workflow.workflow_entity SET meta = $3, "updatedAt" = CURRENT_TIMESTAMP W
 
 -- name: DeleteWorkflowEntity :one
 DELETE FROM workflow.workflow_entity WHERE "sugerOrgId" = $1 and id = $2 RETURNING *;
+
+-- name: ListWorkflowEntitiesWithFilter :many
+SELECT * FROM workflow.workflow_entity
+    WHERE "sugerOrgId" = @suger_org_id
+    AND (@name::text = '' OR name ILIKE '%' || @name::text || '%')
+    AND (sqlc.narg('active')::boolean IS NULL OR active = sqlc.narg('active')::boolean)
+    AND (cardinality(@tag_ids::text[]) = 0 OR id IN (
+        SELECT "workflowId" FROM workflow.workflows_tags WHERE "tagId" = ANY(@tag_ids::text[])
+        GROUP BY "workflowId" HAVING COUNT(*) = cardinality(@tag_ids::text[])))
+    ORDER BY "updatedAt" DESC, id
+    LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountWorkflowEntitiesWithFilter :one
+SELECT COUNT(*) FROM workflow.workflow_entity
+    WHERE "sugerOrgId" = @suger_org_id
+    AND (@name::text = '' OR name ILIKE '%' || @name::text || '%')
+    AND (sqlc.narg('active')::boolean IS NULL OR active = sqlc.narg('active')::boolean)
+    AND (cardinality(@tag_ids::text[]) = 0 OR id IN (
+        SELECT "workflowId" FROM workflow.workflows_tags WHERE "tagId" = ANY(@tag_ids::text[])
+        GROUP BY "workflowId" HAVING COUNT(*) = cardinality(@tag_ids::text[])));
@@ -8,8 +8,8 @@ SELECT count(*) FROM workflow.execution_entity WHERE "workflowId" = $1;
 SELECT * FROM workflow.execution_entity WHERE id = $1;
 
 -- name: CreateWorkflowExecutionEntity :one
-INSERT INTO workflow.execution_entity(finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt")
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+INSERT INTO workflow.execution_entity(finished, mode, "retryOf", "retrySuccessId", "startedAt", "stoppedAt", "waitTill", status, "workflowId", "deletedAt", "workflowVersionId", "startedBy")
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
 
 -- name: DeleteWorkflowExecutionEntity :exec
 DELETE FROM workflow.execution_entity WHERE "workflowId" = $1 AND id = $2;
@@ -19,4 +19,69 @@ DELETE FROM workflow.execution_entity WHERE "workflowId" = $1 AND id = ANY(@exec
 
 -- name: UpdateWorkflowExecutionEntity :one
 UPDATE workflow.execution_entity SET finished = $2, mode = $3, "retryOf" = $4, "retrySuccessId" = $5, "stoppedAt" = $6, "waitTill" = $7, status = $8
-    WHERE id = $1 RETURNING *;
\ No newline at end of file
+    WHERE id = $1 RETURNING *;
+
+-- name: ListWorkflowExecutionEntitiesWithFilter :many
+SELECT e.*,
+    r.data ->> 'lastNodeExecuted' AS "lastNodeExecuted",
+    r.data ->> 'error' AS error,
+    r.data -> 'runData' -> (r.data ->> 'lastNodeExecuted') -> -1 -> 'error' AS "executionError"
+FROM (
+    SELECT e.*, w.name AS "workflowName" FROM workflow.execution_entity e
+        JOIN workflow.workflow_entity w ON w.id = e."workflowId"
+        WHERE w."sugerOrgId" = @suger_org_id
+        AND (@workflow_id::text = '' OR e."workflowId" = @workflow_id::text)
+        AND (@execution_id::integer = 0 OR e.id = @execution_id::integer)
+        AND (cardinality(@statuses::text[]) = 0 OR e.status = ANY(@statuses::text[]))
+        AND (@mode::text = '' OR e.mode = @mode::text)
+        AND (@retry_of::text = '' OR e."retryOf" = @retry_of::text)
+        AND (@retry_success_id::text = '' OR e."retrySuccessId" = @retry_success_id::text)
+        AND (NOT @finished::boolean OR e.finished)
+        AND (sqlc.narg('started_after')::timestamptz IS NULL OR e."startedAt" >= sqlc.narg('started_after')::timestamptz)
+        AND (sqlc.narg('started_before')::timestamptz IS NULL OR e."startedAt" <= sqlc.narg('started_before')::timestamptz)
+        AND (cardinality(@metadata_keys::text[]) = 0 OR e.id IN (
+            SELECT m."executionId" FROM workflow.execution_metadata m
+                JOIN unnest(@metadata_keys::text[], @metadata_values::text[]) AS f(key, value) ON m.key = f.key AND m.value = f.value
+                GROUP BY m."executionId" HAVING COUNT(*) = cardinality(@metadata_keys::text[])))
+        AND (@last_id::integer = 0 OR e.id < @last_id::integer)
+        AND (@first_id::integer = 0 OR e.id > @first_id::integer)
+        ORDER BY e.id DESC
+        LIMIT @page_limit
+) e
+    LEFT JOIN workflow.execution_data d ON d."executionId" = e.id
+    LEFT JOIN LATERAL (SELECT CASE WHEN left(d.data, 1) = '{' THEN d.data::json -> 'resultData' END AS data) r ON true
+    ORDER BY e.id DESC;
+
+-- name: CountWorkflowExecutionEntitiesWithFilter :one
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM workflow.execution_entity e
+        JOIN workflow.workflow_entity w ON w.id = e."workflowId"
+        WHERE w."sugerOrgId" = @suger_org_id
+        AND (@workflow_id::text = '' OR e."workflowId" = @workflow_id::text)
+        AND (@execution_id::integer = 0 OR e.id = @execution_id::integer)
+        AND (cardinality(@statuses::text[]) = 0 OR e.status = ANY(@statuses::text[]))
+        AND (@mode::text = '' OR e.mode = @mode::text)
+        AND (@retry_of::text = '' OR e."retryOf" = @retry_of::text)
+        AND (@retry_success_id::text = '' OR e."retrySuccessId" = @retry_success_id::text)
+        AND (NOT @finished::boolean OR e.finished)
+        AND (sqlc.narg('started_after')::timestamptz IS NULL OR e."startedAt" >= sqlc.narg('started_after')::timestamptz)
+        AND (sqlc.narg('started_before')::timestamptz IS NULL OR e."startedAt" <= sqlc.narg('started_before')::timestamptz)
+        AND (cardinality(@metadata_keys::text[]) = 0 OR e.id IN (
+            SELECT m."executionId" FROM workflow.execution_metadata m
+                JOIN unnest(@metadata_keys::text[], @metadata_values::text[]) AS f(key, value) ON m.key = f.key AND m.value = f.value
+                GROUP BY m."executionId" HAVING COUNT(*) = cardinality(@metadata_keys::text[])))
+        LIMIT @count_limit
+) c;
+
+-- name: ListWaitingWorkflowExecutionEntityIds :many
+SELECT id FROM workflow.execution_entity WHERE status = 'waiting' AND "waitTill" <= $1 ORDER BY "waitTill" LIMIT $2;
+
+-- name: ClaimWaitingWorkflowExecutionEntity :one
+UPDATE workflow.execution_entity e SET status = 'running', "waitTill" = NULL
+    FROM (SELECT id, "waitTill" FROM workflow.execution_entity WHERE id = $1 FOR UPDATE) claimed
+    WHERE e.id = claimed.id AND e.status = 'waiting'
+    RETURNING claimed."waitTill";
+
+-- name: ReleaseClaimedWorkflowExecutionEntity :exec
+UPDATE workflow.execution_entity SET status = 'waiting', "waitTill" = $2
+    WHERE id = $1 AND status = 'running';
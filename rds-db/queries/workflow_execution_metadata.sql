@@ -0,0 +1,7 @@
+-- name: UpsertExecutionMetadata :exec
+INSERT INTO workflow.execution_metadata("executionId", key, value)
+    SELECT $1::integer, unnest(@keys::text[]), unnest(@values::text[])
+    ON CONFLICT ("executionId", key) DO UPDATE SET value = EXCLUDED.value;
+
+-- name: ListExecutionMetadata :many
+SELECT * FROM workflow.execution_metadata WHERE "executionId" = $1 ORDER BY key;
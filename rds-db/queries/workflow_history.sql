@@ -0,0 +1,13 @@
+-- name: ListWorkflowHistory :many
+SELECT "versionId", "workflowId", authors, "createdAt", "updatedAt" FROM workflow.workflow_history
+    WHERE "workflowId" = $1 ORDER BY "createdAt" DESC LIMIT $2 OFFSET $3;
+
+-- name: CountWorkflowHistory :one
+SELECT count(*) FROM workflow.workflow_history WHERE "workflowId" = $1;
+
+-- name: GetWorkflowHistory :one
+SELECT * FROM workflow.workflow_history WHERE "workflowId" = $1 AND "versionId" = $2;
+
+-- name: CreateWorkflowHistory :one
+INSERT INTO workflow.workflow_history("versionId", "workflowId", authors, nodes, connections)
+    VALUES ($1, $2, $3, $4, $5) RETURNING *;
@@ -0,0 +1,15 @@
+-- name: ListSharedCredentials :many
+SELECT * FROM workflow.shared_credentials WHERE "sugerOrgId" = $1 AND "credentialsId" = $2 ORDER BY "createdAt";
+
+-- name: UpsertSharedCredentials :one
+INSERT INTO workflow.shared_credentials("credentialsId", "sugerOrgId", "granteeType", "granteeId", role)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT ("credentialsId", "sugerOrgId", "granteeType", "granteeId") DO UPDATE SET role = EXCLUDED.role, "updatedAt" = CURRENT_TIMESTAMP(3)
+    RETURNING *;
+
+-- name: DeleteSharedCredentials :one
+DELETE FROM workflow.shared_credentials WHERE "sugerOrgId" = $1 AND "credentialsId" = $2 AND "granteeType" = $3 AND "granteeId" = $4 RETURNING *;
+
+-- name: ListSharedCredentialsRolesOfGrantee :many
+SELECT role FROM workflow.shared_credentials WHERE "sugerOrgId" = @suger_org_id AND "credentialsId" = @credentials_id
+    AND (("granteeType" = 'user' AND "granteeId" = @user_id) OR ("granteeType" = 'role' AND "granteeId" = @role));
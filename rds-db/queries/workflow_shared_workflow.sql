@@ -0,0 +1,15 @@
+-- name: ListSharedWorkflows :many
+SELECT * FROM workflow.shared_workflow WHERE "sugerOrgId" = $1 AND "workflowId" = $2 ORDER BY "createdAt";
+
+-- name: UpsertSharedWorkflow :one
+INSERT INTO workflow.shared_workflow("workflowId", "sugerOrgId", "granteeType", "granteeId", role)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT ("workflowId", "granteeType", "granteeId") DO UPDATE SET role = EXCLUDED.role, "updatedAt" = CURRENT_TIMESTAMP(3)
+    RETURNING *;
+
+-- name: DeleteSharedWorkflow :one
+DELETE FROM workflow.shared_workflow WHERE "sugerOrgId" = $1 AND "workflowId" = $2 AND "granteeType" = $3 AND "granteeId" = $4 RETURNING *;
+
+-- name: ListSharedWorkflowRolesOfGrantee :many
+SELECT role FROM workflow.shared_workflow WHERE "sugerOrgId" = @suger_org_id AND "workflowId" = @workflow_id
+    AND (("granteeType" = 'user' AND "granteeId" = @user_id) OR ("granteeType" = 'role' AND "granteeId" = @role));
@@ -0,0 +1,26 @@
+-- name: IncrementWorkflowStatistics :exec
+INSERT INTO workflow.workflow_statistics(name, "workflowId", count, "latestEvent") VALUES ($1, $2, 1, $3)
+    ON CONFLICT ("workflowId", name) DO UPDATE SET count = workflow_statistics.count + 1, "latestEvent" = EXCLUDED."latestEvent";
+
+-- name: ListWorkflowStatistics :many
+SELECT * FROM workflow.workflow_statistics WHERE "workflowId" = $1;
+
+-- name: ListWorkflowStatisticsByOrg :many
+SELECT s.* FROM workflow.workflow_statistics s
+    JOIN workflow.workflow_entity w ON w.id = s."workflowId"
+    WHERE w."sugerOrgId" = $1;
+
+-- name: IncrementWorkflowStatisticsHourly :exec
+INSERT INTO workflow.workflow_statistics_hourly("workflowId", hour, status, "durationBucket", count) VALUES ($1, $2, $3, $4, 1)
+    ON CONFLICT ("workflowId", hour, status, "durationBucket") DO UPDATE SET count = workflow_statistics_hourly.count + 1;
+
+-- name: SumWorkflowStatisticsHourly :many
+SELECT "workflowId", status, "durationBucket", SUM(count)::bigint AS count FROM workflow.workflow_statistics_hourly
+    WHERE "workflowId" = $1 AND hour >= @since
+    GROUP BY "workflowId", status, "durationBucket";
+
+-- name: SumWorkflowStatisticsHourlyByOrg :many
+SELECT h."workflowId", h.status, h."durationBucket", SUM(h.count)::bigint AS count FROM workflow.workflow_statistics_hourly h
+    JOIN workflow.workflow_entity w ON w.id = h."workflowId"
+    WHERE w."sugerOrgId" = $1 AND h.hour >= @since
+    GROUP BY h."workflowId", h.status, h."durationBucket";
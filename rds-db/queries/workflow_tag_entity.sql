@@ -0,0 +1,29 @@
+-- name: ListTagEntities :many
+SELECT t.*, COUNT(wt."workflowId") AS "usageCount" FROM workflow.tag_entity t
+    LEFT JOIN workflow.workflows_tags wt ON wt."tagId" = t.id
+    WHERE t."sugerOrgId" = $1 GROUP BY t.id ORDER BY t.name;
+
+-- name: ListTagEntitiesByWorkflowIds :many
+SELECT wt."workflowId", t.* FROM workflow.workflows_tags wt
+    JOIN workflow.tag_entity t ON t.id = wt."tagId"
+    WHERE wt."workflowId" = ANY(@workflow_ids::text[]) ORDER BY t.name;
+
+-- name: CreateTagEntity :one
+INSERT INTO workflow.tag_entity(name, id, "sugerOrgId") VALUES ($1, $2, $3) RETURNING *;
+
+-- name: UpdateTagEntityName :one
+UPDATE workflow.tag_entity SET name = $3, "updatedAt" = CURRENT_TIMESTAMP WHERE "sugerOrgId" = $1 AND id = $2 RETURNING *;
+
+-- name: DeleteTagEntity :one
+DELETE FROM workflow.tag_entity WHERE "sugerOrgId" = $1 AND id = $2 RETURNING *;
+
+-- name: LockTagEntities :many
+SELECT id FROM workflow.tag_entity WHERE "sugerOrgId" = @suger_org_id AND id = ANY(@tag_ids::text[]) FOR SHARE;
+
+-- name: DeleteWorkflowTags :exec
+DELETE FROM workflow.workflows_tags WHERE "workflowId" = $1;
+
+-- name: CreateWorkflowTags :many
+INSERT INTO workflow.workflows_tags("workflowId", "tagId")
+    SELECT @workflow_id::text, id FROM workflow.tag_entity WHERE "sugerOrgId" = @suger_org_id AND id = ANY(@tag_ids::text[])
+    RETURNING "tagId";
@@ -0,0 +1,16 @@
+-- name: ListVariables :many
+SELECT * FROM workflow.variables WHERE "sugerOrgId" = $1 ORDER BY key;
+
+-- name: GetVariable :one
+SELECT * FROM workflow.variables WHERE "sugerOrgId" = $1 AND id = $2;
+
+-- name: CreateVariable :one
+INSERT INTO workflow.variables(key, type, value, id, "sugerOrgId")
+    VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: UpdateVariable :one
+UPDATE workflow.variables SET key = $3, type = $4, value = $5
+    WHERE "sugerOrgId" = $1 AND id = $2 RETURNING *;
+
+-- name: DeleteVariable :one
+DELETE FROM workflow.variables WHERE "sugerOrgId" = $1 AND id = $2 RETURNING *;
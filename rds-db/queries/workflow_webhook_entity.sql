@@ -15,4 +15,8 @@ DELETE FROM workflow.webhook_entity WHERE "workflowId" = $1;
 DELETE FROM workflow.webhook_entity WHERE "workflowId" = $1 AND "webhookPath" = $2 AND "method" = $3;
 
 -- name: DeleteAllWebhookEntities :exec
-DELETE FROM workflow.webhook_entity;
\ No newline at end of file
+DELETE FROM workflow.webhook_entity;
+
+-- name: ListWebhookEntitiesByOrgId_Method_PathLength :many
+SELECT * FROM workflow.webhook_entity
+    WHERE split_part("webhookPath", '/', 1) = @org_id::text AND method = @method AND "pathLength" = @path_length;
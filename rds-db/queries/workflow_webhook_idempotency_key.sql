@@ -0,0 +1,22 @@
+-- name: ClaimWebhookIdempotencyKey :one
+INSERT INTO workflow.webhook_idempotency_key("workflowId", "nodeId", key, "expiresAt")
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT ("workflowId", "nodeId", key) DO NOTHING RETURNING *;
+
+-- name: GetWebhookIdempotencyKey :one
+SELECT * FROM workflow.webhook_idempotency_key WHERE "workflowId" = $1 AND "nodeId" = $2 AND key = $3;
+
+-- name: UpdateWebhookIdempotencyKeyExecutionId :exec
+UPDATE workflow.webhook_idempotency_key SET "executionId" = $4
+    WHERE "workflowId" = $1 AND "nodeId" = $2 AND key = $3;
+
+-- name: UpdateWebhookIdempotencyKeyResponse :exec
+UPDATE workflow.webhook_idempotency_key
+    SET "responseStatusCode" = $4, "responseContentType" = $5, "responseBody" = $6
+    WHERE "workflowId" = $1 AND "nodeId" = $2 AND key = $3;
+
+-- name: DeleteWebhookIdempotencyKey :exec
+DELETE FROM workflow.webhook_idempotency_key WHERE "workflowId" = $1 AND "nodeId" = $2 AND key = $3;
+
+-- name: DeleteExpiredWebhookIdempotencyKeys :exec
+DELETE FROM workflow.webhook_idempotency_key WHERE "workflowId" = $1 AND "expiresAt" <= CURRENT_TIMESTAMP(3);
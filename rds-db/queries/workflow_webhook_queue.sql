@@ -0,0 +1,45 @@
+-- name: EnqueueWebhookRequest :one
+INSERT INTO workflow.webhook_queue("workflowId", "nodeId", "sugerOrgId", request, "clientIp", params, "idempotencyKey", status)
+    SELECT $1, $2, $3, $4, $5, $6, $7, 'queued'
+    WHERE (SELECT count(*) FROM workflow.webhook_queue WHERE "sugerOrgId" = $3) < @max_backlog::integer
+    RETURNING id;
+
+-- name: ListClaimableWebhookQueueOrgIds :many
+SELECT "sugerOrgId" FROM workflow.webhook_queue
+    WHERE status = 'queued' OR "heartbeatAt" < @stale_before
+    GROUP BY "sugerOrgId"
+    ORDER BY min(id)
+    LIMIT @org_limit::integer;
+
+-- name: LockWebhookQueueOrg :exec
+SELECT pg_advisory_xact_lock(hashtextextended('workflow.webhook_queue:' || @suger_org_id::text, 0));
+
+-- name: ClaimWebhookRequest :one
+UPDATE workflow.webhook_queue
+    SET status = 'running', attempts = attempts + 1, "heartbeatAt" = CURRENT_TIMESTAMP(3)
+    WHERE id = (
+        SELECT queued.id FROM workflow.webhook_queue queued
+            WHERE queued."sugerOrgId" = @suger_org_id
+            AND (queued.status = 'queued' OR queued."heartbeatAt" < @stale_before)
+            AND (SELECT count(*) FROM workflow.webhook_queue running
+                WHERE running."sugerOrgId" = queued."sugerOrgId" AND running."workflowId" = queued."workflowId"
+                AND running.status = 'running' AND running."heartbeatAt" >= @stale_before) < @max_per_workflow::integer
+            AND (SELECT count(*) FROM workflow.webhook_queue running
+                WHERE running."sugerOrgId" = queued."sugerOrgId"
+                AND running.status = 'running' AND running."heartbeatAt" >= @stale_before) < @max_per_org::integer
+            ORDER BY queued.id
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED)
+    RETURNING *;
+
+-- name: UpdateWebhookRequestHeartbeat :exec
+UPDATE workflow.webhook_queue SET "heartbeatAt" = CURRENT_TIMESTAMP(3) WHERE id = $1 AND status = 'running';
+
+-- name: ReleaseWebhookRequest :exec
+UPDATE workflow.webhook_queue SET status = 'queued', "heartbeatAt" = NULL WHERE id = $1;
+
+-- name: DeleteWebhookRequest :exec
+DELETE FROM workflow.webhook_queue WHERE id = $1;
+
+-- name: CountWebhookRequestsByWorkflowId :one
+SELECT count(*) FROM workflow.webhook_queue WHERE "workflowId" = $1;
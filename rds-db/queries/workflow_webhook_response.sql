@@ -0,0 +1,7 @@
+-- name: SaveWebhookResponse :exec
+INSERT INTO workflow.webhook_response("executionId", "statusCode", headers, body)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT ("executionId") DO NOTHING;
+
+-- name: GetWebhookResponse :one
+SELECT * FROM workflow.webhook_response WHERE "executionId" = $1;
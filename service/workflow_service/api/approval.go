@@ -0,0 +1,191 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	sharedLog "github.com/sugerio/workflow-service-trial/shared/log"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const approvalCommentField = "Comment"
+
+func (service *WorkflowService) RegisterRouteMethods_Approval() {
+	app := service.fiberApp
+	app.Get("/workflow/org/:orgId/workflow/execution/:executionId/approval",
+		service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.ListExecutionApprovals)
+
+	// The signed links of the Approval node. The GET shows the confirmation, so the link previews of the
+	// mail and chat clients do not decide the approval, and the POST decides it.
+	approvalApi := service.fiberApp.Group("/workflow/public/approval")
+	approvalApi.Get("/:approvalId/:decision", service.GetApprovalDecision)
+	approvalApi.Post("/:approvalId/:decision", service.DecideApproval)
+}
+
+// ListExecutionApprovals returns the approvals requested by the execution with their decisions.
+func (service *WorkflowService) ListExecutionApprovals(ctx *fiber.Ctx) error {
+	orgId := ctx.Params("orgId")
+	executionId, err := ctx.ParamsInt("executionId")
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, errors.New("executionId is not a valid integer"))
+	}
+	if orgId == "" || executionId == 0 {
+		return HandleBadRequestErrorWithTrace(ctx, errors.New("orgId or executionId is empty"))
+	}
+
+	workflowExecution, err := core.GetWorkflowExecution(ctx.UserContext(), int32(executionId))
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	err = service.validateExecutionOwnership(ctx.UserContext(), orgId, "", workflowExecution)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, err)
+	}
+
+	approvals, err := core.ListExecutionApprovals(ctx.UserContext(), int32(executionId))
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(structs.ListWorkflowApprovalsResponse{Data: approvals})
+}
+
+// GetApprovalDecision shows the form to confirm the decision of the approval link, with the comment.
+func (service *WorkflowService) GetApprovalDecision(ctx *fiber.Ctx) error {
+	approval, err := getApprovalOfLink(ctx)
+	if err != nil {
+		return handleApprovalError(ctx, err)
+	}
+	form := getApprovalForm(approval, ctx.Params("decision"))
+	if !isApprovalOpen(approval) {
+		return sendApprovalPage(ctx, fiber.StatusConflict, form, getApprovalStatusText(approval))
+	}
+	if acceptsFormJson(ctx) {
+		return ctx.Status(fiber.StatusOK).JSON(map[string]interface{}{"approval": approval, "form": form})
+	}
+	page, err := core.RenderFormTrigger(form, nil)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	ctx.Type("html")
+	return ctx.Status(fiber.StatusOK).Send(page)
+}
+
+// DecideApproval records the decision of the approval link and resumes the waiting execution.
+// The approval already decided or expired gets 409.
+func (service *WorkflowService) DecideApproval(ctx *fiber.Ctx) error {
+	approval, err := getApprovalOfLink(ctx)
+	if err != nil {
+		return handleApprovalError(ctx, err)
+	}
+	decision := ctx.Params("decision")
+	form := getApprovalForm(approval, decision)
+	if !isApprovalOpen(approval) {
+		return sendApprovalPage(ctx, fiber.StatusConflict, form, getApprovalStatusText(approval))
+	}
+	submission, _, err := core.ParseFormTriggerSubmission(ctx.Request(), form)
+	if err != nil {
+		return sendFormValidationError(ctx, form, err)
+	}
+	comment, _ := submission[approvalCommentField].(string)
+
+	approval, err = core.DecideApproval(ctx.UserContext(), approval.ID, decision, strings.TrimSpace(comment),
+		service.clientIp(ctx), string(ctx.Request().Header.UserAgent()))
+	if errors.Is(err, core.ErrApprovalNotPending) {
+		approval, err = core.GetApproval(ctx.UserContext(), ctx.Params("approvalId"))
+		if err != nil {
+			return handleApprovalError(ctx, err)
+		}
+		return sendApprovalPage(ctx, fiber.StatusConflict, form, getApprovalStatusText(approval))
+	} else if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+
+	// The decision is recorded, the execution is resumed by the wait tracker if it fails here.
+	if err := core.ResumeApprovalExecution(ctx.UserContext(), approval); err != nil {
+		sharedLog.GetLogger(ctx.UserContext()).Error("Failed to resume the execution of the approval",
+			"approvalId", approval.ID,
+			"executionId", approval.ExecutionId,
+			"err", err)
+	}
+	return sendApprovalPage(ctx, fiber.StatusOK, form, getApprovalStatusText(approval))
+}
+
+// getApprovalOfLink verifies the signature of the approval link and returns the approval.
+func getApprovalOfLink(ctx *fiber.Ctx) (*structs.WorkflowApproval, error) {
+	approvalId := ctx.Params("approvalId")
+	if err := core.VerifyApprovalLink(approvalId, ctx.Params("decision"), ctx.Query("signature")); err != nil {
+		return nil, err
+	}
+	return core.GetApproval(ctx.UserContext(), approvalId)
+}
+
+// handleApprovalError returns 403 for the invalid link, 404 for the unknown approval, and 500 otherwise.
+func handleApprovalError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, core.ErrApprovalInvalidLink):
+		return HandleForbiddenErrorWithTrace(ctx, err)
+	case errors.Is(err, core.ErrApprovalNotFound):
+		return HandleNotFoundErrorWithTrace(ctx, err)
+	default:
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+}
+
+// getApprovalForm returns the form to confirm the decision, with the message of the approval.
+func getApprovalForm(approval *structs.WorkflowApproval, decision string) *structs.WorkflowFrom {
+	form := &structs.WorkflowFrom{
+		FormTitle:       "Approve the request",
+		FormDescription: approval.Message,
+	}
+	if decision == core.ApprovalDecision_Reject {
+		form.FormTitle = "Reject the request"
+	}
+	form.FormFields.Values = []structs.WorkflowFromFields{{
+		FieldLabel:    approvalCommentField,
+		FieldType:     core.FormFieldType_Textarea,
+		RequiredField: approval.RequireComment,
+	}}
+	return form
+}
+
+// isApprovalOpen returns true if the approval is pending and not expired.
+func isApprovalOpen(approval *structs.WorkflowApproval) bool {
+	return approval.Status == structs.WorkflowApprovalStatus_Pending &&
+		(approval.ExpiresAt == nil || approval.ExpiresAt.After(time.Now()))
+}
+
+// getApprovalStatusText returns the text of the approval status shown to the approver.
+func getApprovalStatusText(approval *structs.WorkflowApproval) string {
+	switch approval.Status {
+	case structs.WorkflowApprovalStatus_Pending:
+		if isApprovalOpen(approval) {
+			return "The request is waiting for the decision"
+		}
+		return "The request has expired"
+	case structs.WorkflowApprovalStatus_Timeout:
+		return "The request has expired"
+	default:
+		return fmt.Sprintf("The request is %s", approval.Status)
+	}
+}
+
+// sendApprovalPage shows the status of the approval, or returns it in JSON.
+func sendApprovalPage(ctx *fiber.Ctx, status int, form *structs.WorkflowFrom, text string) error {
+	if acceptsFormJson(ctx) {
+		if status != fiber.StatusOK {
+			return HandleConflictErrorWithTrace(ctx, fmt.Errorf("%w: %s", core.ErrApprovalNotPending, text))
+		}
+		return ctx.Status(status).JSON(map[string]string{"message": text})
+	}
+	page, err := core.RenderFormTriggerCompletion(form, text)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	ctx.Type("html")
+	return ctx.Status(status).Send(page)
+}
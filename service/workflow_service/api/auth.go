@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	awsCore "github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+)
+
+// The org routes /workflow/org/:orgId/... are authenticated by AuthMiddleware with the first of:
+//  1. The API Gateway authorizer context of the request, only if environment.Auth.TrustApiGatewayAuthorizer is set.
+//     The principalId of the authorizer is the user id.
+//  2. The API key in the X-Api-Key header, its sha256 is matched to identity.api_client.api_key_hash.
+//  3. The bearer JWT signed by environment.Auth.JwtSecret with HS256, the "sub" claim is the user id.
+//
+// The API client must belong to the org and the user must be a member of the org, otherwise 403 is returned.
+
+const (
+	CTX_KEY_CALLER = "caller"
+
+	ApiKeyHeader = "X-Api-Key"
+)
+
+type CallerType string
+
+const (
+	CallerType_User      CallerType = "USER"
+	CallerType_ApiClient CallerType = "API_CLIENT"
+)
+
+// Caller is the authenticated user or API client of the request.
+type Caller struct {
+	Type  CallerType
+	Id    string // The id of the user or the API client.
+	Email string // The email of the user if known, empty for the API clients.
+	Role  string // The role of the user or the API client in the org.
+}
+
+var (
+	errUnauthenticated = errors.New("missing or invalid credentials")
+	errNotOrgMember    = errors.New("the caller does not belong to the org")
+)
+
+// The claims of the bearer JWT.
+type workflowJwtClaims struct {
+	Email string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// HashApiKey returns the hash of the API key saved in identity.api_client.api_key_hash.
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetCaller returns the authenticated caller of the request, or nil if the route is not authenticated.
+func GetCaller(ctx context.Context) *Caller {
+	if caller, ok := ctx.Value(CTX_KEY_CALLER).(*Caller); ok {
+		return caller
+	}
+	return nil
+}
+
+// AuthMiddleware authenticates the caller of the org routes and checks the caller belongs to the org.
+func (service *WorkflowService) AuthMiddleware(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+
+	caller, err := service.authenticate(c, orgId)
+	if err != nil {
+		switch {
+		case errors.Is(err, errUnauthenticated):
+			return HandleUnauthorizedErrorWithTrace(c, err)
+		case errors.Is(err, errNotOrgMember):
+			return HandleForbiddenErrorWithTrace(c, err)
+		default:
+			return HandleInternalServerErrorWithTrace(c, err)
+		}
+	}
+	ctx := context.WithValue(c.UserContext(), CTX_KEY_CALLER, caller)
+	c.SetUserContext(ctx)
+	return c.Next()
+}
+
+func (service *WorkflowService) authenticate(c *fiber.Ctx, orgId string) (*Caller, error) {
+	ctx := c.UserContext()
+	// The API Gateway context header can be set by anyone if the service is called directly.
+	if service.environment.Auth.TrustApiGatewayAuthorizer {
+		requestContext := events.APIGatewayProxyRequestContext{}
+		if err := json.Unmarshal([]byte(c.Get(awsCore.APIGwContextHeader)), &requestContext); err == nil {
+			userId, _ := requestContext.Authorizer["principalId"].(string)
+			email, _ := requestContext.Authorizer["email"].(string)
+			if userId != "" {
+				return service.authorizeUser(ctx, orgId, userId, email)
+			}
+		}
+	}
+	if apiKey := c.Get(ApiKeyHeader); apiKey != "" {
+		return service.authenticateApiKey(ctx, orgId, apiKey)
+	}
+	if authorization := c.Get(fiber.HeaderAuthorization); authorization != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return nil, errUnauthenticated
+		}
+		return service.authenticateJwt(ctx, orgId, token)
+	}
+	return nil, errUnauthenticated
+}
+
+// clientIp returns the IP of the client, which is the source IP of the API Gateway context if it is trusted.
+func (service *WorkflowService) clientIp(c *fiber.Ctx) string {
+	if service.environment.Auth.TrustApiGatewayAuthorizer {
+		requestContext := events.APIGatewayProxyRequestContext{}
+		if err := json.Unmarshal([]byte(c.Get(awsCore.APIGwContextHeader)), &requestContext); err == nil &&
+			requestContext.Identity.SourceIP != "" {
+			return requestContext.Identity.SourceIP
+		}
+	}
+	return c.IP()
+}
+
+func (service *WorkflowService) authenticateApiKey(ctx context.Context, orgId string, apiKey string) (*Caller, error) {
+	apiClient, err := service.rdsDbQueries.GetApiClientByApiKeyHash(ctx, HashApiKey(apiKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errUnauthenticated
+		}
+		return nil, err
+	}
+	if apiClient.OrganizationID != orgId {
+		return nil, errNotOrgMember
+	}
+	return &Caller{
+		Type: CallerType_ApiClient,
+		Id:   apiClient.ID,
+		Role: apiClient.Role,
+	}, nil
+}
+
+func (service *WorkflowService) authenticateJwt(ctx context.Context, orgId string, token string) (*Caller, error) {
+	secret := service.environment.Auth.JwtSecret
+	if secret == "" {
+		return nil, errUnauthenticated
+	}
+	claims := &workflowJwtClaims{}
+	_, err := jwt.ParseWithClaims(token, claims,
+		func(*jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return nil, errUnauthenticated
+	}
+	return service.authorizeUser(ctx, orgId, claims.Subject, claims.Email)
+}
+
+// authorizeUser checks the user is a member of the org.
+func (service *WorkflowService) authorizeUser(ctx context.Context, orgId string, userId string, email string) (*Caller, error) {
+	role, err := service.rdsDbQueries.GetUserRoleByUserAndOrganization(ctx, rdsDbLib.GetUserRoleByUserAndOrganizationParams{
+		UserID:         userId,
+		OrganizationID: orgId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNotOrgMember
+		}
+		return nil, err
+	}
+	return &Caller{
+		Type:  CallerType_User,
+		Id:    userId,
+		Email: email,
+		Role:  role,
+	}, nil
+}
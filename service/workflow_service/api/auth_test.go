@@ -0,0 +1,134 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/auth_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+}
+
+func Test_AuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+// Sign the bearer JWT of the user for testing.
+func signJwt_Testing(secret string, userId string, expiresAt time.Time) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   userId,
+		"email": "ruiqi@suger.io",
+		"exp":   expiresAt.Unix(),
+	})
+	signed, _ := token.SignedString([]byte(secret))
+	return signed
+}
+
+func (s *AuthTestSuite) Test() {
+	s.T().Run("TestAuth API key JWT and API Gateway authorizer", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		otherOrganization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		workflowsPath := fmt.Sprintf("/workflow/org/%s/workflow", organization.ID)
+		listWorkflows := func(headers map[string]string, requestContext events.APIGatewayProxyRequestContext) int {
+			headers["Content-Type"] = "application/json"
+			response, err := testFiberLambda.Proxy(events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				Path:           workflowsPath,
+				Headers:        headers,
+				RequestContext: requestContext,
+			})
+			assert.Nil(err)
+			return response.StatusCode
+		}
+		noContext := events.APIGatewayProxyRequestContext{}
+
+		// No credentials.
+		assert.Equal(http.StatusUnauthorized, listWorkflows(map[string]string{}, noContext))
+		assert.Equal(http.StatusUnauthorized, listWorkflows(map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, noContext))
+
+		// The API Gateway authorizer of a user who is not a member of the org.
+		assert.Equal(http.StatusForbidden, listWorkflows(map[string]string{}, events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"principalId": "5GUsZRVzT", "email": "jon@suger.io"},
+		}))
+		assert.Equal(http.StatusOK, listWorkflows(map[string]string{}, api.AuthorizerRequestContext))
+
+		// API keys.
+		apiKey := uuid.NewString()
+		_, err := rdsDbQueries.CreateApiClient(context.Background(), rdsDbLib.CreateApiClientParams{
+			ID:             uuid.NewString(),
+			OrganizationID: organization.ID,
+			Provider:       "SUGER",
+			Info:           json.RawMessage("{}"),
+			Role:           "ADMIN",
+			Type:           "API_KEY",
+			ApiKeyHash:     api.HashApiKey(apiKey),
+		})
+		assert.Nil(err)
+		otherApiKey := uuid.NewString()
+		_, err = rdsDbQueries.CreateApiClient(context.Background(), rdsDbLib.CreateApiClientParams{
+			ID:             uuid.NewString(),
+			OrganizationID: otherOrganization.ID,
+			Provider:       "SUGER",
+			Info:           json.RawMessage("{}"),
+			Role:           "ADMIN",
+			Type:           "API_KEY",
+			ApiKeyHash:     api.HashApiKey(otherApiKey),
+		})
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, listWorkflows(map[string]string{api.ApiKeyHeader: apiKey}, noContext))
+		assert.Equal(http.StatusForbidden, listWorkflows(map[string]string{api.ApiKeyHeader: otherApiKey}, noContext))
+		assert.Equal(http.StatusUnauthorized, listWorkflows(map[string]string{api.ApiKeyHeader: "unknown"}, noContext))
+
+		// Bearer JWTs.
+		token := signJwt_Testing(structs.TEST_WORKFLOW_JWT_SECRET, "vQAUJlvfT", time.Now().Add(time.Hour))
+		assert.Equal(http.StatusOK, listWorkflows(map[string]string{"Authorization": "Bearer " + token}, noContext))
+		token = signJwt_Testing(structs.TEST_WORKFLOW_JWT_SECRET, "5GUsZRVzT", time.Now().Add(time.Hour))
+		assert.Equal(http.StatusForbidden, listWorkflows(map[string]string{"Authorization": "Bearer " + token}, noContext))
+		token = signJwt_Testing(structs.TEST_WORKFLOW_JWT_SECRET, "vQAUJlvfT", time.Now().Add(-time.Hour))
+		assert.Equal(http.StatusUnauthorized, listWorkflows(map[string]string{"Authorization": "Bearer " + token}, noContext))
+		token = signJwt_Testing("wrong-secret", "vQAUJlvfT", time.Now().Add(time.Hour))
+		assert.Equal(http.StatusUnauthorized, listWorkflows(map[string]string{"Authorization": "Bearer " + token}, noContext))
+
+		// The public routes are not authenticated.
+		response, err := testFiberLambda.Proxy(events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodGet,
+			Path:       "/workflow/public/nodes.json",
+		})
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode)
+	})
+
+	s.T().Run("TestAuth records the caller on the executions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "test_files/request_create_workflow.json")
+		assert.Nil(err)
+
+		executionId, err := api.ManualRunWorkflow_Testing(testFiberLambda, newWorkflow)
+		assert.Nil(err)
+		execution, err := api.GetWorkflowExecution_Testing(testFiberLambda, organization.ID, executionId)
+		assert.Nil(err)
+		assert.Equal("vQAUJlvfT", execution.StartedBy)
+	})
+}
@@ -241,7 +241,7 @@ func parseCurrentNodeParametersFromQuerys(querys map[string]string) map[string]i
 }
 
 func (service *WorkflowService) RegisterRouteMethods_DynamicParameter() {
-	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/options", service.GetDynamicNodeParameters_Options)
-	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/resource-locator-results", service.GetDynamicNodeParameters_ResourceLocatorResults)
-	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/resource-mapper-fields", service.GetDynamicNodeParameters_ResourceMapperFields)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/options", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.GetDynamicNodeParameters_Options)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/resource-locator-results", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.GetDynamicNodeParameters_ResourceLocatorResults)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/dynamic-node-parameters/resource-mapper-fields", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.GetDynamicNodeParameters_ResourceMapperFields)
 }
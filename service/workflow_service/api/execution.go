@@ -15,22 +15,25 @@ import (
 func (service *WorkflowService) RegisterRouteMethods_Execution() {
 	app := service.fiberApp
 	app.Get("/workflow/org/:orgId/workflow/:workflowId/execution",
-		service.ListWorkflowExecutions)
+		service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.ListWorkflowExecutions)
+
+	app.Get("/workflow/org/:orgId/executions",
+		service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.ListOrgWorkflowExecutions)
 
 	app.Post("/workflow/org/:orgId/workflow/:workflowId/execution/delete",
-		service.DeleteWorkflowExecutions)
+		service.requirePermission(structs.WorkflowPermission_ExecutionDelete), service.DeleteWorkflowExecutions)
 
 	app.Get("/workflow/org/:orgId/workflow/execution/:executionId",
-		service.GetWorkflowExecution)
+		service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.GetWorkflowExecution)
 
 	app.Post("/workflow/org/:orgId/workflow/execution/:executionId/retry",
-		service.RetryWorkflowExecution)
+		service.requirePermission(structs.WorkflowPermission_WorkflowExecute), service.RetryWorkflowExecution)
 
 	app.Post("/workflow/org/:orgId/workflow/execution/:executionId/stop",
-		service.StopWorkflowCurrentExecution)
+		service.requirePermission(structs.WorkflowPermission_WorkflowExecute), service.StopWorkflowCurrentExecution)
 
 	app.Get("/workflow/org/:orgId/workflow/:workflowId/executions-current",
-		service.ListWorkflowCurrentExecutions)
+		service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.ListWorkflowCurrentExecutions)
 }
 
 func (service *WorkflowService) ListWorkflowExecutions(ctx *fiber.Ctx) error {
@@ -40,18 +43,15 @@ func (service *WorkflowService) ListWorkflowExecutions(ctx *fiber.Ctx) error {
 		return HandleBadRequestErrorWithTrace(ctx, errors.New("orgId or workflowId is empty"))
 	}
 
-	filterStr := ctx.Query("filter")
-	limit := ctx.QueryInt("limit", 100)
-	//lastId := ctx.Query("lastId")
-	//firstId := ctx.Query("firstId")
-	// TODO filter is not really supported
-	filter, err := service.decodeWorkflowExecutionsQueryFilter(workflowId, filterStr)
+	filter, page, err := service.decodeWorkflowExecutionsQuery(ctx)
 	if err != nil {
 		return HandleBadRequestErrorWithTrace(ctx, err)
 	}
 	if filter.WorkflowId != "" && filter.WorkflowId != workflowId {
 		return HandleBadRequestErrorWithTrace(ctx, errors.New("the filter is for a different workflow"))
 	}
+	// Ensure the filter is for the given workflow.
+	filter.WorkflowId = workflowId
 
 	workflowEntity, err := core.GetWorkflowEntityById(ctx.UserContext(), workflowId)
 	if err != nil {
@@ -61,53 +61,30 @@ func (service *WorkflowService) ListWorkflowExecutions(ctx *fiber.Ctx) error {
 		return HandleBadRequestErrorWithTrace(ctx, errors.New("the workflow does not belong to the organization"))
 	}
 
-	// total count
-	count, err := service.rdsDbQueries.CountWorkflowExecutionEntitiesByWorkflowId(ctx.UserContext(), workflowId)
+	data, err := core.ListWorkflowExecutionSummaries(ctx.UserContext(), orgId, filter, page)
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
+	response := structs.ListWorkflowExecutionsResponse{Data: data}
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
 
-	// list within limit
-	executionEntities, err := service.rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(
-		ctx.UserContext(),
-		rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{
-			WorkflowId: workflowId,
-			Limit:      int32(limit),
-			Offset:     0, // TODO pagination
-		})
-	if err != nil {
-		return HandleInternalServerErrorWithTrace(ctx, err)
+// List the executions of all the workflows of the org, the filter may be for a workflow.
+func (service *WorkflowService) ListOrgWorkflowExecutions(ctx *fiber.Ctx) error {
+	orgId := ctx.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(ctx, errors.New("orgId is empty"))
 	}
 
-	var executionSummaries []structs.WorkflowExecutionSummary
-	for idx := range executionEntities {
-		executionEntity := executionEntities[idx]
-		executionSummaries = append(executionSummaries, structs.WorkflowExecutionSummary{
-			Id:                  fmt.Sprint(executionEntity.ID),
-			ExecutionError:      nil, // unused
-			Finished:            executionEntity.Finished,
-			LastNodeExecuted:    "", // unused
-			Mode:                structs.WorkflowExecutionMode(executionEntity.Mode),
-			NodeExecutionStatus: nil, // unused
-			RetryOf:             executionEntity.RetryOf.String,
-			RetrySuccessId:      executionEntity.RetrySuccessId.String,
-			Status:              structs.WorkflowExecutionStatus(executionEntity.Status.String),
-			StartedAt:           &executionEntity.StartedAt,
-			StoppedAt:           core.ConvertNullTimeToStandardTimePointer(executionEntity.StoppedAt),
-			WaitTill:            core.ConvertNullTimeToStandardTimePointer(executionEntity.WaitTill),
-			WorkflowId:          workflowId,
-			WorkflowName:        workflowEntity.Name,
-		})
+	filter, page, err := service.decodeWorkflowExecutionsQuery(ctx)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, err)
 	}
-
-	// Compose response
-	response := structs.ListWorkflowExecutionsResponse{
-		Data: &structs.ListWorkflowExecutionsResponseData{
-			Count:     count,
-			Results:   executionSummaries,
-			Estimated: false,
-		},
+	data, err := core.ListWorkflowExecutionSummaries(ctx.UserContext(), orgId, filter, page)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
+	response := structs.ListWorkflowExecutionsResponse{Data: data}
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
@@ -130,6 +107,10 @@ func (service *WorkflowService) GetWorkflowExecution(ctx *fiber.Ctx) error {
 	if err != nil {
 		return HandleBadRequestErrorWithTrace(ctx, err)
 	}
+	workflowExecution.CustomData, err = core.GetExecutionMetadata(ctx.UserContext(), int32(executionId))
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
 
 	response := structs.GetWorkflowExecutionResponse{
 		Data: workflowExecution,
@@ -275,8 +256,13 @@ func (service *WorkflowService) DeleteWorkflowExecutions(ctx *fiber.Ctx) error {
 		executionIds = append(executionIds, int32(executionId))
 	}
 
+	err := service.validateWorkflowOwnership(ctx.UserContext(), orgId, workflowId)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, err)
+	}
+
 	// Batch delete the execution data.
-	err := service.rdsDbQueries.BatchDeleteWorkflowExecutionData(
+	err = service.rdsDbQueries.BatchDeleteWorkflowExecutionData(
 		ctx.UserContext(),
 		rdsDbLib.BatchDeleteWorkflowExecutionDataParams{
 			WorkflowID:   workflowId,
@@ -307,8 +293,7 @@ func (service *WorkflowService) ListWorkflowCurrentExecutions(ctx *fiber.Ctx) er
 		return HandleBadRequestErrorWithTrace(ctx, errors.New("orgId or workflowId is empty"))
 	}
 
-	filterStr := ctx.Query("filter")
-	_, err := service.decodeWorkflowExecutionsQueryFilter(workflowId, filterStr)
+	_, _, err := service.decodeWorkflowExecutionsQuery(ctx)
 	if err != nil {
 		return HandleBadRequestErrorWithTrace(ctx, err)
 	}
@@ -333,6 +318,9 @@ func (service *WorkflowService) ListWorkflowCurrentExecutions(ctx *fiber.Ctx) er
 			Mode:       executionData.ExecutionMode,
 			WorkflowId: executionData.WorkflowData.ID,
 			Status:     executingWorkflowData.Status,
+
+			WorkflowVersionId: executionData.WorkflowData.VersionId,
+			StartedBy:         executionData.UserId,
 		})
 	}
 	// TODO sort
@@ -375,17 +363,37 @@ func (service *WorkflowService) StopWorkflowCurrentExecution(ctx *fiber.Ctx) err
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
-func (service *WorkflowService) decodeWorkflowExecutionsQueryFilter(
-	workflowId string, filterStr string) (structs.WorkflowExecutionsQueryFilter, error) {
+// decodeWorkflowExecutionsQuery decodes the JSON filter and the page of the query,
+// the page is the executions before "lastId" or after "firstId" within "limit".
+func (service *WorkflowService) decodeWorkflowExecutionsQuery(
+	ctx *fiber.Ctx) (structs.WorkflowExecutionsQueryFilter, core.WorkflowExecutionsPage, error) {
 	filter := structs.WorkflowExecutionsQueryFilter{}
-	err := core.UnmarshalOmitEmpty([]byte(filterStr), &filter)
+	err := core.UnmarshalOmitEmpty([]byte(ctx.Query("filter")), &filter)
 	if err != nil {
-		return structs.WorkflowExecutionsQueryFilter{}, err
+		return filter, core.WorkflowExecutionsPage{}, err
+	}
+	if filter.ID != "" {
+		if _, err := strconv.Atoi(filter.ID); err != nil {
+			return filter, core.WorkflowExecutionsPage{}, fmt.Errorf("invalid execution id %s in the filter", filter.ID)
+		}
 	}
-	// Ensure the filter is for the given workflow.
-	filter.WorkflowId = workflowId
 
-	return filter, nil
+	page := core.WorkflowExecutionsPage{
+		Limit: ctx.QueryInt("limit", 100),
+	}
+	if page.Limit <= 0 {
+		return filter, page, errors.New("invalid limit")
+	}
+	for name, cursor := range map[string]*int32{"lastId": &page.LastId, "firstId": &page.FirstId} {
+		if value := ctx.Query(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || id <= 0 {
+				return filter, page, fmt.Errorf("invalid %s %s", name, value)
+			}
+			*cursor = int32(id)
+		}
+	}
+	return filter, page, nil
 }
 
 func (service *WorkflowService) validateWorkflowOwnership(
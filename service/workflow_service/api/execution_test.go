@@ -5,11 +5,13 @@ package api_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -77,8 +79,191 @@ func (s *ExecutionTestSuite) Test() {
 		assert.Equal(2, int(responseData.Data.Count))
 		assert.Equal(false, responseData.Data.Estimated)
 		assert.Equal(2, len(responseData.Data.Results))
+		// the latest first
+		assert.Equal(fmt.Sprint(executionId2), responseData.Data.Results[0].Id)
+		assert.Equal(fmt.Sprint(executionId1), responseData.Data.Results[1].Id)
+	})
+
+	s.T().Run("TestListWorkflowExecutions filters by metadata", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_simplest.json")
+		assert.Nil(err)
+		executionId1 := createWorkflowExecutionAndData_Testing(assert, newWorkflow)
+		executionId2 := createWorkflowExecutionAndData_Testing(assert, newWorkflow)
+		err = rdsDbQueries.UpsertExecutionMetadata(context.Background(), rdsDbLib.UpsertExecutionMetadataParams{
+			ExecutionId: executionId1,
+			Keys:        []string{"customerId", "orderId"},
+			Values:      []string{"c-1", "12345"},
+		})
+		assert.Nil(err)
+		err = rdsDbQueries.UpsertExecutionMetadata(context.Background(), rdsDbLib.UpsertExecutionMetadataParams{
+			ExecutionId: executionId2,
+			Keys:        []string{"customerId", "orderId"},
+			Values:      []string{"c-1", "67890"},
+		})
+		assert.Nil(err)
+
+		listExecutions := func(filter string) structs.ListWorkflowExecutionsResponse {
+			request, err := GetAPIGatewayProxyRequest_CreateOrganization()
+			assert.Nil(err)
+			request.HTTPMethod = http.MethodGet
+			request.Path = fmt.Sprintf("/workflow/org/%s/workflow/%s/execution", organization.ID, newWorkflow.ID)
+			request.QueryStringParameters = map[string]string{"filter": filter}
+			response, err := testFiberLambda.Proxy(request)
+			assert.Nil(err)
+			assert.Equal(200, response.StatusCode, response.Body)
+			var responseData structs.ListWorkflowExecutionsResponse
+			err = json.Unmarshal([]byte(response.Body), &responseData)
+			assert.Nil(err, fmt.Sprint("response body:", response.Body))
+			return responseData
+		}
+
+		responseData := listExecutions(`{"metadata":[{"key":"orderId","value":"12345"}]}`)
+		assert.Equal(1, int(responseData.Data.Count))
 		assert.Equal(fmt.Sprint(executionId1), responseData.Data.Results[0].Id)
-		assert.Equal(fmt.Sprint(executionId2), responseData.Data.Results[1].Id)
+
+		// all the key and value pairs must match
+		responseData = listExecutions(`{"metadata":[{"key":"customerId","value":"c-1"},{"key":"orderId","value":"67890"}]}`)
+		assert.Equal(1, int(responseData.Data.Count))
+		assert.Equal(fmt.Sprint(executionId2), responseData.Data.Results[0].Id)
+
+		responseData = listExecutions(`{"metadata":[{"key":"customerId","value":"c-1"}]}`)
+		assert.Equal(2, int(responseData.Data.Count))
+
+		responseData = listExecutions(`{"metadata":[{"key":"orderId","value":"0"}]}`)
+		assert.Equal(0, int(responseData.Data.Count))
+
+		// the custom data is returned with the execution
+		request, err := GetAPIGatewayProxyRequest_CreateOrganization()
+		assert.Nil(err)
+		request.HTTPMethod = http.MethodGet
+		request.Path = fmt.Sprintf("/workflow/org/%s/workflow/execution/%d", organization.ID, executionId1)
+		response, err := testFiberLambda.Proxy(request)
+		assert.Nil(err)
+		assert.Equal(200, response.StatusCode)
+		var getResponseData structs.GetWorkflowExecutionResponse
+		err = json.Unmarshal([]byte(response.Body), &getResponseData)
+		assert.Nil(err, fmt.Sprint("response body:", response.Body))
+		assert.Equal(map[string]string{"customerId": "c-1", "orderId": "12345"}, getResponseData.Data.CustomData)
+	})
+
+	s.T().Run("TestListWorkflowExecutions filters and pages", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_simplest.json")
+		assert.Nil(err)
+		startedAt := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		createExecution := func(status structs.WorkflowExecutionStatus, mode structs.WorkflowExecutionMode, data string) int32 {
+			entity, err := rdsDbQueries.CreateWorkflowExecutionEntity(
+				context.Background(),
+				rdsDbLib.CreateWorkflowExecutionEntityParams{
+					WorkflowId: newWorkflow.ID,
+					Status:     sql.NullString{String: string(status), Valid: true},
+					Mode:       string(mode),
+					StartedAt:  startedAt,
+				})
+			assert.Nil(err)
+			_, err = rdsDbQueries.CreateWorkflowExecutionData(
+				context.Background(),
+				rdsDbLib.CreateWorkflowExecutionDataParams{
+					ExecutionId:  entity.ID,
+					WorkflowData: []byte("{}"),
+					Data:         data,
+				})
+			assert.Nil(err)
+			startedAt = startedAt.Add(time.Minute)
+			return entity.ID
+		}
+		successId := createExecution(structs.WorkflowExecutionStatus_Success, structs.WorkflowExecutionMode_Manual,
+			`{"resultData":{"lastNodeExecuted":"Code"}}`)
+		failedId := createExecution(structs.WorkflowExecutionStatus_Failed, structs.WorkflowExecutionMode_Webhook,
+			`{"resultData":{"error":"boom","lastNodeExecuted":"Code","runData":{"Code":[{"error":{"message":"boom","description":"line 1"}}]}}}`)
+		webhookId := createExecution(structs.WorkflowExecutionStatus_Success, structs.WorkflowExecutionMode_Webhook, "{}")
+
+		listExecutions := func(path string, query map[string]string) (int, structs.ListWorkflowExecutionsResponse) {
+			request, err := GetAPIGatewayProxyRequest_CreateOrganization()
+			assert.Nil(err)
+			request.HTTPMethod = http.MethodGet
+			request.Path = path
+			request.QueryStringParameters = query
+			response, err := testFiberLambda.Proxy(request)
+			assert.Nil(err)
+			var responseData structs.ListWorkflowExecutionsResponse
+			if response.StatusCode == 200 {
+				err = json.Unmarshal([]byte(response.Body), &responseData)
+				assert.Nil(err, fmt.Sprint("response body:", response.Body))
+			}
+			return response.StatusCode, responseData
+		}
+		workflowPath := fmt.Sprintf("/workflow/org/%s/workflow/%s/execution", organization.ID, newWorkflow.ID)
+		resultIds := func(responseData structs.ListWorkflowExecutionsResponse) []string {
+			ids := []string{}
+			for _, result := range responseData.Data.Results {
+				ids = append(ids, result.Id)
+			}
+			return ids
+		}
+
+		statusCode, responseData := listExecutions(workflowPath, map[string]string{"filter": `{"status":["failed"]}`})
+		assert.Equal(200, statusCode)
+		assert.Equal(int64(1), responseData.Data.Count)
+		assert.False(responseData.Data.Estimated)
+		failed := responseData.Data.Results[0]
+		assert.Equal(fmt.Sprint(failedId), failed.Id)
+		assert.Equal("Code", failed.LastNodeExecuted)
+		assert.Equal(newWorkflow.Name, failed.WorkflowName)
+		assert.NotNil(failed.ExecutionError)
+		assert.Equal("boom", failed.ExecutionError.Message)
+		assert.Equal("line 1", failed.ExecutionError.Description)
+
+		_, responseData = listExecutions(workflowPath, map[string]string{"filter": `{"mode":"webhook"}`})
+		assert.Equal([]string{fmt.Sprint(webhookId), fmt.Sprint(failedId)}, resultIds(responseData))
+
+		_, responseData = listExecutions(workflowPath, map[string]string{
+			"filter": fmt.Sprintf(`{"startedBefore":"%s"}`, startedAt.Add(-90*time.Second).Format(time.RFC3339Nano)),
+		})
+		assert.Equal([]string{fmt.Sprint(failedId), fmt.Sprint(successId)}, resultIds(responseData))
+		assert.Equal("Code", responseData.Data.Results[1].LastNodeExecuted)
+		assert.Nil(responseData.Data.Results[1].ExecutionError)
+
+		// the cursors
+		_, responseData = listExecutions(workflowPath, map[string]string{"limit": "2"})
+		assert.Equal(int64(3), responseData.Data.Count)
+		assert.Equal([]string{fmt.Sprint(webhookId), fmt.Sprint(failedId)}, resultIds(responseData))
+		_, responseData = listExecutions(workflowPath, map[string]string{"limit": "2", "lastId": fmt.Sprint(failedId)})
+		assert.Equal([]string{fmt.Sprint(successId)}, resultIds(responseData))
+		_, responseData = listExecutions(workflowPath, map[string]string{"firstId": fmt.Sprint(successId)})
+		assert.Equal([]string{fmt.Sprint(webhookId), fmt.Sprint(failedId)}, resultIds(responseData))
+
+		statusCode, _ = listExecutions(workflowPath, map[string]string{"lastId": "abc"})
+		assert.Equal(400, statusCode)
+		statusCode, _ = listExecutions(workflowPath, map[string]string{"filter": `{"id":"abc"}`})
+		assert.Equal(400, statusCode)
+
+		// the executions of all the workflows of the org
+		otherWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_simplest.json")
+		assert.Nil(err)
+		otherId := createWorkflowExecutionAndData_Testing(assert, otherWorkflow)
+		orgPath := fmt.Sprintf("/workflow/org/%s/executions", organization.ID)
+		statusCode, responseData = listExecutions(orgPath, nil)
+		assert.Equal(200, statusCode)
+		assert.Equal(int64(4), responseData.Data.Count)
+		assert.Equal(fmt.Sprint(otherId), responseData.Data.Results[0].Id)
+		assert.Equal(otherWorkflow.ID, responseData.Data.Results[0].WorkflowId)
+
+		_, responseData = listExecutions(orgPath, map[string]string{"filter": fmt.Sprintf(`{"workflowId":"%s"}`, otherWorkflow.ID)})
+		assert.Equal([]string{fmt.Sprint(otherId)}, resultIds(responseData))
+
+		// the executions of the other orgs are not listed
+		otherOrganization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		_, responseData = listExecutions(fmt.Sprintf("/workflow/org/%s/executions", otherOrganization.ID), nil)
+		assert.Equal(int64(0), responseData.Data.Count)
 	})
 
 	s.T().Run("TestGetWorkflowExecution", func(t *testing.T) {
@@ -136,6 +321,46 @@ func (s *ExecutionTestSuite) Test() {
 		assert.Equal(executionId2nd, executionEntities[0].ID)
 	})
 
+	s.T().Run("TestDeleteWorkflowExecutions does not delete the executions of another org", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		otherOrganization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		otherWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, otherOrganization.ID, "./test_files/workflow_execution_simplest.json")
+		assert.Nil(err)
+		otherExecutionId := createWorkflowExecutionAndData_Testing(assert, otherWorkflow)
+
+		// The admin of the org deletes the execution of the workflow of the other org through the org.
+		request, err := GetAPIGatewayProxyRequest_CreateOrganization()
+		assert.Nil(err)
+		request.HTTPMethod = http.MethodPost
+		request.Path = fmt.Sprintf("/workflow/org/%s/workflow/%s/execution/delete", organization.ID, otherWorkflow.ID)
+		request.Headers = map[string]string{"Content-Type": "application/json"}
+		request.Body = fmt.Sprintf("{\"ids\": [\"%d\"]}", otherExecutionId)
+		response, err := testFiberLambda.Proxy(request)
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+
+		// The execution of the other org is neither found through the org.
+		request.HTTPMethod = http.MethodGet
+		request.Path = fmt.Sprintf("/workflow/org/%s/workflow/execution/%d", organization.ID, otherExecutionId)
+		request.Body = ""
+		response, err = testFiberLambda.Proxy(request)
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+
+		executionEntities, err := rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(
+			context.Background(),
+			rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{
+				WorkflowId: otherWorkflow.ID,
+				Limit:      10,
+			})
+		assert.Nil(err)
+		assert.Equal(1, len(executionEntities))
+		assert.Equal(otherExecutionId, executionEntities[0].ID)
+	})
+
 	s.T().Run("TestListWorkflowCurrentExecutions returns empty for no data", func(t *testing.T) {
 		t.Parallel()
 		assert := require.New(s.T())
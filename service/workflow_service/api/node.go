@@ -78,6 +78,11 @@ func (service *WorkflowService) GetNodeIcons(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusBadRequest).SendString(fmt.Errorf("invalid path:%s", path).Error())
 }
 
+// GetExpressionExtensions returns the catalog of the expression extension methods for the editor autocomplete
+func (service *WorkflowService) GetExpressionExtensions(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(core.ExpressionExtensions)
+}
+
 func getContentType(iconType string) string {
 	if iconType == "svg" {
 		return CONTENT_TYPE_SVG
@@ -113,4 +118,5 @@ func getNodeNameAndIconType(path string) (nodeName, iconType string, ok bool) {
 func (service *WorkflowService) RegisterRouteMethods_Node() {
 	service.fiberApp.Get("/workflow/public/nodes.json", service.GetWorkflowNodesJson)
 	service.fiberApp.Get("/workflow/public/nodes/icons/+", service.GetNodeIcons)
+	service.fiberApp.Get("/workflow/public/expressions/extensions.json", service.GetExpressionExtensions)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	_ "github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/code"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
@@ -55,6 +56,29 @@ func (s *NodeTestSuit) Test() {
 		}
 	})
 
+	s.T().Run("TestGetExpressionExtensions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+
+		request := events.APIGatewayProxyRequest{}
+		request.HTTPMethod = http.MethodGet
+		request.Path = "/workflow/public/expressions/extensions.json"
+
+		resp, err := testFiberLambda.Proxy(request)
+		assert.Nil(err)
+		assert.Equal(200, resp.StatusCode)
+		var extensions []core.ExpressionExtension
+		err = json.Unmarshal([]byte(resp.Body), &extensions)
+		assert.Nil(err)
+		assert.Equal(len(core.ExpressionExtensions), len(extensions))
+		for _, extension := range extensions {
+			assert.NotEmpty(extension.Type)
+			assert.NotEmpty(extension.Name)
+			assert.NotEmpty(extension.ReturnType)
+			assert.NotEmpty(extension.Description)
+		}
+	})
+
 	s.T().Run("TestGetNodeIcons", func(t *testing.T) {
 		t.Parallel()
 		assert := require.New(s.T())
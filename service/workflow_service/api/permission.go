@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+var errPermissionDenied = errors.New("permission denied")
+
+// requirePermission returns the handler to check the caller has the permission in the org,
+// or on the workflow of the :workflowId or the :executionId of the route shared with the caller.
+// The workflow, the execution or the :credentialId of another org is not found, so the handlers
+// of the routes can not act on the resources of another org.
+func (service *WorkflowService) requirePermission(permission structs.WorkflowPermission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		caller := GetCaller(c.UserContext())
+		if caller == nil {
+			return HandleUnauthorizedErrorWithTrace(c, errUnauthenticated)
+		}
+		ctx := c.UserContext()
+		orgId := c.Params("orgId")
+		if credentialId := c.Params("credentialId"); credentialId != "" {
+			if err := core.CheckCredentialsOfOrg(ctx, orgId, credentialId); err != nil {
+				return handleOwnershipError(c, err)
+			}
+		}
+		workflowId := c.Params("workflowId")
+		if workflowId == "" && c.Params("executionId") != "" {
+			executionId, err := strconv.Atoi(c.Params("executionId"))
+			if err != nil {
+				return HandleBadRequestErrorWithTrace(c, fmt.Errorf("executionId is invalid"))
+			}
+			// The workflow of the execution, which may be shared with the caller.
+			executionEntity, err := service.rdsDbQueries.GetWorkflowExecutionEntity(ctx, int32(executionId))
+			if err == nil {
+				workflowId = executionEntity.WorkflowId
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return HandleInternalServerErrorWithTrace(c, err)
+			}
+		}
+		allowed, err := core.HasWorkflowPermission(ctx, orgId, workflowId, caller.Id, caller.Role, permission)
+		if err != nil {
+			return handleOwnershipError(c, err)
+		}
+		if !allowed {
+			return HandleForbiddenErrorWithTrace(c, fmt.Errorf("%w: %s is required", errPermissionDenied, permission))
+		}
+		return c.Next()
+	}
+}
+
+// handleOwnershipError returns 404 for the workflow or the credential of another org and 500 otherwise.
+func handleOwnershipError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrWorkflowNotFound) || errors.Is(err, core.ErrCredentialsNotFound) {
+		return HandleNotFoundErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+// checkCredentialsUse checks the caller can use the credentials of the nodes,
+// the credentials already used by the previous nodes of the workflow are not checked.
+func checkCredentialsUse(c *fiber.Ctx, orgId string, nodes []structs.WorkflowNode, previousNodes []structs.WorkflowNode) error {
+	caller := GetCaller(c.UserContext())
+	if caller == nil {
+		return errUnauthenticated
+	}
+	usedCredentialsIds := map[string]bool{}
+	for _, node := range previousNodes {
+		for _, credentials := range node.Credentials {
+			usedCredentialsIds[credentials.ID] = true
+		}
+	}
+	for _, node := range nodes {
+		for _, credentials := range node.Credentials {
+			if credentials.ID == "" || usedCredentialsIds[credentials.ID] {
+				continue
+			}
+			allowed, err := core.HasCredentialPermission(
+				c.UserContext(), orgId, credentials.ID, caller.Id, caller.Role, structs.WorkflowPermission_CredentialUse)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return fmt.Errorf("%w: %s is required on credential %s of node %s",
+					errPermissionDenied, structs.WorkflowPermission_CredentialUse, credentials.ID, node.Name)
+			}
+			usedCredentialsIds[credentials.ID] = true
+		}
+	}
+	return nil
+}
+
+// handlePermissionError returns 401 for no caller, 403 for the denied permission and 500 otherwise.
+func handlePermissionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errUnauthenticated):
+		return HandleUnauthorizedErrorWithTrace(c, err)
+	case errors.Is(err, errPermissionDenied):
+		return HandleForbiddenErrorWithTrace(c, err)
+	default:
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+}
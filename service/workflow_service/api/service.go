@@ -106,6 +106,11 @@ func (service *WorkflowService) Start() error {
 		}
 	}
 
+	// Start the workers executing the queued webhook requests.
+	core.StartWebhookQueueWorkers(core.NewWebhookQueueConfig(service.environment))
+	// Start resuming the waiting executions, e.g. the timed out approvals.
+	core.StartWaitTracker()
+
 	// Set up fiber app.
 	service.fiberApp = fiber.New(
 		fiber.Config{
@@ -124,6 +129,8 @@ func (service *WorkflowService) Start() error {
 	}))
 	service.fiberApp.Use(mw.OrgIdMiddleware)
 	service.fiberApp.Use(mw.LoggerMiddleware)
+	// Authenticate the caller of the org routes.
+	service.fiberApp.Use("/workflow/org/:orgId", service.AuthMiddleware)
 	// Register routes after the above middleware
 	service.RegisterAllRouteMethods()
 
@@ -142,26 +149,38 @@ func (service *WorkflowService) Start() error {
 }
 
 func (service *WorkflowService) Close() {
-	// Shutdown all active executions.
+	// Stop the webhook queue workers and the wait tracker, then shutdown all active executions.
+	core.StopWebhookQueueWorkers()
+	core.StopWaitTracker()
 	core.ShutdownActiveExecutions()
 	// shutdown rest service via fiber app.
 	service.fiberApp.Shutdown()
 }
 
 func (service *WorkflowService) RegisterAllRouteMethods() {
+	service.RegisterRouteMethods_Approval()
 	service.RegisterRouteMethods_Execution()
 	service.RegisterRouteMethods_Node()
 	service.RegisterRouteMethods_Webhook()
 	service.RegisterRouteMethods_Workflow()
+	service.RegisterRouteMethods_WorkflowHistory()
+	service.RegisterRouteMethods_WorkflowStatistics()
 	service.RegisterRouteMethods_DynamicParameter()
+	service.RegisterRouteMethods_Variable()
+	service.RegisterRouteMethods_Tag()
+	service.RegisterRouteMethods_Sharing()
 }
 
 func (service *WorkflowService) GetTestFiberAdapter() *fiberAdapter.FiberLambda {
 	return fiberAdapter.New(service.fiberApp)
 }
 
+// GetContextUserId returns the id of the user or API client of the request, empty if it is not authenticated.
 func GetContextUserId(ctx *fiber.Ctx) string {
-	return "anonymous"
+	if caller := GetCaller(ctx.UserContext()); caller != nil {
+		return caller.Id
+	}
+	return ""
 }
 
 // Check if the current workflow service is the main service.
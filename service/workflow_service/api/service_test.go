@@ -128,6 +128,8 @@ func GetAPIGatewayProxyRequest_CreateOrganization() (events.APIGatewayProxyReque
 		return request, err
 	}
 	err = json.Unmarshal(testRequestFile, &request)
+	// Send the request as the admin of the test orgs.
+	request.RequestContext = api.AuthorizerRequestContext
 	return request, err
 }
 
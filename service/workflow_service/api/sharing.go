@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// handleShareError returns 404 for a missing share or credential and 500 otherwise.
+func handleShareError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrShareNotFound) || errors.Is(err, core.ErrCredentialsNotFound) {
+		return HandleNotFoundErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+// validateCredentialsOwnership returns core.ErrCredentialsNotFound if the credential is not a credential of the org.
+func (service *WorkflowService) validateCredentialsOwnership(ctx context.Context, sugerOrgId string, credentialsId string) error {
+	return core.CheckCredentialsOfOrg(ctx, sugerOrgId, credentialsId)
+}
+
+func (service *WorkflowService) ListWorkflowShares(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	if orgId == "" || workflowId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or workflowId is empty"))
+	}
+	if err := service.validateWorkflowOwnership(c.UserContext(), orgId, workflowId); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	shares, err := core.ListWorkflowShares(c.UserContext(), orgId, workflowId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.ListWorkflowSharesResponse{Data: shares}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Grant the user or the org role access to the workflow as a viewer or an editor.
+func (service *WorkflowService) ShareWorkflow(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	if orgId == "" || workflowId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or workflowId is empty"))
+	}
+	params := structs.WorkflowShare{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if err := service.validateWorkflowOwnership(c.UserContext(), orgId, workflowId); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	share, err := core.ShareWorkflow(c.UserContext(), orgId, workflowId, &params)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	response := structs.GetWorkflowShareResponse{Data: share}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) UnshareWorkflow(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	granteeType := c.Params("granteeType")
+	granteeId := c.Params("granteeId")
+	if orgId == "" || workflowId == "" || granteeType == "" || granteeId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId, workflowId, granteeType or granteeId is empty"))
+	}
+	if err := service.validateWorkflowOwnership(c.UserContext(), orgId, workflowId); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	if err := core.UnshareWorkflow(c.UserContext(), orgId, workflowId, granteeType, granteeId); err != nil {
+		return handleShareError(c, err)
+	}
+	response := structs.DeleteWorkflowShareResponse{Data: true}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) ListCredentialShares(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	credentialId := c.Params("credentialId")
+	if orgId == "" || credentialId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or credentialId is empty"))
+	}
+	if err := service.validateCredentialsOwnership(c.UserContext(), orgId, credentialId); err != nil {
+		return handleShareError(c, err)
+	}
+
+	shares, err := core.ListCredentialShares(c.UserContext(), orgId, credentialId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.ListWorkflowSharesResponse{Data: shares}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Grant the user or the org role the use of the credential in the workflows of the org.
+func (service *WorkflowService) ShareCredential(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	credentialId := c.Params("credentialId")
+	if orgId == "" || credentialId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or credentialId is empty"))
+	}
+	params := structs.WorkflowShare{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if err := service.validateCredentialsOwnership(c.UserContext(), orgId, credentialId); err != nil {
+		return handleShareError(c, err)
+	}
+
+	share, err := core.ShareCredential(c.UserContext(), orgId, credentialId, &params)
+	if err != nil {
+		if errors.Is(err, core.ErrCredentialsNotFound) {
+			return HandleNotFoundErrorWithTrace(c, err)
+		}
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	response := structs.GetWorkflowShareResponse{Data: share}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) UnshareCredential(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	credentialId := c.Params("credentialId")
+	granteeType := c.Params("granteeType")
+	granteeId := c.Params("granteeId")
+	if orgId == "" || credentialId == "" || granteeType == "" || granteeId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId, credentialId, granteeType or granteeId is empty"))
+	}
+	if err := service.validateCredentialsOwnership(c.UserContext(), orgId, credentialId); err != nil {
+		return handleShareError(c, err)
+	}
+
+	if err := core.UnshareCredential(c.UserContext(), orgId, credentialId, granteeType, granteeId); err != nil {
+		return handleShareError(c, err)
+	}
+	response := structs.DeleteWorkflowShareResponse{Data: true}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) RegisterRouteMethods_Sharing() {
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId/shares", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListWorkflowShares)
+	service.fiberApp.Put("/workflow/org/:orgId/workflow/:workflowId/shares", service.requirePermission(structs.WorkflowPermission_WorkflowShare), service.ShareWorkflow)
+	service.fiberApp.Delete("/workflow/org/:orgId/workflow/:workflowId/shares/:granteeType/:granteeId", service.requirePermission(structs.WorkflowPermission_WorkflowShare), service.UnshareWorkflow)
+	service.fiberApp.Get("/workflow/org/:orgId/credentials/:credentialId/shares", service.requirePermission(structs.WorkflowPermission_CredentialShare), service.ListCredentialShares)
+	service.fiberApp.Put("/workflow/org/:orgId/credentials/:credentialId/shares", service.requirePermission(structs.WorkflowPermission_CredentialShare), service.ShareCredential)
+	service.fiberApp.Delete("/workflow/org/:orgId/credentials/:credentialId/shares/:granteeType/:granteeId", service.requirePermission(structs.WorkflowPermission_CredentialShare), service.UnshareCredential)
+}
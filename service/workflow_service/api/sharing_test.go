@@ -0,0 +1,174 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/auth_test.go service/workflow_service/api/variable_test.go service/workflow_service/api/sharing_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type SharingTestSuite struct {
+	suite.Suite
+}
+
+func Test_SharingTestSuite(t *testing.T) {
+	suite.Run(t, new(SharingTestSuite))
+}
+
+func (s *SharingTestSuite) Test() {
+	s.T().Run("TestSharing viewer role and workflow shares", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "test_files/request_create_workflow.json")
+		assert.Nil(err)
+		executionId, err := api.ManualRunWorkflow_Testing(testFiberLambda, newWorkflow)
+		assert.Nil(err)
+
+		// The contractor is a VIEWER of the org.
+		_, err = rdsDbQueries.AddUserToOrganization(context.Background(), rdsDbLib.AddUserToOrganizationParams{
+			UserID:             "5GUsZRVzT",
+			OrganizationID:     organization.ID,
+			UserRole:           "VIEWER",
+			AllowedAuthMethods: []string{},
+		})
+		assert.Nil(err)
+		token := signJwt_Testing(structs.TEST_WORKFLOW_JWT_SECRET, "5GUsZRVzT", time.Now().Add(time.Hour))
+		viewerRequest := func(method, path string, body interface{}) int {
+			request := variableRequest(method, path, body)
+			request.RequestContext = events.APIGatewayProxyRequestContext{}
+			request.Headers["Authorization"] = "Bearer " + token
+			response, err := testFiberLambda.Proxy(request)
+			assert.Nil(err)
+			return response.StatusCode
+		}
+		workflowPath := fmt.Sprintf("/workflow/org/%s/workflow/%s", organization.ID, newWorkflow.ID)
+		sharesPath := workflowPath + "/shares"
+
+		// The viewer can read the workflows and the executions.
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodGet, fmt.Sprintf("/workflow/org/%s/workflow", organization.ID), nil))
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodGet, workflowPath, nil))
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodGet, workflowPath+"/execution", nil))
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodGet,
+			fmt.Sprintf("/workflow/org/%s/workflow/execution/%s", organization.ID, executionId), nil))
+
+		// But can not edit, run, delete or share the workflow.
+		update := map[string]interface{}{"name": "renamed by viewer", "nodes": newWorkflow.Nodes, "connections": newWorkflow.Connections}
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodPatch, workflowPath, update))
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodPost, workflowPath+"/run", nil))
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodDelete, workflowPath, nil))
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodPut, sharesPath,
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_User, GranteeId: "5GUsZRVzT", Role: structs.WorkflowShareRole_Editor}))
+
+		// Invalid shares.
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPut, sharesPath,
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_User, GranteeId: "PJrlnwU4T", Role: structs.WorkflowShareRole_Editor}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode, response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPut, sharesPath,
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_User, GranteeId: "5GUsZRVzT", Role: "owner"}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode, response.Body)
+
+		// The admin shares the workflow with the contractor as an editor.
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPut, sharesPath,
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_User, GranteeId: "5GUsZRVzT", Role: structs.WorkflowShareRole_Editor}))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		shareResponse := structs.GetWorkflowShareResponse{}
+		assert.Nil(json.Unmarshal([]byte(response.Body), &shareResponse))
+		assert.Equal(newWorkflow.ID, shareResponse.Data.ResourceId)
+		assert.Equal(structs.WorkflowShareRole_Editor, shareResponse.Data.Role)
+
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodPatch, workflowPath, update))
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodDelete, workflowPath, nil))
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, sharesPath, nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		listResponse := structs.ListWorkflowSharesResponse{}
+		assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse))
+		assert.Len(listResponse.Data, 1)
+		assert.Equal("5GUsZRVzT", listResponse.Data[0].GranteeId)
+
+		// The access is revoked with the share.
+		unsharePath := fmt.Sprintf("%s/%s/%s", sharesPath, structs.WorkflowShareGranteeType_User, "5GUsZRVzT")
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, unsharePath, nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, unsharePath, nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+		assert.Equal(http.StatusForbidden, viewerRequest(http.MethodPatch, workflowPath, update))
+
+		// The shares of the role apply to all the members of the role.
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPut, sharesPath,
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: structs.WorkflowShareRole_Editor}))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		assert.Equal(http.StatusOK, viewerRequest(http.MethodPatch, workflowPath, update))
+	})
+
+	s.T().Run("TestSharing resources of another org", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		otherOrganization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		otherWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, otherOrganization.ID, "test_files/request_create_workflow.json")
+		assert.Nil(err)
+		otherCredentials, err := rdsDbQueries.CreateCredentialsEntity(context.Background(), rdsDbLib.CreateCredentialsEntityParams{
+			Name:        "Other org credentials",
+			Data:        "{}",
+			Type:        "httpBasicAuth",
+			NodesAccess: json.RawMessage("[]"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  otherOrganization.ID,
+		})
+		assert.Nil(err)
+		share := structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: structs.WorkflowShareRole_User}
+
+		// The workflow of the other org is not found in the org, so it can not be unshared through the org.
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPut,
+			fmt.Sprintf("/workflow/org/%s/workflow/%s/shares", otherOrganization.ID, otherWorkflow.ID),
+			structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: structs.WorkflowShareRole_Editor}))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete,
+			fmt.Sprintf("/workflow/org/%s/workflow/%s/shares/role/VIEWER", organization.ID, otherWorkflow.ID), nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+
+		// The credential of the other org is not found in the org.
+		credentialSharesPath := fmt.Sprintf("/workflow/org/%s/credentials/%s/shares", organization.ID, otherCredentials.ID)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPut, credentialSharesPath, share))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, credentialSharesPath, nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, credentialSharesPath+"/role/VIEWER", nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPut,
+			fmt.Sprintf("/workflow/org/%s/credentials/%s/shares", otherOrganization.ID, otherCredentials.ID), share))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// handleTagError returns 404 for a missing tag, 400 for a duplicate name and 500 otherwise
+func handleTagError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrTagNotFound) {
+		return HandleNotFoundErrorWithTrace(c, err)
+	}
+	if errors.Is(err, core.ErrTagNameExists) {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+func (service *WorkflowService) ListTags(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+
+	tags, err := core.ListTags(c.UserContext(), orgId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.ListTagsResponse{
+		Data:  tags,
+		Count: int64(len(tags)),
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) CreateTag(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+
+	params := structs.WorkflowTag{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if _, err := core.ValidateTagName(params.Name); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	tag, err := core.CreateTag(c.UserContext(), orgId, params.Name)
+	if err != nil {
+		return handleTagError(c, err)
+	}
+	response := structs.GetTagResponse{Data: tag}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Rename the tag.
+func (service *WorkflowService) UpdateTag(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	tagId := c.Params("tagId")
+	if orgId == "" || tagId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or tagId is empty"))
+	}
+
+	params := structs.WorkflowTag{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if _, err := core.ValidateTagName(params.Name); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	tag, err := core.UpdateTag(c.UserContext(), orgId, tagId, params.Name)
+	if err != nil {
+		return handleTagError(c, err)
+	}
+	response := structs.GetTagResponse{Data: tag}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) DeleteTag(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	tagId := c.Params("tagId")
+	if orgId == "" || tagId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or tagId is empty"))
+	}
+
+	tag, err := core.DeleteTag(c.UserContext(), orgId, tagId)
+	if err != nil {
+		return handleTagError(c, err)
+	}
+	response := structs.DeleteTagResponse{
+		Data: tag != nil,
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) RegisterRouteMethods_Tag() {
+	service.fiberApp.Get("/workflow/org/:orgId/tags", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListTags)
+	service.fiberApp.Post("/workflow/org/:orgId/tags", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.CreateTag)
+	service.fiberApp.Patch("/workflow/org/:orgId/tags/:tagId", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.UpdateTag)
+	service.fiberApp.Delete("/workflow/org/:orgId/tags/:tagId", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.DeleteTag)
+}
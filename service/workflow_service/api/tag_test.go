@@ -0,0 +1,167 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/variable_test.go service/workflow_service/api/tag_test.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type TagTestSuit struct {
+	suite.Suite
+}
+
+func Test_TagTestSuit(t *testing.T) {
+	suite.Run(t, new(TagTestSuit))
+}
+
+func (s *TagTestSuit) Test() {
+	s.T().Run("TestTag Create Assign Filter Delete", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		// Create Organization for test
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		tagsPath := fmt.Sprintf("/workflow/org/%s/tags", organization.ID)
+		workflowsPath := fmt.Sprintf("/workflow/org/%s/workflow", organization.ID)
+
+		// Create tags
+		tagIds := map[string]string{}
+		for _, name := range []string{"prod", "billing"} {
+			response, err := testFiberLambda.Proxy(variableRequest(http.MethodPost, tagsPath, structs.WorkflowTag{Name: name}))
+			assert.Nil(err)
+			var createResponse structs.GetTagResponse
+			assert.Nil(json.Unmarshal([]byte(response.Body), &createResponse), response.Body)
+			tagIds[name] = createResponse.Data.ID
+		}
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPost, tagsPath, structs.WorkflowTag{Name: "prod"}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode)
+		assert.Equal("tag name already exists", response.Body)
+
+		// Create workflows with tags, by id or by object
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "test_files/request_create_workflow.json")
+		assert.Nil(err)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch, workflowsPath+"/"+newWorkflow.ID,
+			structs.WorkflowEntity{Tags: []interface{}{tagIds["prod"], map[string]string{"id": tagIds["billing"]}}}))
+		assert.Nil(err)
+		var updateResponse structs.UpdateWorkflowResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &updateResponse), response.Body)
+		assert.Len(updateResponse.Data.Tags, 2)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, workflowsPath,
+			structs.WorkflowEntity{Name: "billing report", Tags: []interface{}{tagIds["billing"]}}))
+		assert.Nil(err)
+		var createResponse structs.GetWorkflowResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &createResponse), response.Body)
+		assert.Len(createResponse.Data.Tags, 1)
+
+		// Unknown tags are rejected before the workflow is saved
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch, workflowsPath+"/"+newWorkflow.ID,
+			structs.WorkflowEntity{Name: "renamed", Tags: []interface{}{tagIds["prod"], "unknown"}}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode)
+		assert.Equal("no such tag", response.Body)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, workflowsPath,
+			structs.WorkflowEntity{Name: "unknown tag", Tags: []interface{}{"unknown"}}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode)
+
+		// Filter the workflows
+		listWorkflows := func(query string) structs.ListWorkflowsResponse {
+			response, err := testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowsPath+query, nil))
+			assert.Nil(err)
+			var listResponse structs.ListWorkflowsResponse
+			assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse), response.Body)
+			return listResponse
+		}
+		assert.Len(listWorkflows("").Data, 2)
+		assert.Len(listWorkflows("?tags="+tagIds["billing"]).Data, 2)
+		assert.Len(listWorkflows("?tags="+tagIds["billing"]+","+tagIds["prod"]).Data, 1)
+		assert.Len(listWorkflows("?tags="+tagIds["billing"]+","+tagIds["billing"]).Data, 2)
+		assert.Len(listWorkflows("?name=REPORT").Data, 1)
+		assert.Equal("New Workflow", listWorkflows("?name=new").Data[0].Name)
+		// The wildcards of the name are matched literally
+		assert.Len(listWorkflows("?name=%25").Data, 0)
+		assert.Len(listWorkflows("?name=new_workflow").Data, 0)
+		assert.Len(listWorkflows("?active=true").Data, 0)
+		page := listWorkflows("?limit=1&offset=1")
+		assert.Len(page.Data, 1)
+		assert.Equal(int64(2), page.Count)
+
+		// List tags with the usage count
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, tagsPath, nil))
+		assert.Nil(err)
+		var listResponse structs.ListTagsResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse), response.Body)
+		assert.Len(listResponse.Data, 2)
+		assert.Equal("billing", listResponse.Data[0].Name)
+		assert.Equal(int64(2), *listResponse.Data[0].UsageCount)
+
+		// Rename and delete the tag
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch, tagsPath+"/"+tagIds["prod"], structs.WorkflowTag{Name: "production"}))
+		assert.Nil(err)
+		var renameResponse structs.GetTagResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &renameResponse), response.Body)
+		assert.Equal("production", renameResponse.Data.Name)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, tagsPath+"/"+tagIds["prod"], nil))
+		assert.Nil(err)
+		var deleteResponse structs.DeleteTagResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &deleteResponse), response.Body)
+		assert.True(deleteResponse.Data)
+		assert.Len(listWorkflows("?tags="+tagIds["billing"]+","+tagIds["prod"]).Data, 0)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, tagsPath+"/"+tagIds["prod"], nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	s.T().Run("TestTag Update only the tags of an active workflow", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		tagsPath := fmt.Sprintf("/workflow/org/%s/tags", organization.ID)
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPost, tagsPath, structs.WorkflowTag{Name: "prod"}))
+		assert.Nil(err)
+		var tagResponse structs.GetTagResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &tagResponse), response.Body)
+
+		workflowEntity, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_lastNode.json")
+		assert.Nil(err)
+		nodeId, webhookId, err := api.GetWebhookIdAndNodeIdInWorkflow(workflowEntity)
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, workflowEntity.ID)
+		assert.Nil(err)
+
+		// The body has neither the name nor the active status.
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch,
+			fmt.Sprintf("/workflow/org/%s/workflow/%s", organization.ID, workflowEntity.ID),
+			map[string]interface{}{"tags": []string{tagResponse.Data.ID}}))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		var updateResponse structs.UpdateWorkflowResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &updateResponse), response.Body)
+		assert.Len(updateResponse.Data.Tags, 1)
+		assert.True(updateResponse.Data.Active)
+
+		// The webhook of the workflow is still registered.
+		requestJson := `{"msg":"content here"}`
+		webhookResponse, err := api.CallWebhook_Testing(
+			testFiberLambda, http.MethodPost, workflowEntity.ID, nodeId, webhookId, false, requestJson)
+		assert.Nil(err)
+		webhookResponseJson, err := json.Marshal(webhookResponse)
+		assert.Nil(err)
+		assert.Equal(requestJson, string(webhookResponseJson))
+	})
+}
@@ -396,6 +396,23 @@ func CallWebhookFullResponse_Testing(
 	return testFiberLambda.Proxy(request)
 }
 
+// Get the webhook response of the execution answered with 202
+func GetWebhookExecutionResponse_Testing(
+	testFiberLambda *fiberAdapter.FiberLambda,
+	workflowId string,
+	nodeId string,
+	executionId string,
+	token string,
+) (events.APIGatewayProxyResponse, error) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod:     http.MethodGet,
+		Path:           fmt.Sprintf("/workflow/public/webhook/workflow/%s/node/%s/execution/%s/%s", workflowId, nodeId, executionId, token),
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		RequestContext: AuthorizerRequestContext,
+	}
+	return testFiberLambda.Proxy(request)
+}
+
 // Delete test webhook
 func DeleteTestWebhook_Testing(
 	testFiberLambda *fiberAdapter.FiberLambda,
@@ -65,6 +65,21 @@ func HandleUnauthorizedErrorWithTrace(c *fiber.Ctx, err error) error {
 	return c.Status(fiber.StatusUnauthorized).SendString(FormatErrorMessage(err))
 }
 
+func HandleForbiddenErrorWithTrace(c *fiber.Ctx, err error) error {
+	// Get the RunTime code file, line & function.
+	pc := make([]uintptr, 10)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	frame, _ := frames.Next()
+
+	logger := sharedLog.GetLogger(c.UserContext())
+	logger.Error(fmt.Sprintf("Failed to %s", frame.Function),
+		"location", fmt.Sprintf("%s:%d", frame.File, frame.Line),
+		"error", err,
+		"request", c.Request())
+	return c.Status(fiber.StatusForbidden).SendString(FormatErrorMessage(err))
+}
+
 func HandleNotFoundErrorWithTrace(c *fiber.Ctx, err error) error {
 	// Get the RunTime code file, line & function.
 	pc := make([]uintptr, 10)
@@ -96,6 +111,22 @@ func HandleConflictErrorWithTrace(c *fiber.Ctx, err error) error {
 	return c.Status(fiber.StatusConflict).SendString(FormatErrorMessage(err))
 }
 
+// HandleTooManyRequestsErrorWithTrace for the request rejected by the backpressure
+func HandleTooManyRequestsErrorWithTrace(c *fiber.Ctx, err error) error {
+	// Get the RunTime code file, line & function.
+	pc := make([]uintptr, 10)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	frame, _ := frames.Next()
+
+	logger := sharedLog.GetLogger(c.UserContext())
+	logger.Error(fmt.Sprintf("Failed to %s", frame.Function),
+		"location", fmt.Sprintf("%s:%d", frame.File, frame.Line),
+		"error", err,
+		"request", c.Request())
+	return c.Status(fiber.StatusTooManyRequests).SendString(FormatErrorMessage(err))
+}
+
 func LogWithTrace(err error) {
 	// Get the RunTime code file, line & function.
 	pc := make([]uintptr, 10)
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// handleVariableError returns 404 for a missing variable, 400 for a duplicate key and 500 otherwise
+func handleVariableError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrVariableNotFound) {
+		return HandleNotFoundErrorWithTrace(c, err)
+	}
+	if errors.Is(err, core.ErrVariableKeyExists) {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+func (service *WorkflowService) ListVariables(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+
+	variables, err := core.ListVariables(c.UserContext(), orgId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	for i := range variables {
+		variables[i] = variables[i].Masked()
+	}
+	response := structs.ListVariablesResponse{
+		Data:  variables,
+		Count: int64(len(variables)),
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) CreateVariable(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+
+	params := structs.WorkflowVariable{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if err := core.ValidateVariable(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	variable, err := core.CreateVariable(c.UserContext(), orgId, params)
+	if err != nil {
+		return handleVariableError(c, err)
+	}
+	masked := variable.Masked()
+	response := structs.GetVariableResponse{Data: &masked}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) GetVariable(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	variableId := c.Params("variableId")
+	if orgId == "" || variableId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or variableId is empty"))
+	}
+
+	variable, err := core.GetVariable(c.UserContext(), orgId, variableId)
+	if err != nil {
+		return handleVariableError(c, err)
+	}
+	masked := variable.Masked()
+	response := structs.GetVariableResponse{Data: &masked}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Update the variable, the value of a secret is kept if it is sent back masked.
+func (service *WorkflowService) UpdateVariable(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	variableId := c.Params("variableId")
+	if orgId == "" || variableId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or variableId is empty"))
+	}
+
+	params := structs.WorkflowVariable{}
+	if err := c.BodyParser(&params); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if params.Value != structs.WorkflowVariableSecretMask {
+		if err := core.ValidateVariable(&params); err != nil {
+			return HandleBadRequestErrorWithTrace(c, err)
+		}
+	}
+
+	variable, err := core.UpdateVariable(c.UserContext(), orgId, variableId, params)
+	if err != nil {
+		return handleVariableError(c, err)
+	}
+	masked := variable.Masked()
+	response := structs.GetVariableResponse{Data: &masked}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) DeleteVariable(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	variableId := c.Params("variableId")
+	if orgId == "" || variableId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or variableId is empty"))
+	}
+
+	variable, err := core.DeleteVariable(c.UserContext(), orgId, variableId)
+	if err != nil {
+		return handleVariableError(c, err)
+	}
+	response := structs.DeleteVariableResponse{
+		Data: variable != nil,
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) RegisterRouteMethods_Variable() {
+	service.fiberApp.Get("/workflow/org/:orgId/variables", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListVariables)
+	service.fiberApp.Post("/workflow/org/:orgId/variables", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.CreateVariable)
+	service.fiberApp.Get("/workflow/org/:orgId/variables/:variableId", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.GetVariable)
+	service.fiberApp.Patch("/workflow/org/:orgId/variables/:variableId", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.UpdateVariable)
+	service.fiberApp.Delete("/workflow/org/:orgId/variables/:variableId", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.DeleteVariable)
+}
@@ -0,0 +1,117 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/variable_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type VariableTestSuit struct {
+	suite.Suite
+}
+
+func Test_VariableTestSuit(t *testing.T) {
+	suite.Run(t, new(VariableTestSuit))
+}
+
+func variableRequest(method, path string, body interface{}) events.APIGatewayProxyRequest {
+	bodyJson := ""
+	if body != nil {
+		bytes, _ := json.Marshal(body)
+		bodyJson = string(bytes)
+	}
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     method,
+		Path:           path,
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body:           bodyJson,
+		RequestContext: api.AuthorizerRequestContext,
+	}
+}
+
+func (s *VariableTestSuit) Test() {
+	s.T().Run("TestVariable Create Update List Get Delete", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		// Create Organization for test
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		variablesPath := fmt.Sprintf("/workflow/org/%s/variables", organization.ID)
+
+		// Create variables
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPost, variablesPath,
+			structs.WorkflowVariable{Key: "API_HOST", Value: "https://api.example.com"}))
+		assert.Nil(err)
+		var createResponse structs.GetVariableResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &createResponse), response.Body)
+		assert.Equal(structs.WorkflowVariableType_String, createResponse.Data.Type)
+		variableId := createResponse.Data.ID
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, variablesPath,
+			structs.WorkflowVariable{Key: "TOKEN", Type: structs.WorkflowVariableType_Secret, Value: "s3cret"}))
+		assert.Nil(err)
+		assert.Nil(json.Unmarshal([]byte(response.Body), &createResponse), response.Body)
+		assert.Equal(structs.WorkflowVariableSecretMask, createResponse.Data.Value)
+		secretId := createResponse.Data.ID
+
+		// Invalid and duplicate variables
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, variablesPath,
+			structs.WorkflowVariable{Key: "RETRIES", Type: structs.WorkflowVariableType_Number, Value: "three"}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode)
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, variablesPath,
+			structs.WorkflowVariable{Key: "API_HOST", Value: "x"}))
+		assert.Nil(err)
+		assert.Equal(http.StatusBadRequest, response.StatusCode)
+		assert.Equal("variable key already exists", response.Body)
+
+		// Update the variable, the secret keeps its value if sent back masked
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch, variablesPath+"/"+variableId,
+			structs.WorkflowVariable{Key: "API_HOST", Value: "https://staging.example.com"}))
+		assert.Nil(err)
+		var updateResponse structs.GetVariableResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &updateResponse), response.Body)
+		assert.Equal("https://staging.example.com", updateResponse.Data.Value)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPatch, variablesPath+"/"+secretId,
+			structs.WorkflowVariable{Key: "API_TOKEN", Type: structs.WorkflowVariableType_Secret, Value: structs.WorkflowVariableSecretMask}))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		variables, err := rdsDbQueries.ListVariables(context.Background(), organization.ID)
+		assert.Nil(err)
+		assert.Len(variables, 2)
+		assert.Equal("API_TOKEN", variables[1].Key)
+		assert.Equal("s3cret", variables[1].Value.String)
+
+		// List variables
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, variablesPath, nil))
+		assert.Nil(err)
+		var listResponse structs.ListVariablesResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse), response.Body)
+		assert.Len(listResponse.Data, 2)
+		assert.Equal(structs.WorkflowVariableSecretMask, listResponse.Data[1].Value)
+
+		// Delete and get the variable
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodDelete, variablesPath+"/"+variableId, nil))
+		assert.Nil(err)
+		var deleteResponse structs.DeleteVariableResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &deleteResponse), response.Body)
+		assert.True(deleteResponse.Data)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, variablesPath+"/"+variableId, nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode)
+		assert.Equal("no such variable", response.Body)
+	})
+}
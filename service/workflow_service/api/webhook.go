@@ -2,14 +2,17 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/gofiber/fiber/v2"
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 	"github.com/valyala/fasthttp"
@@ -26,24 +29,6 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 			ctx, errors.New("missing required parameter: webhookId"))
 	}
 
-	// Confirm the AWS SNS subscription
-	if strings.Contains(string(ctx.Body()), "SubscriptionConfirmation") {
-		event := structs.AwsSnsSubscriptionConfirmationEvent{}
-		if err := json.Unmarshal(ctx.Body(), &event); err != nil {
-			return HandleBadRequestErrorWithTrace(ctx, err)
-		}
-
-		confirmInput := sns.ConfirmSubscriptionInput{
-			Token:    &event.Token,
-			TopicArn: &event.TopicArn,
-		}
-		output, err := service.awsSdkClients.GetSnsClient().ConfirmSubscription(ctx.UserContext(), &confirmInput)
-		if err != nil {
-			return HandleInternalServerErrorWithTrace(ctx, err)
-		}
-		return ctx.Status(fiber.StatusOK).JSON(output)
-	}
-
 	// Load webhook entity
 	webhookEntity, err := core.GetWebhookEntity(ctx.UserContext(), workflowId, webhookId, isTest)
 	if err != nil {
@@ -72,6 +57,78 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 			ctx, errors.New("the webhookId is not associated with the nodeId"))
 	}
 
+	return service.handleWebhookRequest(ctx, workflowEntity, webhookNode, map[string]string{})
+}
+
+// handleWebhookPathError writes the error of the invalid or conflicting custom webhook path.
+func handleWebhookPathError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrInvalidWebhookPath) {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	if errors.Is(err, core.ErrWebhookPathConflict) {
+		return HandleConflictErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+// HandleWebhookPath handles the request to the custom path {orgId}/{path} of the Webhook node,
+// the route parameters matched in the path are passed to the node.
+func (service *WorkflowService) HandleWebhookPath(ctx *fiber.Ctx) error {
+	webhookEntity, params, err := core.FindWebhookEntityByPath(ctx.UserContext(), ctx.Method(), ctx.Params("*"))
+	if errors.Is(err, core.ErrWebhookNotFound) {
+		return HandleNotFoundErrorWithTrace(ctx, err)
+	} else if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+
+	workflowEntity, err := core.GetWorkflowEntityById(ctx.UserContext(), webhookEntity.WorkflowId)
+	if err != nil {
+		return HandleNotFoundErrorWithTrace(ctx, err)
+	}
+	webhookNode := workflowEntity.GetNodeByName(webhookEntity.Node)
+	if webhookNode == nil {
+		return HandleNotFoundErrorWithTrace(ctx, errors.New("no such webhook node in the workflow"))
+	}
+	return service.handleWebhookRequest(ctx, workflowEntity, webhookNode, params)
+}
+
+// handleWebhookRequest authenticates the request of the webhook node and runs the workflow.
+func (service *WorkflowService) handleWebhookRequest(
+	ctx *fiber.Ctx,
+	workflowEntity *structs.WorkflowEntity,
+	webhookNode *structs.WorkflowNode,
+	params map[string]string) error {
+	workflowId := workflowEntity.ID
+	webhookId := webhookNode.WebhookId
+
+	// Reject the request before any execution is started.
+	if err := service.authenticateWebhookRequest(ctx, workflowEntity.SugerOrgId, webhookNode); err != nil {
+		return handleWebhookAuthError(ctx, webhookNode, err)
+	}
+
+	// Confirm the AWS SNS subscription, the signature of the message is verified by authenticateWebhookRequest.
+	signatureOptions, err := core.ParseWebhookSignatureOptions(webhookNode)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	if signatureOptions.Verification == structs.WebhookSignatureVerification_AwsSns {
+		event := structs.AwsSnsSubscriptionConfirmationEvent{}
+		if err := json.Unmarshal(ctx.Body(), &event); err != nil {
+			return HandleBadRequestErrorWithTrace(ctx, err)
+		}
+		if event.Type == "SubscriptionConfirmation" {
+			confirmInput := sns.ConfirmSubscriptionInput{
+				Token:    &event.Token,
+				TopicArn: &event.TopicArn,
+			}
+			output, err := service.awsSdkClients.GetSnsClient().ConfirmSubscription(ctx.UserContext(), &confirmInput)
+			if err != nil {
+				return HandleInternalServerErrorWithTrace(ctx, err)
+			}
+			return ctx.Status(fiber.StatusOK).JSON(output)
+		}
+	}
+
 	// Parse webhook node options
 	options, err := core.ParseWebhookNodeOptions(webhookNode)
 	if err != nil {
@@ -82,6 +139,26 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 		ctx.Set(entry.Name, entry.Value)
 	}
 
+	// The repeated delivery with the idempotency key does not start an execution.
+	idempotencyKey, err := core.GetWebhookIdempotencyKey(
+		workflowEntity, webhookNode, ctx.Request(), params, service.clientIp(ctx), options)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, err)
+	}
+	// The delivery is accepted once its execution is started or queued.
+	accepted := false
+	if idempotencyKey != nil {
+		storedKey, claimed, err := core.ClaimWebhookIdempotencyKey(ctx.UserContext(), idempotencyKey)
+		if err != nil {
+			return HandleInternalServerErrorWithTrace(ctx, err)
+		}
+		if !claimed {
+			return sendWebhookIdempotentResponse(ctx, storedKey)
+		}
+		// Save the response after it is written, or release the key if the delivery is not accepted.
+		defer func() { service.saveWebhookIdempotencyResponse(ctx, idempotencyKey, accepted) }()
+	}
+
 	// Determine respond mode (return immediately or wait for workflow result)
 	responseMode, err := webhookNode.GetWebhookResponseMode()
 	if err != nil {
@@ -93,29 +170,33 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 	httpRequest := fasthttp.Request{}
 	ctx.Request().CopyTo(&httpRequest)
 	additionalData.HttpRequest = &httpRequest
+	additionalData.HttpRequestClientIp = service.clientIp(ctx)
+	additionalData.HttpRequestParams = params
 
 	switch responseMode {
 	case structs.WebhookResponseMode_OnReceived:
-		// Return immediately while executing a workflow in background via goroutine
-		// A known issue in test suite: the goroutine of TestA may report error when TestB is running
-		executionId, _, err := core.RunWorkflow(
+		// Persist the request and return immediately, the webhook queue workers execute the workflow.
+		_, err := core.EnqueueWebhookRequest(
 			ctx.UserContext(),
-			"",
 			workflowEntity,
-			additionalData,
-			structs.WorkflowExecutionMode_Webhook,
-			webhookNode)
-		if err != nil {
+			webhookNode,
+			&httpRequest,
+			params,
+			additionalData.HttpRequestClientIp,
+			idempotencyKey)
+		if errors.Is(err, core.ErrWebhookQueueFull) {
+			return HandleTooManyRequestsErrorWithTrace(ctx, err)
+		} else if err != nil {
 			return HandleInternalServerErrorWithTrace(ctx, err)
 		}
+		accepted = true
 		if options.NoResponseBody {
-			return ctx.Status(fiber.StatusOK).SendString("")
+			return ctx.Status(fiber.StatusAccepted).SendString("")
 		} else if options.ResponseData != "" {
-			return ctx.Status(fiber.StatusOK).SendString(options.ResponseData)
+			return ctx.Status(fiber.StatusAccepted).SendString(options.ResponseData)
 		} else {
-			return ctx.Status(fiber.StatusOK).JSON(map[string]string{
-				"executionId": executionId,
-				"message":     "Workflow was started",
+			return ctx.Status(fiber.StatusAccepted).JSON(map[string]string{
+				"message": "Workflow was queued",
 			})
 		}
 
@@ -131,8 +212,15 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 		if err != nil {
 			return HandleInternalServerErrorWithTrace(ctx, err)
 		}
-
-		executionData, err := executingWorkflowData.WorkflowExecutionRun.Wait(ctx.UserContext(), nil)
+		accepted = true
+		service.recordWebhookIdempotencyKey(ctx, idempotencyKey, executionId)
+
+		waitCtx, cancel := context.WithTimeout(ctx.UserContext(), core.GetWebhookResponseTimeout(options))
+		defer cancel()
+		executionData, err := executingWorkflowData.WorkflowExecutionRun.Wait(waitCtx, nil)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return sendWebhookTimeoutResponse(ctx, workflowId, webhookNode.ID, executionId)
+		}
 		if err != nil {
 			_ = service.Logger.Log(
 				"msg", "Error in workflow",
@@ -150,17 +238,27 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 		// Though workflow execution is single-threaded, still make a thread-safe hook function.
 		sendOnce := sync.Once{}
 		var sendResponseErr error
-		responseSendChan := make(chan struct{})
+		responseSendChan := make(chan struct{}, 1)
+		timedOut := atomic.Bool{}
 
-		sendResponseFunc := func(_ context.Context, hooks *structs.WorkflowHooks, response *fasthttp.Response) {
+		sendResponseFunc := func(hookCtx context.Context, hooks *structs.WorkflowHooks, response *fasthttp.Response) {
+			sent := false
 			sendOnce.Do(func() {
-				for _, headerKeyBytes := range response.Header.PeekKeys() {
-					ctx.Set(string(headerKeyBytes), string(response.Header.Peek(string(headerKeyBytes))))
-				}
-				sendResponseErr = ctx.Status(response.Header.StatusCode()).Send(response.Body())
+				sendResponseErr = sendWebhookResponse(ctx, response)
+				sent = true
 				// Nofity the waiting execution
 				responseSendChan <- struct{}{}
 			})
+			// The request is already answered with 202, keep the response for the status URL.
+			if !sent && timedOut.Load() {
+				if err := core.SaveWebhookResponse(hookCtx, hooks.ExecutionId, response); err != nil {
+					_ = service.Logger.Log(
+						"msg", "Failed to save the webhook response",
+						"err", err,
+						"workflowId", workflowId,
+						"executionId", hooks.ExecutionId)
+				}
+			}
 		}
 
 		additionalData.Hooks.HookFunctions.SendResponse = append(
@@ -177,10 +275,20 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 		if err != nil {
 			return HandleInternalServerErrorWithTrace(ctx, err)
 		}
+		accepted = true
+		service.recordWebhookIdempotencyKey(ctx, idempotencyKey, executionId)
 
 		// Await execution result
-		data, err := executingWorkflowData.WorkflowExecutionRun.Wait(ctx.UserContext(), responseSendChan)
-		// TODO It only covers the workflow DB error. Need to get node execution error.
+		waitCtx, cancel := context.WithTimeout(ctx.UserContext(), core.GetWebhookResponseTimeout(options))
+		defer cancel()
+		data, err := executingWorkflowData.WorkflowExecutionRun.Wait(waitCtx, responseSendChan)
+		if errors.Is(err, context.DeadlineExceeded) {
+			timedOut.Store(true)
+			sendOnce.Do(func() {
+				sendResponseErr = sendWebhookTimeoutResponse(ctx, workflowId, webhookNode.ID, executionId)
+			})
+			return sendResponseErr
+		}
 		if err != nil {
 			_ = service.Logger.Log(
 				"msg", "Error in workflow",
@@ -194,18 +302,18 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 			})
 		}
 
-		// Check workflow execution data error
-		if data != nil && data.ResultData.Error != "" {
+		// Check the error of the workflow execution and of the failed node
+		if executionError := core.GetWorkflowExecutionError(data); executionError != "" {
 			_ = service.Logger.Log(
 				"msg", "Error in workflow",
-				"err", data.ResultData.Error,
+				"err", executionError,
 				"workflowId", workflowId,
 				"nodeName", data.ResultData.LastNodeExecuted)
 			sendOnce.Do(func() {
 				sendResponseErr = ctx.Status(fiber.StatusInternalServerError).JSON(
 					map[string]string{
 						"executionId": executionId,
-						"message":     data.ResultData.Error,
+						"message":     executionError,
 					})
 			})
 		}
@@ -225,6 +333,185 @@ func (service *WorkflowService) HandleWebhook(ctx *fiber.Ctx) error {
 	}
 }
 
+// sendWebhookIdempotentResponse returns the response of the first delivery with the idempotency key,
+// or 200 with its execution if it is not responded yet.
+func sendWebhookIdempotentResponse(ctx *fiber.Ctx, storedKey *rdsDbLib.WorkflowWebhookIdempotencyKey) error {
+	if storedKey.ResponseStatusCode.Valid {
+		if storedKey.ResponseContentType.Valid {
+			ctx.Set(fiber.HeaderContentType, storedKey.ResponseContentType.String)
+		}
+		return ctx.Status(int(storedKey.ResponseStatusCode.Int32)).Send(storedKey.ResponseBody)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(map[string]string{
+		"executionId": storedKey.ExecutionId.String,
+		"message":     "Workflow was already started for the idempotency key",
+	})
+}
+
+// sendWebhookResponse writes the response of the Respond to Webhook node, the body stream is sent chunked.
+func sendWebhookResponse(ctx *fiber.Ctx, response *fasthttp.Response) error {
+	response.Header.VisitAll(func(key, value []byte) {
+		// The length is set by the body.
+		if string(key) != fiber.HeaderContentLength {
+			ctx.Set(string(key), string(value))
+		}
+	})
+	ctx.Status(response.StatusCode())
+	if response.IsBodyStream() {
+		ctx.Context().SetBodyStream(response.BodyStream(), -1)
+		return nil
+	}
+	return ctx.Send(response.Body())
+}
+
+// webhookExecutionPath returns the status URL path of the webhook execution.
+func webhookExecutionPath(workflowId string, nodeId string, executionId string, token string) string {
+	return fmt.Sprintf("/workflow/public/webhook/workflow/%s/node/%s/execution/%s/%s", workflowId, nodeId, executionId, token)
+}
+
+// sendWebhookTimeoutResponse answers the webhook request not responded in the response timeout with 202
+// and the status URL of the execution, which goes on in the background.
+func sendWebhookTimeoutResponse(ctx *fiber.Ctx, workflowId string, nodeId string, executionId string) error {
+	token, err := core.CreateWebhookResponseToken(ctx.UserContext(), executionId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	statusUrl := ctx.BaseURL() + webhookExecutionPath(workflowId, nodeId, executionId, token)
+	ctx.Set(fiber.HeaderLocation, statusUrl)
+	return ctx.Status(fiber.StatusAccepted).JSON(map[string]string{
+		"executionId": executionId,
+		"statusUrl":   statusUrl,
+		"message":     "Workflow is still running",
+	})
+}
+
+// GetWebhookExecutionResponse returns the webhook response of the execution answered with 202 after the
+// response timeout, or 202 until the execution ends. The request must have the token of the status URL,
+// and is authenticated like the webhook request.
+func (service *WorkflowService) GetWebhookExecutionResponse(ctx *fiber.Ctx) error {
+	workflowId := ctx.Params("workflowId")
+	nodeId := ctx.Params("nodeId")
+	executionId := ctx.Params("executionId")
+	id, err := strconv.Atoi(executionId)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, fmt.Errorf("invalid executionId: %s", executionId))
+	}
+
+	workflowEntity, err := core.GetWorkflowEntityById(ctx.UserContext(), workflowId)
+	if err != nil {
+		return HandleNotFoundErrorWithTrace(ctx, err)
+	}
+	webhookNode := workflowEntity.GetNodeById(nodeId)
+	if webhookNode == nil {
+		return HandleNotFoundErrorWithTrace(ctx, errors.New("no such webhook node in the workflow"))
+	}
+	if err := service.authenticateWebhookRequest(ctx, workflowEntity.SugerOrgId, webhookNode); err != nil {
+		return handleWebhookAuthError(ctx, webhookNode, err)
+	}
+	if err := core.VerifyWebhookResponseToken(ctx.UserContext(), int32(id), ctx.Params("token")); err != nil {
+		if errors.Is(err, core.ErrInvalidWebhookResponseToken) {
+			return HandleNotFoundErrorWithTrace(ctx, errors.New("no such execution"))
+		}
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+
+	execution, err := core.GetWorkflowExecution(ctx.UserContext(), int32(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return HandleNotFoundErrorWithTrace(ctx, errors.New("no such execution"))
+	} else if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	// Only the execution started by the webhook node.
+	if execution.WorkflowId != workflowId || execution.Data == nil || execution.Data.ResultData == nil ||
+		len(execution.Data.ResultData.RunData[webhookNode.Name]) == 0 {
+		return HandleNotFoundErrorWithTrace(ctx, errors.New("no such execution of the webhook node"))
+	}
+
+	options, err := core.ParseWebhookNodeOptions(webhookNode)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	for _, entry := range options.ResponseHeaders.Entries {
+		ctx.Set(entry.Name, entry.Value)
+	}
+	if !core.IsWorkflowExecutionEnded(execution) {
+		return ctx.Status(fiber.StatusAccepted).JSON(map[string]string{
+			"executionId": executionId,
+			"status":      string(execution.Status),
+			"message":     "Workflow is still running",
+		})
+	}
+
+	responseMode, err := webhookNode.GetWebhookResponseMode()
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	if responseMode != structs.WebhookResponseMode_ResponseNode {
+		return sendResponseUsingLastNodeResult(ctx, executionId, execution.Data, webhookNode, options)
+	}
+
+	// The response of the Respond to Webhook node
+	response, err := core.GetWebhookResponse(ctx.UserContext(), int32(id))
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	if response != nil {
+		return sendWebhookResponse(ctx, response)
+	}
+	if executionError := core.GetWorkflowExecutionError(execution.Data); executionError != "" {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(map[string]string{
+			"executionId": executionId,
+			"message":     executionError,
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(map[string]string{
+		"executionId": executionId,
+		"message":     "Workflow executed successfully",
+	})
+}
+
+// recordWebhookIdempotencyKey records the execution of the delivery with the idempotency key, if any.
+func (service *WorkflowService) recordWebhookIdempotencyKey(
+	ctx *fiber.Ctx, idempotencyKey *core.WebhookIdempotencyKey, executionId string) {
+	if idempotencyKey == nil {
+		return
+	}
+	if err := core.RecordWebhookIdempotencyKeyExecution(ctx.UserContext(), idempotencyKey, executionId); err != nil {
+		_ = service.Logger.Log("message", "Failed to record the idempotency key",
+			"err", err, "workflowId", idempotencyKey.WorkflowId, "executionId", executionId)
+	}
+}
+
+// saveWebhookIdempotencyResponse saves the written response of the accepted delivery with the idempotency key.
+// The key of the delivery which is not accepted, e.g. rejected with 429, is released so that the retry is accepted.
+func (service *WorkflowService) saveWebhookIdempotencyResponse(
+	ctx *fiber.Ctx, idempotencyKey *core.WebhookIdempotencyKey, accepted bool) {
+	if !accepted {
+		if err := core.ReleaseWebhookIdempotencyKey(ctx.UserContext(), idempotencyKey); err != nil {
+			_ = service.Logger.Log("message", "Failed to release the idempotency key",
+				"err", err, "workflowId", idempotencyKey.WorkflowId)
+		}
+		return
+	}
+	response := ctx.Response()
+	// The streamed body is not kept, the repeated delivery gets the execution instead.
+	if response.IsBodyStream() {
+		return
+	}
+	err := core.SaveWebhookIdempotencyResponse(
+		ctx.UserContext(),
+		idempotencyKey,
+		response.StatusCode(),
+		string(response.Header.ContentType()),
+		response.Body())
+	if err != nil {
+		_ = service.Logger.Log("message", "Failed to save the response of the idempotency key",
+			"err", err, "workflowId", idempotencyKey.WorkflowId)
+	}
+}
+
+// GetFromTrigger returns the HTML page of the form, or the parameters of the Form Trigger node
+// if the client accepts JSON.
 func (service *WorkflowService) GetFromTrigger(ctx *fiber.Ctx) error {
 	orgId := ctx.Params("orgId")
 	workflowId := ctx.Params("workflowId")
@@ -256,22 +543,36 @@ func (service *WorkflowService) GetFromTrigger(ctx *fiber.Ctx) error {
 	if !found {
 		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
+	if err := service.authenticateWebhookRequest(ctx, orgId, fromTriggerNode); err != nil {
+		return handleWebhookAuthError(ctx, fromTriggerNode, err)
+	}
 
 	if len(fromTriggerNode.Parameters) == 0 {
 		return HandleInternalServerErrorWithTrace(ctx, fmt.Errorf("form trigger parameter is empty"))
 	}
 
-	//response := structs.GetWorkflowFromResponse{
-	//	Parameters: workflowEntity.Nodes[0].Parameters,
-	//}
-
-	response := map[string]interface{}{
-		"parameters": fromTriggerNode.Parameters,
+	if acceptsFormJson(ctx) {
+		response := map[string]interface{}{
+			"parameters": fromTriggerNode.Parameters,
+		}
+		return ctx.Status(fiber.StatusOK).JSON(response)
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(response)
+	form, err := core.GetFormTriggerParameters(fromTriggerNode)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	page, err := core.RenderFormTrigger(form, nil)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	ctx.Type("html")
+	return ctx.Status(fiber.StatusOK).Send(page)
 }
 
+// StartFromTriggerData validates the submission of the form and runs the workflow, then shows the completion page
+// or redirects by the response mode of the Form Trigger node. The invalid submission gets 400 with the form
+// and the errors, and does not start an execution.
 func (service *WorkflowService) StartFromTriggerData(ctx *fiber.Ctx) error {
 
 	orgId := ctx.Params("orgId")
@@ -282,38 +583,66 @@ func (service *WorkflowService) StartFromTriggerData(ctx *fiber.Ctx) error {
 		return HandleBadRequestErrorWithTrace(ctx, fmt.Errorf("orgId or workflowId or nodeId is empty"))
 	}
 
-	params := map[string]interface{}{}
-	if err := ctx.BodyParser(&params); err != nil {
-		return HandleBadRequestErrorWithTrace(ctx, err)
-	}
-
 	// Get the workflow entity
 	workflowEntity, err := core.GetWorkflowEntity(ctx.UserContext(), orgId, workflowId)
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
 
-	// Check form data
-	node := workflowEntity.GetNodeById(nodeId)
-	if err = core.CheckFormTriggerParam(params, node); err != nil {
-		return HandleInternalServerErrorWithTrace(ctx, err)
-	}
-
 	// Find webhook node
 	webhookNode := workflowEntity.GetNodeById(nodeId)
 	if webhookNode == nil {
 		return HandleNotFoundErrorWithTrace(ctx, errors.New("no such webhook node in the workflow"))
 	}
+	if err := service.authenticateWebhookRequest(ctx, orgId, webhookNode); err != nil {
+		return handleWebhookAuthError(ctx, webhookNode, err)
+	}
+
+	// Check form data
+	form, err := core.GetFormTriggerParameters(webhookNode)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	submission, binary, err := core.ParseFormTriggerSubmission(ctx.Request(), form)
+	if err != nil {
+		return sendFormValidationError(ctx, form, err)
+	}
+	responseMode, err := webhookNode.GetWebhookResponseMode()
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
 
 	ctx2 := ctx.UserContext()
 	// create WorkflowExecute
 	additionalData, _, err := core.GetAdditionalDataWithHooks(
 		ctx2, structs.WorkflowExecutionMode_Trigger, workflowEntity, "")
 	if err != nil {
-		return HandleNotFoundErrorWithTrace(ctx, fmt.Errorf("Runner flow: workflowId %s create WorkflowExecute failed with err", workflowEntity.ID, err))
+		return HandleNotFoundErrorWithTrace(ctx, fmt.Errorf("Runner flow: workflowId %s create WorkflowExecute failed with err %v", workflowEntity.ID, err))
+	}
+	// Copy the request from fiber ctx.
+	httpRequest := fasthttp.Request{}
+	ctx.Request().CopyTo(&httpRequest)
+	additionalData.HttpRequest = &httpRequest
+	additionalData.HttpRequestClientIp = service.clientIp(ctx)
+	additionalData.FormSubmission = structs.NodeSingleData{"json": submission}
+	if len(binary) > 0 {
+		additionalData.FormSubmission["binary"] = binary
+	}
+
+	// The response of the Respond to Webhook node sets the completion of the form.
+	var webhookResponse atomic.Pointer[fasthttp.Response]
+	responseSendChan := make(chan struct{}, 1)
+	if responseMode == structs.WebhookResponseMode_ResponseNode {
+		sendResponseFunc := func(hookCtx context.Context, hooks *structs.WorkflowHooks, response *fasthttp.Response) {
+			if webhookResponse.CompareAndSwap(nil, response) {
+				responseSendChan <- struct{}{}
+			}
+		}
+		additionalData.Hooks.HookFunctions.SendResponse = append(
+			additionalData.Hooks.HookFunctions.SendResponse, sendResponseFunc)
 	}
 
-	_, _, err = core.RunWorkflow(ctx2, "",
+	executionId, executingWorkflowData, err := core.RunWorkflow(ctx2, "",
 		workflowEntity,
 		additionalData,
 		structs.WorkflowExecutionMode_Trigger,
@@ -323,8 +652,106 @@ func (service *WorkflowService) StartFromTriggerData(ctx *fiber.Ctx) error {
 		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
 
-	return ctx.Status(fiber.StatusOK).SendString("")
+	switch responseMode {
+	case structs.WebhookResponseMode_OnReceived:
+		formResponse, err := core.GetFormTriggerResponse(form)
+		if err != nil {
+			return HandleInternalServerErrorWithTrace(ctx, err)
+		}
+		return sendFormTriggerResponse(ctx, form, executionId, formResponse)
+
+	case structs.WebhookResponseMode_LastNode, structs.WebhookResponseMode_ResponseNode:
+		// Wait for the workflow, the form is completed when it is still running after the timeout.
+		waitCtx, cancel := context.WithTimeout(ctx2, core.GetWebhookResponseTimeout(nil))
+		defer cancel()
+		data, err := executingWorkflowData.WorkflowExecutionRun.Wait(waitCtx, responseSendChan)
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return HandleInternalServerErrorWithTrace(ctx, err)
+		}
+		if response := webhookResponse.Load(); response != nil {
+			return sendFormTriggerResponse(ctx, form, executionId, core.GetFormTriggerResponseFromWebhookResponse(response))
+		}
+		if executionError := core.GetWorkflowExecutionError(data); executionError != "" {
+			return HandleInternalServerErrorWithTrace(ctx, errors.New(executionError))
+		}
+		return sendFormTriggerResponse(ctx, form, executionId, &core.FormTriggerResponse{RespondWith: core.FormRespondWith_Text})
+
+	default:
+		err := fmt.Errorf("workflowId=%s, unknown responseMode: %s", workflowId, responseMode)
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+}
+
+// acceptsFormJson returns true if the client of the form prefers JSON to HTML, e.g. the editor.
+func acceptsFormJson(ctx *fiber.Ctx) bool {
+	return ctx.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON
+}
+
+// sendFormValidationError returns 400 with the errors of the submission, in the form page if the client accepts HTML.
+func sendFormValidationError(ctx *fiber.Ctx, form *structs.WorkflowFrom, err error) error {
+	validationError := &core.FormValidationError{}
+	if !errors.As(err, &validationError) {
+		return HandleBadRequestErrorWithTrace(ctx, err)
+	}
+	if acceptsFormJson(ctx) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(map[string]interface{}{
+			"message": err.Error(),
+			"errors":  validationError.Errors,
+		})
+	}
+	page, renderErr := core.RenderFormTrigger(form, validationError.Errors)
+	if renderErr != nil {
+		return HandleInternalServerErrorWithTrace(ctx, renderErr)
+	}
+	ctx.Type("html")
+	return ctx.Status(fiber.StatusBadRequest).Send(page)
+}
+
+// sendFormTriggerResponse shows the completion page of the submitted form or redirects to the URL.
+func sendFormTriggerResponse(
+	ctx *fiber.Ctx,
+	form *structs.WorkflowFrom,
+	executionId string,
+	formResponse *core.FormTriggerResponse) error {
+	if acceptsFormJson(ctx) {
+		response := map[string]string{"executionId": executionId}
+		if formResponse.RespondWith == core.FormRespondWith_Redirect {
+			response["redirectUrl"] = formResponse.RedirectUrl
+		} else {
+			response["formSubmittedText"] = formResponse.GetText()
+		}
+		return ctx.Status(fiber.StatusOK).JSON(response)
+	}
+	if formResponse.RespondWith == core.FormRespondWith_Redirect {
+		return ctx.Redirect(formResponse.RedirectUrl, fiber.StatusSeeOther)
+	}
+	page, err := core.RenderFormTriggerCompletion(form, formResponse.GetText())
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
+	ctx.Type("html")
+	return ctx.Status(fiber.StatusOK).Send(page)
+}
+
+// authenticateWebhookRequest checks the IP allowlist and the authentication of the webhook node.
+func (service *WorkflowService) authenticateWebhookRequest(ctx *fiber.Ctx, orgId string, webhookNode *structs.WorkflowNode) error {
+	return core.AuthenticateWebhookRequest(ctx.UserContext(), orgId, webhookNode, ctx.Request(), service.clientIp(ctx))
+}
 
+// handleWebhookAuthError returns 401 without credentials, 403 for the wrong credentials or the IP not allowed,
+// and 500 otherwise, e.g. the node has no credential.
+func handleWebhookAuthError(ctx *fiber.Ctx, webhookNode *structs.WorkflowNode, err error) error {
+	switch {
+	case errors.Is(err, core.ErrWebhookUnauthorized):
+		if authentication, _ := webhookNode.GetWebhookAuthentication(); authentication == structs.WebhookAuthentication_BasicAuth {
+			ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="Webhook"`)
+		}
+		return HandleUnauthorizedErrorWithTrace(ctx, err)
+	case errors.Is(err, core.ErrWebhookForbidden):
+		return HandleForbiddenErrorWithTrace(ctx, err)
+	default:
+		return HandleInternalServerErrorWithTrace(ctx, err)
+	}
 }
 
 // https://github.com/sugerio/workflow-service/blob/c1b5d949658247b19abfdb598cf4b427089cb099/packages/cli/src/WebhookHelpers.ts#L668
@@ -344,12 +771,12 @@ func sendResponseUsingLastNodeResult(
 			})
 	}
 
-	// Check workflow execution data error
-	if executionData.ResultData.Error != "" {
+	// Check the error of the workflow execution and of the failed node
+	if executionError := core.GetWorkflowExecutionError(executionData); executionError != "" {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(
 			map[string]string{
 				"executionId": executionId,
-				"message":     executionData.ResultData.Error,
+				"message":     executionError,
 			})
 	}
 
@@ -414,8 +841,38 @@ func sendResponseUsingLastNodeResult(
 		return ctx.Status(fiber.StatusOK).Send(resultRaw)
 
 	case structs.WebhookResponseData_FirstEntryBinary:
-		// TODO firstEntryBinary
-		return HandleInternalServerErrorWithTrace(ctx, errors.New("unimplemented"))
+		entries := mainData[0]
+		if len(entries) == 0 {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(
+				map[string]string{
+					"executionId": executionId,
+					"message":     "no entries are found in mainData[0]",
+				})
+		}
+		propertyName, _ := webhookNode.Parameters["responseBinaryPropertyName"].(string)
+		if propertyName == "" {
+			propertyName = "data"
+		}
+		binaryData, err := core.GetWebhookResponseBinaryData(entries[0], propertyName)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(
+				map[string]string{
+					"executionId": executionId,
+					"message":     err.Error(),
+				})
+		}
+		response := &fasthttp.Response{}
+		if err := core.SetWebhookBinaryResponse(response, binaryData, "attachment"); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(
+				map[string]string{
+					"executionId": executionId,
+					"message":     err.Error(),
+				})
+		}
+		if options.ResponseContentType != "" {
+			response.Header.SetContentType(options.ResponseContentType)
+		}
+		return sendWebhookResponse(ctx, response)
 
 	case structs.WebhookResponseData_NoData:
 		return ctx.Status(fiber.StatusOK).SendString("")
@@ -457,6 +914,11 @@ func getDataLastExecutedNodeData(
 
 func (service *WorkflowService) RegisterRouteMethods_Webhook() {
 	service.fiberApp.All("/workflow/public/webhook/workflow/:workflowId/node/:nodeId", service.HandleWebhook)
+	service.fiberApp.Get(
+		"/workflow/public/webhook/workflow/:workflowId/node/:nodeId/execution/:executionId/:token",
+		service.GetWebhookExecutionResponse)
+	// The custom paths of the Webhook nodes, after the route above.
+	service.fiberApp.All("/workflow/public/webhook/*", service.HandleWebhookPath)
 
 	formTriggerApi := service.fiberApp.Group("/workflow/public/form")
 	formTriggerApi.Get("/:orgId/:workflowId/:nodeId", service.GetFromTrigger)
@@ -0,0 +1,107 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_auth_test.go
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WebhookAuthTestSuite struct {
+	suite.Suite
+}
+
+func Test_WebhookAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookAuthTestSuite))
+}
+
+func (s *WebhookAuthTestSuite) Test() {
+	s.T().Run("TestWebhookAuth form trigger with basic auth and IP allowlist", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "test_files/request_create_workflow_form.json")
+		assert.Nil(err)
+
+		credentialsData, err := json.Marshal(core.HttpBasicAuthCredentials{User: "contact", Password: "s3cret"})
+		assert.Nil(err)
+		credentials, err := rdsDbQueries.CreateCredentialsEntity(context.Background(), rdsDbLib.CreateCredentialsEntityParams{
+			Name:        "Form basic auth",
+			Data:        string(credentialsData),
+			Type:        core.CredentialsType_HttpBasicAuth,
+			NodesAccess: json.RawMessage("[]"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  organization.ID,
+		})
+		assert.Nil(err)
+		formNode := &newWorkflow.Nodes[0]
+		formNode.Parameters["authentication"] = string(structs.WebhookAuthentication_BasicAuth)
+		formNode.Parameters["options"] = map[string]interface{}{"ipWhitelist": "198.51.100.0/24"}
+		formNode.Credentials = map[string]structs.WorkflowNodeCredentialsDetails{
+			core.CredentialsType_HttpBasicAuth: {ID: credentials.ID, Name: credentials.Name},
+		}
+		_, err = api.UpdateWorkflow_Testing(testFiberLambda, newWorkflow)
+		assert.Nil(err)
+
+		getForm := func(sourceIp string, userPassword string) events.APIGatewayProxyResponse {
+			headers := map[string]string{"Content-Type": "application/json"}
+			if userPassword != "" {
+				headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(userPassword))
+			}
+			response, err := testFiberLambda.Proxy(events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Path:       fmt.Sprintf("/workflow/public/form/%s/%s/%s", organization.ID, newWorkflow.ID, formNode.ID),
+				Headers:    headers,
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Identity: events.APIGatewayRequestIdentity{SourceIP: sourceIp},
+				},
+			})
+			assert.Nil(err)
+			return response
+		}
+
+		response := getForm("198.51.100.10", "")
+		assert.Equal(http.StatusUnauthorized, response.StatusCode, response.Body)
+		assert.Equal(`Basic realm="Webhook"`, response.MultiValueHeaders["Www-Authenticate"][0])
+		response = getForm("198.51.100.10", "contact:wrong")
+		assert.Equal(http.StatusForbidden, response.StatusCode, response.Body)
+		response = getForm("203.0.113.1", "contact:s3cret")
+		assert.Equal(http.StatusForbidden, response.StatusCode, response.Body)
+		response = getForm("198.51.100.10", "contact:s3cret")
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+
+		// The rejected submissions do not start any execution.
+		request := events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodPost,
+			Path:       fmt.Sprintf("/workflow/public/form/%s/%s/%s", organization.ID, newWorkflow.ID, formNode.ID),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"name":"test","email":"test@test.com","Hobby":"basketball"}`,
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Identity: events.APIGatewayRequestIdentity{SourceIP: "198.51.100.10"},
+			},
+		}
+		response, err = testFiberLambda.Proxy(request)
+		assert.Nil(err)
+		assert.Equal(http.StatusUnauthorized, response.StatusCode, response.Body)
+		executions, err := rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(context.Background(),
+			rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{WorkflowId: newWorkflow.ID, Limit: 10})
+		assert.Nil(err)
+		assert.Empty(executions)
+	})
+}
@@ -0,0 +1,66 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_idempotency_test.go
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WebhookIdempotencyTestSuite struct {
+	suite.Suite
+}
+
+func Test_WebhookIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookIdempotencyTestSuite))
+}
+
+func (s *WebhookIdempotencyTestSuite) Test() {
+	s.T().Run("TestWebhookIdempotency repeated deliveries", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		workflowEntity, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_idempotency_key.json")
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, workflowEntity.ID)
+		assert.Nil(err)
+		webhookNode := workflowEntity.Nodes[0]
+
+		callWebhook := func(body string) map[string]interface{} {
+			result, err := api.CallWebhook_Testing(testFiberLambda, http.MethodPost,
+				workflowEntity.ID, webhookNode.ID, webhookNode.WebhookId, false, body)
+			assert.Nil(err)
+			return result
+		}
+		first := callWebhook(`{"id":"evt_1","amount":12}`)
+		assert.Equal("evt_1", first["id"])
+		executionId, ok := first["executionId"].(string)
+		assert.True(ok)
+
+		// The retried delivery returns the response of the first one without a new execution.
+		assert.Equal(first, callWebhook(`{"id":"evt_1","amount":12}`))
+
+		// Another key starts a new execution.
+		second := callWebhook(`{"id":"evt_2"}`)
+		assert.Equal("evt_2", second["id"])
+		assert.NotEqual(executionId, second["executionId"])
+
+		// The key is recorded on the execution.
+		id, err := strconv.Atoi(executionId)
+		assert.Nil(err)
+		metadata, err := core.GetExecutionMetadata(context.Background(), int32(id))
+		assert.Nil(err)
+		assert.Equal("evt_1", metadata[core.ExecutionMetadataKey_IdempotencyKey])
+	})
+}
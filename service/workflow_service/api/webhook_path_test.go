@@ -0,0 +1,95 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_path_test.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WebhookPathTestSuite struct {
+	suite.Suite
+}
+
+func Test_WebhookPathTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookPathTestSuite))
+}
+
+func (s *WebhookPathTestSuite) Test() {
+	s.T().Run("TestWebhookPath route parameters and conflicts", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		workflowEntity, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_path.json")
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, workflowEntity.ID)
+		assert.Nil(err)
+
+		callWebhook := func(method string, path string) events.APIGatewayProxyResponse {
+			response, err := testFiberLambda.Proxy(events.APIGatewayProxyRequest{
+				HTTPMethod: method,
+				Path:       fmt.Sprintf("/workflow/public/webhook/%s/%s", organization.ID, path),
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       `{"amount":12}`,
+			})
+			assert.Nil(err)
+			return response
+		}
+		response := callWebhook(http.MethodPost, "orders/o-42")
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		responseBody := map[string]interface{}{}
+		assert.Nil(json.Unmarshal([]byte(response.Body), &responseBody))
+		assert.Equal("o-42", responseBody["orderId"])
+		assert.Equal(map[string]interface{}{"amount": float64(12)}, responseBody["body"])
+
+		response = callWebhook(http.MethodGet, "orders/o-42")
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+		response = callWebhook(http.MethodPost, "orders/o-42/items")
+		assert.Equal(http.StatusNotFound, response.StatusCode, response.Body)
+
+		// Another workflow of the org can not be activated with the same path.
+		conflicting, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_path.json")
+		assert.Nil(err)
+		conflicting.Nodes[0].Parameters["path"] = "/orders/:id"
+		_, err = api.UpdateWorkflow_Testing(testFiberLambda, conflicting)
+		assert.Nil(err)
+		activateRequest, err := json.Marshal(structs.WorkflowEntity{ID: conflicting.ID, SugerOrgId: organization.ID, Active: true})
+		assert.Nil(err)
+		response, err = testFiberLambda.Proxy(events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPatch,
+			Path:           fmt.Sprintf("/workflow/org/%s/workflow/%s", organization.ID, conflicting.ID),
+			Headers:        map[string]string{"Content-Type": "application/json"},
+			Body:           string(activateRequest),
+			RequestContext: api.AuthorizerRequestContext,
+		})
+		assert.Nil(err)
+		assert.Equal(http.StatusConflict, response.StatusCode, response.Body)
+		workflow, err := api.GetWorkflow_Testing(testFiberLambda, organization.ID, conflicting.ID)
+		assert.Nil(err)
+		assert.False(workflow.Active)
+
+		// The more specific path wins.
+		conflicting.Nodes[0].Parameters["path"] = "orders/new"
+		_, err = api.UpdateWorkflow_Testing(testFiberLambda, conflicting)
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, conflicting.ID)
+		assert.Nil(err)
+		response = callWebhook(http.MethodPost, "orders/new")
+		assert.Equal(http.StatusOK, response.StatusCode, response.Body)
+		responseBody = map[string]interface{}{}
+		assert.Nil(json.Unmarshal([]byte(response.Body), &responseBody))
+		assert.Nil(responseBody["orderId"])
+	})
+}
@@ -0,0 +1,61 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_queue_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WebhookQueueTestSuite struct {
+	suite.Suite
+}
+
+func Test_WebhookQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookQueueTestSuite))
+}
+
+func (s *WebhookQueueTestSuite) Test() {
+	s.T().Run("TestWebhookQueue onReceived requests are queued", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		ctx := context.Background()
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		workflowEntity, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_onReceived.json")
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, workflowEntity.ID)
+		assert.Nil(err)
+		nodeId, webhookId, err := api.GetWebhookIdAndNodeIdInWorkflow(workflowEntity)
+		assert.Nil(err)
+
+		for i := 0; i < 3; i++ {
+			response, err := api.CallWebhookFullResponse_Testing(
+				testFiberLambda, http.MethodPost, workflowEntity.ID, nodeId, webhookId, false, `{"msg":"queued"}`)
+			assert.Nil(err)
+			assert.Equal(http.StatusAccepted, response.StatusCode, response.Body)
+			responseBody := map[string]interface{}{}
+			assert.Nil(json.Unmarshal([]byte(response.Body), &responseBody))
+			assert.Equal("Workflow was queued", responseBody["message"])
+		}
+
+		// The workers execute the queued requests.
+		assert.Eventually(func() bool {
+			queued, err := rdsDbQueries.CountWebhookRequestsByWorkflowId(ctx, workflowEntity.ID)
+			return err == nil && queued == 0
+		}, 30*time.Second, 200*time.Millisecond)
+		count, err := rdsDbQueries.CountWorkflowExecutionEntitiesByWorkflowId(ctx, workflowEntity.ID)
+		assert.Nil(err)
+		assert.Equal(int64(3), count)
+	})
+}
@@ -0,0 +1,78 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_response_timeout_test.go
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WebhookResponseTimeoutTestSuite struct {
+	suite.Suite
+}
+
+func Test_WebhookResponseTimeoutTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookResponseTimeoutTestSuite))
+}
+
+func (s *WebhookResponseTimeoutTestSuite) Test() {
+	s.T().Run("TestWebhookResponseTimeout lastNode", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		workflowEntity, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "./test_files/workflow_execution_webhook_with_response_timeout.json")
+		assert.Nil(err)
+		err = api.ActivateWorkflow_Testing(testFiberLambda, organization.ID, workflowEntity.ID)
+		assert.Nil(err)
+		webhookNode := workflowEntity.Nodes[0]
+
+		// The request is answered with 202 after the response timeout.
+		response, err := api.CallWebhookFullResponse_Testing(testFiberLambda, http.MethodPost,
+			workflowEntity.ID, webhookNode.ID, webhookNode.WebhookId, false, `{"id":"evt_1"}`)
+		assert.Nil(err)
+		assert.Equal(http.StatusAccepted, response.StatusCode)
+		result := map[string]string{}
+		assert.Nil(json.Unmarshal([]byte(response.Body), &result))
+		executionId := result["executionId"]
+		assert.NotEmpty(executionId)
+		executionPath := "/workflow/public/webhook/workflow/" + workflowEntity.ID + "/node/" + webhookNode.ID + "/execution/" + executionId + "/"
+		assert.Contains(result["statusUrl"], executionPath)
+		token := result["statusUrl"][strings.Index(result["statusUrl"], executionPath)+len(executionPath):]
+		assert.NotEmpty(token)
+
+		// The status URL without the token of the execution is not found.
+		response, err = api.GetWebhookExecutionResponse_Testing(
+			testFiberLambda, workflowEntity.ID, webhookNode.ID, executionId, "invalid")
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode)
+
+		// The status URL returns 202 until the execution ends, then the response of the last node.
+		response, err = api.GetWebhookExecutionResponse_Testing(testFiberLambda, workflowEntity.ID, webhookNode.ID, executionId, token)
+		assert.Nil(err)
+		assert.Equal(http.StatusAccepted, response.StatusCode)
+		assert.Eventually(func() bool {
+			response, err = api.GetWebhookExecutionResponse_Testing(
+				testFiberLambda, workflowEntity.ID, webhookNode.ID, executionId, token)
+			return err == nil && response.StatusCode != http.StatusAccepted
+		}, 10*time.Second, 200*time.Millisecond)
+		assert.Equal(http.StatusOK, response.StatusCode)
+		assert.JSONEq(`{"id":"evt_1"}`, response.Body)
+
+		// The execution of another workflow is not found.
+		response, err = api.GetWebhookExecutionResponse_Testing(
+			testFiberLambda, workflowEntity.ID, webhookNode.ID, "0", token)
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode)
+	})
+}
@@ -4,9 +4,11 @@ package api_test
 // go test -v service/workflow_service/api/service_test.go service/workflow_service/api/webhook_test.go
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
@@ -15,7 +17,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	_ "github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/code"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
@@ -54,13 +58,11 @@ func (s *WebhookTestSuite) TestHandleWebhook() {
 			testFiberLambda, http.MethodPost, workflowId, nodeId, webhookId, false, defaultRequestJson)
 		assert.Nil(err)
 		// Check Result Body
-		assert.Equal(200, webhookResponse.StatusCode)
+		assert.Equal(202, webhookResponse.StatusCode)
 		var webhookResponseBody map[string]interface{}
 		err = json.Unmarshal([]byte(webhookResponse.Body), &webhookResponseBody)
 		assert.Nil(err)
-		assert.Equal("Workflow was started", webhookResponseBody["message"].(string))
-		executionId := webhookResponseBody["executionId"].(string)
-		assert.NotEmpty(executionId)
+		assert.Equal("Workflow was queued", webhookResponseBody["message"].(string))
 		// Check Result Header
 		headerH1 := webhookResponse.MultiValueHeaders["H1"]
 		assert.Equal(1, len(headerH1))
@@ -68,6 +70,14 @@ func (s *WebhookTestSuite) TestHandleWebhook() {
 
 		time.Sleep(3 * time.Second)
 
+		// The queued request is executed by the webhook queue workers.
+		executionEntities, err := rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(
+			context.Background(),
+			rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{WorkflowId: workflowId, Limit: 1})
+		assert.Nil(err)
+		assert.Len(executionEntities, 1)
+		executionId := strconv.Itoa(int(executionEntities[0].ID))
+
 		// Get and Verify execution result
 		execution, err := api.GetWorkflowExecution_Testing(testFiberLambda, organization.ID, executionId)
 		assert.Nil(err)
@@ -258,7 +268,7 @@ func (s *WorkflowTestSuit) TestGetFromTrigger() {
 			HTTPMethod:     http.MethodGet,
 			Path:           fmt.Sprintf("/workflow/public/form/%s/%s/%s", organization.ID, newWorkflow.ID, newWorkflow.Nodes[0].ID),
 			RequestContext: api.AuthorizerRequestContext,
-			Headers:        map[string]string{"Content-Type": "application/json"},
+			Headers:        map[string]string{"Content-Type": "application/json", "Accept": "application/json"},
 			Body:           "",
 		}
 
@@ -280,6 +290,17 @@ func (s *WorkflowTestSuit) TestGetFromTrigger() {
 
 		// http response  == json file?
 		assert.Equal(workflowFromResponse.Parameters, &workflowFrom2)
+
+		// The browser gets the HTML form.
+		request_FormPage := request_WorkflowsEntity
+		request_FormPage.Headers = map[string]string{"Accept": "text/html,application/xhtml+xml,*/*;q=0.8"}
+		resFormPage, err := testFiberLambda.Proxy(request_FormPage)
+		assert.Nil(err)
+		assert.Equal(fiber.StatusOK, resFormPage.StatusCode)
+		assert.Contains(resFormPage.Headers["Content-Type"], "text/html")
+		assert.Contains(resFormPage.Body, "<h1>Contact Us Now Customize</h1>")
+		assert.Contains(resFormPage.Body, `<input id="field-1" name="Email" type="email" required>`)
+		assert.Contains(resFormPage.Body, `<option value="basketball">basketball</option>`)
 		// Delete workflow
 		err = api.DeleteWorkflow_Testing(testFiberLambda, organization.ID, newWorkflow.ID)
 		assert.Nil(err)
@@ -295,7 +316,7 @@ func (s *WorkflowTestSuit) TestGetFromTrigger() {
 
 func (s *WorkflowTestSuit) TestPostFromTrigger() {
 	s.T().Run("Test nodes execution order", func(t *testing.T) {
-		defaultRequestJson := `{"name":"test","email":"test@test.com","Hobby":"basketball"}`
+		defaultRequestJson := `{"Name":"test","Email":"test@test.com","Address":"Main St","Hobby":"basketball"}`
 		t.Parallel()
 		assert := require.New(s.T())
 
@@ -314,18 +335,41 @@ func (s *WorkflowTestSuit) TestPostFromTrigger() {
 			HTTPMethod:     http.MethodPost,
 			Path:           fmt.Sprintf("/workflow/public/form/%s/%s/%s", organization.ID, newWorkflow.ID, newWorkflow.Nodes[0].ID),
 			RequestContext: api.AuthorizerRequestContext,
-			Headers:        map[string]string{"Content-Type": "application/json"},
+			Headers:        map[string]string{"Content-Type": "application/json", "Accept": "application/json"},
 			Body:           defaultRequestJson,
 		}
 
 		resWorkflowsEntity, err := testFiberLambda.Proxy(request_WorkflowsEntity)
 		assert.Nil(err)
-		assert.Equal(fiber.StatusOK, resWorkflowsEntity.StatusCode)
+		assert.Equal(fiber.StatusOK, resWorkflowsEntity.StatusCode, resWorkflowsEntity.Body)
 		assert.NotNil(resWorkflowsEntity)
 
-		var workflowFromResponse structs.GetWorkflowFromResponse
-		err = json.Unmarshal([]byte(resWorkflowsEntity.Body), &workflowFromResponse)
+		formResponse := map[string]string{}
+		err = json.Unmarshal([]byte(resWorkflowsEntity.Body), &formResponse)
 		assert.Nil(err, fmt.Sprint("response body:", resWorkflowsEntity.Body))
+		assert.NotEmpty(formResponse["executionId"])
+		assert.Equal(core.DefaultFormSubmittedText, formResponse["formSubmittedText"])
+
+		// The invalid submission is rejected with the errors.
+		request_InvalidForm := request_WorkflowsEntity
+		request_InvalidForm.Body = `{"Name":"test","Email":"test","Hobby":"chess"}`
+		resInvalidForm, err := testFiberLambda.Proxy(request_InvalidForm)
+		assert.Nil(err)
+		assert.Equal(fiber.StatusBadRequest, resInvalidForm.StatusCode)
+		invalidResponse := struct {
+			Errors []string `json:"errors"`
+		}{}
+		assert.Nil(json.Unmarshal([]byte(resInvalidForm.Body), &invalidResponse))
+		assert.Equal([]string{"Email must be an email address", "Address is required", "Hobby has no option chess"}, invalidResponse.Errors)
+
+		// The browser gets the completion page.
+		request_FormPage := request_WorkflowsEntity
+		request_FormPage.Headers = map[string]string{"Content-Type": "application/x-www-form-urlencoded", "Accept": "text/html"}
+		request_FormPage.Body = "Name=test&Email=test%40test.com&Address=Main+St&Hobby=soccer"
+		resFormPage, err := testFiberLambda.Proxy(request_FormPage)
+		assert.Nil(err)
+		assert.Equal(fiber.StatusOK, resFormPage.StatusCode)
+		assert.Contains(resFormPage.Body, core.DefaultFormSubmittedText)
 
 		err = api.DeleteWorkflow_Testing(testFiberLambda, organization.ID, newWorkflow.ID)
 		assert.Nil(err)
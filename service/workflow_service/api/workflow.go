@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -31,6 +33,10 @@ func (service *WorkflowService) CreateWorkflow(c *fiber.Ctx) error {
 	if params.Name == "" {
 		return HandleBadRequestErrorWithTrace(c, errors.New("name is invalid"))
 	}
+	tagIds, err := structs.WorkflowTagIds(params.Tags)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
 	// Ensure the request body has the same orgId as the path parameter.
 	params.SugerOrgId = orgId
 	// Ensure each node has the same orgId as the path parameter.
@@ -41,12 +47,26 @@ func (service *WorkflowService) CreateWorkflow(c *fiber.Ctx) error {
 			params.Nodes[i].ID = uuid.NewString()
 		}
 	}
+	if err := checkCredentialsUse(c, orgId, params.Nodes, nil); err != nil {
+		return handlePermissionError(c, err)
+	}
 
 	// Generate new workflow ID and versionId
 	params.ID = uuid.NewString()
 	params.VersionId = uuid.NewString()
+	// The workflow, its history and tags are saved in one transaction, the tags are checked first.
+	txQueries, tx, err := service.rdsDbQueries.BeginTx(c.UserContext())
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	defer tx.Rollback()
+	if tagIds != nil {
+		if tagIds, err = core.LockWorkflowTags(c.UserContext(), txQueries, orgId, tagIds); err != nil {
+			return handleSaveWorkflowError(c, err)
+		}
+	}
 	// insert into execution_entity
-	_, err := service.rdsDbQueries.CreateWorkflowEntity(
+	_, err = txQueries.CreateWorkflowEntity(
 		c.UserContext(),
 		rdsDbLib.CreateWorkflowEntityParams{
 			Name:         params.Name,
@@ -65,6 +85,20 @@ func (service *WorkflowService) CreateWorkflow(c *fiber.Ctx) error {
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(c, err)
 	}
+	if err := core.SaveWorkflowHistory(c.UserContext(), txQueries, &params, requestAuthor(c)); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	if tagIds != nil {
+		if err := core.SetWorkflowTags(c.UserContext(), txQueries, orgId, params.ID, tagIds); err != nil {
+			return handleSaveWorkflowError(c, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	if err := fillWorkflowTags(c.UserContext(), &params); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
 
 	response := structs.GetWorkflowResponse{Data: &params}
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -81,6 +115,9 @@ func (service *WorkflowService) GetWorkflow(c *fiber.Ctx) error {
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(c, err)
 	}
+	if err := fillWorkflowTags(c.UserContext(), workflowEntity); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
 	response := structs.GetWorkflowResponse{Data: workflowEntity}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -99,6 +136,18 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 	if err := c.BodyParser(&params); err != nil {
 		return HandleBadRequestErrorWithTrace(c, err)
 	}
+	// The active status is kept unless it is set in the request body, e.g. if only the tags are updated.
+	activeParams := struct {
+		Active *bool `json:"active"`
+	}{}
+	if err := c.BodyParser(&activeParams); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	// The tags are replaced if set in the request body.
+	tagIds, err := structs.WorkflowTagIds(params.Tags)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
 
 	onlyUpdateActive := false
 	// If request body contains name, it means need update a new version.
@@ -119,11 +168,39 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(c, err)
 	}
+	if activeParams.Active == nil {
+		params.Active = workflowEntity.Active
+	}
+	// The credentials newly added to the nodes must be usable by the caller.
+	if !onlyUpdateActive {
+		if err := checkCredentialsUse(c, orgId, params.Nodes, workflowEntity.Nodes); err != nil {
+			return handlePermissionError(c, err)
+		}
+	}
 
-	// Just update active, handle the webhook register/unregister and return.
+	// Just update active and the tags, handle the webhook register/unregister and return.
+	// The schedule and the webhooks are left as they are if only the tags are updated.
 	if onlyUpdateActive {
+		updateActive := activeParams.Active != nil
+		// The workflow with the invalid or conflicting webhook path can not be activated.
+		if updateActive && params.Active {
+			if err := core.CheckWebhookPaths(c.UserContext(), workflowEntity, false); err != nil {
+				return handleWebhookPathError(c, err)
+			}
+		}
 		// Call hook "workflow.update" here
-		workflowEntityUpdated_RdsDbLib, err := service.rdsDbQueries.UpdateWorkflowEntityActive(
+		// The active status and tags are saved in one transaction, the tags are checked first.
+		txQueries, tx, err := service.rdsDbQueries.BeginTx(c.UserContext())
+		if err != nil {
+			return HandleInternalServerErrorWithTrace(c, err)
+		}
+		defer tx.Rollback()
+		if tagIds != nil {
+			if tagIds, err = core.LockWorkflowTags(c.UserContext(), txQueries, orgId, tagIds); err != nil {
+				return handleSaveWorkflowError(c, err)
+			}
+		}
+		workflowEntityUpdated_RdsDbLib, err := txQueries.UpdateWorkflowEntityActive(
 			c.UserContext(),
 			rdsDbLib.UpdateWorkflowEntityActiveParams{
 				SugerOrgId: orgId,
@@ -133,13 +210,21 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 		if err != nil {
 			return HandleInternalServerErrorWithTrace(c, err)
 		}
+		if tagIds != nil {
+			if err := core.SetWorkflowTags(c.UserContext(), txQueries, orgId, workflowId, tagIds); err != nil {
+				return handleSaveWorkflowError(c, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return HandleInternalServerErrorWithTrace(c, err)
+		}
 		workflowEntityUpdated, err := structs.ToWorkflowEntity(workflowEntityUpdated_RdsDbLib)
 		if err != nil {
 			return HandleInternalServerErrorWithTrace(c, err)
 		}
 
 		// Call hook "workflow.afterUpdate"
-		if params.Active {
+		if updateActive && params.Active {
 			// Set up temporal workflow for active schedule trigger.
 			err := temporal.SetupTemporalWorkflow_ScheduleTrigger(c.UserContext(), &workflowEntityUpdated)
 			if err != nil {
@@ -150,7 +235,7 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 			if err != nil {
 				return HandleInternalServerErrorWithTrace(c, err)
 			}
-		} else {
+		} else if updateActive {
 			err := temporal.TerminateTemporalWorkflow_ScheduleTrigger(c.UserContext(), &workflowEntityUpdated)
 			if err != nil {
 				return HandleInternalServerErrorWithTrace(c, err)
@@ -167,10 +252,30 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 		if err != nil {
 			return HandleInternalServerErrorWithTrace(c, err)
 		}
+		if err := fillWorkflowTags(c.UserContext(), workflowEntity); err != nil {
+			return HandleInternalServerErrorWithTrace(c, err)
+		}
 		response := structs.UpdateWorkflowResponse{Data: workflowEntity}
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 
+	workflowEntityUpdated, err := service.saveWorkflowVersion(c, workflowEntity, &params, tagIds)
+	if err != nil {
+		return handleSaveWorkflowError(c, err)
+	}
+	if err := fillWorkflowTags(c.UserContext(), workflowEntityUpdated); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+
+	response := structs.UpdateWorkflowResponse{Data: workflowEntityUpdated}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// saveWorkflowVersion saves the params as a new version of the workflow entity and in the workflow history,
+// and replaces the tags of the workflow if tagIds is not nil, in one transaction.
+// The schedules and webhooks of an active workflow are re-registered.
+func (service *WorkflowService) saveWorkflowVersion(c *fiber.Ctx, workflowEntity *structs.WorkflowEntity, params *structs.WorkflowEntity, tagIds []string) (*structs.WorkflowEntity, error) {
+	ctx := c.UserContext()
 	// Generate new versionId
 	params.VersionId = uuid.NewString()
 	// Set node ID for new node
@@ -182,6 +287,18 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 
 	// Call hook "workflow.update" here
 
+	// The tags are checked before the schedules and webhooks are changed.
+	txQueries, tx, err := service.rdsDbQueries.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if tagIds != nil {
+		if tagIds, err = core.LockWorkflowTags(ctx, txQueries, workflowEntity.SugerOrgId, tagIds); err != nil {
+			return nil, err
+		}
+	}
+
 	/*
 	 If the workflow being updated is stored as `active`, remove it from
 	 active workflows in memory, and re-add it after the update.
@@ -190,20 +307,26 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 	 will take effect only on removing and re-adding.
 	*/
 	if workflowEntity.Active {
-		err := temporal.TerminateTemporalWorkflow_ScheduleTrigger(c.UserContext(), workflowEntity)
+		// Check the webhook paths of the new nodes before the webhooks are unregistered.
+		updated := *workflowEntity
+		updated.Nodes = params.Nodes
+		if err := core.CheckWebhookPaths(ctx, &updated, false); err != nil {
+			return nil, err
+		}
+		err := temporal.TerminateTemporalWorkflow_ScheduleTrigger(ctx, workflowEntity)
 		if err != nil {
-			return HandleInternalServerErrorWithTrace(c, err)
+			return nil, err
 		}
-		err = core.UnregisterWebhook(c.UserContext(), workflowEntity.ID, false)
+		err = core.UnregisterWebhook(ctx, workflowEntity.ID, false)
 		if err != nil {
-			return HandleInternalServerErrorWithTrace(c, err)
+			return nil, err
 		}
 	}
 	// TODO: Set workflowSettings
 
 	// Update workflow_entity
-	workflowEntityUpdated_RdsDbLib, err := service.rdsDbQueries.UpdateWorkflowEntity(
-		c.UserContext(),
+	workflowEntityUpdated_RdsDbLib, err := txQueries.UpdateWorkflowEntity(
+		ctx,
 		rdsDbLib.UpdateWorkflowEntityParams{
 			SugerOrgId:   workflowEntity.SugerOrgId,
 			ID:           workflowEntity.ID,
@@ -218,29 +341,36 @@ func (service *WorkflowService) UpdateWorkflow(c *fiber.Ctx) error {
 			TriggerCount: 0,
 		})
 	if err != nil {
-		return HandleBadRequestErrorWithTrace(c, err)
+		return nil, err
 	}
 	workflowEntityUpdated, err := structs.ToWorkflowEntity(workflowEntityUpdated_RdsDbLib)
 	if err != nil {
-		return HandleInternalServerErrorWithTrace(c, err)
+		return nil, err
+	}
+	if err := core.SaveWorkflowHistory(ctx, txQueries, &workflowEntityUpdated, requestAuthor(c)); err != nil {
+		return nil, err
+	}
+	if tagIds != nil {
+		if err := core.SetWorkflowTags(ctx, txQueries, workflowEntity.SugerOrgId, workflowEntity.ID, tagIds); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	// TODO: Update tagMappingRepository
-	// TODO: Save version to workflowHistory
 	// Call hook "workflow.afterUpdate"
 	if workflowEntity.Active {
-		err := temporal.SetupTemporalWorkflow_ScheduleTrigger(c.UserContext(), workflowEntity)
+		err := temporal.SetupTemporalWorkflow_ScheduleTrigger(ctx, &workflowEntityUpdated)
 		if err != nil {
-			return HandleInternalServerErrorWithTrace(c, err)
+			return nil, err
 		}
-		err = core.RegisterWebhook(c.UserContext(), workflowEntity.ID, false)
+		err = core.RegisterWebhook(ctx, workflowEntity.ID, false)
 		if err != nil {
-			return HandleInternalServerErrorWithTrace(c, err)
+			return nil, err
 		}
 	}
-
-	response := structs.UpdateWorkflowResponse{Data: &workflowEntityUpdated}
-	return c.Status(fiber.StatusOK).JSON(response)
+	return &workflowEntityUpdated, nil
 }
 
 func (service *WorkflowService) ManualRunWorkflow(ctx *fiber.Ctx) error {
@@ -337,13 +467,17 @@ func (service *WorkflowService) ListWorkflows(ctx *fiber.Ctx) error {
 		return HandleBadRequestErrorWithTrace(ctx, fmt.Errorf("orgId is empty"))
 	}
 
-	workflowEntities, err := core.ListWorkflowEntities(ctx.UserContext(), orgId)
+	filter, err := parseWorkflowEntityFilter(ctx)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(ctx, err)
+	}
+	workflowEntities, count, err := core.ListWorkflowEntitiesWithFilter(ctx.UserContext(), orgId, filter)
 	if err != nil {
 		return HandleInternalServerErrorWithTrace(ctx, err)
 	}
 	response := structs.ListWorkflowsResponse{
 		Data:  workflowEntities,
-		Count: int64(len(workflowEntities)),
+		Count: count,
 	}
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
@@ -407,13 +541,70 @@ func (service *WorkflowService) DeleteTestWebhook(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
+// handleSaveWorkflowError returns 400 for a tag which is not a tag of the org,
+// and writes the webhook path errors as handleWebhookPathError.
+func handleSaveWorkflowError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrTagNotFound) {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	return handleWebhookPathError(c, err)
+}
+
+// fillWorkflowTags sets the tags of the workflow entity.
+func fillWorkflowTags(ctx context.Context, workflowEntity *structs.WorkflowEntity) error {
+	workflowEntities := []structs.WorkflowEntity{*workflowEntity}
+	if err := core.FillWorkflowTags(ctx, workflowEntities); err != nil {
+		return err
+	}
+	workflowEntity.Tags = workflowEntities[0].Tags
+	return nil
+}
+
+// parseWorkflowEntityFilter parses the query params of ListWorkflows:
+// tags (comma separated tag ids), name, active, limit and offset.
+func parseWorkflowEntityFilter(c *fiber.Ctx) (core.WorkflowEntityFilter, error) {
+	filter := core.WorkflowEntityFilter{
+		Name: strings.TrimSpace(c.Query("name")),
+	}
+	// The workflows must have all the tags, so a repeated tag is counted once.
+	seen := make(map[string]bool)
+	for _, tagId := range strings.Split(c.Query("tags"), ",") {
+		if tagId = strings.TrimSpace(tagId); tagId != "" && !seen[tagId] {
+			seen[tagId] = true
+			filter.TagIds = append(filter.TagIds, tagId)
+		}
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid active %s", activeStr)
+		}
+		filter.Active = &active
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return filter, fmt.Errorf("invalid limit %s", limitStr)
+		}
+		filter.Limit = limit
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return filter, fmt.Errorf("invalid offset %s", offsetStr)
+		}
+		filter.Offset = offset
+	}
+	return filter, nil
+}
+
 func (service *WorkflowService) RegisterRouteMethods_Workflow() {
-	service.fiberApp.Get("/workflow/org/:orgId/workflow", service.ListWorkflows)
-	service.fiberApp.Post("/workflow/org/:orgId/workflow", service.CreateWorkflow)
-	service.fiberApp.Get("/workflow/org/:orgId/workflow/active", service.ListActiveWorkflowIds)
-	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId", service.GetWorkflow)
-	service.fiberApp.Patch("/workflow/org/:orgId/workflow/:workflowId", service.UpdateWorkflow)
-	service.fiberApp.Post("/workflow/org/:orgId/workflow/:workflowId/run", service.ManualRunWorkflow)
-	service.fiberApp.Delete("/workflow/org/:orgId/workflow/:workflowId", service.DeleteWorkflow)
-	service.fiberApp.Delete("/workflow/org/:orgId/workflow/:workflowId/test-webhook", service.DeleteTestWebhook)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListWorkflows)
+	service.fiberApp.Post("/workflow/org/:orgId/workflow", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.CreateWorkflow)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/active", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListActiveWorkflowIds)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.GetWorkflow)
+	service.fiberApp.Patch("/workflow/org/:orgId/workflow/:workflowId", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.UpdateWorkflow)
+	service.fiberApp.Post("/workflow/org/:orgId/workflow/:workflowId/run", service.requirePermission(structs.WorkflowPermission_WorkflowExecute), service.ManualRunWorkflow)
+	service.fiberApp.Delete("/workflow/org/:orgId/workflow/:workflowId", service.requirePermission(structs.WorkflowPermission_WorkflowDelete), service.DeleteWorkflow)
+	service.fiberApp.Delete("/workflow/org/:orgId/workflow/:workflowId/test-webhook", service.requirePermission(structs.WorkflowPermission_WorkflowExecute), service.DeleteTestWebhook)
 }
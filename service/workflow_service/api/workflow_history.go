@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// requestAuthor returns the email of the user of the request, or the id of the API client.
+func requestAuthor(c *fiber.Ctx) string {
+	caller := GetCaller(c.UserContext())
+	if caller == nil {
+		return ""
+	}
+	if caller.Email != "" {
+		return caller.Email
+	}
+	return caller.Id
+}
+
+// handleWorkflowHistoryError returns 404 for a missing version and 500 otherwise
+func handleWorkflowHistoryError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, core.ErrWorkflowVersionNotFound) {
+		return HandleNotFoundErrorWithTrace(c, err)
+	}
+	return HandleInternalServerErrorWithTrace(c, err)
+}
+
+func (service *WorkflowService) ListWorkflowHistory(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	if orgId == "" || workflowId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or workflowId is empty"))
+	}
+	limit := c.QueryInt("limit", 100)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || offset < 0 {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("invalid limit or offset"))
+	}
+
+	if err := service.validateWorkflowOwnership(c.UserContext(), orgId, workflowId); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	versions, count, err := core.ListWorkflowHistory(c.UserContext(), workflowId, int32(limit), int32(offset))
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.ListWorkflowHistoryResponse{
+		Data:  versions,
+		Count: count,
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) GetWorkflowHistory(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	versionId := c.Params("versionId")
+	if orgId == "" || workflowId == "" || versionId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId, workflowId or versionId is empty"))
+	}
+
+	if err := service.validateWorkflowOwnership(c.UserContext(), orgId, workflowId); err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+	version, err := core.GetWorkflowHistory(c.UserContext(), workflowId, versionId)
+	if err != nil {
+		return handleWorkflowHistoryError(c, err)
+	}
+	response := structs.GetWorkflowHistoryResponse{Data: version}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Diff the nodes of the versions "from" and "to" in the query, "to" is the current version of the workflow by default.
+func (service *WorkflowService) DiffWorkflowHistory(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	fromVersionId := c.Query("from")
+	if orgId == "" || workflowId == "" || fromVersionId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId, workflowId or from is empty"))
+	}
+
+	workflowEntity, err := core.GetWorkflowEntity(c.UserContext(), orgId, workflowId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	toVersionId := c.Query("to", workflowEntity.VersionId)
+
+	from, err := core.GetWorkflowHistory(c.UserContext(), workflowId, fromVersionId)
+	if err != nil {
+		return handleWorkflowHistoryError(c, err)
+	}
+	to := &structs.WorkflowHistoryVersion{
+		VersionId:   workflowEntity.VersionId,
+		WorkflowId:  workflowId,
+		Nodes:       workflowEntity.Nodes,
+		Connections: workflowEntity.Connections,
+	}
+	if toVersionId != workflowEntity.VersionId {
+		to, err = core.GetWorkflowHistory(c.UserContext(), workflowId, toVersionId)
+		if err != nil {
+			return handleWorkflowHistoryError(c, err)
+		}
+	}
+	response := structs.DiffWorkflowHistoryResponse{Data: core.DiffWorkflowHistory(from, to)}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Restore the nodes and connections of the version as a new version of the workflow.
+func (service *WorkflowService) RestoreWorkflowHistory(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	versionId := c.Params("versionId")
+	if orgId == "" || workflowId == "" || versionId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId, workflowId or versionId is empty"))
+	}
+
+	workflowEntity, err := core.GetWorkflowEntity(c.UserContext(), orgId, workflowId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	version, err := core.GetWorkflowHistory(c.UserContext(), workflowId, versionId)
+	if err != nil {
+		return handleWorkflowHistoryError(c, err)
+	}
+
+	params := *workflowEntity
+	params.Nodes = version.Nodes
+	params.Connections = version.Connections
+	for i := range params.Nodes {
+		params.Nodes[i].SugerOrgId = orgId
+	}
+	if err := checkCredentialsUse(c, orgId, params.Nodes, workflowEntity.Nodes); err != nil {
+		return handlePermissionError(c, err)
+	}
+	workflowEntityUpdated, err := service.saveWorkflowVersion(c, workflowEntity, &params, nil)
+	if err != nil {
+		return handleWebhookPathError(c, err)
+	}
+	if err := fillWorkflowTags(c.UserContext(), workflowEntityUpdated); err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.UpdateWorkflowResponse{Data: workflowEntityUpdated}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) RegisterRouteMethods_WorkflowHistory() {
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId/history", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.ListWorkflowHistory)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId/history/diff", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.DiffWorkflowHistory)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId/history/:versionId", service.requirePermission(structs.WorkflowPermission_WorkflowRead), service.GetWorkflowHistory)
+	service.fiberApp.Post("/workflow/org/:orgId/workflow/:workflowId/history/:versionId/restore", service.requirePermission(structs.WorkflowPermission_WorkflowUpdate), service.RestoreWorkflowHistory)
+}
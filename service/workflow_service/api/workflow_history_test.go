@@ -0,0 +1,93 @@
+package api_test
+
+// Command to run this test only
+// go test -v service/workflow_service/api/service_test.go service/workflow_service/api/variable_test.go service/workflow_service/api/workflow_history_test.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/api"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+type WorkflowHistoryTestSuit struct {
+	suite.Suite
+}
+
+func Test_WorkflowHistoryTestSuit(t *testing.T) {
+	suite.Run(t, new(WorkflowHistoryTestSuit))
+}
+
+func (s *WorkflowHistoryTestSuit) Test() {
+	s.T().Run("TestWorkflowHistory List Get Diff Restore", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+		// Create Organization for test
+		organization := structs.CreateOrganization_Testing(rdsDbQueries, sid, "")
+		newWorkflow, err := api.CreateWorkflow_Testing(
+			testFiberLambda, organization.ID, "test_files/request_create_workflow.json")
+		assert.Nil(err)
+		workflowPath := fmt.Sprintf("/workflow/org/%s/workflow/%s", organization.ID, newWorkflow.ID)
+		firstVersionId := newWorkflow.VersionId
+
+		// Save a new version with a node removed
+		updateWorkflowRequest := *newWorkflow
+		updateWorkflowRequest.Name = "second version"
+		updateWorkflowRequest.Nodes = newWorkflow.Nodes[1:]
+		response, err := testFiberLambda.Proxy(variableRequest(http.MethodPatch, workflowPath, updateWorkflowRequest))
+		assert.Nil(err)
+		var updateResponse structs.UpdateWorkflowResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &updateResponse), response.Body)
+		assert.NotEqual(firstVersionId, updateResponse.Data.VersionId)
+
+		// List and get the versions
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowPath+"/history", nil))
+		assert.Nil(err)
+		var listResponse structs.ListWorkflowHistoryResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse), response.Body)
+		assert.Equal(int64(2), listResponse.Count)
+		assert.Equal(updateResponse.Data.VersionId, listResponse.Data[0].VersionId)
+		assert.Equal("chengjun@suger.io", listResponse.Data[0].Authors)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowPath+"/history/"+firstVersionId, nil))
+		assert.Nil(err)
+		var getResponse structs.GetWorkflowHistoryResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &getResponse), response.Body)
+		assert.Len(getResponse.Data.Nodes, len(newWorkflow.Nodes))
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowPath+"/history/unknown", nil))
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, response.StatusCode)
+
+		// Diff the first version with the current version
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowPath+"/history/diff?from="+firstVersionId, nil))
+		assert.Nil(err)
+		var diffResponse structs.DiffWorkflowHistoryResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &diffResponse), response.Body)
+		assert.Len(diffResponse.Data.Nodes, 1)
+		assert.Equal(structs.WorkflowNodeDiffStatus_Removed, diffResponse.Data.Nodes[0].Status)
+		assert.Equal(newWorkflow.Nodes[0].ID, diffResponse.Data.Nodes[0].NodeId)
+
+		// Restore the first version as a new version
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodPost, workflowPath+"/history/"+firstVersionId+"/restore", nil))
+		assert.Nil(err)
+		var restoreResponse structs.UpdateWorkflowResponse
+		assert.Nil(json.Unmarshal([]byte(response.Body), &restoreResponse), response.Body)
+		assert.Len(restoreResponse.Data.Nodes, len(newWorkflow.Nodes))
+		assert.Equal("second version", restoreResponse.Data.Name)
+		assert.NotEqual(firstVersionId, restoreResponse.Data.VersionId)
+
+		response, err = testFiberLambda.Proxy(variableRequest(http.MethodGet, workflowPath+"/history?limit=1", nil))
+		assert.Nil(err)
+		assert.Nil(json.Unmarshal([]byte(response.Body), &listResponse), response.Body)
+		assert.Equal(int64(3), listResponse.Count)
+		assert.Len(listResponse.Data, 1)
+		assert.Equal(restoreResponse.Data.VersionId, listResponse.Data[0].VersionId)
+	})
+}
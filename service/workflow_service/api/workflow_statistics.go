@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	defaultStatisticsWindow = 24 * time.Hour
+	maxStatisticsWindow     = 90 * 24 * time.Hour
+)
+
+// parseStatisticsWindow parses the window query param, a Go duration like "6h" or a number of days like "7d".
+// Returns the start time of the window.
+func parseStatisticsWindow(c *fiber.Ctx) (time.Time, error) {
+	window := defaultStatisticsWindow
+	if windowStr := c.Query("window"); windowStr != "" {
+		var err error
+		if days, ok := strings.CutSuffix(windowStr, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			window = time.Duration(n) * 24 * time.Hour
+		} else {
+			window, err = time.ParseDuration(windowStr)
+		}
+		if err != nil || window <= 0 || window > maxStatisticsWindow {
+			return time.Time{}, fmt.Errorf("invalid window %s", windowStr)
+		}
+	}
+	// The runs are counted by hour.
+	return time.Now().Add(-window).Truncate(time.Hour), nil
+}
+
+func (service *WorkflowService) GetWorkflowStatistics(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	workflowId := c.Params("workflowId")
+	if orgId == "" || workflowId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId or workflowId is empty"))
+	}
+	since, err := parseStatisticsWindow(c)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	workflowEntity, err := core.GetWorkflowEntity(c.UserContext(), orgId, workflowId)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	statistics, err := core.GetWorkflowStatistics(c.UserContext(), workflowEntity, since)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.GetWorkflowStatisticsResponse{Data: statistics}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// List the statistics of the workflows of the org that ever ran, the workflows with the most errors first.
+func (service *WorkflowService) ListWorkflowStatistics(c *fiber.Ctx) error {
+	orgId := c.Params("orgId")
+	if orgId == "" {
+		return HandleBadRequestErrorWithTrace(c, fmt.Errorf("orgId is empty"))
+	}
+	since, err := parseStatisticsWindow(c)
+	if err != nil {
+		return HandleBadRequestErrorWithTrace(c, err)
+	}
+
+	statistics, err := core.ListWorkflowStatistics(c.UserContext(), orgId, since)
+	if err != nil {
+		return HandleInternalServerErrorWithTrace(c, err)
+	}
+	response := structs.ListWorkflowStatisticsResponse{
+		Data:  statistics,
+		Count: int64(len(statistics)),
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (service *WorkflowService) RegisterRouteMethods_WorkflowStatistics() {
+	service.fiberApp.Get("/workflow/org/:orgId/workflow-statistics", service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.ListWorkflowStatistics)
+	service.fiberApp.Get("/workflow/org/:orgId/workflow/:workflowId/statistics", service.requirePermission(structs.WorkflowPermission_ExecutionRead), service.GetWorkflowStatistics)
+}
@@ -121,8 +121,9 @@ func (activeExecutions *ActiveExecutions) ExecuteAsync(
 ) *structs.ExecutingWorkflowData {
 	executionCtx, cancelFunc := context.WithCancel(context.Background())
 
-	waitErr := make(chan error)
-	waitData := make(chan *structs.WorkflowRunExecutionData)
+	// Buffered, so the execution ends even if nobody waits for it, e.g. the webhook request is already answered.
+	waitErr := make(chan error, 1)
+	waitData := make(chan *structs.WorkflowRunExecutionData, 1)
 	activeExecutions.waitGroup.Add(1)
 	go func() {
 		// Handle panic
@@ -0,0 +1,307 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The Approval node requests an approval per node run of the execution and puts the execution to wait till
+// the approval expires. The approve and reject links are signed with HMAC-SHA256 of the approval id and the
+// decision. The pending approval is decided only once, by the link before it expires or as timed out after,
+// and the decision is kept with its time, comment, IP address and user agent for the audit.
+// The links are sent only in the notification, the approvals returned by the API have no links.
+
+const (
+	ApprovalDecision_Approve = "approve"
+	ApprovalDecision_Reject  = "reject"
+
+	approvalNotificationTimeout = 10 * time.Second
+	// The execution may be put to wait just after the decision, the resume is retried while it is running.
+	approvalResumeAttempts = 10
+	approvalResumeInterval = 500 * time.Millisecond
+)
+
+var approvalNotificationClient = NewPublicHttpClient(approvalNotificationTimeout)
+
+var (
+	ErrApprovalNotFound    = errors.New("the approval is not found")
+	ErrApprovalInvalidLink = errors.New("the approval link is invalid")
+	ErrApprovalNotPending  = errors.New("the approval is already decided or expired")
+)
+
+type (
+	CreateApprovalParams struct {
+		ExecutionId    int32
+		WorkflowId     string
+		NodeName       string
+		RunIndex       int32
+		Message        string
+		RequireComment bool
+		ExpiresAt      time.Time
+	}
+
+	// The body posted to the notification URL of the Approval node.
+	ApprovalNotification struct {
+		Approval *structs.WorkflowApproval `json:"approval"`
+	}
+)
+
+// SignApprovalLink returns the signature of the link to decide the approval.
+func SignApprovalLink(approvalId string, decision string) (string, error) {
+	secret := ""
+	if GetEnvironment() != nil {
+		secret = GetEnvironment().Approval.Secret
+	}
+	if secret == "" {
+		return "", errors.New("the approval secret is not configured")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(approvalId + ":" + decision))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyApprovalLink returns ErrApprovalInvalidLink if the signature is not of the link to decide the approval.
+func VerifyApprovalLink(approvalId string, decision string, signature string) error {
+	if decision != ApprovalDecision_Approve && decision != ApprovalDecision_Reject {
+		return fmt.Errorf("%w: unknown decision %s", ErrApprovalInvalidLink, decision)
+	}
+	expected, err := SignApprovalLink(approvalId, decision)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrApprovalInvalidLink
+	}
+	return nil
+}
+
+// GetApprovalLink returns the signed link to decide the approval, under the public URL of the service.
+func GetApprovalLink(approvalId string, decision string) (string, error) {
+	signature, err := SignApprovalLink(approvalId, decision)
+	if err != nil {
+		return "", err
+	}
+	publicUrl := strings.TrimSuffix(GetEnvironment().Approval.PublicUrl, "/")
+	return fmt.Sprintf("%s/workflow/public/approval/%s/%s?signature=%s",
+		publicUrl, url.PathEscape(approvalId), decision, signature), nil
+}
+
+// SetApprovalLinks sets the signed links to decide the pending approval.
+func SetApprovalLinks(approval *structs.WorkflowApproval) error {
+	approveUrl, err := GetApprovalLink(approval.ID, ApprovalDecision_Approve)
+	if err != nil {
+		return err
+	}
+	rejectUrl, err := GetApprovalLink(approval.ID, ApprovalDecision_Reject)
+	if err != nil {
+		return err
+	}
+	approval.ApproveUrl = approveUrl
+	approval.RejectUrl = rejectUrl
+	return nil
+}
+
+// GetApprovalStatusOfDecision returns the status of the approval decided as the decision.
+func GetApprovalStatusOfDecision(decision string) (structs.WorkflowApprovalStatus, error) {
+	switch decision {
+	case ApprovalDecision_Approve:
+		return structs.WorkflowApprovalStatus_Approved, nil
+	case ApprovalDecision_Reject:
+		return structs.WorkflowApprovalStatus_Rejected, nil
+	default:
+		return "", fmt.Errorf("unknown decision %s", decision)
+	}
+}
+
+// CreateApproval requests the approval of the node run. It returns the existing one if already requested.
+func CreateApproval(ctx context.Context, params CreateApprovalParams) (*structs.WorkflowApproval, error) {
+	approval, err := rdsDbQueries.CreateApproval(ctx, rdsDbLib.CreateApprovalParams{
+		ID:             uuid.NewString(),
+		ExecutionId:    params.ExecutionId,
+		WorkflowId:     params.WorkflowId,
+		NodeName:       params.NodeName,
+		RunIndex:       params.RunIndex,
+		Message:        params.Message,
+		RequireComment: params.RequireComment,
+		ExpiresAt:      params.ExpiresAt,
+	})
+	if err != nil {
+		existing, getErr := GetApprovalOfNode(ctx, params.ExecutionId, params.NodeName, params.RunIndex)
+		if getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return toWorkflowApproval(&approval), nil
+}
+
+// GetApproval returns the approval, or ErrApprovalNotFound.
+func GetApproval(ctx context.Context, approvalId string) (*structs.WorkflowApproval, error) {
+	approval, err := rdsDbQueries.GetApproval(ctx, approvalId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApprovalNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return toWorkflowApproval(&approval), nil
+}
+
+// GetApprovalOfNode returns the approval requested by the node run of the execution, or ErrApprovalNotFound.
+func GetApprovalOfNode(ctx context.Context, executionId int32, nodeName string, runIndex int32) (*structs.WorkflowApproval, error) {
+	approval, err := rdsDbQueries.GetApprovalByNode(ctx, rdsDbLib.GetApprovalByNodeParams{
+		ExecutionId: executionId,
+		NodeName:    nodeName,
+		RunIndex:    runIndex,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApprovalNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return toWorkflowApproval(&approval), nil
+}
+
+// ListExecutionApprovals returns the approvals of the execution in the requested order.
+func ListExecutionApprovals(ctx context.Context, executionId int32) ([]structs.WorkflowApproval, error) {
+	approvals, err := readRdsDbQueries.ListApprovalsByExecutionId(ctx, executionId)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]structs.WorkflowApproval, 0, len(approvals))
+	for idx := range approvals {
+		result = append(result, *toWorkflowApproval(&approvals[idx]))
+	}
+	return result, nil
+}
+
+// DecideApproval records the decision of the pending approval.
+// Returns ErrApprovalNotPending if the approval is already decided or expired.
+func DecideApproval(
+	ctx context.Context, approvalId string, decision string, comment string, clientIp string, userAgent string,
+) (*structs.WorkflowApproval, error) {
+	status, err := GetApprovalStatusOfDecision(decision)
+	if err != nil {
+		return nil, err
+	}
+	approval, err := rdsDbQueries.DecideApproval(ctx, rdsDbLib.DecideApprovalParams{
+		ID:        approvalId,
+		Status:    string(status),
+		Comment:   sql.NullString{String: comment, Valid: comment != ""},
+		ClientIp:  sql.NullString{String: clientIp, Valid: clientIp != ""},
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApprovalNotPending
+	} else if err != nil {
+		return nil, err
+	}
+	return toWorkflowApproval(&approval), nil
+}
+
+// ExpireApproval marks the pending approval as timed out if it is expired, and returns the approval.
+func ExpireApproval(ctx context.Context, approvalId string) (*structs.WorkflowApproval, error) {
+	approval, err := rdsDbQueries.ExpireApproval(ctx, approvalId)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Not expired yet, or decided just now.
+		return GetApproval(ctx, approvalId)
+	} else if err != nil {
+		return nil, err
+	}
+	return toWorkflowApproval(&approval), nil
+}
+
+// ResumeApprovalExecution resumes the execution waiting for the decided approval. If it can not be resumed now,
+// e.g. the pod crashed, the wait tracker resumes it once the approval expires, and the decision is taken then.
+func ResumeApprovalExecution(ctx context.Context, approval *structs.WorkflowApproval) error {
+	executionId, err := strconv.Atoi(approval.ExecutionId)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		_, err := ResumeWorkflowExecution(ctx, int32(executionId))
+		if !errors.Is(err, ErrExecutionNotWaiting) || attempt >= approvalResumeAttempts {
+			return err
+		}
+		execution, err := rdsDbQueries.GetWorkflowExecutionEntity(ctx, int32(executionId))
+		if err != nil {
+			return err
+		}
+		status := structs.WorkflowExecutionStatus(execution.Status.String)
+		if status != structs.WorkflowExecutionStatus_New && status != structs.WorkflowExecutionStatus_Running {
+			// Resumed by another request, or ended.
+			return ErrExecutionNotWaiting
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(approvalResumeInterval):
+		}
+	}
+}
+
+// NotifyApproval posts the pending approval with its links to the notification URL,
+// which must not be an internal address.
+func NotifyApproval(ctx context.Context, notificationUrl string, approval *structs.WorkflowApproval) error {
+	if err := ValidateUrl(notificationUrl); err != nil {
+		return err
+	}
+	body, err := json.Marshal(ApprovalNotification{Approval: approval})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, approvalNotificationTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, notificationUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := approvalNotificationClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to notify the approval: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to notify the approval: %s", response.Status)
+	}
+	return nil
+}
+
+func toWorkflowApproval(approval *rdsDbLib.WorkflowApproval) *structs.WorkflowApproval {
+	result := &structs.WorkflowApproval{
+		ID:             approval.ID,
+		ExecutionId:    fmt.Sprint(approval.ExecutionId),
+		WorkflowId:     approval.WorkflowId,
+		NodeName:       approval.NodeName,
+		RunIndex:       approval.RunIndex,
+		Message:        approval.Message,
+		RequireComment: approval.RequireComment,
+		Status:         structs.WorkflowApprovalStatus(approval.Status),
+		ExpiresAt:      &approval.ExpiresAt,
+		Comment:        approval.Comment.String,
+		ClientIp:       approval.ClientIp.String,
+		UserAgent:      approval.UserAgent.String,
+		CreatedAt:      &approval.CreatedAt,
+	}
+	if approval.DecidedAt.Valid {
+		result.DecidedAt = &approval.DecidedAt.Time
+	}
+	return result
+}
@@ -0,0 +1,229 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_router_test.go service/workflow_service/core/webhook_body_test.go service/workflow_service/core/approval_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// Create the workflow of the Webhook node followed by the Approval node.
+func createApprovalWorkflow_Testing(assert *require.Assertions, orgId string, timeoutAmount float64) *structs.WorkflowEntity {
+	nodes, err := json.Marshal([]structs.WorkflowNode{
+		webhookNode_Testing("Webhook", http.MethodPost, ""),
+		{
+			ID:   uuid.NewString(),
+			Name: "Approval",
+			Type: "n8n-nodes-base.approval",
+			Parameters: map[string]interface{}{
+				"message":       "Refund the order",
+				"timeoutAmount": timeoutAmount,
+				"timeoutUnit":   "hours",
+			},
+		},
+	})
+	assert.Nil(err)
+	workflowEntity, err := rdsDbQueries.CreateWorkflowEntity(context.Background(), rdsDbLib.CreateWorkflowEntityParams{
+		Name:        "approval",
+		Nodes:       nodes,
+		Connections: json.RawMessage(`{"Webhook":{"main":[[{"node":"Approval","type":"main","index":0}]]}}`),
+		ID:          uuid.NewString(),
+		SugerOrgId:  orgId,
+	})
+	assert.Nil(err)
+	workflow, err := structs.ToWorkflowEntity(workflowEntity)
+	assert.Nil(err)
+	return &workflow
+}
+
+// Run the workflow with a webhook request until the execution ends or waits, and return the execution.
+func runApprovalWorkflow_Testing(assert *require.Assertions, workflowEntity *structs.WorkflowEntity) *structs.WorkflowExecution {
+	ctx := context.Background()
+	request := bodyRequest_Testing("application/json", []byte(`{"orderId":"42"}`))
+	request.SetRequestURI("/workflow/public/webhook/workflow/" + workflowEntity.ID + "/node/" + workflowEntity.Nodes[0].ID)
+	_, err := core.EnqueueWebhookRequest(ctx, workflowEntity, &workflowEntity.Nodes[0], request, nil, "", nil)
+	assert.Nil(err)
+	for {
+		processed, err := core.ProcessWebhookRequest(ctx)
+		assert.Nil(err)
+		if !processed {
+			break
+		}
+	}
+	executionEntities, err := rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(
+		ctx, rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{WorkflowId: workflowEntity.ID, Limit: 10})
+	assert.Nil(err)
+	assert.Len(executionEntities, 1)
+	execution, err := core.GetWorkflowExecution(ctx, executionEntities[0].ID)
+	assert.Nil(err)
+	return execution
+}
+
+// Wait for the resumed execution to end.
+func waitExecutionEnded_Testing(assert *require.Assertions, executionId string) *structs.WorkflowExecution {
+	id, err := strconv.Atoi(executionId)
+	assert.Nil(err)
+	for i := 0; i < 100; i++ {
+		execution, err := core.GetWorkflowExecution(context.Background(), int32(id))
+		assert.Nil(err)
+		if core.IsWorkflowExecutionEnded(execution) {
+			return execution
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.FailNow("the execution does not end")
+	return nil
+}
+
+func TestApproval(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	orgId := uuid.NewString()[:8]
+
+	t.Run("Sign the links", func(t *testing.T) {
+		approvalId := uuid.NewString()
+		link, err := core.GetApprovalLink(approvalId, core.ApprovalDecision_Approve)
+		assert.Nil(err)
+		assert.Contains(link, "/workflow/public/approval/"+approvalId+"/approve?signature=")
+		parsed, err := url.Parse(link)
+		assert.Nil(err)
+		signature := parsed.Query().Get("signature")
+		assert.Nil(core.VerifyApprovalLink(approvalId, core.ApprovalDecision_Approve, signature))
+
+		// The signature of the approve link can not reject, nor decide another approval.
+		assert.ErrorIs(core.VerifyApprovalLink(approvalId, core.ApprovalDecision_Reject, signature), core.ErrApprovalInvalidLink)
+		assert.ErrorIs(core.VerifyApprovalLink(uuid.NewString(), core.ApprovalDecision_Approve, signature), core.ErrApprovalInvalidLink)
+		assert.ErrorIs(core.VerifyApprovalLink(approvalId, "maybe", signature), core.ErrApprovalInvalidLink)
+		assert.ErrorIs(core.VerifyApprovalLink(approvalId, core.ApprovalDecision_Approve, ""), core.ErrApprovalInvalidLink)
+	})
+
+	t.Run("Decide the approval once", func(t *testing.T) {
+		workflowEntity := createApprovalWorkflow_Testing(assert, orgId, 1)
+		execution, err := rdsDbQueries.CreateWorkflowExecutionEntity(
+			ctx, rdsDbLib.CreateWorkflowExecutionEntityParams{WorkflowId: workflowEntity.ID})
+		assert.Nil(err)
+		params := core.CreateApprovalParams{
+			ExecutionId: execution.ID,
+			WorkflowId:  workflowEntity.ID,
+			NodeName:    "Approval",
+			Message:     "Refund the order",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		approval, err := core.CreateApproval(ctx, params)
+		assert.Nil(err)
+		assert.Equal(structs.WorkflowApprovalStatus_Pending, approval.Status)
+		// The links are not returned with the approval, only set for the notification.
+		assert.Empty(approval.ApproveUrl)
+		assert.Nil(core.SetApprovalLinks(approval))
+		assert.NotEmpty(approval.ApproveUrl)
+		assert.NotEmpty(approval.RejectUrl)
+		// The notification is not posted to an internal address.
+		assert.NotNil(core.NotifyApproval(ctx, "http://127.0.0.1:8080/approval", approval))
+		// The approval of the node run is requested once.
+		again, err := core.CreateApproval(ctx, params)
+		assert.Nil(err)
+		assert.Equal(approval.ID, again.ID)
+
+		decided, err := core.DecideApproval(ctx, approval.ID, core.ApprovalDecision_Reject, "Too much", "10.0.0.1", "curl")
+		assert.Nil(err)
+		assert.Equal(structs.WorkflowApprovalStatus_Rejected, decided.Status)
+		assert.Equal("Too much", decided.Comment)
+		assert.Equal("10.0.0.1", decided.ClientIp)
+		assert.NotNil(decided.DecidedAt)
+		_, err = core.DecideApproval(ctx, approval.ID, core.ApprovalDecision_Approve, "", "", "")
+		assert.ErrorIs(err, core.ErrApprovalNotPending)
+		// The decided approval does not time out.
+		expired, err := core.ExpireApproval(ctx, approval.ID)
+		assert.Nil(err)
+		assert.Equal(structs.WorkflowApprovalStatus_Rejected, expired.Status)
+
+		// The expired approval can not be decided, it times out.
+		params.RunIndex = 1
+		params.ExpiresAt = time.Now().Add(-time.Second)
+		approval, err = core.CreateApproval(ctx, params)
+		assert.Nil(err)
+		_, err = core.DecideApproval(ctx, approval.ID, core.ApprovalDecision_Approve, "", "", "")
+		assert.ErrorIs(err, core.ErrApprovalNotPending)
+		expired, err = core.ExpireApproval(ctx, approval.ID)
+		assert.Nil(err)
+		assert.Equal(structs.WorkflowApprovalStatus_Timeout, expired.Status)
+
+		approvals, err := core.ListExecutionApprovals(ctx, execution.ID)
+		assert.Nil(err)
+		assert.Len(approvals, 2)
+		assert.Equal(structs.WorkflowApprovalStatus_Rejected, approvals[0].Status)
+		assert.Equal(structs.WorkflowApprovalStatus_Timeout, approvals[1].Status)
+	})
+
+	t.Run("Wait for the approval and resume", func(t *testing.T) {
+		workflowEntity := createApprovalWorkflow_Testing(assert, orgId, 1)
+		execution := runApprovalWorkflow_Testing(assert, workflowEntity)
+		assert.Equal(structs.WorkflowExecutionStatus_Waiting, execution.Status)
+		assert.False(execution.Finished)
+		assert.NotNil(execution.WaitTill)
+		assert.NotContains(execution.Data.ResultData.RunData, "Approval")
+
+		executionId, err := strconv.Atoi(execution.Id)
+		assert.Nil(err)
+		approval, err := core.GetApprovalOfNode(ctx, int32(executionId), "Approval", 0)
+		assert.Nil(err)
+		assert.Equal("Refund the order", approval.Message)
+		assert.WithinDuration(*approval.ExpiresAt, *execution.WaitTill, time.Millisecond)
+
+		// The execution is resumed only once.
+		approval, err = core.DecideApproval(ctx, approval.ID, core.ApprovalDecision_Approve, "", "10.0.0.1", "")
+		assert.Nil(err)
+		assert.Nil(core.ResumeApprovalExecution(ctx, approval))
+		_, err = core.ResumeWorkflowExecution(ctx, int32(executionId))
+		assert.ErrorIs(err, core.ErrExecutionNotWaiting)
+
+		execution = waitExecutionEnded_Testing(assert, execution.Id)
+		assert.Equal(structs.WorkflowExecutionStatus_Success, execution.Status)
+		assert.True(execution.Finished)
+		assert.Nil(execution.WaitTill)
+		outputs := execution.Data.ResultData.RunData["Approval"][0].Data["main"]
+		assert.Len(outputs, 3)
+		assert.Len(outputs[0], 1)
+		assert.Empty(outputs[1])
+		assert.Empty(outputs[2])
+		output, err := json.Marshal(outputs[0][0]["json"])
+		assert.Nil(err)
+		assert.True(strings.Contains(string(output), `"status":"approved"`), string(output))
+		assert.True(strings.Contains(string(output), `"orderId":"42"`), string(output))
+	})
+
+	t.Run("Time out", func(t *testing.T) {
+		workflowEntity := createApprovalWorkflow_Testing(assert, orgId, 0)
+		execution := runApprovalWorkflow_Testing(assert, workflowEntity)
+		assert.Equal(structs.WorkflowExecutionStatus_Waiting, execution.Status)
+
+		_, err := core.ResumeDueExecutions(ctx)
+		assert.Nil(err)
+		execution = waitExecutionEnded_Testing(assert, execution.Id)
+		assert.Equal(structs.WorkflowExecutionStatus_Success, execution.Status)
+		outputs := execution.Data.ResultData.RunData["Approval"][0].Data["main"]
+		assert.Empty(outputs[0])
+		assert.Empty(outputs[1])
+		assert.Len(outputs[2], 1)
+
+		executionId, err := strconv.Atoi(execution.Id)
+		assert.Nil(err)
+		approval, err := core.GetApprovalOfNode(ctx, int32(executionId), "Approval", 0)
+		assert.Nil(err)
+		assert.Equal(structs.WorkflowApprovalStatus_Timeout, approval.Status)
+	})
+}
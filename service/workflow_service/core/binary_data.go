@@ -0,0 +1,103 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+var (
+	binaryFileTypes = map[string]string{
+		"image/":                 "image",
+		"audio/":                 "audio",
+		"video/":                 "video",
+		"text/html":              "html",
+		"text/":                  "text",
+		"application/json":       "json",
+		"application/javascript": "text",
+		"application/pdf":        "pdf",
+	}
+
+	binaryFileExtensions = map[string]string{
+		"application/pdf":             ".pdf",
+		"application/zip":             ".zip",
+		"application/gzip":            ".gz",
+		"application/x-gzip":          ".gz",
+		"application/x-tar":           ".tar",
+		"application/x-7z-compressed": ".7z",
+		"audio/mpeg":                  ".mp3",
+		"audio/wav":                   ".wav",
+		"audio/ogg":                   ".ogg",
+		"image/jpeg":                  ".jpeg",
+		"image/png":                   ".png",
+		"image/gif":                   ".gif",
+		"image/bmp":                   ".bmp",
+		"image/webp":                  ".webp",
+		"video/mp4":                   ".mp4",
+		"video/mpeg":                  ".mpeg",
+		"video/quicktime":             ".mov",
+		"video/x-msvideo":             ".avi",
+		"text/plain":                  ".txt",
+		"text/html":                   ".html",
+		"text/css":                    ".css",
+		"application/json":            ".json",
+		"application/xml":             ".xml",
+	}
+)
+
+// NewBinaryData constructs the binary data of the item from the content. The content is base64 encoded as a string.
+// The mime type is text/plain if empty, the file extension is taken from the file name if it has one.
+func NewBinaryData(content []byte, mimeType string, fileName string) structs.WorkflowBinaryData {
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	data := structs.WorkflowBinaryData{
+		Data:          base64.StdEncoding.EncodeToString(content),
+		Base64Encoded: true,
+		MimeType:      mimeType,
+		FileType:      structs.WorkflowBinaryFileType(BinaryFileTypeFromMimeType(mimeType)),
+		FileName:      fileName,
+		FileExtension: BinaryFileExtensionFromMimeType(mimeType),
+		FileSize:      PrettyBytes(float64(len(content))),
+	}
+	if fileExtension := strings.TrimPrefix(path.Ext(fileName), "."); fileExtension != "" {
+		data.FileExtension = fileExtension
+	}
+	return data
+}
+
+// BinaryFileTypeFromMimeType returns the file type of the mime type, e.g. image for image/png.
+func BinaryFileTypeFromMimeType(mimeType string) string {
+	for ct, fileType := range binaryFileTypes {
+		if strings.HasPrefix(mimeType, ct) {
+			return fileType
+		}
+	}
+	return ""
+}
+
+// BinaryFileExtensionFromMimeType returns the file extension of the mime type, e.g. .png for image/png.
+func BinaryFileExtensionFromMimeType(mimeType string) string {
+	for ct, ext := range binaryFileExtensions {
+		if strings.HasPrefix(mimeType, ct) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// PrettyBytes formats the size in bytes, e.g. 1.50 KB.
+func PrettyBytes(size float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+
+	unitIndex := 0
+	for size >= 1024 && unitIndex < len(units)-1 {
+		size /= 1024
+		unitIndex++
+	}
+
+	return fmt.Sprintf("%.2f %s", size, units[unitIndex])
+}
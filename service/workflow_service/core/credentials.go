@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The data of workflow.credentials_entity is the JSON of the credential fields, e.g. {"user":"...","password":"..."}
+// for httpBasicAuth. The credentials are referenced by the nodes with node.credentials[type].id.
+
+const (
+	CredentialsType_HttpBasicAuth  = "httpBasicAuth"
+	CredentialsType_HttpHeaderAuth = "httpHeaderAuth"
+	CredentialsType_JwtAuth        = "jwtAuth"
+)
+
+type (
+	HttpBasicAuthCredentials struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+
+	HttpHeaderAuthCredentials struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	JwtAuthCredentials struct {
+		// HS256, HS384, HS512, RS256, RS384 or RS512, default HS256
+		Algorithm string `json:"algorithm,omitempty"`
+		// The secret of the HS algorithms
+		Secret string `json:"secret,omitempty"`
+		// The PEM public key of the RS algorithms
+		PublicKey string `json:"publicKey,omitempty"`
+		// The PEM private key of the RS algorithms to sign the JWT
+		PrivateKey string `json:"privateKey,omitempty"`
+		// The required "iss" and "aud" claims if set
+		Issuer   string `json:"issuer,omitempty"`
+		Audience string `json:"audience,omitempty"`
+		// The other claims required to equal the values
+		Claims map[string]interface{} `json:"claims,omitempty"`
+	}
+)
+
+// GetNodeCredentials reads the credential of the type set on the node into data.
+// Returns ErrCredentialsNotFound if the node has no such credential or it is not a credential of the org.
+func GetNodeCredentials(ctx context.Context, orgId string, node *structs.WorkflowNode, credentialsType string, data interface{}) error {
+	details, ok := node.Credentials[credentialsType]
+	if !ok || details.ID == "" {
+		return fmt.Errorf("%w: %s is not set on node %s", ErrCredentialsNotFound, credentialsType, node.Name)
+	}
+	credentials, err := rdsDbQueries.GetCredentialsEntity(ctx, rdsDbLib.GetCredentialsEntityParams{
+		SugerOrgId: orgId,
+		ID:         details.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrCredentialsNotFound, details.ID)
+		}
+		return err
+	}
+	if credentials.Type != credentialsType {
+		return fmt.Errorf("credential %s is of type %s, not %s", details.ID, credentials.Type, credentialsType)
+	}
+	return json.Unmarshal([]byte(credentials.Data), data)
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
@@ -53,6 +54,17 @@ func GenerateSuccessResponse(
 	}
 }
 
+// GenerateWaitingResponse puts the execution to wait till the time. The node runs again with the same input
+// when the execution is resumed, at the latest once the time is passed.
+func GenerateWaitingResponse(runExecutionData *structs.WorkflowRunExecutionData, waitTill time.Time) *structs.NodeExecutionResult {
+	runExecutionData.WaitTill = &waitTill
+	return &structs.NodeExecutionResult{
+		ExecutionStatus: structs.WorkflowExecutionStatus_Waiting,
+		TriggerData:     structs.NodeData{},
+		ExecutorData:    []structs.NodeData{},
+	}
+}
+
 // GenerateEmptyResponse returns an empty response.
 func GenerateEmptyResponse() *structs.NodeExecutionResult {
 	return &structs.NodeExecutionResult{
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The custom data of an execution, e.g. the customer id or the order id, is set by the Execution Data node
+// or $execution.customData in the Code node. It is kept in ResultData.MetaData during the run
+// and saved to workflow.execution_metadata when the run ends, so the executions can be filtered by it.
+
+const (
+	ExecutionCustomDataMaxKeys        = 10
+	ExecutionCustomDataKeyMaxLength   = 50
+	ExecutionCustomDataValueMaxLength = 255
+)
+
+var executionCustomDataKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// SetExecutionCustomData sets the custom data of the execution.
+// The key may only contain letters, digits and underscores, the too long key and value are truncated.
+func SetExecutionCustomData(resultData *structs.WorkflowRunExecutionResultData, key string, value string) error {
+	if resultData == nil {
+		return fmt.Errorf("the execution has no result data")
+	}
+	if !executionCustomDataKeyRegexp.MatchString(key) {
+		return fmt.Errorf("custom data key %q may only contain letters, digits and underscores", key)
+	}
+	if len(key) > ExecutionCustomDataKeyMaxLength {
+		key = key[:ExecutionCustomDataKeyMaxLength]
+	}
+	if len(value) > ExecutionCustomDataValueMaxLength {
+		value = value[:ExecutionCustomDataValueMaxLength]
+	}
+	if resultData.MetaData == nil {
+		resultData.MetaData = make(map[string]string)
+	}
+	if _, ok := resultData.MetaData[key]; !ok && len(resultData.MetaData) >= ExecutionCustomDataMaxKeys {
+		return fmt.Errorf("custom data can only have %d keys", ExecutionCustomDataMaxKeys)
+	}
+	resultData.MetaData[key] = value
+	return nil
+}
+
+// ExecutionCustomDataValue converts the value to the string saved as custom data, the non-string values as JSON.
+func ExecutionCustomDataValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return JsonStr(v)
+	}
+}
+
+// SaveExecutionMetadata saves the custom data of the execution, the existing keys are overwritten.
+func SaveExecutionMetadata(ctx context.Context, executionId int32, metadata map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, metadata[key])
+	}
+	return rdsDbQueries.UpsertExecutionMetadata(ctx, rdsDbLib.UpsertExecutionMetadataParams{
+		ExecutionId: executionId,
+		Keys:        keys,
+		Values:      values,
+	})
+}
+
+// Get the saved custom data of the execution.
+func GetExecutionMetadata(ctx context.Context, executionId int32) (map[string]string, error) {
+	rows, err := rdsDbQueries.ListExecutionMetadata(ctx, executionId)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]string, len(rows))
+	for _, row := range rows {
+		metadata[row.Key] = row.Value
+	}
+	return metadata, nil
+}
@@ -0,0 +1,46 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/execution_metadata_test.go
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestSetExecutionCustomData(t *testing.T) {
+	assert := require.New(t)
+	resultData := &structs.WorkflowRunExecutionResultData{}
+
+	assert.Nil(core.SetExecutionCustomData(resultData, "orderId", "12345"))
+	assert.Nil(core.SetExecutionCustomData(resultData, "orderId", "67890"))
+	assert.Equal(map[string]string{"orderId": "67890"}, resultData.MetaData)
+
+	// the key may only contain letters, digits and underscores
+	assert.NotNil(core.SetExecutionCustomData(resultData, "order-id", "1"))
+	assert.NotNil(core.SetExecutionCustomData(resultData, "", "1"))
+
+	// the too long key and value are truncated
+	longKey := strings.Repeat("k", core.ExecutionCustomDataKeyMaxLength+1)
+	assert.Nil(core.SetExecutionCustomData(resultData, longKey, strings.Repeat("v", core.ExecutionCustomDataValueMaxLength+1)))
+	assert.Equal(strings.Repeat("v", core.ExecutionCustomDataValueMaxLength), resultData.MetaData[longKey[:core.ExecutionCustomDataKeyMaxLength]])
+
+	// at most ExecutionCustomDataMaxKeys keys, the existing keys can still be set
+	for i := len(resultData.MetaData); i < core.ExecutionCustomDataMaxKeys; i++ {
+		assert.Nil(core.SetExecutionCustomData(resultData, fmt.Sprintf("key_%d", i), "value"))
+	}
+	assert.NotNil(core.SetExecutionCustomData(resultData, "oneMore", "value"))
+	assert.Nil(core.SetExecutionCustomData(resultData, "orderId", "12345"))
+	assert.Len(resultData.MetaData, core.ExecutionCustomDataMaxKeys)
+
+	assert.Equal("", core.ExecutionCustomDataValue(nil))
+	assert.Equal("abc", core.ExecutionCustomDataValue("abc"))
+	assert.Equal("12345", core.ExecutionCustomDataValue(int64(12345)))
+	assert.Equal("true", core.ExecutionCustomDataValue(true))
+}
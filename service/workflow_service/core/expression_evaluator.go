@@ -196,6 +196,9 @@ func convertAnyValueToString(val interface{}) (string, error) {
 		// date
 	case time.Time:
 		return v.UTC().Format(time.RFC3339), nil
+	case *DateTime, *Duration, *Interval:
+		isoValue, _ := standardizeDateTimeValue(v)
+		return convertAnyValueToString(isoValue)
 	case nil:
 		return "", nil
 	default:
@@ -209,19 +212,26 @@ func convertAnyValueToString(val interface{}) (string, error) {
 	}
 }
 
-// NewExpressionEvaluator creates a new ExpressionEvaluator
+// NewExpressionEvaluator creates a new ExpressionEvaluator with a pooled VM, call Release when done
 // sbc can be nil
 func NewExpressionEvaluator(sbc *SandboxContext) *ExpressionEvaluator {
 	sb := Sandbox{
 		Lang:    CodeLanguage,
 		Context: sbc,
 	}
-	sb.Initialize()
+	sb.InitializeFromPool()
 	return &ExpressionEvaluator{
 		Sandbox: &sb,
 	}
 }
 
+// Release returns the VM of the evaluator to the pool
+func (ee *ExpressionEvaluator) Release() {
+	if ee.Sandbox != nil {
+		ee.Sandbox.Release()
+	}
+}
+
 // Get the parameter value from the input with Javascript expression evaluation.
 func GetParameterValue(
 	value interface{},
@@ -237,6 +247,7 @@ func GetParameterValue(
 
 	sandboxContext := getSandboxContextFromInput(input)
 	evaluator := NewExpressionEvaluator(sandboxContext)
+	defer evaluator.Release()
 	returnData, err := resolveParameterValue(value, evaluator, itemIndex)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// The extension methods are implemented in javascript and defined on the prototypes,
+// the helpers that javascript does not have (base64, hash) are implemented in Go.
+//
+//go:embed expression_extensions.js
+var expressionExtensionsJs string
+
+// compiled once and shared by all the sandboxes, a goja.Program is safe to run in many runtimes
+var expressionExtensionsProgram = goja.MustCompile("expression_extensions.js", expressionExtensionsJs, true)
+
+// ExpressionExtension describes an extension method for the editor autocomplete
+type ExpressionExtension struct {
+	// Type is the value type the method is called on: string, number, boolean, array, object or date
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Args        []string `json:"args,omitempty"`
+	ReturnType  string   `json:"returnType"`
+	Description string   `json:"description"`
+	Example     string   `json:"example,omitempty"`
+}
+
+// ExpressionExtensions is the catalog of the extension methods in expression_extensions.js
+var ExpressionExtensions = []ExpressionExtension{
+	// string
+	{Type: "string", Name: "isEmpty", ReturnType: "boolean", Description: "Returns true if the string has no characters"},
+	{Type: "string", Name: "isNotEmpty", ReturnType: "boolean", Description: "Returns true if the string has at least one character"},
+	{Type: "string", Name: "toTitleCase", ReturnType: "string", Description: "Capitalizes the first letter of each word", Example: `"hello world".toTitleCase() // "Hello World"`},
+	{Type: "string", Name: "toSentenceCase", ReturnType: "string", Description: "Capitalizes the first letter of each sentence", Example: `"hello. bye".toSentenceCase() // "Hello. Bye"`},
+	{Type: "string", Name: "toSnakeCase", ReturnType: "string", Description: "Converts the string to snake case", Example: `"Hello World".toSnakeCase() // "hello_world"`},
+	{Type: "string", Name: "toCamelCase", ReturnType: "string", Description: "Converts the string to camel case", Example: `"hello world".toCamelCase() // "helloWorld"`},
+	{Type: "string", Name: "extractEmail", ReturnType: "string", Description: "Extracts the first email address in the string"},
+	{Type: "string", Name: "extractDomain", ReturnType: "string", Description: "Extracts the domain of an email address or URL"},
+	{Type: "string", Name: "extractUrl", ReturnType: "string", Description: "Extracts the first URL in the string"},
+	{Type: "string", Name: "extractUrlPath", ReturnType: "string", Description: "Extracts the path of the URL"},
+	{Type: "string", Name: "isEmail", ReturnType: "boolean", Description: "Returns true if the string is an email address"},
+	{Type: "string", Name: "isUrl", ReturnType: "boolean", Description: "Returns true if the string is a http or https URL"},
+	{Type: "string", Name: "isDomain", ReturnType: "boolean", Description: "Returns true if the string is a domain name"},
+	{Type: "string", Name: "isNumeric", ReturnType: "boolean", Description: "Returns true if the string is a number"},
+	{Type: "string", Name: "removeTags", ReturnType: "string", Description: "Removes the HTML tags"},
+	{Type: "string", Name: "removeMarkdown", ReturnType: "string", Description: "Removes the Markdown formatting"},
+	{Type: "string", Name: "replaceSpecialChars", ReturnType: "string", Description: "Replaces the accented characters with the plain ones", Example: `"café".replaceSpecialChars() // "cafe"`},
+	{Type: "string", Name: "quote", Args: []string{"mark?"}, ReturnType: "string", Description: `Wraps the string in quotes, the mark defaults to "`},
+	{Type: "string", Name: "toNumber", ReturnType: "number", Description: "Converts the string to a number, throws if it is not numeric"},
+	{Type: "string", Name: "toInt", ReturnType: "number", Description: "Parses the string as an integer"},
+	{Type: "string", Name: "toFloat", ReturnType: "number", Description: "Parses the string as a float"},
+	{Type: "string", Name: "toBoolean", ReturnType: "boolean", Description: `Returns false for "", "false", "0", "no", "off", otherwise true`},
+	{Type: "string", Name: "toDateTime", ReturnType: "DateTime", Description: "Parses the ISO, SQL or date string to a DateTime"},
+	{Type: "string", Name: "parseJson", ReturnType: "any", Description: "Parses the string as JSON"},
+	{Type: "string", Name: "toJsonString", ReturnType: "string", Description: "Converts the string to a JSON string literal"},
+	{Type: "string", Name: "urlEncode", Args: []string{"allChars?"}, ReturnType: "string", Description: "Encodes the string as URL, encodes all the special characters if allChars is true"},
+	{Type: "string", Name: "urlDecode", Args: []string{"allChars?"}, ReturnType: "string", Description: "Decodes the URL encoded string"},
+	{Type: "string", Name: "base64Encode", ReturnType: "string", Description: "Encodes the string as base64"},
+	{Type: "string", Name: "base64Decode", ReturnType: "string", Description: "Decodes the base64 string"},
+	{Type: "string", Name: "hash", Args: []string{"algorithm?"}, ReturnType: "string", Description: "Returns the hex hash, the algorithm is one of md5 (default), sha1, sha256, sha384, sha512"},
+
+	// number
+	{Type: "number", Name: "round", Args: []string{"decimals?"}, ReturnType: "number", Description: "Rounds the number to the decimal places", Example: `(1.256).round(2) // 1.26`},
+	{Type: "number", Name: "ceil", ReturnType: "number", Description: "Rounds the number up"},
+	{Type: "number", Name: "floor", ReturnType: "number", Description: "Rounds the number down"},
+	{Type: "number", Name: "abs", ReturnType: "number", Description: "Returns the absolute value"},
+	{Type: "number", Name: "isEven", ReturnType: "boolean", Description: "Returns true if the number is even"},
+	{Type: "number", Name: "isOdd", ReturnType: "boolean", Description: "Returns true if the number is odd"},
+	{Type: "number", Name: "isInteger", ReturnType: "boolean", Description: "Returns true if the number is an integer"},
+	{Type: "number", Name: "toBoolean", ReturnType: "boolean", Description: "Returns false for 0, otherwise true"},
+	{Type: "number", Name: "toDateTime", Args: []string{"format?"}, ReturnType: "DateTime", Description: "Converts the epoch to a DateTime, the format is one of ms (default), s, us, excel"},
+	{Type: "number", Name: "format", Args: []string{"fractionDigits?"}, ReturnType: "string", Description: "Formats the number with thousands separators", Example: `(1234.5).format(2) // "1,234.50"`},
+
+	// boolean
+	{Type: "boolean", Name: "toInt", ReturnType: "number", Description: "Returns 1 for true and 0 for false"},
+	{Type: "boolean", Name: "toNumber", ReturnType: "number", Description: "Returns 1 for true and 0 for false"},
+
+	// array
+	{Type: "array", Name: "isEmpty", ReturnType: "boolean", Description: "Returns true if the array has no elements"},
+	{Type: "array", Name: "isNotEmpty", ReturnType: "boolean", Description: "Returns true if the array has at least one element"},
+	{Type: "array", Name: "first", ReturnType: "any", Description: "Returns the first element"},
+	{Type: "array", Name: "last", ReturnType: "any", Description: "Returns the last element"},
+	{Type: "array", Name: "pluck", Args: []string{"...fields"}, ReturnType: "array", Description: "Returns the field values of the objects, or the objects with only the fields if there are many fields", Example: `$input.all().pluck("json").pluck("name")`},
+	{Type: "array", Name: "unique", Args: []string{"...fields?"}, ReturnType: "array", Description: "Removes the duplicates, the objects are compared by the fields if any"},
+	{Type: "array", Name: "removeDuplicates", Args: []string{"...fields?"}, ReturnType: "array", Description: "Alias of unique"},
+	{Type: "array", Name: "sum", ReturnType: "number", Description: "Returns the sum of the numbers"},
+	{Type: "array", Name: "average", ReturnType: "number", Description: "Returns the average of the numbers"},
+	{Type: "array", Name: "min", ReturnType: "number", Description: "Returns the smallest number"},
+	{Type: "array", Name: "max", ReturnType: "number", Description: "Returns the largest number"},
+	{Type: "array", Name: "compact", ReturnType: "array", Description: "Removes the empty values: null, undefined, empty string, array or object"},
+	{Type: "array", Name: "chunk", Args: []string{"size"}, ReturnType: "array", Description: "Splits the array into arrays of the size"},
+	{Type: "array", Name: "difference", Args: []string{"other"}, ReturnType: "array", Description: "Returns the elements that are not in the other array"},
+	{Type: "array", Name: "intersection", Args: []string{"other"}, ReturnType: "array", Description: "Returns the unique elements that are in both arrays"},
+	{Type: "array", Name: "union", Args: []string{"other"}, ReturnType: "array", Description: "Returns the unique elements of both arrays"},
+	{Type: "array", Name: "append", Args: []string{"...items"}, ReturnType: "array", Description: "Returns a new array with the items added to the end"},
+	{Type: "array", Name: "randomItem", ReturnType: "any", Description: "Returns a random element"},
+	{Type: "array", Name: "smartJoin", Args: []string{"keyField", "valueField"}, ReturnType: "object", Description: "Merges the objects into one object, using keyField as the key and valueField as the value"},
+	{Type: "array", Name: "renameKeys", Args: []string{"from", "to", "..."}, ReturnType: "array", Description: "Renames the keys of the objects"},
+	{Type: "array", Name: "toJsonString", ReturnType: "string", Description: "Converts the array to a JSON string"},
+
+	// object
+	{Type: "object", Name: "isEmpty", ReturnType: "boolean", Description: "Returns true if the object has no fields"},
+	{Type: "object", Name: "isNotEmpty", ReturnType: "boolean", Description: "Returns true if the object has at least one field"},
+	{Type: "object", Name: "keys", ReturnType: "array", Description: "Returns the field names"},
+	{Type: "object", Name: "values", ReturnType: "array", Description: "Returns the field values"},
+	{Type: "object", Name: "hasField", Args: []string{"field"}, ReturnType: "boolean", Description: "Returns true if the object has the field"},
+	{Type: "object", Name: "removeField", Args: []string{"field"}, ReturnType: "object", Description: "Returns a copy of the object without the field"},
+	{Type: "object", Name: "removeFieldsContaining", Args: []string{"value"}, ReturnType: "object", Description: "Removes the fields whose value contains or equals the value"},
+	{Type: "object", Name: "keepFieldsContaining", Args: []string{"value"}, ReturnType: "object", Description: "Keeps only the fields whose value contains or equals the value"},
+	{Type: "object", Name: "compact", ReturnType: "object", Description: "Removes the fields with empty values"},
+	{Type: "object", Name: "toJsonString", ReturnType: "string", Description: "Converts the object to a JSON string"},
+	{Type: "object", Name: "urlEncode", ReturnType: "string", Description: "Converts the object to a URL query string", Example: `{a: 1, b: "x y"}.urlEncode() // "a=1&b=x%20y"`},
+
+	// date, the same methods are on DateTime
+	{Type: "date", Name: "toDateTime", ReturnType: "DateTime", Description: "Converts the date to a DateTime"},
+	{Type: "date", Name: "format", Args: []string{"format"}, ReturnType: "string", Description: "Formats the date with the Luxon tokens", Example: `$now.format("yyyy-MM-dd")`},
+	{Type: "date", Name: "plus", Args: []string{"duration", "unit?"}, ReturnType: "date", Description: "Adds the duration, e.g. plus(5, 'days') or plus({days: 5})"},
+	{Type: "date", Name: "minus", Args: []string{"duration", "unit?"}, ReturnType: "date", Description: "Subtracts the duration, e.g. minus(5, 'days') or minus({days: 5})"},
+	{Type: "date", Name: "beginningOf", Args: []string{"unit?"}, ReturnType: "date", Description: "Returns the start of the unit, the unit defaults to week"},
+	{Type: "date", Name: "endOfMonth", ReturnType: "date", Description: "Returns the last millisecond of the month"},
+	{Type: "date", Name: "isWeekend", ReturnType: "boolean", Description: "Returns true if the date is Saturday or Sunday"},
+	{Type: "date", Name: "isBetween", Args: []string{"start", "end"}, ReturnType: "boolean", Description: "Returns true if the date is between the two dates"},
+	{Type: "date", Name: "isInLast", Args: []string{"count", "unit?"}, ReturnType: "boolean", Description: "Returns true if the date is within the last count units, e.g. isInLast(2, 'days')"},
+	{Type: "date", Name: "extract", Args: []string{"unit?"}, ReturnType: "number", Description: "Returns the unit value, the unit defaults to week (the week number)"},
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(strings.ReplaceAll(algorithm, "-", "")) {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
+	case "sha512":
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+}
+
+// setupExpressionExtensions defines the extension methods on the prototypes of the VM
+func setupExpressionExtensions(vm *goja.Runtime) error {
+	value, err := vm.RunProgram(expressionExtensionsProgram)
+	if err != nil {
+		return err
+	}
+	install, ok := goja.AssertFunction(value)
+	if !ok {
+		return fmt.Errorf("expression extensions must be a function")
+	}
+	helpers := map[string]interface{}{
+		"base64Encode": func(text string) string {
+			return base64.StdEncoding.EncodeToString([]byte(text))
+		},
+		"base64Decode": func(text string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		},
+		"hash": func(text string, algorithm string) (string, error) {
+			h, err := newHash(algorithm)
+			if err != nil {
+				return "", err
+			}
+			h.Write([]byte(text))
+			return hex.EncodeToString(h.Sum(nil)), nil
+		},
+	}
+	_, err = install(goja.Undefined(), vm.ToValue(helpers))
+	return err
+}
@@ -0,0 +1,488 @@
+// Data transformation extension methods of the n8n expressions,
+// see https://docs.n8n.io/code/builtin/data-transformation-functions/
+// The methods are defined as non-enumerable prototype properties so they never show up
+// in JSON.stringify, Object.keys or for...in of the user data.
+// Keep ExpressionExtensions in expression_extensions.go in sync with this file.
+(function (helpers) {
+	'use strict';
+
+	function define(proto, name, fn) {
+		Object.defineProperty(proto, name, {
+			value: fn,
+			writable: true,
+			configurable: true,
+			enumerable: false,
+		});
+	}
+
+	function isPlainObject(value) {
+		return value !== null && typeof value === 'object' && !Array.isArray(value) && !(value instanceof Date);
+	}
+
+	function isEmptyValue(value) {
+		if (value === null || value === undefined || value === '') {
+			return true;
+		}
+		if (Array.isArray(value)) {
+			return value.length === 0;
+		}
+		if (isPlainObject(value)) {
+			return Object.keys(value).length === 0;
+		}
+		return false;
+	}
+
+	function sameValue(a, b) {
+		if (a === b) {
+			return true;
+		}
+		if (a !== null && b !== null && typeof a === 'object' && typeof b === 'object') {
+			return JSON.stringify(a) === JSON.stringify(b);
+		}
+		return false;
+	}
+
+	function uniqueBy(list, keyOf) {
+		const seen = [];
+		const result = [];
+		for (const item of list) {
+			const key = keyOf(item);
+			if (!seen.some((s) => sameValue(s, key))) {
+				seen.push(key);
+				result.push(item);
+			}
+		}
+		return result;
+	}
+
+	function numbersOf(list) {
+		return list.map((value) => (typeof value === 'string' ? parseFloat(value) : value))
+			.filter((value) => typeof value === 'number' && !isNaN(value));
+	}
+
+	function splitWords(text) {
+		return String(text)
+			.replace(/([a-z0-9])([A-Z])/g, '$1 $2')
+			.split(/[^A-Za-z0-9À-ɏ]+/)
+			.filter((word) => word.length > 0);
+	}
+
+	const emailRegex = /[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*\.[a-zA-Z]{2,}/;
+	const urlRegex = /https?:\/\/(?:www\.)?[-a-zA-Z0-9@:%._+~#=]{1,256}\.[a-zA-Z0-9()]{1,6}\b[-a-zA-Z0-9()@:%_+.~#?&/=]*/;
+	const domainRegex = /^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$/;
+
+	function parseUrl(text) {
+		const match = /^([a-zA-Z][a-zA-Z0-9+.-]*):\/\/([^/?#]*)([^?#]*)(\?[^#]*)?(#.*)?$/.exec(text);
+		if (!match) {
+			return undefined;
+		}
+		const host = match[2].replace(/^[^@]*@/, '').replace(/:\d+$/, '');
+		return { protocol: match[1], host: host, path: match[3] || '/', search: match[4] || '', hash: match[5] || '' };
+	}
+
+	function shiftDate(date, duration, unit, sign) {
+		const dt = DateTime.fromJSDate(date);
+		const shifted = sign > 0
+			? (unit === undefined ? dt.plus(duration) : dt.plus(duration, unit))
+			: (unit === undefined ? dt.minus(duration) : dt.minus(duration, unit));
+		return shifted.toJSDate();
+	}
+
+	// ------------------------------ string ------------------------------
+
+	define(String.prototype, 'isEmpty', function () {
+		return this.length === 0;
+	});
+	define(String.prototype, 'isNotEmpty', function () {
+		return this.length > 0;
+	});
+	define(String.prototype, 'toTitleCase', function () {
+		return this.replace(/\S+/g, (word) => word.charAt(0).toUpperCase() + word.slice(1).toLowerCase());
+	});
+	define(String.prototype, 'toSentenceCase', function () {
+		return this.toLowerCase().replace(/(^\s*|[.!?]\s+)([a-z])/g, (_, prefix, letter) => prefix + letter.toUpperCase());
+	});
+	define(String.prototype, 'toSnakeCase', function () {
+		return splitWords(this).map((word) => word.toLowerCase()).join('_');
+	});
+	define(String.prototype, 'toCamelCase', function () {
+		return splitWords(this)
+			.map((word, i) => (i === 0 ? word.toLowerCase() : word.charAt(0).toUpperCase() + word.slice(1).toLowerCase()))
+			.join('');
+	});
+	define(String.prototype, 'extractEmail', function () {
+		const match = emailRegex.exec(this);
+		return match ? match[0] : undefined;
+	});
+	define(String.prototype, 'extractUrl', function () {
+		const match = urlRegex.exec(this);
+		return match ? match[0] : undefined;
+	});
+	define(String.prototype, 'extractDomain', function () {
+		const text = String(this).trim();
+		if (emailRegex.test(text) && text.indexOf('://') === -1) {
+			return text.slice(text.lastIndexOf('@') + 1);
+		}
+		const url = parseUrl(text.indexOf('://') === -1 ? 'http://' + text : text);
+		return url && domainRegex.test(url.host) ? url.host : undefined;
+	});
+	define(String.prototype, 'extractUrlPath', function () {
+		const url = parseUrl(String(this).trim());
+		return url ? url.path : undefined;
+	});
+	define(String.prototype, 'isEmail', function () {
+		const text = String(this).trim();
+		const match = emailRegex.exec(text);
+		return !!match && match[0] === text;
+	});
+	define(String.prototype, 'isUrl', function () {
+		const url = parseUrl(String(this).trim());
+		return !!url && /^https?$/i.test(url.protocol) && url.host.length > 0;
+	});
+	define(String.prototype, 'isDomain', function () {
+		return domainRegex.test(String(this).trim());
+	});
+	define(String.prototype, 'isNumeric', function () {
+		const text = String(this).trim();
+		return text.length > 0 && !isNaN(Number(text));
+	});
+	define(String.prototype, 'removeTags', function () {
+		return this.replace(/<[^>]*>?/gm, '');
+	});
+	define(String.prototype, 'removeMarkdown', function () {
+		return this
+			.replace(/```[\s\S]*?```/g, '')
+			.replace(/!\[([^\]]*)\]\([^)]*\)/g, '$1')
+			.replace(/\[([^\]]*)\]\([^)]*\)/g, '$1')
+			.replace(/^\s{0,3}#{1,6}\s+/gm, '')
+			.replace(/^\s*>\s?/gm, '')
+			.replace(/^\s*[-*+]\s+/gm, '')
+			.replace(/(\*\*|__)(.*?)\1/g, '$2')
+			.replace(/(\*|_)(.*?)\1/g, '$2')
+			.replace(/`([^`]*)`/g, '$1')
+			.trim();
+	});
+	define(String.prototype, 'replaceSpecialChars', function () {
+		return this.normalize('NFD').replace(/[̀-ͯ]/g, '');
+	});
+	define(String.prototype, 'quote', function (mark) {
+		const quoteMark = mark === undefined ? '"' : String(mark);
+		return quoteMark + this.split(quoteMark).join('\\' + quoteMark) + quoteMark;
+	});
+	define(String.prototype, 'toNumber', function () {
+		const text = String(this).trim();
+		if (text.length === 0 || isNaN(Number(text))) {
+			throw new Error(`cannot convert "${this}" to a number`);
+		}
+		return Number(text);
+	});
+	define(String.prototype, 'toInt', function () {
+		const value = parseInt(this, 10);
+		if (isNaN(value)) {
+			throw new Error(`cannot convert "${this}" to an integer`);
+		}
+		return value;
+	});
+	define(String.prototype, 'toFloat', function () {
+		const value = parseFloat(this);
+		if (isNaN(value)) {
+			throw new Error(`cannot convert "${this}" to a float`);
+		}
+		return value;
+	});
+	define(String.prototype, 'toBoolean', function () {
+		const text = String(this).trim().toLowerCase();
+		return !['', 'false', '0', 'no', 'n', 'off', 'null', 'undefined'].includes(text);
+	});
+	define(String.prototype, 'toDateTime', function () {
+		let dt = DateTime.fromISO(String(this));
+		if (!dt.isValid) {
+			dt = DateTime.fromSQL(String(this));
+		}
+		if (!dt.isValid) {
+			const date = new Date(String(this));
+			if (!isNaN(date.getTime())) {
+				dt = DateTime.fromJSDate(date);
+			}
+		}
+		return dt;
+	});
+	define(String.prototype, 'parseJson', function () {
+		return JSON.parse(this);
+	});
+	define(String.prototype, 'toJsonString', function () {
+		return JSON.stringify(String(this));
+	});
+	define(String.prototype, 'urlEncode', function (allChars) {
+		return allChars ? encodeURIComponent(this) : encodeURI(this);
+	});
+	define(String.prototype, 'urlDecode', function (allChars) {
+		return allChars ? decodeURIComponent(this) : decodeURI(this);
+	});
+	define(String.prototype, 'base64Encode', function () {
+		return helpers.base64Encode(String(this));
+	});
+	define(String.prototype, 'base64Decode', function () {
+		return helpers.base64Decode(String(this));
+	});
+	define(String.prototype, 'hash', function (algorithm) {
+		return helpers.hash(String(this), algorithm || 'md5');
+	});
+
+	// ------------------------------ number ------------------------------
+
+	define(Number.prototype, 'round', function (decimals) {
+		const factor = Math.pow(10, decimals || 0);
+		return Math.round(this * factor) / factor;
+	});
+	define(Number.prototype, 'ceil', function () {
+		return Math.ceil(this);
+	});
+	define(Number.prototype, 'floor', function () {
+		return Math.floor(this);
+	});
+	define(Number.prototype, 'abs', function () {
+		return Math.abs(this);
+	});
+	define(Number.prototype, 'isEven', function () {
+		return Number.isInteger(Number(this)) && this % 2 === 0;
+	});
+	define(Number.prototype, 'isOdd', function () {
+		return Number.isInteger(Number(this)) && Math.abs(this % 2) === 1;
+	});
+	define(Number.prototype, 'isInteger', function () {
+		return Number.isInteger(Number(this));
+	});
+	define(Number.prototype, 'toBoolean', function () {
+		return Number(this) !== 0;
+	});
+	define(Number.prototype, 'toDateTime', function (format) {
+		switch (format || 'ms') {
+			case 's':
+				return DateTime.fromSeconds(Number(this));
+			case 'us':
+				return DateTime.fromMillis(Number(this) / 1000);
+			case 'excel':
+				// days since 1899-12-30
+				return DateTime.fromMillis((Number(this) - 25569) * 86400 * 1000);
+			default:
+				return DateTime.fromMillis(Number(this));
+		}
+	});
+	define(Number.prototype, 'format', function (fractionDigits) {
+		const value = fractionDigits === undefined ? Number(this) : Number(this).toFixed(fractionDigits);
+		const parts = String(value).split('.');
+		parts[0] = parts[0].replace(/\B(?=(\d{3})+(?!\d))/g, ',');
+		return parts.join('.');
+	});
+
+	// ------------------------------ boolean ------------------------------
+
+	define(Boolean.prototype, 'toInt', function () {
+		return this.valueOf() ? 1 : 0;
+	});
+	define(Boolean.prototype, 'toNumber', function () {
+		return this.valueOf() ? 1 : 0;
+	});
+
+	// ------------------------------ array ------------------------------
+
+	define(Array.prototype, 'isEmpty', function () {
+		return this.length === 0;
+	});
+	define(Array.prototype, 'isNotEmpty', function () {
+		return this.length > 0;
+	});
+	define(Array.prototype, 'first', function () {
+		return this[0];
+	});
+	define(Array.prototype, 'last', function () {
+		return this[this.length - 1];
+	});
+	define(Array.prototype, 'pluck', function (...fields) {
+		return Array.prototype.map.call(this, (item) => {
+			if (!isPlainObject(item)) {
+				return undefined;
+			}
+			if (fields.length === 1) {
+				return item[fields[0]];
+			}
+			const picked = {};
+			for (const field of fields) {
+				if (field in item) {
+					picked[field] = item[field];
+				}
+			}
+			return picked;
+		}).filter((value) => value !== undefined);
+	});
+	define(Array.prototype, 'unique', function (...fields) {
+		const list = Array.prototype.slice.call(this);
+		if (fields.length === 0) {
+			return uniqueBy(list, (item) => item);
+		}
+		return uniqueBy(list, (item) => (isPlainObject(item) ? fields.map((field) => item[field]) : item));
+	});
+	define(Array.prototype, 'removeDuplicates', Array.prototype.unique);
+	define(Array.prototype, 'sum', function () {
+		return numbersOf(this).reduce((total, value) => total + value, 0);
+	});
+	define(Array.prototype, 'average', function () {
+		const numbers = numbersOf(this);
+		return numbers.length === 0 ? NaN : numbers.reduce((total, value) => total + value, 0) / numbers.length;
+	});
+	define(Array.prototype, 'min', function () {
+		return Math.min(...numbersOf(this));
+	});
+	define(Array.prototype, 'max', function () {
+		return Math.max(...numbersOf(this));
+	});
+	define(Array.prototype, 'compact', function () {
+		return Array.prototype.filter.call(this, (value) => !isEmptyValue(value))
+			.map((value) => (isPlainObject(value) ? value.compact() : value));
+	});
+	define(Array.prototype, 'chunk', function (size) {
+		const chunkSize = Math.max(1, size || 1);
+		const chunks = [];
+		for (let i = 0; i < this.length; i += chunkSize) {
+			chunks.push(Array.prototype.slice.call(this, i, i + chunkSize));
+		}
+		return chunks;
+	});
+	define(Array.prototype, 'difference', function (other) {
+		return Array.prototype.filter.call(this, (value) => !(other || []).some((o) => sameValue(o, value)));
+	});
+	define(Array.prototype, 'intersection', function (other) {
+		return uniqueBy(Array.prototype.filter.call(this, (value) => (other || []).some((o) => sameValue(o, value))), (v) => v);
+	});
+	define(Array.prototype, 'union', function (other) {
+		return uniqueBy(Array.prototype.concat.call(Array.prototype.slice.call(this), other || []), (v) => v);
+	});
+	define(Array.prototype, 'append', function (...items) {
+		return Array.prototype.concat.call(Array.prototype.slice.call(this), items);
+	});
+	define(Array.prototype, 'randomItem', function () {
+		return this[Math.floor(Math.random() * this.length)];
+	});
+	define(Array.prototype, 'smartJoin', function (keyField, valueField) {
+		const result = {};
+		for (const item of this) {
+			if (isPlainObject(item) && item[keyField] !== undefined) {
+				result[String(item[keyField])] = item[valueField];
+			}
+		}
+		return result;
+	});
+	define(Array.prototype, 'renameKeys', function (...pairs) {
+		return Array.prototype.map.call(this, (item) => {
+			if (!isPlainObject(item)) {
+				return item;
+			}
+			const renamed = Object.assign({}, item);
+			for (let i = 0; i + 1 < pairs.length; i += 2) {
+				if (pairs[i] in renamed) {
+					renamed[pairs[i + 1]] = renamed[pairs[i]];
+					delete renamed[pairs[i]];
+				}
+			}
+			return renamed;
+		});
+	});
+	define(Array.prototype, 'toJsonString', function () {
+		return JSON.stringify(this);
+	});
+
+	// ------------------------------ object ------------------------------
+
+	define(Object.prototype, 'isEmpty', function () {
+		return Object.keys(this).length === 0;
+	});
+	define(Object.prototype, 'isNotEmpty', function () {
+		return Object.keys(this).length > 0;
+	});
+	define(Object.prototype, 'keys', function () {
+		return Object.keys(this);
+	});
+	define(Object.prototype, 'values', function () {
+		return Object.keys(this).map((key) => this[key]);
+	});
+	define(Object.prototype, 'hasField', function (field) {
+		return Object.prototype.hasOwnProperty.call(this, field);
+	});
+	define(Object.prototype, 'removeField', function (field) {
+		const result = Object.assign({}, this);
+		delete result[field];
+		return result;
+	});
+	define(Object.prototype, 'removeFieldsContaining', function (value) {
+		const result = {};
+		for (const key of Object.keys(this)) {
+			const fieldValue = this[key];
+			if (!(typeof fieldValue === 'string' && fieldValue.includes(String(value))) && !sameValue(fieldValue, value)) {
+				result[key] = fieldValue;
+			}
+		}
+		return result;
+	});
+	define(Object.prototype, 'keepFieldsContaining', function (value) {
+		const result = {};
+		for (const key of Object.keys(this)) {
+			const fieldValue = this[key];
+			if ((typeof fieldValue === 'string' && fieldValue.includes(String(value))) || sameValue(fieldValue, value)) {
+				result[key] = fieldValue;
+			}
+		}
+		return result;
+	});
+	define(Object.prototype, 'compact', function () {
+		const result = {};
+		for (const key of Object.keys(this)) {
+			const value = this[key];
+			if (!isEmptyValue(value)) {
+				result[key] = isPlainObject(value) ? value.compact() : value;
+			}
+		}
+		return result;
+	});
+	define(Object.prototype, 'toJsonString', function () {
+		return JSON.stringify(this);
+	});
+	define(Object.prototype, 'urlEncode', function () {
+		return Object.keys(this)
+			.map((key) => encodeURIComponent(key) + '=' + encodeURIComponent(String(this[key])))
+			.join('&');
+	});
+
+	// ------------------------------ date ------------------------------
+
+	define(Date.prototype, 'toDateTime', function () {
+		return DateTime.fromJSDate(this);
+	});
+	define(Date.prototype, 'format', function (format) {
+		return DateTime.fromJSDate(this).toFormat(format);
+	});
+	define(Date.prototype, 'plus', function (duration, unit) {
+		return shiftDate(this, duration, unit, 1);
+	});
+	define(Date.prototype, 'minus', function (duration, unit) {
+		return shiftDate(this, duration, unit, -1);
+	});
+	define(Date.prototype, 'beginningOf', function (unit) {
+		return DateTime.fromJSDate(this).beginningOf(unit || 'week').toJSDate();
+	});
+	define(Date.prototype, 'endOfMonth', function () {
+		return DateTime.fromJSDate(this).endOfMonth().toJSDate();
+	});
+	define(Date.prototype, 'isWeekend', function () {
+		return DateTime.fromJSDate(this).isWeekend;
+	});
+	define(Date.prototype, 'isBetween', function (start, end) {
+		return DateTime.fromJSDate(this).isBetween(start, end);
+	});
+	define(Date.prototype, 'isInLast', function (count, unit) {
+		return DateTime.fromJSDate(this).isInLast(count, unit || 'minutes');
+	});
+	define(Date.prototype, 'extract', function (unit) {
+		return DateTime.fromJSDate(this).extract(unit || 'week');
+	});
+});
@@ -0,0 +1,141 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/sandbox_datetime_test.go service/workflow_service/core/expression_extensions_test.go
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+)
+
+func TestExpressionExtensions(t *testing.T) {
+
+	t.Run("Extension catalog is implemented", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+		samples := map[string]string{
+			"string":  `'text'`,
+			"number":  `(1)`,
+			"boolean": `true`,
+			"array":   `[1]`,
+			"object":  `({a: 1})`,
+			"date":    `new Date()`,
+		}
+		for _, extension := range core.ExpressionExtensions {
+			sample, ok := samples[extension.Type]
+			assert.True(ok, extension.Type)
+			res, err := sandbox.RunCode(fmt.Sprintf(`typeof %s.%s`, sample, extension.Name), 0)
+			assert.Nil(err)
+			assert.Equal("function", res, extension.Type+"."+extension.Name)
+		}
+	})
+
+	t.Run("String extensions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		cases := map[string]interface{}{
+			`'hello wORLD'.toTitleCase()`:                           "Hello World",
+			`'helloWorld foo-bar'.toSnakeCase()`:                    "hello_world_foo_bar",
+			`''.isEmpty()`:                                          true,
+			`'contact: Jane <jane.doe@example.com>'.extractEmail()`: "jane.doe@example.com",
+			`'see https://example.com/a?b=1 now'.extractUrl()`:      "https://example.com/a?b=1",
+			`'jane@example.com'.extractDomain()`:                    "example.com",
+			`'https://www.example.com/path'.extractDomain()`:        "www.example.com",
+			`'12.5'.toNumber() + 1`:                                 13.5,
+			`'no'.toBoolean()`:                                      false,
+			`'abc'.base64Encode().base64Decode()`:                   "abc",
+			`'abc'.hash('sha256')`:                                  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			`'<p>hi</p>'.removeTags()`:                              "hi",
+			`'2024-01-02'.toDateTime().toISODate()`:                 "2024-01-02",
+		}
+		for code, expected := range cases {
+			res, err := sandbox.RunCode(code, 0)
+			assert.Nil(err, code)
+			assert.Equal(expected, res, code)
+		}
+
+		_, err := sandbox.RunCode(`'abc'.toNumber()`, 0)
+		assert.NotNil(err)
+	})
+
+	t.Run("Number and boolean extensions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		cases := map[string]interface{}{
+			`(1.256).round(2)`:                     1.26,
+			`(4).isEven()`:                         true,
+			`(1234567.891).format(2)`:              "1,234,567.89",
+			`(1704067200).toDateTime('s').toISO()`: "2024-01-01T00:00:00.000Z",
+			`true.toInt()`:                         int64(1),
+		}
+		for code, expected := range cases {
+			res, err := sandbox.RunCode(code, 0)
+			assert.Nil(err, code)
+			assert.Equal(expected, res, code)
+		}
+	})
+
+	t.Run("Array and object extensions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		cases := map[string]interface{}{
+			`[{a: 1, b: 2}, {a: 3}].pluck('a')`:                       []interface{}{int64(1), int64(3)},
+			`[1, 2, 2, {a: 1}, {a: 1}].unique()`:                      []interface{}{int64(1), int64(2), map[string]interface{}{"a": int64(1)}},
+			`[{id: 1, v: 'a'}, {id: 1, v: 'b'}].unique('id').length`:  int64(1),
+			`[1, '2', 3.5, 'x'].sum()`:                                6.5,
+			`[1, 2, 3, 4, 5].chunk(2).length`:                         int64(3),
+			`[{k: 'a', v: 1}, {k: 'b', v: 2}].smartJoin('k', 'v')`:    map[string]interface{}{"a": int64(1), "b": int64(2)},
+			`({a: 1, b: null, c: ''}).compact()`:                      map[string]interface{}{"a": int64(1)},
+			`({a: 1, b: 2}).keys()`:                                   []interface{}{"a", "b"},
+			`({}).isEmpty()`:                                          true,
+			`({a: 1, b: 'x y'}).urlEncode()`:                          "a=1&b=x%20y",
+			`$json.keys()`:                                            []interface{}{"a"},
+			`Object.keys({a: 1}).length + JSON.stringify([1]).length`: int64(4),
+		}
+		for code, expected := range cases {
+			res, err := sandbox.RunCode(code, 0)
+			assert.Nil(err, code)
+			assert.Equal(expected, res, code)
+		}
+	})
+
+	t.Run("Date extensions", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		cases := map[string]interface{}{
+			`DateTime.utc(2024, 3, 10).format('yyyy-MM-dd')`:                  "2024-03-10",
+			`DateTime.utc(2024, 3, 10).plus(5, 'days').toISODate()`:           "2024-03-15",
+			`DateTime.utc(2024, 3, 10).minus({months: 1}).toISODate()`:        "2024-02-10",
+			`DateTime.utc(2024, 3, 10).beginningOf('month').toISODate()`:      "2024-03-01",
+			`DateTime.utc(2024, 2, 10).endOfMonth().toISODate()`:              "2024-02-29",
+			`DateTime.utc(2024, 3, 10).isBetween('2024-03-01', '2024-04-01')`: true,
+			`DateTime.utc(2024, 3, 10).extract()`:                             int64(10),
+			`$now.minus(1, 'hours').isInLast(2, 'hours')`:                     true,
+			`new Date('2024-03-10T00:00:00Z').plus(1, 'days').toISOString()`:  "2024-03-11T00:00:00.000Z",
+			`new Date('2024-03-10T00:00:00Z').format('yyyy LLL d')`:           "2024 Mar 10",
+			`new Date('2024-03-10T00:00:00Z').isWeekend()`:                    true,
+		}
+		for code, expected := range cases {
+			res, err := sandbox.RunCode(code, 0)
+			assert.Nil(err, code)
+			assert.Equal(expected, res, code)
+		}
+
+		eval := core.ExpressionEvaluator{Sandbox: sandbox}
+		res, err := eval.EvaluateExpression(`={{ 'jane doe'.toTitleCase() }} {{ [1, 2].sum() }}`, 0)
+		assert.Nil(err)
+		assert.Equal("Jane Doe 3", res)
+	})
+}
@@ -0,0 +1,434 @@
+package core
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The Form Trigger serves the HTML form of its fields, and starts the workflow with the submission.
+// The submission is the urlencoded or multipart form posted by the page, or a JSON object, keyed by the field labels.
+// It is validated by the field types before any execution is started, the numbers are converted to float64,
+// the multiple choices of the dropdown are arrays, and the uploaded files are put into the item binary
+// keyed by the field label, with the index if the field has multiple files.
+// After the submission the page shows the text of options.respondWithOptions or redirects to its URL.
+
+const (
+	FormFieldType_Date     = "date"
+	FormFieldType_Dropdown = "dropdown"
+	FormFieldType_Email    = "email"
+	FormFieldType_File     = "file"
+	FormFieldType_Number   = "number"
+	FormFieldType_Password = "password"
+	FormFieldType_Text     = "text"
+	FormFieldType_Textarea = "textarea"
+
+	FormRespondWith_Text     = "text"
+	FormRespondWith_Redirect = "redirect"
+
+	DefaultFormSubmittedText = "Your response has been recorded"
+
+	formDateLayout = "2006-01-02"
+)
+
+var (
+	//go:embed form_trigger.html
+	formTriggerHtml string
+
+	formTriggerTemplate = template.Must(template.New("form_trigger").Parse(formTriggerHtml))
+)
+
+// FormValidationError is the error of the submission which does not match the form fields.
+type FormValidationError struct {
+	Errors []string
+}
+
+func (err *FormValidationError) Error() string {
+	return "invalid form submission: " + strings.Join(err.Errors, "; ")
+}
+
+// FormTriggerResponse is what the page shows after the form is submitted.
+type FormTriggerResponse struct {
+	RespondWith       string  `json:"respondWith"`
+	FormSubmittedText *string `json:"formSubmittedText"`
+	RedirectUrl       string  `json:"redirectUrl"`
+}
+
+// GetText returns the text to show, the default if it is not set.
+func (response *FormTriggerResponse) GetText() string {
+	if response.FormSubmittedText == nil {
+		return DefaultFormSubmittedText
+	}
+	return *response.FormSubmittedText
+}
+
+type (
+	formTriggerPage struct {
+		Title       string
+		Description string
+		Errors      []string
+		Fields      []formTriggerPageField
+		HasFile     bool
+	}
+
+	formTriggerPageField struct {
+		Id       string
+		Label    string
+		Type     string
+		Required bool
+		Multiple bool
+		Options  []string
+		Accept   string
+	}
+)
+
+// GetFormTriggerParameters returns the form of the Form Trigger node, the field type is text if it is not set.
+func GetFormTriggerParameters(node *structs.WorkflowNode) (*structs.WorkflowFrom, error) {
+	parameters, err := json.Marshal(node.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	form := &structs.WorkflowFrom{}
+	if err := json.Unmarshal(parameters, form); err != nil {
+		return nil, fmt.Errorf("invalid form trigger parameters: %w", err)
+	}
+	for i := range form.FormFields.Values {
+		if form.FormFields.Values[i].FieldType == "" {
+			form.FormFields.Values[i].FieldType = FormFieldType_Text
+		}
+	}
+	return form, nil
+}
+
+// GetFormTriggerResponse returns the response of the form set in options.respondWithOptions.
+func GetFormTriggerResponse(form *structs.WorkflowFrom) (*FormTriggerResponse, error) {
+	options := struct {
+		RespondWithOptions struct {
+			Values FormTriggerResponse `json:"values"`
+		} `json:"respondWithOptions"`
+	}{}
+	raw, err := json.Marshal(form.Options)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &options); err != nil {
+		return nil, fmt.Errorf("invalid form trigger options: %w", err)
+	}
+	response := options.RespondWithOptions.Values
+	if response.RespondWith == "" {
+		response.RespondWith = FormRespondWith_Text
+	}
+	if response.RespondWith == FormRespondWith_Redirect && response.RedirectUrl == "" {
+		return nil, fmt.Errorf("the redirect URL of the form is empty")
+	}
+	return &response, nil
+}
+
+// GetFormTriggerResponseFromWebhookResponse returns the response of the form set by the Respond to Webhook node,
+// which responds with the JSON of the formSubmittedText or the redirectURL key.
+func GetFormTriggerResponseFromWebhookResponse(response *fasthttp.Response) *FormTriggerResponse {
+	body := map[string]interface{}{}
+	if response != nil && !response.IsBodyStream() {
+		_ = json.Unmarshal(response.Body(), &body)
+	}
+	if redirectUrl, ok := body["redirectURL"].(string); ok && redirectUrl != "" {
+		return &FormTriggerResponse{RespondWith: FormRespondWith_Redirect, RedirectUrl: redirectUrl}
+	}
+	formResponse := &FormTriggerResponse{RespondWith: FormRespondWith_Text}
+	if text, ok := body["formSubmittedText"].(string); ok {
+		formResponse.FormSubmittedText = &text
+	}
+	return formResponse
+}
+
+// RenderFormTrigger returns the HTML page of the form, with the errors of the invalid submission if any.
+func RenderFormTrigger(form *structs.WorkflowFrom, errors []string) ([]byte, error) {
+	page := formTriggerPage{
+		Title:       form.FormTitle,
+		Description: form.FormDescription,
+		Errors:      errors,
+	}
+	for i, field := range form.FormFields.Values {
+		pageField := formTriggerPageField{
+			Id:       fmt.Sprintf("field-%d", i),
+			Label:    field.FieldLabel,
+			Type:     field.FieldType,
+			Required: field.RequiredField,
+		}
+		switch field.FieldType {
+		case FormFieldType_Dropdown:
+			pageField.Multiple = field.Multiselect
+			for _, option := range field.FieldOptions.Values {
+				pageField.Options = append(pageField.Options, option.Option)
+			}
+		case FormFieldType_File:
+			pageField.Multiple = field.MultipleFiles
+			pageField.Accept = field.AcceptFileTypes
+			page.HasFile = true
+		}
+		page.Fields = append(page.Fields, pageField)
+	}
+	return renderFormTriggerTemplate("form", page)
+}
+
+// RenderFormTriggerCompletion returns the HTML page shown after the form is submitted.
+func RenderFormTriggerCompletion(form *structs.WorkflowFrom, text string) ([]byte, error) {
+	return renderFormTriggerTemplate("completion", formTriggerPage{
+		Title:       form.FormTitle,
+		Description: text,
+	})
+}
+
+func renderFormTriggerTemplate(name string, page formTriggerPage) ([]byte, error) {
+	result := bytes.Buffer{}
+	if err := formTriggerTemplate.ExecuteTemplate(&result, name, page); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
+
+// ParseFormTriggerSubmission validates the submission of the form and returns the json and the binary data
+// of the item output by the Form Trigger node. Returns FormValidationError if the submission does not match
+// the form fields.
+func ParseFormTriggerSubmission(
+	request *fasthttp.Request,
+	form *structs.WorkflowFrom) (map[string]interface{}, map[string]structs.WorkflowBinaryData, error) {
+	values, files, err := parseFormTriggerRequest(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item := map[string]interface{}{}
+	binary := map[string]structs.WorkflowBinaryData{}
+	validationErrors := []string{}
+	for _, field := range form.FormFields.Values {
+		label := field.FieldLabel
+		if field.FieldType == FormFieldType_File {
+			fileNames, err := addFormTriggerFiles(field, files[label], binary)
+			if err != nil {
+				validationErrors = append(validationErrors, err.Error())
+			} else if fileNames != nil {
+				item[label] = fileNames
+			}
+			continue
+		}
+
+		value, err := parseFormTriggerFieldValue(field, values[label])
+		if err != nil {
+			validationErrors = append(validationErrors, err.Error())
+			continue
+		}
+		item[label] = value
+	}
+	if len(validationErrors) > 0 {
+		return nil, nil, &FormValidationError{Errors: validationErrors}
+	}
+	return item, binary, nil
+}
+
+// parseFormTriggerRequest returns the values and the files of the submission keyed by the field labels.
+func parseFormTriggerRequest(request *fasthttp.Request) (map[string][]string, map[string][]*multipart.FileHeader, error) {
+	values := map[string][]string{}
+	files := map[string][]*multipart.FileHeader{}
+	mediaType, _, _ := mime.ParseMediaType(string(request.Header.ContentType()))
+	switch strings.ToLower(mediaType) {
+	case "multipart/form-data":
+		multipartForm, err := request.MultipartForm()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		return multipartForm.Value, multipartForm.File, nil
+
+	case "application/x-www-form-urlencoded":
+		request.PostArgs().VisitAll(func(key, value []byte) {
+			values[string(key)] = append(values[string(key)], string(value))
+		})
+		return values, files, nil
+
+	case "application/json":
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(request.Body(), &body); err != nil {
+			return nil, nil, fmt.Errorf("the form submission must be a JSON object: %w", err)
+		}
+		for key, value := range body {
+			values[key] = formTriggerJsonValues(value)
+		}
+		return values, files, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported content type of the form submission: %s", mediaType)
+	}
+}
+
+// formTriggerJsonValues converts the JSON value of the submission to the values of the field.
+func formTriggerJsonValues(value interface{}) []string {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{value}
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}
+	case []interface{}:
+		values := []string{}
+		for _, element := range value {
+			values = append(values, formTriggerJsonValues(element)...)
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(value)}
+	}
+}
+
+// parseFormTriggerFieldValue validates the values submitted for the field and converts them by the field type.
+func parseFormTriggerFieldValue(field structs.WorkflowFromFields, values []string) (interface{}, error) {
+	label := field.FieldLabel
+	submitted := []string{}
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			submitted = append(submitted, value)
+		}
+	}
+	multiple := field.FieldType == FormFieldType_Dropdown && field.Multiselect
+	if len(submitted) == 0 {
+		if field.RequiredField {
+			return nil, fmt.Errorf("%s is required", label)
+		}
+		if multiple {
+			return []string{}, nil
+		}
+		return "", nil
+	}
+	if len(submitted) > 1 && !multiple {
+		return nil, fmt.Errorf("%s accepts only one value", label)
+	}
+
+	value := strings.TrimSpace(submitted[0])
+	switch field.FieldType {
+	case FormFieldType_Number:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", label)
+		}
+		return number, nil
+
+	case FormFieldType_Date:
+		if _, err := time.Parse(formDateLayout, value); err != nil {
+			return nil, fmt.Errorf("%s must be a date in the format YYYY-MM-DD", label)
+		}
+		return value, nil
+
+	case FormFieldType_Email:
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return nil, fmt.Errorf("%s must be an email address", label)
+		}
+		return value, nil
+
+	case FormFieldType_Dropdown:
+		for _, option := range submitted {
+			if !hasFormFieldOption(field, option) {
+				return nil, fmt.Errorf("%s has no option %s", label, option)
+			}
+		}
+		if multiple {
+			return submitted, nil
+		}
+		return submitted[0], nil
+
+	default:
+		// The text is kept as it is submitted.
+		return submitted[0], nil
+	}
+}
+
+func hasFormFieldOption(field structs.WorkflowFromFields, value string) bool {
+	for _, option := range field.FieldOptions.Values {
+		if option.Option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// addFormTriggerFiles puts the uploaded files of the file field into the binary and returns their names,
+// the name of the single file or the array of names if the field accepts multiple files.
+func addFormTriggerFiles(
+	field structs.WorkflowFromFields,
+	fileHeaders []*multipart.FileHeader,
+	binary map[string]structs.WorkflowBinaryData) (interface{}, error) {
+	label := field.FieldLabel
+	if len(fileHeaders) == 0 {
+		if field.RequiredField {
+			return nil, fmt.Errorf("%s is required", label)
+		}
+		return nil, nil
+	}
+	if len(fileHeaders) > 1 && !field.MultipleFiles {
+		return nil, fmt.Errorf("%s accepts only one file", label)
+	}
+
+	fileNames := []string{}
+	for index, fileHeader := range fileHeaders {
+		contentType := fileHeader.Header.Get("Content-Type")
+		if !isFormFileTypeAccepted(field.AcceptFileTypes, fileHeader.Filename, contentType) {
+			return nil, fmt.Errorf("%s does not accept the file %s", label, fileHeader.Filename)
+		}
+		content, err := readMultipartFile(fileHeader)
+		if err != nil {
+			return nil, err
+		}
+		key := label
+		if len(fileHeaders) > 1 {
+			key = fmt.Sprintf("%s%d", label, index)
+		}
+		binary[key] = NewBinaryData(content, contentType, fileHeader.Filename)
+		fileNames = append(fileNames, fileHeader.Filename)
+	}
+	if !field.MultipleFiles {
+		return fileNames[0], nil
+	}
+	return fileNames, nil
+}
+
+// isFormFileTypeAccepted returns whether the file matches the comma-separated extensions or MIME types,
+// e.g. ".pdf, image/*". All files are accepted if the types are empty.
+func isFormFileTypeAccepted(acceptFileTypes string, fileName string, contentType string) bool {
+	if strings.TrimSpace(acceptFileTypes) == "" {
+		return true
+	}
+	extension := strings.ToLower(filepath.Ext(fileName))
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+	for _, accepted := range strings.Split(acceptFileTypes, ",") {
+		accepted = strings.ToLower(strings.TrimSpace(accepted))
+		switch {
+		case accepted == "":
+			continue
+		case strings.HasPrefix(accepted, "."):
+			if extension == accepted {
+				return true
+			}
+		case strings.HasSuffix(accepted, "/*"):
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(accepted, "*")) {
+				return true
+			}
+		case mediaType == accepted:
+			return true
+		}
+	}
+	return false
+}
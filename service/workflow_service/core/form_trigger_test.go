@@ -0,0 +1,236 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_body_test.go service/workflow_service/core/form_trigger_test.go
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func formTriggerNode_Testing(options map[string]interface{}) *structs.WorkflowNode {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return &structs.WorkflowNode{
+		Name: "Form Trigger",
+		Type: "n8n-nodes-base.formTrigger",
+		Parameters: map[string]interface{}{
+			"path":            "contact",
+			"formTitle":       "Contact <us>",
+			"formDescription": "We'll get back to you soon",
+			"formFields": map[string]interface{}{
+				"values": []interface{}{
+					map[string]interface{}{"fieldLabel": "Name", "requiredField": true},
+					map[string]interface{}{"fieldLabel": "Email", "fieldType": "email", "requiredField": true},
+					map[string]interface{}{"fieldLabel": "Seats", "fieldType": "number"},
+					map[string]interface{}{"fieldLabel": "Start", "fieldType": "date"},
+					map[string]interface{}{"fieldLabel": "Plan", "fieldType": "dropdown",
+						"fieldOptions": map[string]interface{}{"values": []interface{}{
+							map[string]interface{}{"option": "basic"}, map[string]interface{}{"option": "pro"}}}},
+					map[string]interface{}{"fieldLabel": "Regions", "fieldType": "dropdown", "multiselect": true,
+						"fieldOptions": map[string]interface{}{"values": []interface{}{
+							map[string]interface{}{"option": "us"}, map[string]interface{}{"option": "eu"}}}},
+					map[string]interface{}{"fieldLabel": "Contract", "fieldType": "file", "acceptFileTypes": ".pdf, image/*"},
+				},
+			},
+			"options": options,
+		},
+	}
+}
+
+func TestFormTrigger(t *testing.T) {
+
+	t.Run("Parse the submission", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		form, err := core.GetFormTriggerParameters(formTriggerNode_Testing(nil))
+		assert.Nil(err)
+		assert.Equal(core.FormFieldType_Text, form.FormFields.Values[0].FieldType)
+
+		values := url.Values{
+			"Name":    {"Ada"},
+			"Email":   {"ada@example.com"},
+			"Seats":   {"12.5"},
+			"Start":   {"2024-05-01"},
+			"Plan":    {"pro"},
+			"Regions": {"us", "eu"},
+		}
+		item, binary, err := core.ParseFormTriggerSubmission(
+			bodyRequest_Testing("application/x-www-form-urlencoded", []byte(values.Encode())), form)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{
+			"Name":    "Ada",
+			"Email":   "ada@example.com",
+			"Seats":   12.5,
+			"Start":   "2024-05-01",
+			"Plan":    "pro",
+			"Regions": []string{"us", "eu"},
+		}, item)
+		assert.Empty(binary)
+
+		// The JSON object, the optional fields are empty.
+		item, _, err = core.ParseFormTriggerSubmission(
+			bodyRequest_Testing("application/json", []byte(`{"Name":"Ada","Email":"ada@example.com","Seats":3}`)), form)
+		assert.Nil(err)
+		assert.Equal(3.0, item["Seats"])
+		assert.Equal("", item["Plan"])
+		assert.Equal([]string{}, item["Regions"])
+
+		_, _, err = core.ParseFormTriggerSubmission(bodyRequest_Testing("text/plain", []byte("Ada")), form)
+		assert.EqualError(err, "unsupported content type of the form submission: text/plain")
+	})
+
+	t.Run("Validate the submission", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		form, err := core.GetFormTriggerParameters(formTriggerNode_Testing(nil))
+		assert.Nil(err)
+
+		values := url.Values{
+			"Email":   {"not an email"},
+			"Seats":   {"many"},
+			"Start":   {"05/01/2024"},
+			"Plan":    {"enterprise"},
+			"Regions": {"us", "apac"},
+		}
+		_, _, err = core.ParseFormTriggerSubmission(
+			bodyRequest_Testing("application/x-www-form-urlencoded", []byte(values.Encode())), form)
+		validationError := &core.FormValidationError{}
+		assert.ErrorAs(err, &validationError)
+		assert.Equal([]string{
+			"Name is required",
+			"Email must be an email address",
+			"Seats must be a number",
+			"Start must be a date in the format YYYY-MM-DD",
+			"Plan has no option enterprise",
+			"Regions has no option apac",
+		}, validationError.Errors)
+
+		// The single choice dropdown accepts one value.
+		_, _, err = core.ParseFormTriggerSubmission(bodyRequest_Testing("application/json",
+			[]byte(`{"Name":"Ada","Email":"ada@example.com","Plan":["basic","pro"]}`)), form)
+		assert.EqualError(err, "invalid form submission: Plan accepts only one value")
+	})
+
+	t.Run("Upload files", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		form, err := core.GetFormTriggerParameters(formTriggerNode_Testing(nil))
+		assert.Nil(err)
+
+		submit := func(fileNames ...string) (map[string]interface{}, map[string]structs.WorkflowBinaryData, error) {
+			content := &bytes.Buffer{}
+			writer := multipart.NewWriter(content)
+			assert.Nil(writer.WriteField("Name", "Ada"))
+			assert.Nil(writer.WriteField("Email", "ada@example.com"))
+			for _, fileName := range fileNames {
+				file, err := writer.CreateFormFile("Contract", fileName)
+				assert.Nil(err)
+				_, err = file.Write([]byte("%PDF-1.4"))
+				assert.Nil(err)
+			}
+			assert.Nil(writer.Close())
+			return core.ParseFormTriggerSubmission(bodyRequest_Testing(writer.FormDataContentType(), content.Bytes()), form)
+		}
+
+		item, binary, err := submit("contract.PDF")
+		assert.Nil(err)
+		assert.Equal("contract.PDF", item["Contract"])
+		assert.Equal("contract.PDF", binary["Contract"].FileName)
+		assert.Equal(base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")), binary["Contract"].Data)
+
+		// The field without the file is not in the item.
+		item, binary, err = submit()
+		assert.Nil(err)
+		assert.NotContains(item, "Contract")
+		assert.Empty(binary)
+
+		_, _, err = submit("contract.docx")
+		assert.EqualError(err, "invalid form submission: Contract does not accept the file contract.docx")
+		_, _, err = submit("a.pdf", "b.pdf")
+		assert.EqualError(err, "invalid form submission: Contract accepts only one file")
+
+		form.FormFields.Values[6].MultipleFiles = true
+		item, binary, err = submit("a.pdf", "b.pdf")
+		assert.Nil(err)
+		assert.Equal([]string{"a.pdf", "b.pdf"}, item["Contract"])
+		assert.Equal("a.pdf", binary["Contract0"].FileName)
+		assert.Equal("b.pdf", binary["Contract1"].FileName)
+	})
+
+	t.Run("Render the form and the completion", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		form, err := core.GetFormTriggerParameters(formTriggerNode_Testing(nil))
+		assert.Nil(err)
+
+		page, err := core.RenderFormTrigger(form, []string{"Name is required"})
+		assert.Nil(err)
+		html := string(page)
+		assert.Contains(html, "<title>Contact &lt;us&gt;</title>")
+		assert.Contains(html, `<form method="post" enctype="multipart/form-data">`)
+		assert.Contains(html, "<li>Name is required</li>")
+		assert.Contains(html, `<input id="field-0" name="Name" type="text" required>`)
+		assert.Contains(html, `<input id="field-1" name="Email" type="email" required>`)
+		assert.Contains(html, `<input id="field-2" name="Seats" type="number" step="any">`)
+		assert.Contains(html, `<select id="field-5" name="Regions" multiple>`)
+		assert.Contains(html, `<option value="pro">pro</option>`)
+		assert.Contains(html, `<input id="field-6" name="Contract" type="file" accept=".pdf, image/*">`)
+
+		page, err = core.RenderFormTriggerCompletion(form, "Thanks!")
+		assert.Nil(err)
+		assert.Contains(string(page), "Thanks!")
+		assert.False(strings.Contains(string(page), "<form"))
+	})
+
+	t.Run("Form response", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		form, err := core.GetFormTriggerParameters(formTriggerNode_Testing(nil))
+		assert.Nil(err)
+		response, err := core.GetFormTriggerResponse(form)
+		assert.Nil(err)
+		assert.Equal(core.FormRespondWith_Text, response.RespondWith)
+		assert.Equal(core.DefaultFormSubmittedText, response.GetText())
+
+		form, err = core.GetFormTriggerParameters(formTriggerNode_Testing(map[string]interface{}{
+			"respondWithOptions": map[string]interface{}{
+				"values": map[string]interface{}{"respondWith": "redirect", "redirectUrl": "https://example.com/thanks"}},
+		}))
+		assert.Nil(err)
+		response, err = core.GetFormTriggerResponse(form)
+		assert.Nil(err)
+		assert.Equal(core.FormRespondWith_Redirect, response.RespondWith)
+		assert.Equal("https://example.com/thanks", response.RedirectUrl)
+
+		// The empty text is kept.
+		form.Options = map[string]interface{}{"respondWithOptions": map[string]interface{}{
+			"values": map[string]interface{}{"respondWith": "text", "formSubmittedText": ""}}}
+		response, err = core.GetFormTriggerResponse(form)
+		assert.Nil(err)
+		assert.Equal("", response.GetText())
+
+		// The response of the Respond to Webhook node.
+		webhookResponse := &fasthttp.Response{}
+		webhookResponse.SetBodyString(`{"redirectURL":"https://example.com/next"}`)
+		response = core.GetFormTriggerResponseFromWebhookResponse(webhookResponse)
+		assert.Equal(core.FormRespondWith_Redirect, response.RespondWith)
+		assert.Equal("https://example.com/next", response.RedirectUrl)
+		webhookResponse.SetBodyString(`{"formSubmittedText":"See you"}`)
+		assert.Equal("See you", core.GetFormTriggerResponseFromWebhookResponse(webhookResponse).GetText())
+		webhookResponse.SetBodyString("done")
+		assert.Equal(core.DefaultFormSubmittedText, core.GetFormTriggerResponseFromWebhookResponse(webhookResponse).GetText())
+	})
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// The requests of the user, e.g. of the HTTP Request node and the approval notifications, must not reach the
+// internal network of the pod. ValidateUrl rejects the forbidden URLs early with a clear error, but the check
+// that holds is done by the dialer of NewPublicHttpClient: the resolved address of every connection is checked,
+// so neither a hostname resolving to an internal address nor a redirect to one is dialed.
+
+// ErrForbiddenAddress is returned when a request of the user is dialed to an internal address.
+var ErrForbiddenAddress = errors.New("forbidden ip address")
+
+// forbiddenNetworks are the internal networks not covered by the net.IP methods
+var forbiddenNetworks = mustParseCIDRs(
+	"100.64.0.0/10", // carrier-grade NAT, used by some cloud networks
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsForbiddenIp returns whether the IP is a private, loopback, link-local, unspecified or multicast address,
+// including the cloud metadata address 169.254.169.254 and the IPv4 addresses mapped to IPv6.
+func IsForbiddenIp(ip net.IP) bool {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDialAddress is the Control of the dialer, it is called with the resolved address of each connection.
+func checkDialAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsForbiddenIp(ip) {
+		return fmt.Errorf("%w %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// NewPublicHttpClient returns the HTTP client which only dials the public addresses, also after the redirects.
+// The proxy of the environment is not used, it would dial the address on behalf of the client.
+func NewPublicHttpClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
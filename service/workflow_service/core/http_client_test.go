@@ -0,0 +1,56 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/http_client_test.go
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+)
+
+func TestPublicHttpClient(t *testing.T) {
+	assert := require.New(t)
+
+	t.Run("Forbid the internal addresses", func(t *testing.T) {
+		for _, ip := range []string{
+			"10.0.2.15", "172.16.0.1", "172.31.255.255", "192.168.1.1", "127.0.0.1", "169.254.169.254",
+			"100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+		} {
+			assert.True(core.IsForbiddenIp(net.ParseIP(ip)), ip)
+		}
+		for _, ip := range []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"} {
+			assert.False(core.IsForbiddenIp(net.ParseIP(ip)), ip)
+		}
+
+		assert.NotNil(core.ValidateUrl("http://172.16.0.1/api"))
+		assert.NotNil(core.ValidateUrl("http://169.254.169.254/latest/meta-data/"))
+		assert.NotNil(core.ValidateUrl("http://[::1]:8080/api"))
+		assert.NotNil(core.ValidateUrl("http://[fe80::1]/api"))
+		assert.Nil(core.ValidateUrl("https://api.restful-api.dev/objects"))
+	})
+
+	t.Run("Never dial an internal address", func(t *testing.T) {
+		reached := false
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+		}))
+		defer internal.Close()
+		client := core.NewPublicHttpClient(5 * time.Second)
+
+		// The address is checked after the hostname is resolved.
+		_, err := client.Get(internal.URL)
+		assert.ErrorIs(err, core.ErrForbiddenAddress)
+		_, port, err := net.SplitHostPort(internal.Listener.Addr().String())
+		assert.Nil(err)
+		_, err = client.Get("http://localhost:" + port)
+		assert.ErrorIs(err, core.ErrForbiddenAddress)
+		assert.False(reached)
+	})
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The permissions of a caller are the permissions of its org role, plus the permissions of the share roles
+// granted on the workflow or the credential to the caller or to its org role.
+// The org roles ADMIN, EDITOR and VIEWER are built in, any other org role is the id of an identity.role
+// of the org with its permissions. No permission is ever granted on the workflow or the credential of another org.
+
+const (
+	OrgRole_Admin  = "ADMIN"
+	OrgRole_Editor = "EDITOR"
+	OrgRole_Viewer = "VIEWER"
+
+	// The column size of workflow.shared_workflow.granteeId
+	ShareGranteeIdMaxLength = 36
+)
+
+var (
+	ErrShareNotFound       = errors.New("no such share")
+	ErrCredentialsNotFound = errors.New("no such credential")
+	ErrWorkflowNotFound    = errors.New("no such workflow")
+)
+
+var orgRolePermissions = map[string][]structs.WorkflowPermission{
+	OrgRole_Admin: {
+		structs.WorkflowPermission_WorkflowRead,
+		structs.WorkflowPermission_WorkflowUpdate,
+		structs.WorkflowPermission_WorkflowExecute,
+		structs.WorkflowPermission_WorkflowDelete,
+		structs.WorkflowPermission_WorkflowShare,
+		structs.WorkflowPermission_CredentialUse,
+		structs.WorkflowPermission_CredentialShare,
+		structs.WorkflowPermission_ExecutionRead,
+		structs.WorkflowPermission_ExecutionDelete,
+	},
+	OrgRole_Editor: {
+		structs.WorkflowPermission_WorkflowRead,
+		structs.WorkflowPermission_WorkflowUpdate,
+		structs.WorkflowPermission_WorkflowExecute,
+		structs.WorkflowPermission_WorkflowDelete,
+		structs.WorkflowPermission_CredentialUse,
+		structs.WorkflowPermission_ExecutionRead,
+		structs.WorkflowPermission_ExecutionDelete,
+	},
+	OrgRole_Viewer: {
+		structs.WorkflowPermission_WorkflowRead,
+		structs.WorkflowPermission_ExecutionRead,
+	},
+}
+
+var shareRolePermissions = map[structs.WorkflowShareRole][]structs.WorkflowPermission{
+	structs.WorkflowShareRole_Viewer: {
+		structs.WorkflowPermission_WorkflowRead,
+		structs.WorkflowPermission_ExecutionRead,
+	},
+	structs.WorkflowShareRole_Editor: {
+		structs.WorkflowPermission_WorkflowRead,
+		structs.WorkflowPermission_WorkflowUpdate,
+		structs.WorkflowPermission_WorkflowExecute,
+		structs.WorkflowPermission_ExecutionRead,
+		structs.WorkflowPermission_ExecutionDelete,
+	},
+	structs.WorkflowShareRole_User: {
+		structs.WorkflowPermission_CredentialUse,
+	},
+}
+
+// GetOrgRolePermissions returns the permissions of the org role, the unknown role has no permission.
+func GetOrgRolePermissions(ctx context.Context, orgId string, role string) (map[structs.WorkflowPermission]bool, error) {
+	permissions := map[structs.WorkflowPermission]bool{}
+	if builtInPermissions, ok := orgRolePermissions[role]; ok {
+		for _, permission := range builtInPermissions {
+			permissions[permission] = true
+		}
+		return permissions, nil
+	}
+	role_RdsDbLib, err := rdsDbQueries.GetRole(ctx, rdsDbLib.GetRoleParams{
+		OrganizationID: orgId,
+		ID:             role,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return permissions, nil
+		}
+		return nil, err
+	}
+	for _, permission := range role_RdsDbLib.Permissions {
+		permissions[structs.WorkflowPermission(permission)] = true
+	}
+	return permissions, nil
+}
+
+// shareRolesHavePermission returns whether any of the share roles has the permission.
+func shareRolesHavePermission(shareRoles []string, permission structs.WorkflowPermission) bool {
+	for _, shareRole := range shareRoles {
+		for _, rolePermission := range shareRolePermissions[structs.WorkflowShareRole(shareRole)] {
+			if rolePermission == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasWorkflowPermission returns whether the caller with the org role has the permission on the workflow.
+// If workflowId is empty, only the permissions of the org role are checked.
+// Returns ErrWorkflowNotFound if the workflow belongs to another org.
+func HasWorkflowPermission(
+	ctx context.Context,
+	orgId string,
+	workflowId string,
+	callerId string,
+	role string,
+	permission structs.WorkflowPermission) (bool, error) {
+	if workflowId != "" {
+		workflowEntity, err := rdsDbQueries.GetWorkflowEntityById(ctx, workflowId)
+		if err == nil && workflowEntity.SugerOrgId != orgId {
+			return false, ErrWorkflowNotFound
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+	}
+	permissions, err := GetOrgRolePermissions(ctx, orgId, role)
+	if err != nil {
+		return false, err
+	}
+	if permissions[permission] || workflowId == "" {
+		return permissions[permission], nil
+	}
+	shareRoles, err := rdsDbQueries.ListSharedWorkflowRolesOfGrantee(ctx, rdsDbLib.ListSharedWorkflowRolesOfGranteeParams{
+		SugerOrgId: orgId,
+		WorkflowID: workflowId,
+		UserID:     callerId,
+		Role:       role,
+	})
+	if err != nil {
+		return false, err
+	}
+	return shareRolesHavePermission(shareRoles, permission), nil
+}
+
+// HasCredentialPermission returns whether the caller with the org role has the permission on the credential.
+func HasCredentialPermission(
+	ctx context.Context,
+	orgId string,
+	credentialsId string,
+	callerId string,
+	role string,
+	permission structs.WorkflowPermission) (bool, error) {
+	permissions, err := GetOrgRolePermissions(ctx, orgId, role)
+	if err != nil {
+		return false, err
+	}
+	if permissions[permission] {
+		return true, nil
+	}
+	shareRoles, err := rdsDbQueries.ListSharedCredentialsRolesOfGrantee(ctx, rdsDbLib.ListSharedCredentialsRolesOfGranteeParams{
+		SugerOrgId:    orgId,
+		CredentialsID: credentialsId,
+		UserID:        callerId,
+		Role:          role,
+	})
+	if err != nil {
+		return false, err
+	}
+	return shareRolesHavePermission(shareRoles, permission), nil
+}
+
+// CheckCredentialsOfOrg returns ErrCredentialsNotFound if the credential is not a credential of the org.
+func CheckCredentialsOfOrg(ctx context.Context, orgId string, credentialsId string) error {
+	_, err := rdsDbQueries.GetCredentialsEntity(ctx, rdsDbLib.GetCredentialsEntityParams{
+		SugerOrgId: orgId,
+		ID:         credentialsId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCredentialsNotFound
+	}
+	return err
+}
+
+// ValidateShare checks the grantee and the role of the share, a user grantee must be a member of the org.
+func ValidateShare(ctx context.Context, orgId string, share *structs.WorkflowShare, roles ...structs.WorkflowShareRole) error {
+	if share.GranteeId == "" || len(share.GranteeId) > ShareGranteeIdMaxLength {
+		return fmt.Errorf("granteeId must be 1 to %d characters", ShareGranteeIdMaxLength)
+	}
+	validRole := false
+	for _, role := range roles {
+		validRole = validRole || share.Role == role
+	}
+	if !validRole {
+		return fmt.Errorf("role must be one of %v", roles)
+	}
+	switch share.GranteeType {
+	case structs.WorkflowShareGranteeType_Role:
+		return nil
+	case structs.WorkflowShareGranteeType_User:
+		_, err := rdsDbQueries.GetUserRoleByUserAndOrganization(ctx, rdsDbLib.GetUserRoleByUserAndOrganizationParams{
+			UserID:         share.GranteeId,
+			OrganizationID: orgId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %s is not a member of the org", share.GranteeId)
+		}
+		return err
+	default:
+		return fmt.Errorf("granteeType must be %s or %s", structs.WorkflowShareGranteeType_User, structs.WorkflowShareGranteeType_Role)
+	}
+}
+
+// List the shares of the workflow, the earliest first.
+func ListWorkflowShares(ctx context.Context, orgId string, workflowId string) ([]structs.WorkflowShare, error) {
+	shares_RdsDbLib, err := rdsDbQueries.ListSharedWorkflows(ctx, rdsDbLib.ListSharedWorkflowsParams{
+		SugerOrgId: orgId,
+		WorkflowId: workflowId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]structs.WorkflowShare, 0, len(shares_RdsDbLib))
+	for _, share_RdsDbLib := range shares_RdsDbLib {
+		shares = append(shares, structs.ToWorkflowShare(share_RdsDbLib))
+	}
+	return shares, nil
+}
+
+// ShareWorkflow grants the access to the workflow, the role of an existing share of the grantee is replaced.
+func ShareWorkflow(ctx context.Context, orgId string, workflowId string, share *structs.WorkflowShare) (*structs.WorkflowShare, error) {
+	if err := ValidateShare(ctx, orgId, share, structs.WorkflowShareRole_Viewer, structs.WorkflowShareRole_Editor); err != nil {
+		return nil, err
+	}
+	share_RdsDbLib, err := rdsDbQueries.UpsertSharedWorkflow(ctx, rdsDbLib.UpsertSharedWorkflowParams{
+		WorkflowId:  workflowId,
+		SugerOrgId:  orgId,
+		GranteeType: string(share.GranteeType),
+		GranteeId:   share.GranteeId,
+		Role:        string(share.Role),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := structs.ToWorkflowShare(share_RdsDbLib)
+	return &result, nil
+}
+
+// UnshareWorkflow removes the share of the grantee, returns ErrShareNotFound if there is no such share.
+func UnshareWorkflow(ctx context.Context, orgId string, workflowId string, granteeType string, granteeId string) error {
+	_, err := rdsDbQueries.DeleteSharedWorkflow(ctx, rdsDbLib.DeleteSharedWorkflowParams{
+		SugerOrgId:  orgId,
+		WorkflowId:  workflowId,
+		GranteeType: granteeType,
+		GranteeId:   granteeId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareNotFound
+	}
+	return err
+}
+
+// List the shares of the credential in the org, the earliest first.
+func ListCredentialShares(ctx context.Context, orgId string, credentialsId string) ([]structs.WorkflowShare, error) {
+	shares_RdsDbLib, err := rdsDbQueries.ListSharedCredentials(ctx, rdsDbLib.ListSharedCredentialsParams{
+		SugerOrgId:    orgId,
+		CredentialsId: credentialsId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]structs.WorkflowShare, 0, len(shares_RdsDbLib))
+	for _, share_RdsDbLib := range shares_RdsDbLib {
+		shares = append(shares, structs.ToCredentialShare(share_RdsDbLib))
+	}
+	return shares, nil
+}
+
+// ShareCredential grants the use of the credential in the org.
+// Returns ErrCredentialsNotFound if the credential does not exist.
+func ShareCredential(ctx context.Context, orgId string, credentialsId string, share *structs.WorkflowShare) (*structs.WorkflowShare, error) {
+	if err := ValidateShare(ctx, orgId, share, structs.WorkflowShareRole_User); err != nil {
+		return nil, err
+	}
+	share_RdsDbLib, err := rdsDbQueries.UpsertSharedCredentials(ctx, rdsDbLib.UpsertSharedCredentialsParams{
+		CredentialsId: credentialsId,
+		SugerOrgId:    orgId,
+		GranteeType:   string(share.GranteeType),
+		GranteeId:     share.GranteeId,
+		Role:          string(share.Role),
+	})
+	if err != nil {
+		if shared.IsForeignKeyViolationError(err) {
+			return nil, ErrCredentialsNotFound
+		}
+		return nil, err
+	}
+	result := structs.ToCredentialShare(share_RdsDbLib)
+	return &result, nil
+}
+
+// UnshareCredential removes the share of the grantee, returns ErrShareNotFound if there is no such share.
+func UnshareCredential(ctx context.Context, orgId string, credentialsId string, granteeType string, granteeId string) error {
+	_, err := rdsDbQueries.DeleteSharedCredentials(ctx, rdsDbLib.DeleteSharedCredentialsParams{
+		SugerOrgId:    orgId,
+		CredentialsId: credentialsId,
+		GranteeType:   granteeType,
+		GranteeId:     granteeId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareNotFound
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/permissions_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestPermissions(t *testing.T) {
+
+	t.Run("Permissions of the built-in org roles", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+
+		admin, err := core.GetOrgRolePermissions(ctx, "org", core.OrgRole_Admin)
+		assert.Nil(err)
+		assert.True(admin[structs.WorkflowPermission_WorkflowShare])
+		assert.True(admin[structs.WorkflowPermission_CredentialShare])
+
+		editor, err := core.GetOrgRolePermissions(ctx, "org", core.OrgRole_Editor)
+		assert.Nil(err)
+		assert.True(editor[structs.WorkflowPermission_WorkflowUpdate])
+		assert.True(editor[structs.WorkflowPermission_CredentialUse])
+		assert.False(editor[structs.WorkflowPermission_WorkflowShare])
+
+		viewer, err := core.GetOrgRolePermissions(ctx, "org", core.OrgRole_Viewer)
+		assert.Nil(err)
+		assert.Equal(map[structs.WorkflowPermission]bool{
+			structs.WorkflowPermission_WorkflowRead:  true,
+			structs.WorkflowPermission_ExecutionRead: true,
+		}, viewer)
+
+		// Without a workflow only the org role is checked.
+		allowed, err := core.HasWorkflowPermission(ctx, "org", "", "user", core.OrgRole_Viewer, structs.WorkflowPermission_ExecutionRead)
+		assert.Nil(err)
+		assert.True(allowed)
+		allowed, err = core.HasWorkflowPermission(ctx, "org", "", "user", core.OrgRole_Viewer, structs.WorkflowPermission_WorkflowUpdate)
+		assert.Nil(err)
+		assert.False(allowed)
+	})
+
+	t.Run("Validate share", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+
+		invalid := []structs.WorkflowShare{
+			{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "", Role: structs.WorkflowShareRole_Viewer},
+			{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: "owner"},
+			{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: structs.WorkflowShareRole_User},
+			{GranteeType: "team", GranteeId: "VIEWER", Role: structs.WorkflowShareRole_Editor},
+		}
+		for _, share := range invalid {
+			assert.NotNil(core.ValidateShare(ctx, "org", &share, structs.WorkflowShareRole_Viewer, structs.WorkflowShareRole_Editor), share)
+		}
+		share := structs.WorkflowShare{GranteeType: structs.WorkflowShareGranteeType_Role, GranteeId: "VIEWER", Role: structs.WorkflowShareRole_Editor}
+		assert.Nil(core.ValidateShare(ctx, "org", &share, structs.WorkflowShareRole_Viewer, structs.WorkflowShareRole_Editor))
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
@@ -100,6 +103,73 @@ func ListWorkflowEntities(ctx context.Context, orgId string) ([]structs.Workflow
 	return workflowEntities, nil
 }
 
+// The filter of ListWorkflowEntitiesWithFilter, the zero value matches all the workflows.
+type WorkflowEntityFilter struct {
+	Name   string   // The case insensitive substring of the workflow name.
+	Active *bool    // Only the active or inactive workflows if set.
+	TagIds []string // The workflows with all the tags.
+	Limit  int      // No limit if 0.
+	Offset int
+}
+
+// likePatternEscaper escapes the wildcards of the LIKE pattern with the default escape character.
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// List the workflow entities of the org matching the filter, most recently updated first, with their tags.
+// Returns the page of workflow entities and the total number of the matching workflow entities.
+func ListWorkflowEntitiesWithFilter(ctx context.Context, orgId string, filter WorkflowEntityFilter) ([]structs.WorkflowEntity, int64, error) {
+	active := sql.NullBool{}
+	if filter.Active != nil {
+		active = sql.NullBool{Bool: *filter.Active, Valid: true}
+	}
+	name := likePatternEscaper.Replace(filter.Name)
+	tagIds := filter.TagIds
+	if tagIds == nil {
+		tagIds = []string{}
+	}
+	limit := int32(math.MaxInt32)
+	if filter.Limit > 0 && filter.Limit < math.MaxInt32 {
+		limit = int32(filter.Limit)
+	}
+	offset := int32(0)
+	if filter.Offset > 0 && filter.Offset < math.MaxInt32 {
+		offset = int32(filter.Offset)
+	}
+
+	workflowEntities_RdsDbLib, err := rdsDbQueries.ListWorkflowEntitiesWithFilter(ctx, rdsDbLib.ListWorkflowEntitiesWithFilterParams{
+		SugerOrgId: orgId,
+		Name:       name,
+		Active:     active,
+		TagIds:     tagIds,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := rdsDbQueries.CountWorkflowEntitiesWithFilter(ctx, rdsDbLib.CountWorkflowEntitiesWithFilterParams{
+		SugerOrgId: orgId,
+		Name:       name,
+		Active:     active,
+		TagIds:     tagIds,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	workflowEntities := make([]structs.WorkflowEntity, 0)
+	for _, workflowEntity_RdsDbLib := range workflowEntities_RdsDbLib {
+		workflowEntity, err := structs.ToWorkflowEntity(workflowEntity_RdsDbLib)
+		if err != nil {
+			return nil, 0, err
+		}
+		workflowEntities = append(workflowEntities, workflowEntity)
+	}
+	if err := FillWorkflowTags(ctx, workflowEntities); err != nil {
+		return nil, 0, err
+	}
+	return workflowEntities, count, nil
+}
+
 func DeleteWorkflowEntity(ctx context.Context, orgId string, workflowId string) (*structs.WorkflowEntity, error) {
 	workflowEntity_RdsDbLib, err := rdsDbQueries.DeleteWorkflowEntity(ctx, rdsDbLib.DeleteWorkflowEntityParams{
 		SugerOrgId: orgId,
@@ -161,21 +231,151 @@ func toWorkflowExecution(
 	}
 
 	return &structs.WorkflowExecution{
-		Id:             fmt.Sprint(executionEntity.ID),
-		Data:           &runExecutionData,
-		Finished:       executionEntity.Finished,
-		Mode:           structs.WorkflowExecutionMode(executionEntity.Mode),
-		Status:         structs.WorkflowExecutionStatus(executionEntity.Status.String),
-		RetryOf:        executionEntity.RetryOf.String,
-		RetrySuccessId: executionEntity.RetrySuccessId.String,
-		StartedAt:      &executionEntity.StartedAt,
-		StoppedAt:      ConvertNullTimeToStandardTimePointer(executionEntity.StoppedAt),
-		WaitTill:       ConvertNullTimeToStandardTimePointer(executionEntity.WaitTill),
-		WorkflowData:   &workflowData,
-		WorkflowId:     executionEntity.WorkflowId,
+		Id:                fmt.Sprint(executionEntity.ID),
+		Data:              &runExecutionData,
+		Finished:          executionEntity.Finished,
+		Mode:              structs.WorkflowExecutionMode(executionEntity.Mode),
+		Status:            structs.WorkflowExecutionStatus(executionEntity.Status.String),
+		RetryOf:           executionEntity.RetryOf.String,
+		RetrySuccessId:    executionEntity.RetrySuccessId.String,
+		StartedAt:         &executionEntity.StartedAt,
+		StoppedAt:         ConvertNullTimeToStandardTimePointer(executionEntity.StoppedAt),
+		WaitTill:          ConvertNullTimeToStandardTimePointer(executionEntity.WaitTill),
+		WorkflowData:      &workflowData,
+		WorkflowId:        executionEntity.WorkflowId,
+		WorkflowVersionId: executionEntity.WorkflowVersionId.String,
+		StartedBy:         executionEntity.StartedBy.String,
 	}, nil
 }
 
+// The executions are counted up to WorkflowExecutionsCountLimit, so counting is fast for the very large tables.
+// A larger count is returned as WorkflowExecutionsCountLimit and marked as estimated.
+const WorkflowExecutionsCountLimit = 10000
+
+// The cursor and the size of the page of ListWorkflowExecutionSummaries.
+type WorkflowExecutionsPage struct {
+	LastId  int32 // The executions older than the execution if set.
+	FirstId int32 // The executions newer than the execution if set.
+	Limit   int
+}
+
+// List the summaries of the executions of the org matching the filter, the latest first.
+// The filter of a workflow is set by filter.WorkflowId, the page is given by the cursor of the page.
+func ListWorkflowExecutionSummaries(
+	ctx context.Context,
+	orgId string,
+	filter structs.WorkflowExecutionsQueryFilter,
+	page WorkflowExecutionsPage) (*structs.ListWorkflowExecutionsResponseData, error) {
+	executionId := 0
+	if filter.ID != "" {
+		var err error
+		executionId, err = strconv.Atoi(filter.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid execution id %s", filter.ID)
+		}
+	}
+	statuses := make([]string, 0, len(filter.Status))
+	for _, status := range filter.Status {
+		statuses = append(statuses, string(status))
+	}
+	metadataKeys := make([]string, 0, len(filter.Metadata))
+	metadataValues := make([]string, 0, len(filter.Metadata))
+	for _, metadata := range filter.Metadata {
+		metadataKeys = append(metadataKeys, metadata.Key)
+		metadataValues = append(metadataValues, metadata.Value)
+	}
+	limit := int32(math.MaxInt32)
+	if page.Limit > 0 && page.Limit < math.MaxInt32 {
+		limit = int32(page.Limit)
+	}
+
+	count, err := rdsDbQueries.CountWorkflowExecutionEntitiesWithFilter(ctx, rdsDbLib.CountWorkflowExecutionEntitiesWithFilterParams{
+		SugerOrgId:     orgId,
+		WorkflowID:     filter.WorkflowId,
+		ExecutionID:    int32(executionId),
+		Statuses:       statuses,
+		Mode:           filter.Mode,
+		RetryOf:        filter.RetryOf,
+		RetrySuccessId: filter.RetrySuccessId,
+		Finished:       filter.Finished,
+		StartedAfter:   makeNullTime(filter.StartedAfter),
+		StartedBefore:  makeNullTime(filter.StartedBefore),
+		MetadataKeys:   metadataKeys,
+		MetadataValues: metadataValues,
+		CountLimit:     WorkflowExecutionsCountLimit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := rdsDbQueries.ListWorkflowExecutionEntitiesWithFilter(ctx, rdsDbLib.ListWorkflowExecutionEntitiesWithFilterParams{
+		SugerOrgId:     orgId,
+		WorkflowID:     filter.WorkflowId,
+		ExecutionID:    int32(executionId),
+		Statuses:       statuses,
+		Mode:           filter.Mode,
+		RetryOf:        filter.RetryOf,
+		RetrySuccessId: filter.RetrySuccessId,
+		Finished:       filter.Finished,
+		StartedAfter:   makeNullTime(filter.StartedAfter),
+		StartedBefore:  makeNullTime(filter.StartedBefore),
+		MetadataKeys:   metadataKeys,
+		MetadataValues: metadataValues,
+		LastID:         page.LastId,
+		FirstID:        page.FirstId,
+		PageLimit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &structs.ListWorkflowExecutionsResponseData{
+		Count:   count,
+		Results: make([]structs.WorkflowExecutionSummary, 0, len(rows)),
+	}
+	if count > WorkflowExecutionsCountLimit {
+		data.Count = WorkflowExecutionsCountLimit
+		data.Estimated = true
+	}
+	for _, row := range rows {
+		data.Results = append(data.Results, toWorkflowExecutionSummary(row))
+	}
+	return data, nil
+}
+
+func toWorkflowExecutionSummary(row rdsDbLib.ListWorkflowExecutionEntitiesWithFilterRow) structs.WorkflowExecutionSummary {
+	startedAt := row.StartedAt
+	summary := structs.WorkflowExecutionSummary{
+		Id:                fmt.Sprint(row.ID),
+		Finished:          row.Finished,
+		LastNodeExecuted:  row.LastNodeExecuted.String,
+		Mode:              structs.WorkflowExecutionMode(row.Mode),
+		RetryOf:           row.RetryOf.String,
+		RetrySuccessId:    row.RetrySuccessId.String,
+		Status:            structs.WorkflowExecutionStatus(row.Status.String),
+		StartedAt:         &startedAt,
+		StoppedAt:         ConvertNullTimeToStandardTimePointer(row.StoppedAt),
+		WaitTill:          ConvertNullTimeToStandardTimePointer(row.WaitTill),
+		WorkflowId:        row.WorkflowId,
+		WorkflowName:      row.WorkflowName,
+		WorkflowVersionId: row.WorkflowVersionId.String,
+		StartedBy:         row.StartedBy.String,
+	}
+	// The error of the last node has the details, the error of the run only has the message.
+	if row.ExecutionError.Valid {
+		executionError := &structs.WorkflowExecutionError{}
+		if err := json.Unmarshal(row.ExecutionError.RawMessage, executionError); err == nil && executionError.Message != "" {
+			summary.ExecutionError = executionError
+		}
+	}
+	if summary.ExecutionError == nil && row.Error.String != "" {
+		summary.ExecutionError = &structs.WorkflowExecutionError{
+			Message:    row.Error.String,
+			WorkflowId: row.WorkflowId,
+		}
+	}
+	return summary
+}
+
 func makeNullTime(t *time.Time) sql.NullTime {
 	if t == nil {
 		return sql.NullTime{Valid: false}
@@ -240,6 +440,16 @@ func CreateWorkflowExecutionAndData(ctx context.Context,
 			StartedAt:      *data.StartedAt,
 			Status:         sql.NullString{String: string(data.Status), Valid: true},
 			WorkflowId:     data.ExecutionData.WorkflowData.ID,
+			// Record the version of the workflow that runs.
+			WorkflowVersionId: sql.NullString{
+				String: data.ExecutionData.WorkflowData.VersionId,
+				Valid:  data.ExecutionData.WorkflowData.VersionId != "",
+			},
+			// Record the user or API client that starts the run.
+			StartedBy: sql.NullString{
+				String: data.ExecutionData.UserId,
+				Valid:  data.ExecutionData.UserId != "",
+			},
 		})
 	if err != nil {
 		return nil, err
@@ -2,13 +2,17 @@ package core
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"strconv"
 
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
 	sharedlog "github.com/sugerio/workflow-service-trial/shared/log"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
 
+var ErrExecutionNotWaiting = errors.New("the execution is not waiting")
+
 // RunWorkflow should be placed in WorkflowRunner (like n8n), place here temporarily
 func RunWorkflow(
 	ctx context.Context,
@@ -60,3 +64,83 @@ func RunWorkflow(
 
 	return strconv.Itoa(executionId), executingWorkflowData, nil
 }
+
+// ResumeWorkflowExecution runs the waiting execution again from the node which put it to wait, with the
+// saved execution data. Only one caller resumes the execution, the others get ErrExecutionNotWaiting.
+// The execution is put back to wait if it can not be resumed.
+func ResumeWorkflowExecution(ctx context.Context, executionId int32) (_ *structs.ExecutingWorkflowData, err error) {
+	waitTill, err := GetRdsDbQueries().ClaimWaitingWorkflowExecutionEntity(ctx, executionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrExecutionNotWaiting
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		releaseErr := GetRdsDbQueries().ReleaseClaimedWorkflowExecutionEntity(context.WithoutCancel(ctx),
+			rdsDbLib.ReleaseClaimedWorkflowExecutionEntityParams{ID: executionId, WaitTill: waitTill})
+		if releaseErr != nil {
+			sharedlog.GetLogger(ctx).Error("Failed to put the workflow execution back to wait",
+				"executionId", executionId,
+				"err", releaseErr)
+		}
+	}()
+
+	execution, err := GetWorkflowExecution(ctx, executionId)
+	if err != nil {
+		return nil, err
+	}
+	workflowEntity := execution.WorkflowData
+	runExecutionData := execution.Data
+	if workflowEntity == nil || runExecutionData == nil || runExecutionData.ExecutionData == nil ||
+		runExecutionData.ExecutionData.NodeExecutionStack == nil {
+		return nil, errors.New("the execution has no node to resume")
+	}
+	runExecutionData.WaitTill = nil
+	if runExecutionData.StartData == nil {
+		runExecutionData.StartData = &structs.WorkflowRunExecutionStartData{}
+	}
+	if runExecutionData.ResultData == nil {
+		runExecutionData.ResultData = &structs.WorkflowRunExecutionResultData{}
+	}
+	if runExecutionData.ResultData.RunData == nil {
+		runExecutionData.ResultData.RunData = make(map[string][]*structs.WorkflowExecutionTaskData)
+	}
+
+	executionData := structs.WorkflowExecutionDataProcess{
+		ExecutionMode: execution.Mode,
+		ExecutionData: runExecutionData,
+		RetryOf:       execution.RetryOf,
+		WorkflowData:  workflowEntity,
+	}
+	if _, err := GetActiveExecutions().AddExecution(ctx, &executionData, int(executionId)); err != nil {
+		return nil, err
+	}
+
+	additionalData := GetBaseAdditionalData()
+	additionalData.Hooks = GetWorkflowHooksMain(execution.Id)
+	additionalData.Hooks.Mode = execution.Mode
+	additionalData.Hooks.RetryOf = execution.RetryOf
+	additionalData.Hooks.WorkflowData = workflowEntity
+
+	executingWorkflowData := GetActiveExecutions().ExecuteAsync(
+		ctx,
+		execution.Id,
+		func(ctx context.Context) (*structs.WorkflowRunExecutionData, error) {
+			workflowExecute := NewWorkflowExecute(ctx, additionalData, execution.Mode)
+			workflowExecute.RunExecutionData = runExecutionData
+			err := workflowExecute.Run(ctx, workflowEntity)
+			if err != nil {
+				sharedlog.GetLogger(ctx).Error("Failed to resume the workflow execution",
+					"workflowId", workflowEntity.ID,
+					"executionId", executionId,
+					"err", err)
+				return nil, err
+			}
+			return workflowExecute.RunExecutionData, nil
+		},
+	)
+	return executingWorkflowData, nil
+}
@@ -1,15 +1,17 @@
 package core
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"time"
 
 	"github.com/dop251/goja"
-	"github.com/dop251/goja_nodejs/console"
-	"github.com/dop251/goja_nodejs/require"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
 
@@ -52,6 +54,213 @@ type SandboxContext struct {
 	Variables map[string]interface{}
 	Functions map[string]interface{}
 	RunData   map[string][]*structs.WorkflowExecutionTaskData
+	// Vars are the values of $vars, the variables of the execution or the org, they are never set as globals
+	Vars map[string]interface{}
+
+	// execution info for the built-in values like $workflow, $execution, $prevNode
+	Workflow    *structs.WorkflowEntity
+	Timezone    string // IANA zone of DateTime, $now and $today, the server zone if empty
+	ExecutionId string
+	Mode        structs.WorkflowExecutionMode
+	RunIndex    int
+	PrevNode    *SandboxPrevNode
+	Env         map[string]interface{}
+	// The result data of the run, $execution.customData is kept in its MetaData
+	ResultData *structs.WorkflowRunExecutionResultData
+}
+
+// SandboxPrevNode is the value of $prevNode
+type SandboxPrevNode struct {
+	Name        string `json:"name"`
+	OutputIndex int    `json:"outputIndex"`
+	RunIndex    int    `json:"runIndex"`
+}
+
+// SandboxEnvPrefix is the prefix of the environment variables exposed as $env.
+// The prefix is stripped, e.g. WORKFLOW_ENV_API_HOST can be read by $env.API_HOST
+// Other environment variables are never visible to the user code.
+const SandboxEnvPrefix = "WORKFLOW_ENV_"
+
+// SetupExecutionInfo fills the execution info of the context from the node input
+func (sc *SandboxContext) SetupExecutionInfo(input *structs.NodeExecuteInput) {
+	if input == nil {
+		return
+	}
+	sc.Workflow = input.Workflow
+	sc.Mode = input.Mode
+	sc.RunIndex = int(input.RunIndex)
+
+	if input.AdditionalData != nil {
+		sc.ExecutionId = input.AdditionalData.Hooks.ExecutionId
+		if sc.ExecutionId == "" {
+			sc.ExecutionId = input.AdditionalData.ExecutionId
+		}
+		if sc.Mode == "" {
+			sc.Mode = input.AdditionalData.Hooks.Mode
+		}
+		if sc.Workflow == nil {
+			sc.Workflow = input.AdditionalData.Hooks.WorkflowData
+		}
+		if variables, ok := input.AdditionalData.Variables.(map[string]interface{}); ok && sc.Vars == nil {
+			sc.Vars = variables
+		}
+	}
+
+	// the variables of the org are the $vars, unless the execution provides them
+	if sc.Vars == nil && sc.Workflow != nil && sc.Workflow.SugerOrgId != "" && rdsDbQueries != nil {
+		variables, err := GetVariableValues(context.Background(), sc.Workflow.SugerOrgId)
+		if err != nil {
+			Warnf("Failed to get the variables of org %s: %v", sc.Workflow.SugerOrgId, err)
+		} else {
+			sc.Vars = variables
+		}
+	}
+
+	if input.RunExecutionData != nil {
+		sc.ResultData = input.RunExecutionData.ResultData
+	}
+
+	if input.Params != nil && input.RunExecutionData != nil && input.RunExecutionData.ExecutionData != nil {
+		sources := input.RunExecutionData.ExecutionData.WaitingExecutionSource[input.Params.Name]
+		if len(sources) > 0 {
+			sc.PrevNode = &SandboxPrevNode{
+				Name:        sources[0].PreviousNode,
+				OutputIndex: sources[0].PreviousNodeOutput,
+				RunIndex:    max(len(sc.RunData[sources[0].PreviousNode])-1, 0),
+			}
+		}
+	}
+
+	if sc.Workflow != nil && sc.Workflow.Settings != nil {
+		sc.Timezone = sc.Workflow.Settings.Timezone
+	}
+
+	sc.Env = getSandboxEnv()
+}
+
+func (sc *SandboxContext) location() *time.Location {
+	if sc == nil {
+		return time.Local
+	}
+	loc, err := loadLocation(sc.Timezone, time.Local)
+	if err != nil {
+		Warnf("invalid workflow timezone %s: %v", sc.Timezone, err)
+		return time.Local
+	}
+	return loc
+}
+
+var (
+	sandboxEnv     map[string]interface{}
+	sandboxEnvOnce sync.Once
+)
+
+// getSandboxEnv returns a copy of the environment variables exposed as $env, which are read once.
+// It is a copy as the user code can change $env.
+func getSandboxEnv() map[string]interface{} {
+	sandboxEnvOnce.Do(func() {
+		sandboxEnv = make(map[string]interface{})
+		for _, kv := range os.Environ() {
+			key, value, ok := strings.Cut(kv, "=")
+			if ok && strings.HasPrefix(key, SandboxEnvPrefix) && len(key) > len(SandboxEnvPrefix) {
+				sandboxEnv[strings.TrimPrefix(key, SandboxEnvPrefix)] = value
+			}
+		}
+	})
+	env := make(map[string]interface{}, len(sandboxEnv))
+	for key, value := range sandboxEnv {
+		env[key] = value
+	}
+	return env
+}
+
+// setupBuiltInValues sets $now, $today, $workflow, $execution, $prevNode, $runIndex, $vars and $env
+func (sc *SandboxContext) setupBuiltInValues(s *Sandbox) {
+	if s.dateTime == nil {
+		s.dateTime = setupDateTimeLib(s.VM, sc.location())
+	}
+	now := s.dateTime.now()
+	s.VM.Set("$now", now)
+	s.VM.Set("$today", now.StartOf("day"))
+
+	workflowObj := map[string]interface{}{
+		"id":     "",
+		"name":   "",
+		"active": false,
+	}
+	if sc.Workflow != nil {
+		workflowObj["id"] = sc.Workflow.ID
+		workflowObj["name"] = sc.Workflow.Name
+		workflowObj["active"] = sc.Workflow.Active
+	}
+	s.VM.Set("$workflow", workflowObj)
+
+	s.VM.Set("$execution", map[string]interface{}{
+		"id":         sc.ExecutionId,
+		"mode":       string(sc.Mode),
+		"customData": sc.executionCustomData(),
+	})
+
+	if sc.PrevNode != nil {
+		s.VM.Set("$prevNode", sc.PrevNode)
+	} else {
+		s.VM.Set("$prevNode", &SandboxPrevNode{})
+	}
+
+	s.VM.Set("$runIndex", sc.RunIndex)
+
+	vars := sc.Vars
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+	s.VM.Set("$vars", vars)
+
+	env := sc.Env
+	if env == nil {
+		env = map[string]interface{}{}
+	}
+	s.VM.Set("$env", env)
+}
+
+// executionCustomData is the value of $execution.customData
+func (sc *SandboxContext) executionCustomData() map[string]interface{} {
+	resultData := sc.ResultData
+	if resultData == nil {
+		// not in a run, the custom data is dropped
+		resultData = &structs.WorkflowRunExecutionResultData{}
+	}
+	return map[string]interface{}{
+		"set": func(key string, value interface{}) error {
+			return SetExecutionCustomData(resultData, key, ExecutionCustomDataValue(value))
+		},
+		"setAll": func(data map[string]interface{}) error {
+			keys := make([]string, 0, len(data))
+			for key := range data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if err := SetExecutionCustomData(resultData, key, ExecutionCustomDataValue(data[key])); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		"get": func(key string) interface{} {
+			value, ok := resultData.MetaData[key]
+			if !ok {
+				return nil
+			}
+			return value
+		},
+		"getAll": func() map[string]string {
+			data := make(map[string]string, len(resultData.MetaData))
+			for key, value := range resultData.MetaData {
+				data[key] = value
+			}
+			return data
+		},
+	}
 }
 
 func (sc *SandboxContext) SetupCtxForRunCode(s *Sandbox) {
@@ -86,26 +295,33 @@ func (sc *SandboxContext) SetupCtxForRunCode(s *Sandbox) {
 	} else {
 		s.VM.Set("$json", nil)
 	}
+	s.VM.Set("$itemIndex", sc.ItemIndex)
+	s.VM.Set("$position", sc.ItemIndex)
+	sc.setupBuiltInValues(s)
 
 	// setup Functions
 	for k, v := range sc.Functions {
 		s.VM.Set(k, v)
 	}
 
-	// setup Variables
+	// setup Variables, they never shadow a global like JSON or $json
 	for k, v := range sc.Variables {
-		s.VM.Set(k, v)
+		if s.VM.Get(k) == nil {
+			s.VM.Set(k, v)
+		}
 	}
 
-	nodesResults := make(map[string]interface{})
-	nodeOutputItems := sc.getNodeOutputItems()
-	for nodeName := range nodeOutputItems {
-		items := nodeOutputItems[nodeName]
+	// the node results are looked up only when $() is called
+	s.VM.Set("$", func(name string) interface{} {
+		items, ok := sc.getNodeOutputItems(name)
+		if !ok {
+			return nil
+		}
 		var itemData structs.NodeSingleData
 		if len(items) > sc.ItemIndex {
 			itemData = items[sc.ItemIndex]
 		}
-		nodesResults[nodeName] = map[string]interface{}{
+		return map[string]interface{}{
 			"item": itemData,
 			"all": func() structs.NodeData {
 				return items
@@ -123,9 +339,6 @@ func (sc *SandboxContext) SetupCtxForRunCode(s *Sandbox) {
 				return nil
 			},
 		}
-	}
-	s.VM.Set("$", func(name string) interface{} {
-		return nodesResults[name]
 	})
 }
 
@@ -152,21 +365,25 @@ func (sc *SandboxContext) SetupCtxForRunCodeAllItems(s *Sandbox) {
 	}
 	s.VM.Set("$input", inputObj)
 	s.VM.Set("$items", sc.Items)
+	sc.setupBuiltInValues(s)
 
 	// setup Functions
 	for k, v := range sc.Functions {
 		s.VM.Set(k, v)
 	}
-	// setup Variables
+	// setup Variables, they never shadow a global like JSON or $json
 	for k, v := range sc.Variables {
-		s.VM.Set(k, v)
+		if s.VM.Get(k) == nil {
+			s.VM.Set(k, v)
+		}
 	}
 
-	nodeOutputItems := sc.getNodeOutputItems()
-	nodesResults := make(map[string]interface{})
-	for nodeName := range sc.getNodeOutputItems() {
-		items := nodeOutputItems[nodeName]
-		nodesResults[nodeName] = map[string]interface{}{
+	s.VM.Set("$", func(name string) interface{} {
+		items, ok := sc.getNodeOutputItems(name)
+		if !ok {
+			return nil
+		}
+		return map[string]interface{}{
 			"all": func() structs.NodeData {
 				return items
 			},
@@ -184,28 +401,24 @@ func (sc *SandboxContext) SetupCtxForRunCodeAllItems(s *Sandbox) {
 				return nil
 			},
 		}
-	}
-	s.VM.Set("$", func(name string) interface{} {
-		return nodesResults[name]
 	})
 }
 
-func (sc *SandboxContext) getNodeOutputItems() map[string]structs.NodeData {
-	nodesResults := make(map[string]structs.NodeData)
-	for nodeName := range sc.RunData {
-		taskDataList := sc.RunData[nodeName]
-		if len(taskDataList) > 0 {
-			taskData := taskDataList[len(taskDataList)-1]
-			if taskData != nil && taskData.Data != nil {
-				outputItems := taskData.Data["main"]
-				if len(outputItems) > 0 && outputItems[0] != nil {
-					items := outputItems[0]
-					nodesResults[nodeName] = items
-				}
-			}
-		}
+// getNodeOutputItems returns the first main output of the last run of the node
+func (sc *SandboxContext) getNodeOutputItems(nodeName string) (structs.NodeData, bool) {
+	taskDataList := sc.RunData[nodeName]
+	if len(taskDataList) == 0 {
+		return nil, false
+	}
+	taskData := taskDataList[len(taskDataList)-1]
+	if taskData == nil || taskData.Data == nil {
+		return nil, false
 	}
-	return nodesResults
+	outputItems := taskData.Data["main"]
+	if len(outputItems) == 0 || outputItems[0] == nil {
+		return nil, false
+	}
+	return outputItems[0], true
 }
 
 // The default timeout for code execution in sandbox is 180 seconds (3 minutes).
@@ -217,27 +430,54 @@ type Sandbox struct {
 	Context *SandboxContext
 	VM      *goja.Runtime
 	Timeout time.Duration
+	// Limits are the limits of the organization if nil, see GetSandboxLimits
+	Limits *structs.WorkflowSandboxLimits
+
+	dateTime *dateTimeLib
+	modules  *sandboxModules
+	// runtime is the pooled runtime of VM, nil if the VM is not from the pool
+	runtime *pooledRuntime
+	// reusable is false once the VM can not be reset, e.g. a top level `let` of an unwrapped script
+	reusable bool
 }
 
-func newGoja() (*goja.Runtime, *require.RequireModule) {
-	registry := new(require.Registry)
+func newGoja() (*goja.Runtime, *sandboxModules) {
 	vm := goja.New()
-	req := registry.Enable(vm)
-	console.Enable(vm)
+	modules := enableSandboxModules(vm)
 
 	vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
 	// https://github.com/dop251/goja#mapping-struct-field-and-method-names
 	// use this if we need optionally uncapitalises
 	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
-	return vm, req
+	return vm, modules
 
 }
 
 func (s *Sandbox) Initialize() {
-	s.VM, _ = newGoja()
-	if s.Timeout <= 0 {
-		s.Timeout = TimeoutDefault * time.Millisecond
+	s.VM, s.modules = newGoja()
+	s.dateTime = setupDateTimeLib(s.VM, s.Context.location())
+	if err := setupExpressionExtensions(s.VM); err != nil {
+		Errorf("Failed to setup the expression extensions: %v", err)
+	}
+	s.applyLimits()
+	if s.Name == "" {
+		s.Name = "Main"
+	}
+
+	if len(s.Lang) > 0 && s.Lang != CodeLanguage {
+		panic("Only support js language")
 	}
+}
+
+// InitializeFromPool is Initialize with a pooled VM, call Release when the Sandbox is no longer used
+func (s *Sandbox) InitializeFromPool() {
+	s.runtime = runtimePool.Get().(*pooledRuntime)
+	s.reusable = true
+	s.VM = s.runtime.vm
+	s.modules = s.runtime.modules
+	s.dateTime = s.runtime.dateTime
+	s.dateTime.loc = s.Context.location()
+	s.applyLimits()
 	if s.Name == "" {
 		s.Name = "Main"
 	}
@@ -247,6 +487,20 @@ func (s *Sandbox) Initialize() {
 	}
 }
 
+// Release puts the pooled VM back to the pool, the Sandbox can not run code after that
+func (s *Sandbox) Release() {
+	if s.runtime == nil {
+		return
+	}
+	if s.reusable && !s.modules.loaded {
+		s.runtime.release()
+	}
+	s.runtime = nil
+	s.VM = nil
+	s.modules = nil
+	s.dateTime = nil
+}
+
 const RunCodeWrapperFmt = `(()=>{return %s
 	})()`
 
@@ -258,6 +512,9 @@ func (s *Sandbox) RunCode(code string, itemIndex int) (interface{}, error) {
 	// see test `Evaluate expression first line //` in expression_test.go
 	if !(strings.HasPrefix(code, "//") || strings.HasPrefix(code, "/*")) {
 		code = fmt.Sprintf(RunCodeWrapperFmt, code)
+	} else {
+		// the declarations of an unwrapped script stay in the global scope
+		s.reusable = false
 	}
 
 	if s.Context != nil {
@@ -265,11 +522,8 @@ func (s *Sandbox) RunCode(code string, itemIndex int) (interface{}, error) {
 		s.Context.SetupCtxForRunCode(s)
 	}
 
-	// -------- timeout ---------
-	s.SetupTimeout()
-
 	// --------- run script -------
-	v, err := s.VM.RunScript(s.Name, code)
+	v, err := s.runWithLimits(code)
 	if err != nil {
 		err = HandleJavaScriptError(err)
 		return nil, err
@@ -280,6 +534,14 @@ func (s *Sandbox) RunCode(code string, itemIndex int) (interface{}, error) {
 	}
 	returnData := v.Export()
 
+	if returnData, ok := standardizeDateTimeValue(returnData); ok {
+		return returnData, nil
+	}
+
+	if returnData, ok := returnData.([]interface{}); ok {
+		return standardizeJavaScriptArray(returnData), nil
+	}
+
 	if returnData, ok := returnData.(map[string]interface{}); ok {
 		return StandardizeJavaScriptObject(returnData), nil
 
@@ -293,18 +555,6 @@ func (s *Sandbox) RunCode(code string, itemIndex int) (interface{}, error) {
 	return returnData, nil
 }
 
-func (s *Sandbox) SetupTimeout() *time.Timer {
-	// -------- timeout ---------
-	if s.Timeout > 0 {
-		s.VM.ClearInterrupt()
-		// set timeout
-		return time.AfterFunc(s.Timeout, func() {
-			s.VM.Interrupt("Code run timeout")
-		})
-	}
-	return nil
-}
-
 const ScriptWrapperFmt = `(()=>{%s
 	})()`
 
@@ -316,13 +566,8 @@ func (s *Sandbox) RunCodeAllItems() (structs.NodeData, error) {
 
 	script := fmt.Sprintf(ScriptWrapperFmt, s.JsCode)
 
-	// -------- timeout ---------
-	timer := s.SetupTimeout()
-
 	// --------- run script -------
-	v, err := s.VM.RunScript(s.Name, script)
-	// Cancel the timer if the script runs successfully
-	timer.Stop()
+	v, err := s.runWithLimits(script)
 
 	if err != nil {
 		err = HandleJavaScriptError(err)
@@ -339,6 +584,9 @@ func (s *Sandbox) RunCodeAllItems() (structs.NodeData, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkOutputSize(returnData); err != nil {
+		return nil, err
+	}
 	return StandardizeOutput(returnData), nil
 
 }
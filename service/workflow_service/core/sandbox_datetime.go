@@ -0,0 +1,1368 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// the sandbox must resolve IANA zones even if the host has no zoneinfo
+	_ "time/tzdata"
+
+	"github.com/dop251/goja"
+)
+
+// A Luxon (https://moment.github.io/luxon) compatible DateTime, Duration and Interval
+// implementation bound into the sandbox, so the n8n style expressions like
+// `$now.plus({days: 1}).toISO()` or `DateTime.fromISO('2024-01-01').startOf('month')` work.
+//
+// All the objects are immutable, every method returns a new object.
+// The exported fields are read-only getters in the VM, the exported methods are uncapitalised,
+// e.g. DateTime.ToISO is `toISO()` in javascript.
+
+// DateTime is the Luxon DateTime
+type DateTime struct {
+	lib *dateTimeLib
+	t   time.Time
+
+	Year            int    `json:"year"`
+	Quarter         int    `json:"quarter"`
+	Month           int    `json:"month"`
+	Day             int    `json:"day"`
+	Hour            int    `json:"hour"`
+	Minute          int    `json:"minute"`
+	Second          int    `json:"second"`
+	Millisecond     int    `json:"millisecond"`
+	Weekday         int    `json:"weekday"` // 1 is Monday and 7 is Sunday
+	Ordinal         int    `json:"ordinal"`
+	WeekNumber      int    `json:"weekNumber"`
+	WeekYear        int    `json:"weekYear"`
+	DaysInMonth     int    `json:"daysInMonth"`
+	DaysInYear      int    `json:"daysInYear"`
+	MonthLong       string `json:"monthLong"`
+	MonthShort      string `json:"monthShort"`
+	WeekdayLong     string `json:"weekdayLong"`
+	WeekdayShort    string `json:"weekdayShort"`
+	Offset          int    `json:"offset"` // minutes
+	OffsetNameShort string `json:"offsetNameShort"`
+	ZoneName        string `json:"zoneName"`
+	IsInLeapYear    bool   `json:"isInLeapYear"`
+	IsWeekend       bool   `json:"isWeekend"`
+	IsValid         bool   `json:"isValid"`
+	InvalidReason   string `json:"invalidReason"`
+}
+
+// Duration is the Luxon Duration
+type Duration struct {
+	lib    *dateTimeLib
+	values map[string]float64
+
+	Years         float64 `json:"years"`
+	Quarters      float64 `json:"quarters"`
+	Months        float64 `json:"months"`
+	Weeks         float64 `json:"weeks"`
+	Days          float64 `json:"days"`
+	Hours         float64 `json:"hours"`
+	Minutes       float64 `json:"minutes"`
+	Seconds       float64 `json:"seconds"`
+	Milliseconds  float64 `json:"milliseconds"`
+	IsValid       bool    `json:"isValid"`
+	InvalidReason string  `json:"invalidReason"`
+}
+
+// Interval is the Luxon Interval, a half-open interval [start, end)
+type Interval struct {
+	lib *dateTimeLib
+
+	Start         *DateTime `json:"start"`
+	End           *DateTime `json:"end"`
+	IsValid       bool      `json:"isValid"`
+	InvalidReason string    `json:"invalidReason"`
+}
+
+// durationUnits are ordered from the largest to the smallest
+var durationUnits = []string{
+	"years", "quarters", "months", "weeks", "days", "hours", "minutes", "seconds", "milliseconds",
+}
+
+// casual length of the units in milliseconds, the same as the Luxon casual conversion matrix
+var durationUnitMillis = map[string]float64{
+	"years":        365 * 24 * 3600 * 1000,
+	"quarters":     91 * 24 * 3600 * 1000,
+	"months":       30 * 24 * 3600 * 1000,
+	"weeks":        7 * 24 * 3600 * 1000,
+	"days":         24 * 3600 * 1000,
+	"hours":        3600 * 1000,
+	"minutes":      60 * 1000,
+	"seconds":      1000,
+	"milliseconds": 1,
+}
+
+var durationISORegex = regexp.MustCompile(
+	`^(-)?P(?:(\d+(?:[.,]\d+)?)Y)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?` +
+		`(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+var fixedZoneRegex = regexp.MustCompile(`^(?i:utc|gmt)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// normalizeDurationUnit converts "day", "Days" and "days" to "days"
+func normalizeDurationUnit(unit string) (string, bool) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if !strings.HasSuffix(unit, "s") {
+		unit += "s"
+	}
+	_, ok := durationUnitMillis[unit]
+	return unit, ok
+}
+
+func isCalendarUnit(unit string) bool {
+	switch unit {
+	case "years", "quarters", "months", "weeks", "days":
+		return true
+	}
+	return false
+}
+
+// loadLocation resolves the Luxon zone names, IANA names and fixed offsets like UTC+8
+func loadLocation(zone string, fallback *time.Location) (*time.Location, error) {
+	zone = strings.TrimSpace(zone)
+	switch strings.ToLower(zone) {
+	case "", "default":
+		return fallback, nil
+	case "local", "system":
+		return time.Local, nil
+	case "utc", "gmt", "z":
+		return time.UTC, nil
+	}
+	if match := fixedZoneRegex.FindStringSubmatch(zone); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(zone, offset), nil
+	}
+	return time.LoadLocation(zone)
+}
+
+// ---------------------------------- lib ----------------------------------
+
+type dateTimeLib struct {
+	vm  *goja.Runtime
+	loc *time.Location
+}
+
+// setupDateTimeLib binds DateTime, Duration and Interval into the VM, loc is the default zone
+func setupDateTimeLib(vm *goja.Runtime, loc *time.Location) *dateTimeLib {
+	if loc == nil {
+		loc = time.Local
+	}
+	lib := &dateTimeLib{vm: vm, loc: loc}
+
+	vm.Set("DateTime", lib.newStaticObject(map[string]interface{}{
+		"now": func() *DateTime {
+			return lib.now()
+		},
+		"local": func(args ...int) *DateTime {
+			return lib.fromUnits(lib.loc, args)
+		},
+		"utc": func(args ...int) *DateTime {
+			return lib.fromUnits(time.UTC, args)
+		},
+		"fromISO":     lib.fromISO,
+		"fromSQL":     lib.fromSQL,
+		"fromFormat":  lib.fromFormat,
+		"fromObject":  lib.fromObject,
+		"fromMillis":  lib.fromMillis,
+		"fromSeconds": lib.fromSeconds,
+		"fromJSDate": func(value interface{}, opts map[string]interface{}) *DateTime {
+			date, ok := value.(time.Time)
+			if !ok {
+				return lib.invalidDateTime("invalid input")
+			}
+			return lib.withZoneOption(date, opts)
+		},
+		"invalid": lib.invalidDateTime,
+		"isDateTime": func(value interface{}) bool {
+			_, ok := value.(*DateTime)
+			return ok
+		},
+		"max": func(values ...interface{}) *DateTime {
+			return lib.pick(values, func(a, b *DateTime) bool { return a.t.After(b.t) })
+		},
+		"min": func(values ...interface{}) *DateTime {
+			return lib.pick(values, func(a, b *DateTime) bool { return a.t.Before(b.t) })
+		},
+	}))
+
+	vm.Set("Duration", lib.newStaticObject(map[string]interface{}{
+		"fromObject": func(values map[string]interface{}) *Duration {
+			return lib.toDuration(values)
+		},
+		"fromMillis": func(millis float64) *Duration {
+			return lib.newDuration(map[string]float64{"milliseconds": millis})
+		},
+		"fromISO": func(text string) *Duration {
+			return lib.toDuration(text)
+		},
+		"isDuration": func(value interface{}) bool {
+			_, ok := value.(*Duration)
+			return ok
+		},
+	}))
+
+	vm.Set("Interval", lib.newStaticObject(map[string]interface{}{
+		"fromDateTimes": func(start, end interface{}) *Interval {
+			return lib.newInterval(start, end)
+		},
+		"after": func(start, duration interface{}) *Interval {
+			startDt, ok := lib.toDateTime(start)
+			if !ok {
+				return lib.invalidInterval("invalid start")
+			}
+			return lib.newInterval(startDt, startDt.Plus(duration))
+		},
+		"before": func(end, duration interface{}) *Interval {
+			endDt, ok := lib.toDateTime(end)
+			if !ok {
+				return lib.invalidInterval("invalid end")
+			}
+			return lib.newInterval(endDt.Minus(duration), endDt)
+		},
+		"fromISO": func(text string) *Interval {
+			start, end, found := strings.Cut(text, "/")
+			if !found {
+				return lib.invalidInterval("unparsable")
+			}
+			startDt := lib.fromISO(start, nil)
+			endDt := lib.fromISO(end, nil)
+			// either side can be a duration, e.g. 2024-01-01/P1D
+			if !startDt.IsValid && endDt.IsValid {
+				if duration := lib.toDuration(start); duration.IsValid {
+					startDt = endDt.Minus(duration)
+				}
+			}
+			if !endDt.IsValid && startDt.IsValid {
+				if duration := lib.toDuration(end); duration.IsValid {
+					endDt = startDt.Plus(duration)
+				}
+			}
+			return lib.newInterval(startDt, endDt)
+		},
+		"isInterval": func(value interface{}) bool {
+			_, ok := value.(*Interval)
+			return ok
+		},
+	}))
+
+	return lib
+}
+
+// newStaticObject creates a plain javascript object of the static methods like DateTime.fromISO,
+// unlike a wrapped Go map the methods are stable values the sandbox pool can check for changes
+func (lib *dateTimeLib) newStaticObject(methods map[string]interface{}) *goja.Object {
+	obj := lib.vm.NewObject()
+	for name, method := range methods {
+		obj.Set(name, method)
+	}
+	return obj
+}
+
+func (lib *dateTimeLib) now() *DateTime {
+	return lib.newDateTime(time.Now().In(lib.loc))
+}
+
+func (lib *dateTimeLib) newDateTime(t time.Time) *DateTime {
+	year, week := t.ISOWeek()
+	zoneAbbr, offset := t.Zone()
+	return &DateTime{
+		lib:             lib,
+		t:               t,
+		Year:            t.Year(),
+		Quarter:         (int(t.Month())-1)/3 + 1,
+		Month:           int(t.Month()),
+		Day:             t.Day(),
+		Hour:            t.Hour(),
+		Minute:          t.Minute(),
+		Second:          t.Second(),
+		Millisecond:     t.Nanosecond() / int(time.Millisecond),
+		Weekday:         isoWeekday(t),
+		Ordinal:         t.YearDay(),
+		WeekNumber:      week,
+		WeekYear:        year,
+		DaysInMonth:     daysIn(t.Month(), t.Year()),
+		DaysInYear:      time.Date(t.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay(),
+		MonthLong:       t.Month().String(),
+		MonthShort:      t.Month().String()[:3],
+		WeekdayLong:     t.Weekday().String(),
+		WeekdayShort:    t.Weekday().String()[:3],
+		Offset:          offset / 60,
+		OffsetNameShort: zoneAbbr,
+		ZoneName:        zoneName(t.Location()),
+		IsInLeapYear:    daysIn(time.February, t.Year()) == 29,
+		IsWeekend:       t.Weekday() == time.Saturday || t.Weekday() == time.Sunday,
+		IsValid:         true,
+	}
+}
+
+func (lib *dateTimeLib) invalidDateTime(reason string) *DateTime {
+	return &DateTime{lib: lib, InvalidReason: reason}
+}
+
+// fromUnits is DateTime.local(year, month, day, hour, minute, second, millisecond)
+func (lib *dateTimeLib) fromUnits(loc *time.Location, args []int) *DateTime {
+	if len(args) == 0 {
+		return lib.newDateTime(time.Now().In(loc))
+	}
+	units := []int{0, 1, 1, 0, 0, 0, 0}
+	copy(units, args)
+	return lib.newDateTime(time.Date(units[0], time.Month(units[1]), units[2],
+		units[3], units[4], units[5], units[6]*int(time.Millisecond), loc))
+}
+
+// zoneOption returns the location of the `zone` option, or the default zone
+func (lib *dateTimeLib) zoneOption(opts map[string]interface{}) (*time.Location, error) {
+	zone, _ := GetValueFromMap[string](opts, "zone")
+	return loadLocation(zone, lib.loc)
+}
+
+func (lib *dateTimeLib) withZoneOption(t time.Time, opts map[string]interface{}) *DateTime {
+	loc, err := lib.zoneOption(opts)
+	if err != nil {
+		return lib.invalidDateTime(fmt.Sprintf("unsupported zone: %v", err))
+	}
+	return lib.newDateTime(t.In(loc))
+}
+
+func (lib *dateTimeLib) parseWithLayouts(text string, layouts []string, opts map[string]interface{}) *DateTime {
+	loc, err := lib.zoneOption(opts)
+	if err != nil {
+		return lib.invalidDateTime(fmt.Sprintf("unsupported zone: %v", err))
+	}
+	setZone, _ := GetValueFromMap[bool](opts, "setZone")
+	text = strings.TrimSpace(text)
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, text, loc)
+		if err != nil {
+			continue
+		}
+		if setZone {
+			return lib.newDateTime(t)
+		}
+		return lib.newDateTime(t.In(loc))
+	}
+	return lib.invalidDateTime(fmt.Sprintf("unparsable: the input %q can't be parsed", text))
+}
+
+var isoLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02T15",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"20060102",
+}
+
+var sqlLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999 Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+func (lib *dateTimeLib) fromISO(text string, opts map[string]interface{}) *DateTime {
+	return lib.parseWithLayouts(text, isoLayouts, opts)
+}
+
+func (lib *dateTimeLib) fromSQL(text string, opts map[string]interface{}) *DateTime {
+	return lib.parseWithLayouts(text, sqlLayouts, opts)
+}
+
+func (lib *dateTimeLib) fromFormat(text string, format string, opts map[string]interface{}) *DateTime {
+	switch format {
+	case "X":
+		seconds, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return lib.invalidDateTime(fmt.Sprintf("unparsable: %v", err))
+		}
+		return lib.fromSeconds(seconds, opts)
+	case "x":
+		millis, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return lib.invalidDateTime(fmt.Sprintf("unparsable: %v", err))
+		}
+		return lib.fromMillis(millis, opts)
+	}
+	layout, err := luxonFormatToLayout(format)
+	if err != nil {
+		return lib.invalidDateTime(err.Error())
+	}
+	return lib.parseWithLayouts(text, []string{layout}, opts)
+}
+
+func (lib *dateTimeLib) fromMillis(millis float64, opts map[string]interface{}) *DateTime {
+	return lib.withZoneOption(time.UnixMilli(int64(millis)), opts)
+}
+
+func (lib *dateTimeLib) fromSeconds(seconds float64, opts map[string]interface{}) *DateTime {
+	return lib.withZoneOption(time.UnixMilli(int64(math.Round(seconds*1000))), opts)
+}
+
+// fromObject is DateTime.fromObject({year, month, day, hour, minute, second, millisecond}).
+// The units larger than the largest given unit default to now, the smaller ones to their minimum.
+func (lib *dateTimeLib) fromObject(values map[string]interface{}, opts map[string]interface{}) *DateTime {
+	loc, err := lib.zoneOption(opts)
+	if err != nil {
+		return lib.invalidDateTime(fmt.Sprintf("unsupported zone: %v", err))
+	}
+	now := time.Now().In(loc)
+	keys := []string{"year", "month", "day", "hour", "minute", "second", "millisecond"}
+	units := []int{now.Year(), int(now.Month()), now.Day(), now.Hour(), now.Minute(), now.Second(), 0}
+	minimums := []int{0, 1, 1, 0, 0, 0, 0}
+
+	largest := len(keys)
+	for i, key := range keys {
+		if _, ok := values[key]; ok {
+			largest = i
+			break
+		}
+	}
+	for i, key := range keys {
+		if i > largest {
+			units[i] = minimums[i]
+		}
+		if value, ok := values[key]; ok {
+			number, err := ConvertToFloat64(value)
+			if err != nil {
+				return lib.invalidDateTime(fmt.Sprintf("invalid unit %s: %v", key, err))
+			}
+			units[i] = int(number)
+		}
+	}
+	return lib.newDateTime(time.Date(units[0], time.Month(units[1]), units[2],
+		units[3], units[4], units[5], units[6]*int(time.Millisecond), loc))
+}
+
+func (lib *dateTimeLib) pick(values []interface{}, better func(a, b *DateTime) bool) *DateTime {
+	var result *DateTime
+	for _, value := range values {
+		dt, ok := lib.toDateTime(value)
+		if !ok {
+			continue
+		}
+		if result == nil || better(dt, result) {
+			result = dt
+		}
+	}
+	return result
+}
+
+// toDateTime accepts DateTime, JS Date, ISO string and epoch milliseconds
+func (lib *dateTimeLib) toDateTime(value interface{}) (*DateTime, bool) {
+	switch v := value.(type) {
+	case *DateTime:
+		return v, v.IsValid
+	case time.Time:
+		return lib.newDateTime(v.In(lib.loc)), true
+	case string:
+		dt := lib.fromISO(v, nil)
+		if !dt.IsValid {
+			dt = lib.fromSQL(v, nil)
+		}
+		return dt, dt.IsValid
+	case nil:
+		return nil, false
+	}
+	millis, err := ConvertToFloat64(value)
+	if err != nil {
+		return nil, false
+	}
+	return lib.fromMillis(millis, nil), true
+}
+
+// ---------------------------------- DateTime ----------------------------------
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func zoneName(loc *time.Location) string {
+	if loc == time.UTC {
+		return "UTC"
+	}
+	return loc.String()
+}
+
+// addMonths adds months in the Luxon way, the day is clamped to the end of the target month,
+// e.g. Jan 31 + 1 month is Feb 28 (Go's AddDate returns Mar 3)
+func addMonths(t time.Time, months int) time.Time {
+	total := int(t.Month()) - 1 + months
+	year := t.Year() + total/12
+	month := total % 12
+	if month < 0 {
+		month += 12
+		year--
+	}
+	day := t.Day()
+	if dim := daysIn(time.Month(month+1), year); day > dim {
+		day = dim
+	}
+	return time.Date(year, time.Month(month+1), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func (dt *DateTime) shift(duration *Duration, sign float64) *DateTime {
+	if !dt.IsValid || !duration.IsValid {
+		return dt
+	}
+	values := duration.values
+	months := sign * (values["years"]*12 + values["quarters"]*3 + values["months"])
+	days := sign * (values["weeks"]*7 + values["days"])
+	wholeMonths, fracMonths := math.Modf(months)
+	wholeDays, fracDays := math.Modf(days)
+
+	t := addMonths(dt.t, int(wholeMonths))
+	t = t.AddDate(0, 0, int(wholeDays))
+
+	millis := fracMonths*durationUnitMillis["months"] + fracDays*durationUnitMillis["days"]
+	for _, unit := range []string{"hours", "minutes", "seconds", "milliseconds"} {
+		millis += sign * values[unit] * durationUnitMillis[unit]
+	}
+	t = t.Add(time.Duration(math.Round(millis * float64(time.Millisecond))))
+	return dt.lib.newDateTime(t)
+}
+
+// durationWithUnit supports the n8n style plus(5, 'days') besides the Luxon style plus({days: 5})
+func (lib *dateTimeLib) durationWithUnit(duration interface{}, unit []string) *Duration {
+	if len(unit) > 0 && unit[0] != "" {
+		return lib.toDuration(map[string]interface{}{unit[0]: duration})
+	}
+	return lib.toDuration(duration)
+}
+
+// Plus adds a duration, a duration like object {days: 1} or milliseconds, or a number of the unit like plus(1, 'days')
+func (dt *DateTime) Plus(duration interface{}, unit ...string) *DateTime {
+	return dt.shift(dt.lib.durationWithUnit(duration, unit), 1)
+}
+
+// Minus subtracts a duration, a duration like object {days: 1} or milliseconds, or a number of the unit like minus(1, 'days')
+func (dt *DateTime) Minus(duration interface{}, unit ...string) *DateTime {
+	return dt.shift(dt.lib.durationWithUnit(duration, unit), -1)
+}
+
+// Set sets the units, e.g. set({hour: 0, minute: 0})
+func (dt *DateTime) Set(values map[string]interface{}) *DateTime {
+	if !dt.IsValid {
+		return dt
+	}
+	units := map[string]int{
+		"year": dt.Year, "month": dt.Month, "day": dt.Day, "hour": dt.Hour,
+		"minute": dt.Minute, "second": dt.Second, "millisecond": dt.Millisecond,
+	}
+	weekday := 0
+	for key, value := range values {
+		number, err := ConvertToFloat64(value)
+		if err != nil {
+			return dt.lib.invalidDateTime(fmt.Sprintf("invalid unit %s: %v", key, err))
+		}
+		if key == "weekday" {
+			weekday = int(number)
+			continue
+		}
+		if _, ok := units[key]; ok {
+			units[key] = int(number)
+		}
+	}
+	if _, ok := values["day"]; !ok {
+		// keep the day in the target month like Luxon
+		if dim := daysIn(time.Month(units["month"]), units["year"]); units["day"] > dim {
+			units["day"] = dim
+		}
+	}
+	t := time.Date(units["year"], time.Month(units["month"]), units["day"], units["hour"],
+		units["minute"], units["second"], units["millisecond"]*int(time.Millisecond), dt.t.Location())
+	if weekday > 0 {
+		t = t.AddDate(0, 0, weekday-isoWeekday(t))
+	}
+	return dt.lib.newDateTime(t)
+}
+
+// StartOf returns the start of the unit, unit is one of year, quarter, month, week, day, hour, minute, second
+func (dt *DateTime) StartOf(unit string) *DateTime {
+	if !dt.IsValid {
+		return dt
+	}
+	t := dt.t
+	loc := t.Location()
+	unit, _ = normalizeDurationUnit(unit)
+	switch unit {
+	case "years":
+		t = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+	case "quarters":
+		t = time.Date(t.Year(), time.Month((int(t.Month())-1)/3*3+1), 1, 0, 0, 0, 0, loc)
+	case "months":
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case "weeks":
+		t = time.Date(t.Year(), t.Month(), t.Day()-isoWeekday(t)+1, 0, 0, 0, 0, loc)
+	case "days":
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	case "hours":
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case "minutes":
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	case "seconds":
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+	case "milliseconds":
+	default:
+		return dt.lib.invalidDateTime(fmt.Sprintf("invalid unit: %s", unit))
+	}
+	return dt.lib.newDateTime(t)
+}
+
+// EndOf returns the last millisecond of the unit
+func (dt *DateTime) EndOf(unit string) *DateTime {
+	start := dt.StartOf(unit)
+	if !start.IsValid {
+		return start
+	}
+	unit, _ = normalizeDurationUnit(unit)
+	return start.Plus(map[string]interface{}{unit: 1}).Minus(map[string]interface{}{"milliseconds": 1})
+}
+
+// SetZone converts the DateTime to the zone, keeping the instant
+func (dt *DateTime) SetZone(zone string) *DateTime {
+	if !dt.IsValid {
+		return dt
+	}
+	loc, err := loadLocation(zone, dt.lib.loc)
+	if err != nil {
+		return dt.lib.invalidDateTime(fmt.Sprintf("unsupported zone: %v", err))
+	}
+	return dt.lib.newDateTime(dt.t.In(loc))
+}
+
+func (dt *DateTime) ToUTC() *DateTime {
+	return dt.SetZone("utc")
+}
+
+func (dt *DateTime) ToLocal() *DateTime {
+	return dt.SetZone("default")
+}
+
+func (dt *DateTime) isoString() string {
+	return dt.t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// ToISO returns null if the DateTime is invalid, like Luxon
+func (dt *DateTime) ToISO() interface{} {
+	if !dt.IsValid {
+		return nil
+	}
+	return dt.isoString()
+}
+
+func (dt *DateTime) ToISODate() interface{} {
+	if !dt.IsValid {
+		return nil
+	}
+	return dt.t.Format(time.DateOnly)
+}
+
+func (dt *DateTime) ToISOTime() interface{} {
+	if !dt.IsValid {
+		return nil
+	}
+	return dt.t.Format("15:04:05.000Z07:00")
+}
+
+func (dt *DateTime) ToSQL() interface{} {
+	if !dt.IsValid {
+		return nil
+	}
+	return dt.t.Format("2006-01-02 15:04:05.000 -07:00")
+}
+
+func (dt *DateTime) ToSQLDate() interface{} {
+	return dt.ToISODate()
+}
+
+func (dt *DateTime) ToJSON() interface{} {
+	return dt.ToISO()
+}
+
+func (dt *DateTime) ToString() string {
+	if !dt.IsValid {
+		return "Invalid DateTime"
+	}
+	return dt.isoString()
+}
+
+// ToFormat formats with the Luxon tokens, e.g. toFormat("yyyy-MM-dd HH:mm")
+func (dt *DateTime) ToFormat(format string) string {
+	if !dt.IsValid {
+		return "Invalid DateTime"
+	}
+	return formatDateTime(dt, format)
+}
+
+// ToLocaleString returns the en-US representation, the date only if no time option is given
+func (dt *DateTime) ToLocaleString(opts map[string]interface{}) string {
+	if !dt.IsValid {
+		return "Invalid DateTime"
+	}
+	if _, ok := opts["hour"]; ok {
+		return formatDateTime(dt, "D, t")
+	}
+	return formatDateTime(dt, "D")
+}
+
+func (dt *DateTime) ToMillis() int64 {
+	return dt.t.UnixMilli()
+}
+
+func (dt *DateTime) ToSeconds() float64 {
+	return float64(dt.t.UnixMilli()) / 1000
+}
+
+func (dt *DateTime) ToUnixInteger() int64 {
+	return dt.t.Unix()
+}
+
+// ValueOf makes comparisons like `a < b` work
+func (dt *DateTime) ValueOf() interface{} {
+	if !dt.IsValid {
+		return math.NaN()
+	}
+	return dt.t.UnixMilli()
+}
+
+func (dt *DateTime) ToJSDate() goja.Value {
+	date, err := dt.lib.vm.New(dt.lib.vm.Get("Date"), dt.lib.vm.ToValue(dt.ValueOf()))
+	if err != nil {
+		return goja.Undefined()
+	}
+	return date
+}
+
+func (dt *DateTime) ToObject() map[string]interface{} {
+	if !dt.IsValid {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"year":        dt.Year,
+		"month":       dt.Month,
+		"day":         dt.Day,
+		"hour":        dt.Hour,
+		"minute":      dt.Minute,
+		"second":      dt.Second,
+		"millisecond": dt.Millisecond,
+	}
+}
+
+// Get returns the unit value, e.g. get('hour')
+func (dt *DateTime) Get(unit string) interface{} {
+	object := dt.ToObject()
+	object["weekday"] = dt.Weekday
+	object["ordinal"] = dt.Ordinal
+	object["quarter"] = dt.Quarter
+	object["weekNumber"] = dt.WeekNumber
+	return object[unit]
+}
+
+// Diff returns the duration from other to this DateTime in the units, unit is a string or a list of strings
+func (dt *DateTime) Diff(other interface{}, unit interface{}) *Duration {
+	otherDt, ok := dt.lib.toDateTime(other)
+	if !ok || !dt.IsValid {
+		return dt.lib.invalidDuration("invalid DateTime")
+	}
+	units, err := parseDurationUnits(unit)
+	if err != nil {
+		return dt.lib.invalidDuration(err.Error())
+	}
+	return dt.lib.newDuration(diffTimes(otherDt.t.In(dt.t.Location()), dt.t, units))
+}
+
+// DiffNow returns the duration from now to this DateTime
+func (dt *DateTime) DiffNow(unit interface{}) *Duration {
+	return dt.Diff(dt.lib.now(), unit)
+}
+
+// Until returns the interval from this DateTime to other
+func (dt *DateTime) Until(other interface{}) *Interval {
+	return dt.lib.newInterval(dt, other)
+}
+
+// Equals checks the instant and the zone
+func (dt *DateTime) Equals(other interface{}) bool {
+	otherDt, ok := other.(*DateTime)
+	if !ok || !dt.IsValid || !otherDt.IsValid {
+		return false
+	}
+	return dt.t.Equal(otherDt.t) && dt.ZoneName == otherDt.ZoneName
+}
+
+// HasSame checks both are in the same unit, e.g. hasSame(other, 'day')
+func (dt *DateTime) HasSame(other interface{}, unit string) bool {
+	otherDt, ok := dt.lib.toDateTime(other)
+	if !ok || !dt.IsValid {
+		return false
+	}
+	otherDt = dt.lib.newDateTime(otherDt.t.In(dt.t.Location()))
+	return dt.StartOf(unit).t.Equal(otherDt.StartOf(unit).t)
+}
+
+// ToRelative returns the en-US relative time to now, e.g. "in 2 days", "3 hours ago"
+func (dt *DateTime) ToRelative() string {
+	if !dt.IsValid {
+		return "Invalid DateTime"
+	}
+	units := []string{"years", "months", "days", "hours", "minutes", "seconds"}
+	values := diffTimes(time.Now().In(dt.t.Location()), dt.t, units)
+	for _, unit := range units {
+		value := math.Trunc(values[unit])
+		if value == 0 && unit != "seconds" {
+			continue
+		}
+		text := formatUnitCount(math.Abs(value), unit)
+		if value < 0 || (value == 0 && dt.t.Before(time.Now())) {
+			return text + " ago"
+		}
+		return "in " + text
+	}
+	return ""
+}
+
+// The n8n extension methods of DateTime,
+// see https://docs.n8n.io/code/builtin/data-transformation-functions/dates/
+
+// Format is toFormat of n8n
+func (dt *DateTime) Format(format string) string {
+	return dt.ToFormat(format)
+}
+
+// ToDateTime returns itself, so toDateTime() works on any date like value
+func (dt *DateTime) ToDateTime() *DateTime {
+	return dt
+}
+
+// BeginningOf is startOf of n8n, the unit defaults to week
+func (dt *DateTime) BeginningOf(unit string) *DateTime {
+	if unit == "" {
+		unit = "week"
+	}
+	return dt.StartOf(unit)
+}
+
+// EndOfMonth returns the last millisecond of the month
+func (dt *DateTime) EndOfMonth() *DateTime {
+	return dt.EndOf("month")
+}
+
+// IsBetween checks the DateTime is after start and before end, in either order
+func (dt *DateTime) IsBetween(start, end interface{}) bool {
+	startDt, ok := dt.lib.toDateTime(start)
+	if !ok || !dt.IsValid {
+		return false
+	}
+	endDt, ok := dt.lib.toDateTime(end)
+	if !ok {
+		return false
+	}
+	if startDt.t.After(endDt.t) {
+		startDt, endDt = endDt, startDt
+	}
+	return dt.t.After(startDt.t) && dt.t.Before(endDt.t)
+}
+
+// IsInLast checks the DateTime is within the last count units until now, e.g. isInLast(2, 'days')
+func (dt *DateTime) IsInLast(count interface{}, unit string) bool {
+	if !dt.IsValid {
+		return false
+	}
+	if count == nil {
+		count = 0
+	}
+	if unit == "" {
+		unit = "minutes"
+	}
+	now := dt.lib.now()
+	return !dt.t.After(now.t) && !dt.t.Before(now.Minus(count, unit).t)
+}
+
+// Extract returns the unit value like get, the unit defaults to week (the week number)
+func (dt *DateTime) Extract(unit string) interface{} {
+	switch strings.ToLower(strings.TrimSuffix(unit, "s")) {
+	case "", "week", "weeknumber":
+		return dt.WeekNumber
+	case "year":
+		return dt.Year
+	case "quarter":
+		return dt.Quarter
+	case "month":
+		return dt.Month
+	case "day":
+		return dt.Day
+	case "weekday":
+		return dt.Weekday
+	case "ordinal", "dayofyear":
+		return dt.Ordinal
+	case "hour":
+		return dt.Hour
+	case "minute":
+		return dt.Minute
+	case "second":
+		return dt.Second
+	case "millisecond":
+		return dt.Millisecond
+	}
+	return nil
+}
+
+func formatUnitCount(value float64, unit string) string {
+	if value == 1 {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64) + " " + unit
+}
+
+func parseDurationUnits(unit interface{}) ([]string, error) {
+	var rawUnits []string
+	switch u := unit.(type) {
+	case nil:
+		rawUnits = []string{"milliseconds"}
+	case string:
+		rawUnits = []string{u}
+	case []string:
+		rawUnits = u
+	case []interface{}:
+		for _, item := range u {
+			rawUnits = append(rawUnits, fmt.Sprintf("%v", item))
+		}
+	default:
+		return nil, fmt.Errorf("invalid unit: %v", unit)
+	}
+	units := make([]string, 0, len(rawUnits))
+	for _, rawUnit := range rawUnits {
+		normalized, ok := normalizeDurationUnit(rawUnit)
+		if !ok {
+			return nil, fmt.Errorf("invalid unit: %s", rawUnit)
+		}
+		units = append(units, normalized)
+	}
+	if len(units) == 0 {
+		units = []string{"milliseconds"}
+	}
+	// largest first
+	order := make(map[string]int, len(durationUnits))
+	for i, u := range durationUnits {
+		order[u] = i
+	}
+	sort.SliceStable(units, func(i, j int) bool { return order[units[i]] < order[units[j]] })
+	return units, nil
+}
+
+func stepUnit(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "years":
+		return addMonths(t, n*12)
+	case "quarters":
+		return addMonths(t, n*3)
+	case "months":
+		return addMonths(t, n)
+	case "weeks":
+		return t.AddDate(0, 0, n*7)
+	case "days":
+		return t.AddDate(0, 0, n)
+	}
+	return t.Add(time.Duration(float64(n) * durationUnitMillis[unit] * float64(time.Millisecond)))
+}
+
+// diffTimes splits end - start into the units like Luxon,
+// the calendar units are counted on the calendar and the smallest unit keeps the fraction
+func diffTimes(start, end time.Time, units []string) map[string]float64 {
+	sign := 1.0
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+	result := make(map[string]float64, len(units))
+	cursor := start
+	for i, unit := range units {
+		last := i == len(units)-1
+		if !isCalendarUnit(unit) {
+			remaining := float64(end.Sub(cursor)) / float64(time.Millisecond)
+			value := remaining / durationUnitMillis[unit]
+			if !last {
+				value = math.Trunc(value)
+			}
+			result[unit] = value
+			cursor = cursor.Add(time.Duration(value * durationUnitMillis[unit] * float64(time.Millisecond)))
+			continue
+		}
+		// estimate with the casual length then adjust on the calendar
+		n := int(float64(end.Sub(cursor)) / float64(time.Millisecond) / durationUnitMillis[unit])
+		for n > 0 && stepUnit(cursor, unit, n).After(end) {
+			n--
+		}
+		for !stepUnit(cursor, unit, n+1).After(end) {
+			n++
+		}
+		next := stepUnit(cursor, unit, n)
+		value := float64(n)
+		if last {
+			if unitLength := stepUnit(next, unit, 1).Sub(next); unitLength > 0 {
+				value += float64(end.Sub(next)) / float64(unitLength)
+			}
+		}
+		result[unit] = value
+		cursor = next
+	}
+	for unit := range result {
+		result[unit] = result[unit] * sign
+		if result[unit] == 0 {
+			result[unit] = 0 // no -0
+		}
+	}
+	return result
+}
+
+// ---------------------------------- Duration ----------------------------------
+
+func (lib *dateTimeLib) newDuration(values map[string]float64) *Duration {
+	d := &Duration{lib: lib, values: values, IsValid: true}
+	d.Years = values["years"]
+	d.Quarters = values["quarters"]
+	d.Months = values["months"]
+	d.Weeks = values["weeks"]
+	d.Days = values["days"]
+	d.Hours = values["hours"]
+	d.Minutes = values["minutes"]
+	d.Seconds = values["seconds"]
+	d.Milliseconds = values["milliseconds"]
+	return d
+}
+
+func (lib *dateTimeLib) invalidDuration(reason string) *Duration {
+	return &Duration{lib: lib, values: map[string]float64{}, InvalidReason: reason}
+}
+
+// toDuration accepts Duration, duration like object, ISO duration string and milliseconds
+func (lib *dateTimeLib) toDuration(value interface{}) *Duration {
+	switch v := value.(type) {
+	case *Duration:
+		return v
+	case map[string]interface{}:
+		values := make(map[string]float64, len(v))
+		for key, raw := range v {
+			unit, ok := normalizeDurationUnit(key)
+			if !ok {
+				return lib.invalidDuration(fmt.Sprintf("invalid unit: %s", key))
+			}
+			number, err := ConvertToFloat64(raw)
+			if err != nil {
+				return lib.invalidDuration(fmt.Sprintf("invalid unit %s: %v", key, err))
+			}
+			values[unit] = number
+		}
+		return lib.newDuration(values)
+	case string:
+		match := durationISORegex.FindStringSubmatch(strings.TrimSpace(v))
+		if match == nil || v == "P" || strings.HasSuffix(v, "T") {
+			return lib.invalidDuration(fmt.Sprintf("unparsable: the input %q can't be parsed as ISO 8601", v))
+		}
+		sign := 1.0
+		if match[1] == "-" {
+			sign = -1
+		}
+		values := make(map[string]float64)
+		for i, unit := range []string{"years", "months", "weeks", "days", "hours", "minutes", "seconds"} {
+			if match[i+2] == "" {
+				continue
+			}
+			number, _ := strconv.ParseFloat(strings.Replace(match[i+2], ",", ".", 1), 64)
+			values[unit] = sign * number
+		}
+		// fractional seconds are milliseconds in Luxon
+		if seconds, ok := values["seconds"]; ok && seconds != math.Trunc(seconds) {
+			values["seconds"] = math.Trunc(seconds)
+			values["milliseconds"] = math.Round((seconds - math.Trunc(seconds)) * 1000)
+		}
+		return lib.newDuration(values)
+	case nil:
+		return lib.invalidDuration("invalid input")
+	}
+	millis, err := ConvertToFloat64(value)
+	if err != nil {
+		return lib.invalidDuration(fmt.Sprintf("invalid input: %v", value))
+	}
+	return lib.newDuration(map[string]float64{"milliseconds": millis})
+}
+
+// As returns the duration in the unit, e.g. as('hours')
+func (d *Duration) As(unit string) float64 {
+	unit, ok := normalizeDurationUnit(unit)
+	if !ok || !d.IsValid {
+		return math.NaN()
+	}
+	return d.ToMillis() / durationUnitMillis[unit]
+}
+
+func (d *Duration) ToMillis() float64 {
+	if !d.IsValid {
+		return math.NaN()
+	}
+	millis := 0.0
+	for unit, value := range d.values {
+		millis += value * durationUnitMillis[unit]
+	}
+	return millis
+}
+
+func (d *Duration) ValueOf() float64 {
+	return d.ToMillis()
+}
+
+// ShiftTo converts the duration into the units, e.g. shiftTo('hours', 'minutes')
+func (d *Duration) ShiftTo(units ...string) *Duration {
+	if !d.IsValid {
+		return d
+	}
+	rawUnits := make([]interface{}, len(units))
+	for i, unit := range units {
+		rawUnits[i] = unit
+	}
+	normalized, err := parseDurationUnits(rawUnits)
+	if err != nil {
+		return d.lib.invalidDuration(err.Error())
+	}
+	remaining := d.ToMillis()
+	values := make(map[string]float64, len(normalized))
+	for i, unit := range normalized {
+		value := remaining / durationUnitMillis[unit]
+		if i < len(normalized)-1 {
+			value = math.Trunc(value)
+		}
+		values[unit] = value
+		remaining -= value * durationUnitMillis[unit]
+	}
+	return d.lib.newDuration(values)
+}
+
+func (d *Duration) combine(other interface{}, sign float64) *Duration {
+	otherDuration := d.lib.toDuration(other)
+	if !d.IsValid || !otherDuration.IsValid {
+		return d.lib.invalidDuration("invalid duration")
+	}
+	values := make(map[string]float64, len(d.values))
+	for unit, value := range d.values {
+		values[unit] = value
+	}
+	for unit, value := range otherDuration.values {
+		values[unit] += sign * value
+	}
+	return d.lib.newDuration(values)
+}
+
+func (d *Duration) Plus(other interface{}) *Duration {
+	return d.combine(other, 1)
+}
+
+func (d *Duration) Minus(other interface{}) *Duration {
+	return d.combine(other, -1)
+}
+
+func (d *Duration) Negate() *Duration {
+	return d.lib.newDuration(map[string]float64{}).Minus(d)
+}
+
+func (d *Duration) Get(unit string) float64 {
+	unit, _ = normalizeDurationUnit(unit)
+	return d.values[unit]
+}
+
+func (d *Duration) ToObject() map[string]interface{} {
+	object := make(map[string]interface{}, len(d.values))
+	for unit, value := range d.values {
+		object[unit] = value
+	}
+	return object
+}
+
+// ToISO returns the ISO 8601 duration, e.g. P1DT2H
+func (d *Duration) ToISO() interface{} {
+	if !d.IsValid {
+		return nil
+	}
+	number := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	var date, clock strings.Builder
+	if d.values["years"] != 0 {
+		date.WriteString(number(d.values["years"]) + "Y")
+	}
+	if months := d.values["months"] + d.values["quarters"]*3; months != 0 {
+		date.WriteString(number(months) + "M")
+	}
+	if d.values["weeks"] != 0 {
+		date.WriteString(number(d.values["weeks"]) + "W")
+	}
+	if d.values["days"] != 0 {
+		date.WriteString(number(d.values["days"]) + "D")
+	}
+	if d.values["hours"] != 0 {
+		clock.WriteString(number(d.values["hours"]) + "H")
+	}
+	if d.values["minutes"] != 0 {
+		clock.WriteString(number(d.values["minutes"]) + "M")
+	}
+	if seconds := d.values["seconds"] + d.values["milliseconds"]/1000; seconds != 0 {
+		clock.WriteString(strconv.FormatFloat(math.Round(seconds*1000)/1000, 'f', -1, 64) + "S")
+	}
+	if date.Len() == 0 && clock.Len() == 0 {
+		return "PT0S"
+	}
+	if clock.Len() > 0 {
+		return "P" + date.String() + "T" + clock.String()
+	}
+	return "P" + date.String()
+}
+
+func (d *Duration) ToJSON() interface{} {
+	return d.ToISO()
+}
+
+func (d *Duration) ToString() string {
+	if !d.IsValid {
+		return "Invalid Duration"
+	}
+	return d.ToISO().(string)
+}
+
+// ToHuman returns the en-US representation, e.g. "1 day, 2 hours"
+func (d *Duration) ToHuman() string {
+	if !d.IsValid {
+		return "Invalid Duration"
+	}
+	parts := make([]string, 0, len(d.values))
+	for _, unit := range durationUnits {
+		if value, ok := d.values[unit]; ok && value != 0 {
+			parts = append(parts, formatUnitCount(value, unit))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ---------------------------------- Interval ----------------------------------
+
+func (lib *dateTimeLib) newInterval(start, end interface{}) *Interval {
+	startDt, ok := lib.toDateTime(start)
+	if !ok {
+		return lib.invalidInterval("invalid start")
+	}
+	endDt, ok := lib.toDateTime(end)
+	if !ok {
+		return lib.invalidInterval("invalid end")
+	}
+	if endDt.t.Before(startDt.t) {
+		return lib.invalidInterval("end before start")
+	}
+	return &Interval{lib: lib, Start: startDt, End: endDt, IsValid: true}
+}
+
+func (lib *dateTimeLib) invalidInterval(reason string) *Interval {
+	return &Interval{lib: lib, InvalidReason: reason}
+}
+
+// Length returns the length in the unit, default milliseconds
+func (i *Interval) Length(unit string) float64 {
+	if !i.IsValid {
+		return math.NaN()
+	}
+	if unit == "" {
+		unit = "milliseconds"
+	}
+	return i.ToDuration(unit).As(unit)
+}
+
+func (i *Interval) ToDuration(unit interface{}) *Duration {
+	if !i.IsValid {
+		return i.lib.invalidDuration(i.InvalidReason)
+	}
+	return i.End.Diff(i.Start, unit)
+}
+
+func (i *Interval) Contains(value interface{}) bool {
+	dt, ok := i.lib.toDateTime(value)
+	if !ok || !i.IsValid {
+		return false
+	}
+	return !dt.t.Before(i.Start.t) && dt.t.Before(i.End.t)
+}
+
+func (i *Interval) Overlaps(other *Interval) bool {
+	if other == nil || !i.IsValid || !other.IsValid {
+		return false
+	}
+	return i.Start.t.Before(other.End.t) && other.Start.t.Before(i.End.t)
+}
+
+func (i *Interval) IsEmpty() bool {
+	return !i.IsValid || i.Start.t.Equal(i.End.t)
+}
+
+func (i *Interval) IsBefore(value interface{}) bool {
+	dt, ok := i.lib.toDateTime(value)
+	return ok && i.IsValid && !i.End.t.After(dt.t)
+}
+
+func (i *Interval) IsAfter(value interface{}) bool {
+	dt, ok := i.lib.toDateTime(value)
+	return ok && i.IsValid && i.Start.t.After(dt.t)
+}
+
+func (i *Interval) ToISO() interface{} {
+	if !i.IsValid {
+		return nil
+	}
+	return i.Start.isoString() + "/" + i.End.isoString()
+}
+
+func (i *Interval) ToJSON() interface{} {
+	return i.ToISO()
+}
+
+func (i *Interval) ToString() string {
+	if !i.IsValid {
+		return "Invalid Interval"
+	}
+	return "[" + i.Start.isoString() + " – " + i.End.isoString() + ")"
+}
+
+// standardizeDateTimeValue converts DateTime, Duration and Interval to their ISO strings
+func standardizeDateTimeValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case *DateTime:
+		return v.ToISO(), true
+	case *Duration:
+		return v.ToISO(), true
+	case *Interval:
+		return v.ToISO(), true
+	}
+	return value, false
+}
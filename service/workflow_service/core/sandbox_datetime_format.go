@@ -0,0 +1,279 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatToken is a Luxon format token, a run of the same letter like "yyyy" or a quoted literal
+type formatToken struct {
+	literal bool
+	value   string
+}
+
+// tokenizeLuxonFormat splits the format, the text in single quotes is literal, two single quotes are a quote
+func tokenizeLuxonFormat(format string) []formatToken {
+	tokens := make([]formatToken, 0)
+	runes := []rune(format)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '\'' {
+			j := i + 1
+			var literal strings.Builder
+			for j < len(runes) {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						literal.WriteRune('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				literal.WriteRune(runes[j])
+				j++
+			}
+			if j == i+1 && j < len(runes) {
+				// two single quotes outside of a literal
+				literal.WriteRune('\'')
+			}
+			tokens = append(tokens, formatToken{literal: true, value: literal.String()})
+			i = j + 1
+			continue
+		}
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			j := i
+			for j < len(runes) && runes[j] == r {
+				j++
+			}
+			tokens = append(tokens, formatToken{value: string(runes[i:j])})
+			i = j
+			continue
+		}
+		tokens = append(tokens, formatToken{literal: true, value: string(r)})
+		i++
+	}
+	return tokens
+}
+
+func pad(value int, width int) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	text := strconv.Itoa(value)
+	for len(text) < width {
+		text = "0" + text
+	}
+	return sign + text
+}
+
+func formatOffset(offsetMinutes int, style string) string {
+	sign := "+"
+	if offsetMinutes < 0 {
+		sign = "-"
+		offsetMinutes = -offsetMinutes
+	}
+	hours, minutes := offsetMinutes/60, offsetMinutes%60
+	switch style {
+	case "narrow":
+		if minutes > 0 {
+			return fmt.Sprintf("%s%d:%s", sign, hours, pad(minutes, 2))
+		}
+		return fmt.Sprintf("%s%d", sign, hours)
+	case "techie":
+		return sign + pad(hours, 2) + pad(minutes, 2)
+	}
+	return sign + pad(hours, 2) + ":" + pad(minutes, 2)
+}
+
+func hour12(hour int) int {
+	if hour%12 == 0 {
+		return 12
+	}
+	return hour % 12
+}
+
+func meridiem(hour int) string {
+	if hour < 12 {
+		return "AM"
+	}
+	return "PM"
+}
+
+// formatDateTime formats the DateTime with the Luxon tokens in en-US,
+// see https://moment.github.io/luxon/#/formatting?id=table-of-tokens
+func formatDateTime(dt *DateTime, format string) string {
+	var builder strings.Builder
+	for _, token := range tokenizeLuxonFormat(format) {
+		if token.literal {
+			builder.WriteString(token.value)
+			continue
+		}
+		builder.WriteString(formatDateTimeToken(dt, token.value))
+	}
+	return builder.String()
+}
+
+func formatDateTimeToken(dt *DateTime, token string) string {
+	switch token {
+	// millisecond
+	case "S":
+		return strconv.Itoa(dt.Millisecond)
+	case "SSS":
+		return pad(dt.Millisecond, 3)
+	// second
+	case "s":
+		return strconv.Itoa(dt.Second)
+	case "ss":
+		return pad(dt.Second, 2)
+	// minute
+	case "m":
+		return strconv.Itoa(dt.Minute)
+	case "mm":
+		return pad(dt.Minute, 2)
+	// hour
+	case "h":
+		return strconv.Itoa(hour12(dt.Hour))
+	case "hh":
+		return pad(hour12(dt.Hour), 2)
+	case "H":
+		return strconv.Itoa(dt.Hour)
+	case "HH":
+		return pad(dt.Hour, 2)
+	case "a":
+		return meridiem(dt.Hour)
+	// offset and zone
+	case "Z":
+		return formatOffset(dt.Offset, "narrow")
+	case "ZZ":
+		return formatOffset(dt.Offset, "short")
+	case "ZZZ":
+		return formatOffset(dt.Offset, "techie")
+	case "ZZZZ":
+		return dt.OffsetNameShort
+	case "ZZZZZ", "z":
+		return dt.ZoneName
+	// day
+	case "d":
+		return strconv.Itoa(dt.Day)
+	case "dd":
+		return pad(dt.Day, 2)
+	// weekday
+	case "c", "E":
+		return strconv.Itoa(dt.Weekday)
+	case "ccc", "EEE":
+		return dt.WeekdayShort
+	case "cccc", "EEEE":
+		return dt.WeekdayLong
+	case "ccccc", "EEEEE":
+		return dt.WeekdayShort[:1]
+	// month
+	case "L", "M":
+		return strconv.Itoa(dt.Month)
+	case "LL", "MM":
+		return pad(dt.Month, 2)
+	case "LLL", "MMM":
+		return dt.MonthShort
+	case "LLLL", "MMMM":
+		return dt.MonthLong
+	case "LLLLL", "MMMMM":
+		return dt.MonthShort[:1]
+	// year
+	case "y":
+		return strconv.Itoa(dt.Year)
+	case "yy":
+		return pad(dt.Year%100, 2)
+	case "yyyy":
+		return pad(dt.Year, 4)
+	case "yyyyyy":
+		return pad(dt.Year, 6)
+	case "G":
+		if dt.Year > 0 {
+			return "AD"
+		}
+		return "BC"
+	// week
+	case "kk":
+		return pad(dt.WeekYear%100, 2)
+	case "kkkk":
+		return pad(dt.WeekYear, 4)
+	case "W":
+		return strconv.Itoa(dt.WeekNumber)
+	case "WW":
+		return pad(dt.WeekNumber, 2)
+	// ordinal and quarter
+	case "o":
+		return strconv.Itoa(dt.Ordinal)
+	case "ooo":
+		return pad(dt.Ordinal, 3)
+	case "q":
+		return strconv.Itoa(dt.Quarter)
+	case "qq":
+		return pad(dt.Quarter, 2)
+	// macro tokens
+	case "D":
+		return formatDateTime(dt, "M/d/yyyy")
+	case "DD":
+		return formatDateTime(dt, "MMM d, yyyy")
+	case "DDD":
+		return formatDateTime(dt, "MMMM d, yyyy")
+	case "DDDD":
+		return formatDateTime(dt, "EEEE, MMMM d, yyyy")
+	case "t":
+		return formatDateTime(dt, "h:mm a")
+	case "tt":
+		return formatDateTime(dt, "h:mm:ss a")
+	case "T":
+		return formatDateTime(dt, "HH:mm")
+	case "TT":
+		return formatDateTime(dt, "HH:mm:ss")
+	case "f":
+		return formatDateTime(dt, "D, t")
+	case "ff":
+		return formatDateTime(dt, "DD, t")
+	case "F":
+		return formatDateTime(dt, "D, tt")
+	case "FF":
+		return formatDateTime(dt, "DD, tt")
+	// epoch
+	case "X":
+		return strconv.FormatInt(dt.ToUnixInteger(), 10)
+	case "x":
+		return strconv.FormatInt(dt.ToMillis(), 10)
+	}
+	// unknown tokens are kept as they are
+	return token
+}
+
+// the Go layout of the Luxon tokens supported by DateTime.fromFormat
+var luxonTokenLayouts = map[string]string{
+	"yyyy": "2006", "y": "2006", "yy": "06",
+	"M": "1", "L": "1", "MM": "01", "LL": "01",
+	"MMM": "Jan", "LLL": "Jan", "MMMM": "January", "LLLL": "January",
+	"d": "2", "dd": "02",
+	"EEE": "Mon", "ccc": "Mon", "EEEE": "Monday", "cccc": "Monday",
+	"H": "15", "HH": "15", "h": "3", "hh": "03", "a": "PM",
+	"m": "4", "mm": "04", "s": "5", "ss": "05",
+	"S": "000", "SSS": "000",
+	"Z": "-07", "ZZ": "-07:00", "ZZZ": "-0700", "ZZZZ": "MST",
+}
+
+// luxonFormatToLayout converts the Luxon parsing tokens to the Go layout.
+// Note the literal text must not contain the Go layout words like "Jan" or "2006".
+func luxonFormatToLayout(format string) (string, error) {
+	var builder strings.Builder
+	for _, token := range tokenizeLuxonFormat(format) {
+		if token.literal {
+			builder.WriteString(token.value)
+			continue
+		}
+		layout, ok := luxonTokenLayouts[token.value]
+		if !ok {
+			return "", fmt.Errorf("unsupported format token: %s", token.value)
+		}
+		builder.WriteString(layout)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,133 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/sandbox_datetime_test.go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func newDateTimeSandbox(timezone string) *core.Sandbox {
+	sandbox := core.Sandbox{
+		Context: &core.SandboxContext{
+			Items:    structs.NodeData{{"json": map[string]interface{}{"a": 1}}},
+			Timezone: timezone,
+		},
+	}
+	sandbox.Initialize()
+	return &sandbox
+}
+
+func TestSandboxDateTime(t *testing.T) {
+
+	t.Run("DateTime $now and $today use the workflow timezone", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("America/New_York")
+
+		res, err := sandbox.RunCode(`$now.zoneName`, 0)
+		assert.Nil(err)
+		assert.Equal("America/New_York", res)
+
+		res, err = sandbox.RunCode(`[$today.hour, $today.minute, $today.second]`, 0)
+		assert.Nil(err)
+		assert.Equal([]interface{}{int64(0), int64(0), int64(0)}, res)
+
+		res, err = sandbox.RunCode(`$now.plus({days: 1}) > $now && $today <= $now`, 0)
+		assert.Nil(err)
+		assert.Equal(true, res)
+	})
+
+	t.Run("DateTime parse and format", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		res, err := sandbox.RunCode(`DateTime.fromISO('2024-03-10T05:06:07.089Z').setZone('Asia/Tokyo').toISO()`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-03-10T14:06:07.089+09:00", res)
+
+		res, err = sandbox.RunCode(`DateTime.fromISO('2024-03-10T15:06:07').toFormat("yyyy-MM-dd hh:mm a 'at' EEEE")`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-03-10 03:06 PM at Sunday", res)
+
+		res, err = sandbox.RunCode(`DateTime.fromFormat('10/03/2024 14:05', 'dd/MM/yyyy HH:mm').toISO()`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-03-10T14:05:00.000Z", res)
+
+		res, err = sandbox.RunCode(`DateTime.fromISO('not a date').isValid`, 0)
+		assert.Nil(err)
+		assert.Equal(false, res)
+	})
+
+	t.Run("DateTime math", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		res, err := sandbox.RunCode(`DateTime.utc(2024, 1, 31).plus({months: 1}).toISODate()`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-02-29", res)
+
+		res, err = sandbox.RunCode(`DateTime.utc(2024, 3, 10, 12).startOf('week').toISO()`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-03-04T00:00:00.000Z", res)
+
+		res, err = sandbox.RunCode(`DateTime.utc(2024, 1, 1).endOf('month').toISO()`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-01-31T23:59:59.999Z", res)
+
+		res, err = sandbox.RunCode(`DateTime.utc(2024, 3, 10).diff(DateTime.utc(2024, 1, 1), ['months', 'days']).toObject()`, 0)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{"months": float64(2), "days": float64(9)}, res)
+	})
+
+	t.Run("Duration and Interval", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		res, err := sandbox.RunCode(`Duration.fromObject({hours: 2, minutes: 30}).as('minutes')`, 0)
+		assert.Nil(err)
+		assert.Equal(int64(150), res)
+
+		res, err = sandbox.RunCode(`Duration.fromMillis(90061000).shiftTo('days', 'hours', 'minutes', 'seconds').toISO()`, 0)
+		assert.Nil(err)
+		assert.Equal("P1DT1H1M1S", res)
+
+		res, err = sandbox.RunCode(`Interval.fromDateTimes(DateTime.utc(2024, 1, 1), DateTime.utc(2024, 1, 8)).length('days')`, 0)
+		assert.Nil(err)
+		assert.Equal(int64(7), res)
+
+		res, err = sandbox.RunCode(`Interval.fromISO('2024-01-01T00:00:00Z/P1W').contains(DateTime.utc(2024, 1, 3))`, 0)
+		assert.Nil(err)
+		assert.Equal(true, res)
+	})
+
+	t.Run("DateTime is returned as ISO string", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newDateTimeSandbox("UTC")
+
+		res, err := sandbox.RunCode(`DateTime.utc(2024, 5, 6, 7, 8, 9, 10)`, 0)
+		assert.Nil(err)
+		assert.Equal("2024-05-06T07:08:09.010Z", res)
+
+		res, err = sandbox.RunCode(`({date: DateTime.utc(2024, 1, 2), list: [DateTime.utc(2024, 1, 3)], duration: Duration.fromObject({days: 1})})`, 0)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{
+			"date":     "2024-01-02T00:00:00.000Z",
+			"list":     []interface{}{"2024-01-03T00:00:00.000Z"},
+			"duration": "P1D",
+		}, res)
+
+		eval := core.ExpressionEvaluator{Sandbox: sandbox}
+		res, err = eval.EvaluateExpression(`=Due {{ DateTime.utc(2024, 1, 2).plus({days: 1}) }}`, 0)
+		assert.Nil(err)
+		assert.Equal("Due 2024-01-03T00:00:00.000Z", res)
+	})
+}
@@ -0,0 +1,339 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/console"
+	"github.com/dop251/goja_nodejs/require"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The user code shares the pod with all the other workflows, so every run of the sandbox is limited:
+//   - require() only loads the allowed built-in modules, never a file of the host
+//   - the call stack depth, the run time and the heap growth are capped, and the output size of the Code node
+//   - the blocked globals are undefined
+//
+// The limits can be configured per organization in the organization config info, see structs.WorkflowSandboxLimits.
+
+const (
+	// DefaultSandboxMaxCallStackSize is the default max depth of the function calls
+	DefaultSandboxMaxCallStackSize = 10000
+	// DefaultSandboxMaxMemory is the default max heap growth of a code run, 256 MiB
+	DefaultSandboxMaxMemory = 256 << 20
+	// DefaultSandboxMaxOutputSize is the default max size of the JSON encoded output of the Code node, 64 MiB
+	DefaultSandboxMaxOutputSize = 64 << 20
+	// SandboxLimitsCacheTTL is how long the limits of an organization are cached
+	SandboxLimitsCacheTTL = 5 * time.Minute
+	// sandboxMemoryCheckInterval is how often the heap is checked during a code run
+	sandboxMemoryCheckInterval = 10 * time.Millisecond
+)
+
+// DefaultSandboxLimits are the limits used if the organization does not configure them
+var DefaultSandboxLimits = structs.WorkflowSandboxLimits{
+	AllowedModules: &structs.WorkflowAllowedModules{
+		BuiltIn: []string{"console", "util"},
+	},
+	Timeout:          TimeoutDefault,
+	MaxCallStackSize: DefaultSandboxMaxCallStackSize,
+	MaxMemory:        DefaultSandboxMaxMemory,
+	MaxOutputSize:    DefaultSandboxMaxOutputSize,
+}
+
+// withDefaultSandboxLimits returns a copy of the limits with the zero fields set to the default
+func withDefaultSandboxLimits(limits *structs.WorkflowSandboxLimits) *structs.WorkflowSandboxLimits {
+	result := DefaultSandboxLimits
+	if limits == nil {
+		return &result
+	}
+	if limits.AllowedModules != nil {
+		result.AllowedModules = limits.AllowedModules
+	}
+	if limits.Timeout > 0 {
+		result.Timeout = limits.Timeout
+	}
+	if limits.MaxCallStackSize > 0 {
+		result.MaxCallStackSize = limits.MaxCallStackSize
+	}
+	if limits.MaxMemory > 0 {
+		result.MaxMemory = limits.MaxMemory
+	}
+	if limits.MaxOutputSize > 0 {
+		result.MaxOutputSize = limits.MaxOutputSize
+	}
+	if len(limits.BlockedGlobals) > 0 {
+		result.BlockedGlobals = limits.BlockedGlobals
+	}
+	return &result
+}
+
+type sandboxLimitsCacheEntry struct {
+	limits   *structs.WorkflowSandboxLimits
+	expireAt time.Time
+}
+
+var sandboxLimitsCache sync.Map
+
+// GetSandboxLimits returns the sandbox limits of the organization, the defaults are used for the fields not configured.
+// The limits are cached for SandboxLimitsCacheTTL as they are needed by every expression.
+func GetSandboxLimits(ctx context.Context, orgId string) *structs.WorkflowSandboxLimits {
+	if orgId == "" || rdsDbQueries == nil {
+		return withDefaultSandboxLimits(nil)
+	}
+	if entry, ok := sandboxLimitsCache.Load(orgId); ok && time.Now().Before(entry.(*sandboxLimitsCacheEntry).expireAt) {
+		return entry.(*sandboxLimitsCacheEntry).limits
+	}
+
+	var orgLimits *structs.WorkflowSandboxLimits
+	info, err := rdsDbQueries.GetOrganizationInfo(ctx, orgId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		// not cached, the next run tries again
+		Warnf("Failed to get the sandbox limits of org %s: %v", orgId, err)
+		return withDefaultSandboxLimits(nil)
+	}
+	if len(info) > 0 {
+		var orgInfo structs.OrganizationInfo
+		if err := json.Unmarshal(info, &orgInfo); err != nil {
+			Warnf("Failed to parse the sandbox limits of org %s: %v", orgId, err)
+		} else if orgInfo.OrganizationConfigInfo != nil {
+			orgLimits = orgInfo.OrganizationConfigInfo.WorkflowSandboxLimits
+		}
+	}
+
+	limits := withDefaultSandboxLimits(orgLimits)
+	sandboxLimitsCache.Store(orgId, &sandboxLimitsCacheEntry{
+		limits:   limits,
+		expireAt: time.Now().Add(SandboxLimitsCacheTTL),
+	})
+	return limits
+}
+
+// sandboxLimits returns the limits of the organization of the workflow
+func (sc *SandboxContext) sandboxLimits() *structs.WorkflowSandboxLimits {
+	if sc == nil || sc.Workflow == nil {
+		return withDefaultSandboxLimits(nil)
+	}
+	return GetSandboxLimits(context.Background(), sc.Workflow.SugerOrgId)
+}
+
+// sandboxRegistry never reads the file system, so only the native and built-in modules can be loaded
+var sandboxRegistry = require.NewRegistry(require.WithLoader(func(path string) ([]byte, error) {
+	return nil, require.ModuleFileDoesNotExistError
+}))
+
+// sandboxModules is the require() of a runtime, it only loads the allowed modules
+type sandboxModules struct {
+	vm      *goja.Runtime
+	module  *require.RequireModule
+	allowed map[string]bool
+	// loaded is true once a module is loaded, the module objects are shared by the later runs of the runtime
+	loaded bool
+}
+
+func enableSandboxModules(vm *goja.Runtime) *sandboxModules {
+	m := &sandboxModules{vm: vm, module: sandboxRegistry.Enable(vm)}
+	// console is loaded by the original require() before the allow-list is in place
+	console.Enable(vm)
+	vm.Set("require", m.require)
+	return m
+}
+
+func (m *sandboxModules) allow(modules *structs.WorkflowAllowedModules) {
+	m.allowed = make(map[string]bool)
+	if modules == nil {
+		return
+	}
+	for _, names := range [][]string{modules.BuiltIn, modules.External} {
+		for _, name := range names {
+			m.allowed[strings.TrimPrefix(name, require.NodePrefix)] = true
+		}
+	}
+}
+
+func (m *sandboxModules) require(call goja.FunctionCall) goja.Value {
+	name := call.Argument(0).String()
+	// the same error as a module which does not exist, so the user code can not probe the host
+	if !m.allowed[strings.TrimPrefix(name, require.NodePrefix)] {
+		panic(m.vm.NewGoError(require.InvalidModuleError))
+	}
+	m.loaded = true
+	module, err := m.module.Require(name)
+	if err != nil {
+		if exception, ok := err.(*goja.Exception); ok {
+			panic(exception)
+		}
+		panic(m.vm.NewGoError(err))
+	}
+	return module
+}
+
+// applyLimits sets the limits to the VM, it must be called before any code runs
+func (s *Sandbox) applyLimits() {
+	if s.Limits == nil {
+		s.Limits = s.Context.sandboxLimits()
+	} else {
+		s.Limits = withDefaultSandboxLimits(s.Limits)
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = time.Duration(s.Limits.Timeout) * time.Millisecond
+	}
+	s.VM.SetMaxCallStackSize(s.Limits.MaxCallStackSize)
+	if s.modules != nil {
+		s.modules.allow(s.Limits.AllowedModules)
+	}
+	global := s.VM.GlobalObject()
+	for _, name := range s.Limits.BlockedGlobals {
+		// the blocked globals of a pooled VM are restored by the pool
+		if err := global.Set(name, goja.Undefined()); err != nil {
+			Warnf("Failed to block the global %s: %v", name, err)
+		}
+	}
+}
+
+// sandboxWatchdog interrupts the VM when the code runs too long or the heap grows too much during the run.
+// It is stopped right after the run and never interrupts the VM after that.
+// Both the Code node and the expressions run with the watchdog.
+type sandboxWatchdog struct {
+	mu          sync.Mutex
+	vm          *goja.Runtime
+	timer       *time.Timer
+	deadline    time.Time
+	maxMemory   uint64
+	heapBase    uint64
+	stopped     bool
+	interrupted bool
+}
+
+// startWatchdog starts the watchdog of a code run, nil if there is no limit to watch
+func (s *Sandbox) startWatchdog() *sandboxWatchdog {
+	var maxMemory int64
+	if s.Limits != nil {
+		maxMemory = s.Limits.MaxMemory
+	}
+	if s.Timeout <= 0 && maxMemory <= 0 {
+		return nil
+	}
+	s.VM.ClearInterrupt()
+
+	w := &sandboxWatchdog{vm: s.VM}
+	interval := s.Timeout
+	if s.Timeout > 0 {
+		w.deadline = time.Now().Add(s.Timeout)
+	}
+	if maxMemory > 0 {
+		w.maxMemory = uint64(maxMemory)
+		w.heapBase = liveHeapSize()
+		if interval <= 0 || interval > sandboxMemoryCheckInterval {
+			interval = sandboxMemoryCheckInterval
+		}
+	}
+	w.timer = time.AfterFunc(interval, w.check)
+	return w
+}
+
+func (w *sandboxWatchdog) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+	now := time.Now()
+	if !w.deadline.IsZero() && !now.Before(w.deadline) {
+		w.interrupt("Code run timeout")
+		return
+	}
+	if w.maxMemory > 0 {
+		if heap := liveHeapSize(); heap > w.heapBase && heap-w.heapBase > w.maxMemory {
+			w.interrupt(fmt.Sprintf("Code run exceeds the memory limit of %d bytes", w.maxMemory))
+			return
+		}
+	}
+	interval := sandboxMemoryCheckInterval
+	if w.maxMemory == 0 || (!w.deadline.IsZero() && w.deadline.Sub(now) < interval) {
+		interval = w.deadline.Sub(now)
+	}
+	w.timer.Reset(interval)
+}
+
+func (w *sandboxWatchdog) interrupt(reason string) {
+	w.interrupted = true
+	w.vm.Interrupt(reason)
+}
+
+// stop stops the watchdog, it returns true if the VM was interrupted
+func (w *sandboxWatchdog) stop() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	w.timer.Stop()
+	return w.interrupted
+}
+
+var liveHeapSample = []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+var liveHeapSampleLock sync.Mutex
+
+// liveHeapSize is the heap marked live by the last GC. A string or an array built by the user code keeps it
+// growing, while the garbage of the code does not count. Go has no heap per goroutine, so the growth during
+// a run is an approximation of the memory used by the run; the default limit is far above what a run of a
+// normal workflow grows the heap by, so only a run building a huge value is expected to reach it.
+func liveHeapSize() uint64 {
+	liveHeapSampleLock.Lock()
+	defer liveHeapSampleLock.Unlock()
+	metrics.Read(liveHeapSample)
+	if liveHeapSample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return liveHeapSample[0].Value.Uint64()
+}
+
+// runWithLimits runs the code with the watchdog, the watchdog is always stopped before it returns
+func (s *Sandbox) runWithLimits(code string) (v goja.Value, err error) {
+	watchdog := s.startWatchdog()
+	defer func() {
+		if watchdog.stop() {
+			// never reuse an interrupted VM
+			s.reusable = false
+		}
+	}()
+
+	v, err = runScript(s.VM, s.Name, code)
+	var stackOverflow *goja.StackOverflowError
+	if errors.As(err, &stackOverflow) {
+		err = fmt.Errorf("RangeError: Maximum call stack size exceeded")
+	}
+	return v, err
+}
+
+// checkOutputSize returns an error if the JSON encoded output of the Code node exceeds the limit.
+// It is not checked for the expressions, the encoding would cost every parameter of every item.
+func (s *Sandbox) checkOutputSize(output interface{}) error {
+	if s.Limits == nil || s.Limits.MaxOutputSize <= 0 {
+		return nil
+	}
+	size := 0
+	if str, ok := output.(string); ok {
+		size = len(str)
+	} else if output != nil {
+		encoded, err := json.Marshal(output)
+		if err != nil {
+			// not a JSON output, which is handled by the caller
+			return nil
+		}
+		size = len(encoded)
+	}
+	if size > s.Limits.MaxOutputSize {
+		return fmt.Errorf("Code output of %d bytes exceeds the limit of %d bytes", size, s.Limits.MaxOutputSize)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/sandbox_limits_test.go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func newLimitedSandbox(code string, limits *structs.WorkflowSandboxLimits) *core.Sandbox {
+	sandbox := core.Sandbox{
+		Name:    "main",
+		JsCode:  code,
+		Limits:  limits,
+		Context: &core.SandboxContext{},
+	}
+	sandbox.Initialize()
+	return &sandbox
+}
+
+func TestSandboxLimits(t *testing.T) {
+
+	t.Run("Default limits", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newLimitedSandbox(`return {a: 1}`, nil)
+
+		assert.Equal(180*time.Second, sandbox.Timeout)
+		assert.Equal(core.DefaultSandboxMaxCallStackSize, sandbox.Limits.MaxCallStackSize)
+		assert.Equal(int64(core.DefaultSandboxMaxMemory), sandbox.Limits.MaxMemory)
+		assert.Equal([]string{"console", "util"}, sandbox.Limits.AllowedModules.BuiltIn)
+	})
+
+	t.Run("Only the allowed modules can be required", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		res, err := newLimitedSandbox(`return {s: require('util').format('%s-%d', 'a', 1)}`, nil).RunCodeAllItems()
+		assert.Nil(err)
+		assert.Equal(structs.NodeData{{"s": "a-1"}}, res)
+
+		for _, code := range []string{`require('./sandbox.go')`, `require('/etc/hosts')`, `require('node:fs')`} {
+			_, err = newLimitedSandbox(code, nil).RunCodeAllItems()
+			assert.EqualError(err, "GoError: Invalid module [line 1]", code)
+		}
+
+		limits := &structs.WorkflowSandboxLimits{AllowedModules: &structs.WorkflowAllowedModules{BuiltIn: []string{"console"}}}
+		_, err = newLimitedSandbox(`require('util')`, limits).RunCodeAllItems()
+		assert.EqualError(err, "GoError: Invalid module [line 1]")
+	})
+
+	t.Run("Max call stack size", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		const code = `const f = (n) => n === 0 ? 0 : 1 + f(n - 1); return {n: f(500)}`
+
+		res, err := newLimitedSandbox(code, nil).RunCodeAllItems()
+		assert.Nil(err)
+		assert.Equal(structs.NodeData{{"n": int64(500)}}, res)
+
+		res, err = newLimitedSandbox(code, &structs.WorkflowSandboxLimits{MaxCallStackSize: 100}).RunCodeAllItems()
+		assert.EqualError(err, "RangeError: Maximum call stack size exceeded")
+		assert.Nil(res)
+	})
+
+	t.Run("Max memory", func(t *testing.T) {
+		assert := require.New(t)
+		limits := &structs.WorkflowSandboxLimits{MaxMemory: 64 << 20, Timeout: 60 * 1000}
+
+		start := time.Now()
+		res, err := newLimitedSandbox(`let s = ''; while (true) { s += 'x'.repeat(1 << 20) }`, limits).RunCodeAllItems()
+		assert.Nil(res)
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "Code run exceeds the memory limit of 67108864 bytes")
+		assert.Less(time.Since(start), 30*time.Second)
+
+		// the expressions are limited as well
+		start = time.Now()
+		value, err := newLimitedSandbox(``, limits).RunCode(`(() => { const chunks = []; while (true) { chunks.push('x'.repeat(1 << 20) + chunks.length) } })()`, 0)
+		assert.Nil(value)
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "Code run exceeds the memory limit of 67108864 bytes")
+		assert.Less(time.Since(start), 30*time.Second)
+	})
+
+	t.Run("Max output size", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		limits := &structs.WorkflowSandboxLimits{MaxOutputSize: 1000}
+
+		res, err := newLimitedSandbox(`return [{a: 'x'.repeat(100)}]`, limits).RunCodeAllItems()
+		assert.Nil(err)
+		assert.Len(res, 1)
+
+		res, err = newLimitedSandbox(`return [{a: 'x'.repeat(2000)}]`, limits).RunCodeAllItems()
+		assert.EqualError(err, "Code output of 2010 bytes exceeds the limit of 1000 bytes")
+		assert.Nil(res)
+
+		// the expressions are not limited
+		str, err := newLimitedSandbox(``, limits).RunCode(`'x'.repeat(2000)`, 0)
+		assert.Nil(err)
+		assert.Len(str, 2000)
+	})
+
+	t.Run("Blocked globals", func(t *testing.T) {
+		assert := require.New(t)
+		limits := &structs.WorkflowSandboxLimits{BlockedGlobals: []string{"eval", "console"}}
+
+		res, err := newLimitedSandbox(``, limits).RunCode(`typeof eval + ' ' + typeof console`, 0)
+		assert.Nil(err)
+		assert.Equal("undefined undefined", res)
+
+		// the blocked globals of a pooled VM are restored for the next use
+		for i := 0; i < 3; i++ {
+			sandbox := core.Sandbox{Context: &core.SandboxContext{}, Limits: limits}
+			sandbox.InitializeFromPool()
+			eval := core.ExpressionEvaluator{Sandbox: &sandbox}
+			res, err = eval.EvaluateExpression(`={{ typeof eval }}`, 0)
+			sandbox.Release()
+			assert.Nil(err)
+			assert.Equal("undefined", res)
+
+			res, err = evaluateWithPooledRuntime(`={{ typeof eval + ' ' + eval('1 + 1') }}`)
+			assert.Nil(err)
+			assert.Equal("function 2", res)
+		}
+	})
+
+	t.Run("Timer is stopped after the run", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandbox := newLimitedSandbox(`return {a: 1}`, &structs.WorkflowSandboxLimits{Timeout: 20})
+
+		_, err := sandbox.RunCodeAllItems()
+		assert.Nil(err)
+		time.Sleep(50 * time.Millisecond)
+		res, err := sandbox.RunCode(`[1, 2, 3].map((i) => i * 2).length`, 0)
+		assert.Nil(err)
+		assert.Equal(int64(3), res)
+
+		_, err = sandbox.RunCode(`(() => { while (true) {} })()`, 0)
+		assert.EqualError(err, "Code run timeout [line 1]")
+	})
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
+// The expressions are evaluated for every parameter of every item, so neither the parsing
+// nor the runtime setup (DateTime, the extension methods) should be paid each time:
+//   - the compiled programs are cached by the script name and code
+//   - the runtimes of the expression evaluators are pooled, frozen and reset between uses
+
+// ProgramCacheSize is the max number of the compiled programs kept in the cache
+const ProgramCacheSize = 4096
+
+type programCacheEntry struct {
+	key     string
+	program *goja.Program
+}
+
+// programCache is a LRU cache of the compiled programs, safe for concurrent use
+type programCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+func newProgramCache(capacity int) *programCache {
+	return &programCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+var compiledPrograms = newProgramCache(ProgramCacheSize)
+
+// get returns the compiled program of the code, ok is false if the code can not be compiled,
+// the caller should use RunScript then to get the same error as before
+func (c *programCache) get(name, code string) (*goja.Program, bool) {
+	key := name + "\x00" + code
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*programCacheEntry).program, true
+	}
+	c.mu.Unlock()
+
+	// compile outside of the lock, the same code compiled twice is harmless
+	program, err := goja.Compile(name, code, false)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*programCacheEntry).program, true
+	}
+	c.entries[key] = c.order.PushFront(&programCacheEntry{key: key, program: program})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*programCacheEntry).key)
+	}
+	return program, true
+}
+
+// runScript runs the code with the cached program, the result is the same as vm.RunScript(name, code)
+func runScript(vm *goja.Runtime, name, code string) (goja.Value, error) {
+	program, ok := compiledPrograms.get(name, code)
+	if !ok {
+		return vm.RunScript(name, code)
+	}
+	return vm.RunProgram(program)
+}
+
+// The built-in objects of a pooled runtime are frozen right after the setup, before the runtime is used,
+// so no evaluation can change what the later ones see, e.g. `Array.prototype.sum = ...` or `delete Math.max`
+// are ignored as on any frozen object. All the objects reachable from the global object are frozen, with the
+// prototypes only reachable from the instances, e.g. the iterator and generator prototypes and %TypedArray%.
+// Only the global object is left extensible for the globals like $json, its built-in bindings are made
+// non-configurable, and on release the globals added by the last use are deleted and the built-in bindings
+// assigned by it, e.g. `JSON = null` or the blocked globals, are restored.
+//
+// The assignment to an own property shadowing a frozen one, e.g. `err.name = 'x'` or `obj.toString = f`, is kept
+// working by turning the inherited property into an accessor whose setter defines the own property.
+//
+// The fresh runtimes of the Code node are not frozen.
+const sandboxFreezeJs = `(function (global) {
+	'use strict';
+	const { deleteProperty, defineProperty, getOwnPropertyDescriptor, getPrototypeOf, isExtensible, ownKeys } = Reflect;
+	const freeze = Object.freeze;
+	const is = Object.is;
+	const roots = [
+		global,
+		getPrototypeOf([][Symbol.iterator]()),
+		getPrototypeOf(new Map()[Symbol.iterator]()),
+		getPrototypeOf(new Set()[Symbol.iterator]()),
+		getPrototypeOf(''[Symbol.iterator]()),
+		getPrototypeOf(Int8Array),
+	];
+	const syntaxRoots = ['function* () {}', 'async function () {}', 'async function* () {}', "''.matchAll(/a/g)"];
+	for (let i = 0; i < syntaxRoots.length; i++) {
+		try {
+			roots[roots.length] = getPrototypeOf(Function('return ' + syntaxRoots[i])());
+		} catch (e) {
+			// not supported by the runtime
+		}
+	}
+
+	const seen = new Set();
+	const objects = [];
+	const queue = roots;
+	while (queue.length > 0) {
+		const obj = queue.pop();
+		if ((typeof obj !== 'object' && typeof obj !== 'function') || obj === null || seen.has(obj)) {
+			continue;
+		}
+		seen.add(obj);
+		objects[objects.length] = obj;
+		queue[queue.length] = getPrototypeOf(obj);
+		const keys = ownKeys(obj);
+		for (let j = 0; j < keys.length; j++) {
+			const d = getOwnPropertyDescriptor(obj, keys[j]);
+			if ('value' in d) {
+				queue[queue.length] = d.value;
+			} else {
+				queue[queue.length] = d.get;
+				queue[queue.length] = d.set;
+			}
+		}
+	}
+
+	const shadowed = ['constructor', 'name', 'message', 'toString', 'valueOf', 'toLocaleString'];
+	const tame = function (obj, key, d) {
+		const value = d.value;
+		const accessor = {
+			get() {
+				return value;
+			},
+			set(newValue) {
+				if (this !== obj && (typeof this === 'object' || typeof this === 'function') && this !== null) {
+					defineProperty(this, key, { value: newValue, writable: true, enumerable: true, configurable: true });
+				}
+			},
+		};
+		freeze(accessor.get);
+		freeze(accessor.set);
+		defineProperty(obj, key, { get: accessor.get, set: accessor.set, enumerable: d.enumerable, configurable: false });
+	};
+	for (let i = 0; i < objects.length; i++) {
+		const obj = objects[i];
+		if (obj === global) {
+			continue;
+		}
+		if (typeof obj === 'object') {
+			for (let j = 0; j < shadowed.length; j++) {
+				const d = getOwnPropertyDescriptor(obj, shadowed[j]);
+				if (d !== undefined && 'value' in d && d.writable && d.configurable) {
+					tame(obj, shadowed[j], d);
+				}
+			}
+		}
+		freeze(obj);
+	}
+
+	const globalPrototype = getPrototypeOf(global);
+	const globalKeys = new Set();
+	const bindingKeys = [];
+	const bindingValues = [];
+	const keys = ownKeys(global);
+	for (let i = 0; i < keys.length; i++) {
+		const d = getOwnPropertyDescriptor(global, keys[i]);
+		globalKeys.add(keys[i]);
+		if ('value' in d && d.writable) {
+			bindingKeys[bindingKeys.length] = keys[i];
+			bindingValues[bindingValues.length] = d.value;
+		}
+		if (d.configurable) {
+			defineProperty(global, keys[i], { configurable: false });
+		}
+	}
+
+	// reset deletes the globals added since the snapshot and restores the built-in bindings,
+	// it returns false if the global object can not be reset
+	return function reset() {
+		if (!isExtensible(global) || getPrototypeOf(global) !== globalPrototype) {
+			return false;
+		}
+		const keys = ownKeys(global);
+		for (let i = 0; i < keys.length; i++) {
+			if (!globalKeys.has(keys[i]) && !deleteProperty(global, keys[i])) {
+				return false;
+			}
+		}
+		for (let i = 0; i < bindingKeys.length; i++) {
+			const d = getOwnPropertyDescriptor(global, bindingKeys[i]);
+			if (!d.writable) {
+				return false;
+			}
+			if (!is(d.value, bindingValues[i]) && !defineProperty(global, bindingKeys[i], { value: bindingValues[i] })) {
+				return false;
+			}
+		}
+		return true;
+	};
+})(globalThis)`
+
+var sandboxFreezeProgram = goja.MustCompile("sandbox_freeze.js", sandboxFreezeJs, true)
+
+// pooledRuntime is a runtime with DateTime and the extension methods ready
+type pooledRuntime struct {
+	vm       *goja.Runtime
+	modules  *sandboxModules
+	dateTime *dateTimeLib
+	reset    goja.Callable
+}
+
+// newPooledRuntime sets up the runtime and freezes the built-in objects,
+// the runtime is still usable if the freeze fails, but it never goes back to the pool
+func newPooledRuntime() *pooledRuntime {
+	vm, modules := newGoja()
+	r := &pooledRuntime{vm: vm, modules: modules, dateTime: setupDateTimeLib(vm, nil)}
+	if err := setupExpressionExtensions(vm); err != nil {
+		Errorf("Failed to setup the expression extensions: %v", err)
+		return r
+	}
+	if err := r.freeze(); err != nil {
+		Errorf("Failed to freeze the sandbox runtime: %v", err)
+		r.reset = nil
+	}
+	return r
+}
+
+func (r *pooledRuntime) freeze() error {
+	value, err := r.vm.RunProgram(sandboxFreezeProgram)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if r.reset, ok = goja.AssertFunction(value); !ok {
+		return fmt.Errorf("reset must be a function")
+	}
+	return nil
+}
+
+var runtimePool = sync.Pool{
+	New: func() interface{} {
+		return newPooledRuntime()
+	},
+}
+
+// release resets the globals of the runtime and puts it back to the pool, or drops it if they can not be reset
+func (r *pooledRuntime) release() {
+	if r.reset == nil {
+		return
+	}
+	r.vm.ClearInterrupt()
+	ok, err := r.reset(goja.Undefined())
+	if err != nil || !ok.ToBoolean() {
+		Debugf("Sandbox runtime is dropped as the globals can not be reset: %v", err)
+		return
+	}
+	runtimePool.Put(r)
+}
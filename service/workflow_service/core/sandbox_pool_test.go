@@ -0,0 +1,238 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/sandbox_pool_test.go
+// go test -run ^$ -bench . -benchmem service/workflow_service/core/init_test.go service/workflow_service/core/sandbox_pool_test.go
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func evaluateWithPooledRuntime(expression string) (interface{}, error) {
+	eval := core.NewExpressionEvaluator(&core.SandboxContext{
+		Items: structs.NodeData{{"json": map[string]interface{}{"a": 1}}},
+	})
+	defer eval.Release()
+	return eval.EvaluateExpression(expression, 0)
+}
+
+func TestSandboxPool(t *testing.T) {
+
+	t.Run("Pooled runtime does not leak globals", func(t *testing.T) {
+		assert := require.New(t)
+
+		res, err := evaluateWithPooledRuntime(`={{ (globalThis.leaked = $json.a, JSON = null, leaked) }}`)
+		assert.Nil(err)
+		assert.Equal(int64(1), res)
+
+		for i := 0; i < 10; i++ {
+			res, err = evaluateWithPooledRuntime(`={{ typeof leaked + ' ' + typeof JSON.stringify }}`)
+			assert.Nil(err)
+			assert.Equal("undefined function", res)
+		}
+	})
+
+	t.Run("Pooled runtime does not leak changed prototypes", func(t *testing.T) {
+		assert := require.New(t)
+
+		res, err := evaluateWithPooledRuntime(`={{ (String.prototype.isEmpty = () => 'hacked', DateTime.now = null, 'x') }}`)
+		assert.Nil(err)
+		assert.Equal("x", res)
+
+		for i := 0; i < 10; i++ {
+			res, err = evaluateWithPooledRuntime(`={{ ''.isEmpty() && DateTime.isDateTime(DateTime.now()) }}`)
+			assert.Nil(err)
+			assert.Equal(true, res)
+		}
+	})
+
+	t.Run("Built-in objects of pooled runtimes are frozen", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := evaluateWithPooledRuntime(`={{ Object.defineProperty(Array.prototype, 'total', {
+			value: function () { return this.reduce((a, b) => a + b, 0) }, configurable: true,
+		}) }}`)
+		assert.ErrorContains(err, "TypeError")
+		res, err := evaluateWithPooledRuntime(`={{ [delete Math.max, typeof Math.max, Object.isFrozen(Array.prototype)] }}`)
+		assert.Nil(err)
+		assert.Equal([]interface{}{false, "function", true}, res)
+
+		// the own properties shadowing the frozen ones can still be assigned
+		res, err = evaluateWithPooledRuntime(`={{ (() => {
+			const error = new TypeError('x'); error.name = 'CustomError';
+			const obj = {}; obj.toString = () => 'custom';
+			return error.name + ' ' + obj + ' ' + TypeError.prototype.name + ' ' + {};
+		})() }}`)
+		assert.Nil(err)
+		assert.Equal("CustomError custom TypeError [object Object]", res)
+	})
+
+	t.Run("Pooled runtime does not leak the intrinsics reachable from the instances", func(t *testing.T) {
+		assert := require.New(t)
+
+		res, err := evaluateWithPooledRuntime(`={{ (() => {
+			Object.getPrototypeOf([][Symbol.iterator]()).next = () => ({ done: true });
+			WeakMap.prototype.get = () => 'pwn';
+			TypeError.prototype.name = 'PWN';
+			Object.getPrototypeOf(Int8Array).prototype.join = () => 'pwn';
+			return 'x';
+		})() }}`)
+		assert.Nil(err)
+		assert.Equal("x", res)
+
+		for i := 0; i < 10; i++ {
+			res, err = evaluateWithPooledRuntime(`={{ (() => {
+				const key = {};
+				return [[...[1, 2]].length, new WeakMap([[key, 1]]).get(key), new TypeError('x').name, new Int8Array([1, 2]).join()];
+			})() }}`)
+			assert.Nil(err)
+			assert.Equal([]interface{}{int64(2), int64(1), "TypeError", "1,2"}, res)
+		}
+	})
+
+	t.Run("Pooled runtime keeps the key sets and attributes of built-in objects", func(t *testing.T) {
+		assert := require.New(t)
+
+		// the same number of keys, but an accessor is deleted
+		res, err := evaluateWithPooledRuntime(`={{ (delete Map.prototype.size, Map.prototype.x = 1, 'x') }}`)
+		assert.Nil(err)
+		assert.Equal("x", res)
+		// the same value, but enumerable
+		res, err = evaluateWithPooledRuntime(
+			`={{ (Reflect.defineProperty(Object.prototype, 'toString', {enumerable: true}), 'x') }}`)
+		assert.Nil(err)
+		assert.Equal("x", res)
+
+		for i := 0; i < 10; i++ {
+			res, err = evaluateWithPooledRuntime(`={{ [new Map([[1, 2]]).size, Map.prototype.x, Object.keys(Object.prototype).length,
+				Object.defineProperty.name, Object.defineProperty({}, 'a', {value: 1}).a] }}`)
+			assert.Nil(err)
+			assert.Equal([]interface{}{int64(1), nil, int64(0), "defineProperty", int64(1)}, res)
+		}
+	})
+
+	t.Run("Pooled runtime of unwrapped code is dropped", func(t *testing.T) {
+		assert := require.New(t)
+
+		for i := 0; i < 3; i++ {
+			res, err := evaluateWithPooledRuntime("={{ // comment\nlet declared = $json.a; declared }}")
+			assert.Nil(err)
+			assert.Equal(int64(1), res)
+		}
+	})
+
+	t.Run("Pooled runtime uses the timezone of the context", func(t *testing.T) {
+		assert := require.New(t)
+
+		for _, timezone := range []string{"Asia/Tokyo", "America/New_York", "Asia/Tokyo"} {
+			eval := core.NewExpressionEvaluator(&core.SandboxContext{Timezone: timezone})
+			res, err := eval.EvaluateExpression(`={{ $now.zoneName }}`, 0)
+			eval.Release()
+			assert.Nil(err)
+			assert.Equal(timezone, res)
+		}
+	})
+
+	t.Run("Node results are looked up lazily", func(t *testing.T) {
+		assert := require.New(t)
+
+		eval := core.NewExpressionEvaluator(&core.SandboxContext{
+			Items: structs.NodeData{{"json": map[string]interface{}{"a": 1}}},
+			RunData: map[string][]*structs.WorkflowExecutionTaskData{
+				"Node1": {{Data: map[string][]structs.NodeData{
+					"main": {{{"json": map[string]interface{}{"b": 2}}}},
+				}}},
+			},
+		})
+		defer eval.Release()
+
+		res, err := eval.EvaluateExpression(`={{ $('Node1').item.json.b }}`, 0)
+		assert.Nil(err)
+		assert.Equal(int64(2), res)
+
+		res, err = eval.EvaluateExpression(`={{ $('NotExist') === null }}`, 0)
+		assert.Nil(err)
+		assert.Equal(true, res)
+	})
+}
+
+// the n8n Set like node resolves every parameter of every item
+func newBenchmarkInput(itemCount int) *structs.NodeExecuteInput {
+	items := make(structs.NodeData, itemCount)
+	for i := range items {
+		items[i] = structs.NodeSingleData{"json": map[string]interface{}{
+			"name":  fmt.Sprintf("user %d", i),
+			"score": i,
+		}}
+	}
+	runData := make(map[string][]*structs.WorkflowExecutionTaskData)
+	for i := 0; i < 20; i++ {
+		runData[fmt.Sprintf("Node%d", i)] = []*structs.WorkflowExecutionTaskData{
+			{Data: map[string][]structs.NodeData{"main": {items}}},
+		}
+	}
+	return &structs.NodeExecuteInput{
+		Data:   []structs.NodeData{items},
+		Params: &structs.WorkflowNode{Name: "Set", Parameters: map[string]interface{}{}},
+		RunExecutionData: &structs.WorkflowRunExecutionData{
+			ResultData: &structs.WorkflowRunExecutionResultData{RunData: runData},
+		},
+	}
+}
+
+const benchmarkExpression = `={{ $json.name.toUpperCase() }} {{ $json.score * 2 }} {{ $('Node3').item.json.name }}`
+
+const benchmarkItemCount = 5000
+
+// BenchmarkExpressionFreshRuntime is the cost of a new runtime per item, as before the pool
+func BenchmarkExpressionFreshRuntime(b *testing.B) {
+	input := newBenchmarkInput(benchmarkItemCount)
+	items := input.Data[0]
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := range items {
+			sandbox := core.Sandbox{
+				Context: &core.SandboxContext{Items: items, RunData: input.RunExecutionData.ResultData.RunData},
+			}
+			sandbox.Initialize()
+			eval := core.ExpressionEvaluator{Sandbox: &sandbox}
+			if _, err := eval.EvaluateExpression(benchmarkExpression, i); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkExpressionPooledRuntime(b *testing.B) {
+	input := newBenchmarkInput(benchmarkItemCount)
+	items := input.Data[0]
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := range items {
+			eval := core.NewExpressionEvaluator(&core.SandboxContext{
+				Items: items, RunData: input.RunExecutionData.ResultData.RunData,
+			})
+			if _, err := eval.EvaluateExpression(benchmarkExpression, i); err != nil {
+				b.Fatal(err)
+			}
+			eval.Release()
+		}
+	}
+}
+
+func BenchmarkGetParameterValue(b *testing.B) {
+	input := newBenchmarkInput(benchmarkItemCount)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < benchmarkItemCount; i++ {
+			if _, err := core.GetParameterValue(benchmarkExpression, "value", input, i, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
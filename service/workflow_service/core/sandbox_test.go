@@ -794,6 +794,100 @@ func TestSandboxContext(t *testing.T) {
 
 	})
 
+	t.Run("Sandbox context setup built in values", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		sandboxContext := &core.SandboxContext{
+			Items: structs.NodeData{
+				{"json": map[string]interface{}{"a": 1}},
+				{"json": map[string]interface{}{"a": 2}},
+			},
+			RunData: map[string][]*structs.WorkflowExecutionTaskData{
+				"Webhook": {{}, {}},
+			},
+		}
+		sandboxContext.SetupExecutionInfo(&structs.NodeExecuteInput{
+			Workflow: &structs.WorkflowEntity{ID: "workflow-1", Name: "My Workflow", Active: true},
+			Params:   &structs.WorkflowNode{Name: "Code"},
+			RunIndex: 1,
+			Mode:     structs.WorkflowExecutionMode_Webhook,
+			AdditionalData: &structs.WorkflowExecuteAdditionalData{
+				Hooks:     structs.WorkflowHooks{ExecutionId: "42"},
+				Variables: map[string]interface{}{"region": "us-east-1"},
+			},
+			RunExecutionData: &structs.WorkflowRunExecutionData{
+				ExecutionData: &structs.WorkflowRunExecutionExecutionData{
+					WaitingExecutionSource: map[string][]structs.ExecutionSourceData{
+						"Code": {{PreviousNode: "Webhook", PreviousNodeOutput: 0}},
+					},
+				},
+			},
+		})
+		sandbox := core.Sandbox{
+			Context: sandboxContext,
+		}
+		sandbox.Initialize()
+
+		res, err := sandbox.RunCode(`$workflow.id + "|" + $workflow.name + "|" + $workflow.active`, 1)
+		assert.Nil(err)
+		assert.Equal("workflow-1|My Workflow|true", res)
+
+		res, err = sandbox.RunCode(`$execution.id + "|" + $execution.mode`, 1)
+		assert.Nil(err)
+		assert.Equal("42|webhook", res)
+
+		res, err = sandbox.RunCode(`[$prevNode.name, $prevNode.outputIndex, $prevNode.runIndex]`, 1)
+		assert.Nil(err)
+		assert.Equal([]interface{}{"Webhook", int64(0), int64(1)}, res)
+
+		res, err = sandbox.RunCode(`[$runIndex, $itemIndex, $position]`, 1)
+		assert.Nil(err)
+		assert.Equal([]interface{}{int64(1), int64(1), int64(1)}, res)
+
+		res, err = sandbox.RunCode(`$vars.region`, 1)
+		assert.Nil(err)
+		assert.Equal("us-east-1", res)
+
+		// the variables are never globals
+		res, err = sandbox.RunCode(`typeof region`, 1)
+		assert.Nil(err)
+		assert.Equal("undefined", res)
+
+		res, err = sandbox.RunCode(`DateTime.isDateTime($now) && $today <= $now`, 1)
+		assert.Nil(err)
+		assert.Equal(true, res)
+
+		res, err = sandbox.RunCode(`typeof $env`, 1)
+		assert.Nil(err)
+		assert.Equal("object", res)
+	})
+
+	t.Run("Sandbox execution custom data", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		resultData := &structs.WorkflowRunExecutionResultData{}
+		sandboxContext := &core.SandboxContext{}
+		sandboxContext.SetupExecutionInfo(&structs.NodeExecuteInput{
+			Params:           &structs.WorkflowNode{Name: "Code"},
+			RunExecutionData: &structs.WorkflowRunExecutionData{ResultData: resultData},
+		})
+		sandbox := core.Sandbox{
+			Context: sandboxContext,
+		}
+		sandbox.Initialize()
+
+		_, err := sandbox.RunCode(`($execution.customData.set("orderId", 12345), $execution.customData.setAll({customerId: "c-1"}))`, 0)
+		assert.Nil(err)
+		assert.Equal(map[string]string{"orderId": "12345", "customerId": "c-1"}, resultData.MetaData)
+
+		res, err := sandbox.RunCode(`$execution.customData.get("orderId") + "|" + $execution.customData.getAll().customerId`, 0)
+		assert.Nil(err)
+		assert.Equal("12345|c-1", res)
+
+		_, err = sandbox.RunCode(`$execution.customData.set("order id", "1")`, 0)
+		assert.NotNil(err)
+	})
+
 }
 
 func TestSandboxIsolation(t *testing.T) {
@@ -61,12 +61,7 @@ func StandardizeJavaScriptObject(item map[string]interface{}) map[string]interfa
 		case map[string]interface{}:
 			item[key] = StandardizeJavaScriptObject(v)
 		case []interface{}:
-			for i, val := range v {
-				switch val.(type) {
-				case map[string]interface{}:
-					v[i] = StandardizeJavaScriptObject(val.(map[string]interface{}))
-				}
-			}
+			item[key] = standardizeJavaScriptArray(v)
 		case func(), goja.FunctionCall, func(goja.FunctionCall) goja.Value: // see test `Sandbox run code with return obj has func inside` in sandbox_test.go
 			delete(item, key)
 
@@ -76,12 +71,31 @@ func StandardizeJavaScriptObject(item map[string]interface{}) map[string]interfa
 			delete(item, key)
 		}
 
+		// DateTime, Duration and Interval to ISO string
+		if isoValue, ok := standardizeDateTimeValue(value); ok {
+			item[key] = isoValue
+		}
+
 		// TODO: stringify Date, RegExp if needed
 	}
 
 	return item
 }
 
+func standardizeJavaScriptArray(items []interface{}) []interface{} {
+	for i, val := range items {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			items[i] = StandardizeJavaScriptObject(v)
+		case []interface{}:
+			items[i] = standardizeJavaScriptArray(v)
+		default:
+			items[i], _ = standardizeDateTimeValue(v)
+		}
+	}
+	return items
+}
+
 /**
  * Stringify any non-standard JS objects (e.g. `Date`, `RegExp`) inside output items at any depth.
  * remove fn, undefined, null
@@ -113,10 +127,12 @@ func getSandboxContextFromInput(input *structs.NodeExecuteInput) *SandboxContext
 	if input.RunExecutionData != nil && input.RunExecutionData.ResultData != nil && input.RunExecutionData.ResultData.RunData != nil {
 		runData = input.RunExecutionData.ResultData.RunData
 	}
-	return &SandboxContext{
+	sc := &SandboxContext{
 		Items:     GetInputData(input.Data),
 		Params:    input.Params.Parameters,
 		Functions: BuiltInFunctions,
 		RunData:   runData,
 	}
+	sc.SetupExecutionInfo(input)
+	return sc
 }
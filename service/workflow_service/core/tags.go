@@ -0,0 +1,201 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// TagNameMaxLength is the column size of workflow.tag_entity.name
+const TagNameMaxLength = 24
+
+var (
+	ErrTagNotFound   = errors.New("no such tag")
+	ErrTagNameExists = errors.New("tag name already exists")
+)
+
+// ValidateTagName trims the tag name and checks its length
+func ValidateTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > TagNameMaxLength {
+		return "", fmt.Errorf("tag name must be 1 to %d characters", TagNameMaxLength)
+	}
+	return name, nil
+}
+
+// List the tags of the org with the number of workflows using each tag.
+func ListTags(ctx context.Context, orgId string) ([]structs.WorkflowTag, error) {
+	tags_RdsDbLib, err := rdsDbQueries.ListTagEntities(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]structs.WorkflowTag, 0, len(tags_RdsDbLib))
+	for _, tag_RdsDbLib := range tags_RdsDbLib {
+		tag := structs.ToWorkflowTag(rdsDbLib.WorkflowTagEntity{
+			Name:       tag_RdsDbLib.Name,
+			CreatedAt:  tag_RdsDbLib.CreatedAt,
+			UpdatedAt:  tag_RdsDbLib.UpdatedAt,
+			ID:         tag_RdsDbLib.ID,
+			SugerOrgId: tag_RdsDbLib.SugerOrgId,
+		})
+		usageCount := tag_RdsDbLib.UsageCount
+		tag.UsageCount = &usageCount
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func CreateTag(ctx context.Context, orgId string, name string) (*structs.WorkflowTag, error) {
+	name, err := ValidateTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	tag_RdsDbLib, err := rdsDbQueries.CreateTagEntity(ctx, rdsDbLib.CreateTagEntityParams{
+		Name:       name,
+		ID:         uuid.NewString(),
+		SugerOrgId: orgId,
+	})
+	if err != nil {
+		if shared.IsDuplicateKeyError(err) {
+			return nil, ErrTagNameExists
+		}
+		return nil, err
+	}
+	tag := structs.ToWorkflowTag(tag_RdsDbLib)
+	return &tag, nil
+}
+
+// Rename the tag, the workflows with the tag are not changed.
+func UpdateTag(ctx context.Context, orgId string, tagId string, name string) (*structs.WorkflowTag, error) {
+	name, err := ValidateTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	tag_RdsDbLib, err := rdsDbQueries.UpdateTagEntityName(ctx, rdsDbLib.UpdateTagEntityNameParams{
+		SugerOrgId: orgId,
+		ID:         tagId,
+		Name:       name,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		if shared.IsDuplicateKeyError(err) {
+			return nil, ErrTagNameExists
+		}
+		return nil, err
+	}
+	tag := structs.ToWorkflowTag(tag_RdsDbLib)
+	return &tag, nil
+}
+
+// Delete the tag, it is removed from the workflows by the foreign key of workflow.workflows_tags.
+func DeleteTag(ctx context.Context, orgId string, tagId string) (*structs.WorkflowTag, error) {
+	tag_RdsDbLib, err := rdsDbQueries.DeleteTagEntity(ctx, rdsDbLib.DeleteTagEntityParams{
+		SugerOrgId: orgId,
+		ID:         tagId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	tag := structs.ToWorkflowTag(tag_RdsDbLib)
+	return &tag, nil
+}
+
+// LockWorkflowTags checks that all the tags belong to the org and locks them in the transaction of queries,
+// so they can not be deleted before the workflow is saved. Returns the unique tag ids or ErrTagNotFound.
+func LockWorkflowTags(ctx context.Context, queries *rdsDbLib.Queries, orgId string, tagIds []string) ([]string, error) {
+	uniqueTagIds := make([]string, 0, len(tagIds))
+	seen := make(map[string]bool, len(tagIds))
+	for _, tagId := range tagIds {
+		if !seen[tagId] {
+			seen[tagId] = true
+			uniqueTagIds = append(uniqueTagIds, tagId)
+		}
+	}
+	if len(uniqueTagIds) == 0 {
+		return uniqueTagIds, nil
+	}
+
+	locked, err := queries.LockTagEntities(ctx, rdsDbLib.LockTagEntitiesParams{
+		SugerOrgId: orgId,
+		TagIds:     uniqueTagIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) != len(uniqueTagIds) {
+		return nil, ErrTagNotFound
+	}
+	return uniqueTagIds, nil
+}
+
+// SetWorkflowTags replaces the tags of the workflow in the transaction of queries.
+// The tags are checked as by LockWorkflowTags, which callers call before they write the workflow.
+func SetWorkflowTags(ctx context.Context, queries *rdsDbLib.Queries, orgId string, workflowId string, tagIds []string) error {
+	tagIds, err := LockWorkflowTags(ctx, queries, orgId, tagIds)
+	if err != nil {
+		return err
+	}
+	if err := queries.DeleteWorkflowTags(ctx, workflowId); err != nil {
+		return err
+	}
+	if len(tagIds) > 0 {
+		created, err := queries.CreateWorkflowTags(ctx, rdsDbLib.CreateWorkflowTagsParams{
+			WorkflowID: workflowId,
+			SugerOrgId: orgId,
+			TagIds:     tagIds,
+		})
+		if err != nil {
+			return err
+		}
+		if len(created) != len(tagIds) {
+			return ErrTagNotFound
+		}
+	}
+	return nil
+}
+
+// FillWorkflowTags sets the tags of the workflow entities with one query.
+func FillWorkflowTags(ctx context.Context, workflowEntities []structs.WorkflowEntity) error {
+	if len(workflowEntities) == 0 {
+		return nil
+	}
+	workflowIds := make([]string, 0, len(workflowEntities))
+	for _, workflowEntity := range workflowEntities {
+		workflowIds = append(workflowIds, workflowEntity.ID)
+	}
+	rows, err := rdsDbQueries.ListTagEntitiesByWorkflowIds(ctx, workflowIds)
+	if err != nil {
+		return err
+	}
+	tagsByWorkflowId := make(map[string][]interface{}, len(workflowEntities))
+	for _, row := range rows {
+		tagsByWorkflowId[row.WorkflowId] = append(tagsByWorkflowId[row.WorkflowId], structs.ToWorkflowTag(rdsDbLib.WorkflowTagEntity{
+			Name:       row.Name,
+			CreatedAt:  row.CreatedAt,
+			UpdatedAt:  row.UpdatedAt,
+			ID:         row.ID,
+			SugerOrgId: row.SugerOrgId,
+		}))
+	}
+	for i := range workflowEntities {
+		tags := tagsByWorkflowId[workflowEntities[i].ID]
+		if tags == nil {
+			tags = []interface{}{}
+		}
+		workflowEntities[i].Tags = tags
+	}
+	return nil
+}
@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 )
 
@@ -159,6 +160,94 @@ func GetMapValueByPath(m map[string]interface{}, path string) (interface{}, bool
 	return val, ok
 }
 
+// SetMapValueByPath sets the value at the path like a.b.c.
+// The missing or non-object values on the way are replaced with objects.
+func SetMapValueByPath(m map[string]interface{}, path string, value interface{}) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := m[segment].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[segment] = child
+		}
+		m = child
+	}
+	m[segments[len(segments)-1]] = value
+}
+
+// DeleteMapValueByPath deletes the value at the path like a.b.c.
+func DeleteMapValueByPath(m map[string]interface{}, path string) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := m[segment].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = child
+	}
+	delete(m, segments[len(segments)-1])
+}
+
+// GetItemFieldValue returns the value of the item field, by the path like a.b.c with the dot notation,
+// otherwise by the key.
+func GetItemFieldValue(itemJson map[string]interface{}, field string, dotNotation bool) (interface{}, bool) {
+	if dotNotation {
+		return GetMapValueByPath(itemJson, field)
+	}
+	value, ok := itemJson[field]
+	return value, ok
+}
+
+// SetItemFieldValue sets the value of the item field, by the path with the dot notation.
+func SetItemFieldValue(itemJson map[string]interface{}, field string, value interface{}, dotNotation bool) {
+	if dotNotation {
+		SetMapValueByPath(itemJson, field, value)
+		return
+	}
+	itemJson[field] = value
+}
+
+// DeleteItemFieldValue deletes the item field, by the path with the dot notation.
+func DeleteItemFieldValue(itemJson map[string]interface{}, field string, dotNotation bool) {
+	if dotNotation {
+		DeleteMapValueByPath(itemJson, field)
+		return
+	}
+	delete(itemJson, field)
+}
+
+// DeepCopyJson copies the maps and slices of the JSON value, so the copy shares none of them.
+func DeepCopyJson(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = DeepCopyJson(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for idx, child := range v {
+			result[idx] = DeepCopyJson(child)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// SplitFieldNames splits the comma-separated field names of the node parameter, without the empty ones.
+func SplitFieldNames(fields string) []string {
+	result := []string{}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
 // Unflatten the string which is stringfied using github.com/WebReflection/flatted
 func UnflattenString(target string) (string, error) {
 	// Unmarshal to json array
@@ -329,41 +418,23 @@ func GetDataFromBinaryData(binaryData *structs.WorkflowBinaryData) (string, erro
 	return string(decoded), nil
 }
 
-func CheckDataType(typ string, val interface{}) bool {
-	// TODO:
-	switch typ {
-	case "email":
-	case "number":
-		//....
-	}
-
-	return true
-}
-func CheckFormTriggerParam(params map[string]interface{}, node *structs.WorkflowNode) error {
-	parameters, err := json.Marshal(params)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-
-	var workflowFrom structs.WorkflowFrom
-	err = json.Unmarshal(parameters, &workflowFrom)
+// Validate the URL. It should not be an internal IP address or a local hostname.
+// Return an error if the URL is invalid. Otherwise, return nil.
+// It is only the early check, the client of NewPublicHttpClient checks the resolved addresses.
+func ValidateUrl(urlStr string) error {
+	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-
-	for _, field := range workflowFrom.FormFields.Values {
-
-		val, ok := params[field.FieldLabel]
-		if field.RequiredField && !ok {
-			return errors.Errorf("%s is empty", field.FieldLabel)
+	hostname := parsedURL.Hostname()
+	ip := net.ParseIP(hostname)
+	if ip != nil {
+		if IsForbiddenIp(ip) {
+			return fmt.Errorf("%s forbidden ip address", urlStr)
 		}
-
-		if !ok {
-			continue
-		}
-
-		if !CheckDataType(field.FieldLabel, val) {
-			return errors.Errorf("%s invalid value", field.FieldLabel)
+	} else {
+		if strings.HasSuffix(hostname, ".svc") || strings.Contains(hostname, "localhost") {
+			return fmt.Errorf("%s forbidden hostname", urlStr)
 		}
 	}
 
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The org scoped variables, available as $vars.KEY in the expressions and the Code node.
+// The values are cached per org, the cache is invalidated by the writes of this pod and expires after
+// VariablesCacheTTL for the writes of the other pods.
+
+const (
+	// VariablesCacheTTL is how long the variables of an organization are cached
+	VariablesCacheTTL = time.Minute
+	// VariableKeyMaxLength and VariableValueMaxLength are the column sizes of workflow.variables
+	VariableKeyMaxLength   = 50
+	VariableValueMaxLength = 255
+)
+
+var variableKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+var (
+	ErrVariableNotFound  = errors.New("no such variable")
+	ErrVariableKeyExists = errors.New("variable key already exists")
+)
+
+// ValidateVariable checks the key, type and value of the variable, the empty type is set to string
+func ValidateVariable(variable *structs.WorkflowVariable) error {
+	if !variableKeyRegexp.MatchString(variable.Key) || len(variable.Key) > VariableKeyMaxLength {
+		return fmt.Errorf("key must be 1 to %d letters, digits or underscores", VariableKeyMaxLength)
+	}
+	if len(variable.Value) > VariableValueMaxLength {
+		return fmt.Errorf("value must be at most %d characters", VariableValueMaxLength)
+	}
+	if variable.Type == "" {
+		variable.Type = structs.WorkflowVariableType_String
+	}
+	switch variable.Type {
+	case structs.WorkflowVariableType_String, structs.WorkflowVariableType_Secret:
+		return nil
+	case structs.WorkflowVariableType_Number, structs.WorkflowVariableType_Boolean:
+		_, err := variableValue(*variable)
+		return err
+	default:
+		return fmt.Errorf("invalid variable type %s", variable.Type)
+	}
+}
+
+// variableValue converts the stored string to the value of the variable type
+func variableValue(variable structs.WorkflowVariable) (interface{}, error) {
+	switch variable.Type {
+	case structs.WorkflowVariableType_Number:
+		value, err := strconv.ParseFloat(strings.TrimSpace(variable.Value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s is not a number", variable.Key)
+		}
+		return value, nil
+	case structs.WorkflowVariableType_Boolean:
+		value, err := strconv.ParseBool(strings.TrimSpace(variable.Value))
+		if err != nil {
+			return nil, fmt.Errorf("value of %s is not a boolean", variable.Key)
+		}
+		return value, nil
+	default:
+		return variable.Value, nil
+	}
+}
+
+func ListVariables(ctx context.Context, orgId string) ([]structs.WorkflowVariable, error) {
+	variables_RdsDbLib, err := rdsDbQueries.ListVariables(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	variables := make([]structs.WorkflowVariable, 0, len(variables_RdsDbLib))
+	for _, variable_RdsDbLib := range variables_RdsDbLib {
+		variables = append(variables, structs.ToWorkflowVariable(variable_RdsDbLib))
+	}
+	return variables, nil
+}
+
+// Get the variable by orgId and variableId.
+// If the variable does not exist, return an error.
+func GetVariable(ctx context.Context, orgId string, variableId string) (*structs.WorkflowVariable, error) {
+	variable_RdsDbLib, err := rdsDbQueries.GetVariable(ctx, rdsDbLib.GetVariableParams{
+		SugerOrgId: orgId,
+		ID:         variableId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVariableNotFound
+		}
+		return nil, err
+	}
+	variable := structs.ToWorkflowVariable(variable_RdsDbLib)
+	return &variable, nil
+}
+
+func CreateVariable(ctx context.Context, orgId string, variable structs.WorkflowVariable) (*structs.WorkflowVariable, error) {
+	if err := ValidateVariable(&variable); err != nil {
+		return nil, err
+	}
+	variable_RdsDbLib, err := rdsDbQueries.CreateVariable(ctx, rdsDbLib.CreateVariableParams{
+		Key:        variable.Key,
+		Type:       string(variable.Type),
+		Value:      sql.NullString{String: variable.Value, Valid: true},
+		ID:         uuid.NewString(),
+		SugerOrgId: orgId,
+	})
+	if err != nil {
+		if shared.IsDuplicateKeyError(err) {
+			return nil, ErrVariableKeyExists
+		}
+		return nil, err
+	}
+	InvalidateVariablesCache(orgId)
+	result := structs.ToWorkflowVariable(variable_RdsDbLib)
+	return &result, nil
+}
+
+// UpdateVariable replaces the key, type and value of the variable.
+// The value of a secret is kept if the masked value is sent back.
+func UpdateVariable(ctx context.Context, orgId string, variableId string, variable structs.WorkflowVariable) (*structs.WorkflowVariable, error) {
+	if variable.Type == structs.WorkflowVariableType_Secret && variable.Value == structs.WorkflowVariableSecretMask {
+		existing, err := GetVariable(ctx, orgId, variableId)
+		if err != nil {
+			return nil, err
+		}
+		variable.Value = existing.Value
+	}
+	if err := ValidateVariable(&variable); err != nil {
+		return nil, err
+	}
+	variable_RdsDbLib, err := rdsDbQueries.UpdateVariable(ctx, rdsDbLib.UpdateVariableParams{
+		SugerOrgId: orgId,
+		ID:         variableId,
+		Key:        variable.Key,
+		Type:       string(variable.Type),
+		Value:      sql.NullString{String: variable.Value, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVariableNotFound
+		}
+		if shared.IsDuplicateKeyError(err) {
+			return nil, ErrVariableKeyExists
+		}
+		return nil, err
+	}
+	InvalidateVariablesCache(orgId)
+	result := structs.ToWorkflowVariable(variable_RdsDbLib)
+	return &result, nil
+}
+
+func DeleteVariable(ctx context.Context, orgId string, variableId string) (*structs.WorkflowVariable, error) {
+	variable_RdsDbLib, err := rdsDbQueries.DeleteVariable(ctx, rdsDbLib.DeleteVariableParams{
+		SugerOrgId: orgId,
+		ID:         variableId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVariableNotFound
+		}
+		return nil, err
+	}
+	InvalidateVariablesCache(orgId)
+	variable := structs.ToWorkflowVariable(variable_RdsDbLib)
+	return &variable, nil
+}
+
+type variablesCacheEntry struct {
+	values   map[string]interface{}
+	expireAt time.Time
+}
+
+var variablesCache sync.Map
+
+// InvalidateVariablesCache drops the cached variables of the organization
+func InvalidateVariablesCache(orgId string) {
+	variablesCache.Delete(orgId)
+}
+
+// GetVariableValues returns the typed values of the variables of the organization by key, the value of $vars.
+// The returned map is a copy, so the user code can not change the cached values.
+func GetVariableValues(ctx context.Context, orgId string) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if entry, ok := variablesCache.Load(orgId); ok && time.Now().Before(entry.(*variablesCacheEntry).expireAt) {
+		values = entry.(*variablesCacheEntry).values
+	} else {
+		variables, err := ListVariables(ctx, orgId)
+		if err != nil {
+			return nil, err
+		}
+		values = make(map[string]interface{}, len(variables))
+		for _, variable := range variables {
+			value, err := variableValue(variable)
+			if err != nil {
+				Warnf("Invalid variable %s of org %s: %v", variable.Key, orgId, err)
+				value = variable.Value
+			}
+			values[variable.Key] = value
+		}
+		variablesCache.Store(orgId, &variablesCacheEntry{
+			values:   values,
+			expireAt: time.Now().Add(VariablesCacheTTL),
+		})
+	}
+
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	return result, nil
+}
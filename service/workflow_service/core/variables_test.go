@@ -0,0 +1,98 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/variables_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestVariables(t *testing.T) {
+
+	t.Run("Validate variable", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		variable := structs.WorkflowVariable{Key: "API_HOST", Value: "https://api.example.com"}
+		assert.Nil(core.ValidateVariable(&variable))
+		assert.Equal(structs.WorkflowVariableType_String, variable.Type)
+
+		invalid := []structs.WorkflowVariable{
+			{Key: "", Value: "a"},
+			{Key: "api-host", Value: "a"},
+			{Key: "COUNT", Type: structs.WorkflowVariableType_Number, Value: "ten"},
+			{Key: "ENABLED", Type: structs.WorkflowVariableType_Boolean, Value: "maybe"},
+			{Key: "OBJECT", Type: "object", Value: "{}"},
+		}
+		for _, variable := range invalid {
+			assert.NotNil(core.ValidateVariable(&variable), variable.Key)
+		}
+	})
+
+	t.Run("Variables of the org are $vars", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		orgId := uuid.NewString()[:8]
+
+		for _, variable := range []structs.WorkflowVariable{
+			{Key: "API_HOST", Value: "https://api.example.com"},
+			{Key: "RETRIES", Type: structs.WorkflowVariableType_Number, Value: "3"},
+			{Key: "ENABLED", Type: structs.WorkflowVariableType_Boolean, Value: "true"},
+			{Key: "TOKEN", Type: structs.WorkflowVariableType_Secret, Value: "s3cret"},
+		} {
+			_, err := core.CreateVariable(ctx, orgId, variable)
+			assert.Nil(err)
+		}
+		_, err := core.CreateVariable(ctx, orgId, structs.WorkflowVariable{Key: "API_HOST", Value: "x"})
+		assert.ErrorIs(err, core.ErrVariableKeyExists)
+
+		sandboxContext := &core.SandboxContext{}
+		sandboxContext.SetupExecutionInfo(&structs.NodeExecuteInput{
+			Workflow: &structs.WorkflowEntity{ID: "wf", SugerOrgId: orgId},
+		})
+		eval := core.NewExpressionEvaluator(sandboxContext)
+		res, err := eval.EvaluateExpression(`={{ $vars.API_HOST }}/{{ $vars.RETRIES + 1 }}/{{ $vars.ENABLED === true }}/{{ $vars.TOKEN }}`, 0)
+		eval.Release()
+		assert.Nil(err)
+		assert.Equal("https://api.example.com/4/true/s3cret", res)
+
+		eval = core.NewExpressionEvaluator(sandboxContext)
+		res, err = eval.EvaluateExpression(`={{ typeof API_HOST }}`, 0)
+		eval.Release()
+		assert.Nil(err)
+		assert.Equal("undefined", res)
+
+		// the writes invalidate the cache
+		variables, err := core.ListVariables(ctx, orgId)
+		assert.Nil(err)
+		assert.Len(variables, 4)
+		_, err = core.UpdateVariable(ctx, orgId, variables[0].ID, structs.WorkflowVariable{Key: "API_HOST", Value: "https://staging.example.com"})
+		assert.Nil(err)
+		values, err := core.GetVariableValues(ctx, orgId)
+		assert.Nil(err)
+		assert.Equal("https://staging.example.com", values["API_HOST"])
+
+		// the user code can not change the cached values
+		values["API_HOST"] = "changed"
+		values, err = core.GetVariableValues(ctx, orgId)
+		assert.Nil(err)
+		assert.Equal("https://staging.example.com", values["API_HOST"])
+
+		for _, variable := range variables {
+			_, err = core.DeleteVariable(ctx, orgId, variable.ID)
+			assert.Nil(err)
+		}
+		values, err = core.GetVariableValues(ctx, orgId)
+		assert.Nil(err)
+		assert.Empty(values)
+		_, err = core.GetVariable(ctx, orgId, variables[0].ID)
+		assert.ErrorIs(err, core.ErrVariableNotFound)
+	})
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	sharedLog "github.com/sugerio/workflow-service-trial/shared/log"
+)
+
+// The node puts the execution to wait by setting WaitTill, e.g. the Approval node, and the execution is
+// saved with the waiting status and its node execution stack. The execution is resumed early by its node,
+// e.g. when the approval is decided, or by the wait tracker of any pod once WaitTill is passed. The
+// execution is claimed before it is resumed, so it is resumed only once.
+
+const (
+	waitTrackerPollInterval = 10 * time.Second
+	// The max executions resumed per poll.
+	waitTrackerBatchSize = 100
+)
+
+type waitTracker struct {
+	logger  sharedLog.Logger
+	stop    chan struct{}
+	done    chan struct{}
+	lock    sync.Mutex
+	started bool
+}
+
+var (
+	executionWaitTracker = &waitTracker{
+		logger: sharedLog.GetLogger(context.Background()),
+	}
+)
+
+// StartWaitTracker starts resuming the waiting executions whose WaitTill is passed.
+func StartWaitTracker() {
+	tracker := executionWaitTracker
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	if tracker.started {
+		return
+	}
+	tracker.stop = make(chan struct{})
+	tracker.done = make(chan struct{})
+	tracker.started = true
+	go tracker.track()
+}
+
+// StopWaitTracker stops resuming the waiting executions. The resumed ones are stopped with the active executions.
+func StopWaitTracker() {
+	tracker := executionWaitTracker
+	tracker.lock.Lock()
+	if !tracker.started {
+		tracker.lock.Unlock()
+		return
+	}
+	tracker.started = false
+	close(tracker.stop)
+	tracker.lock.Unlock()
+	<-tracker.done
+	tracker.logger.Info("Wait tracker is stopped.")
+}
+
+// ResumeDueExecutions resumes the waiting executions whose WaitTill is passed, and returns their number.
+func ResumeDueExecutions(ctx context.Context) (int, error) {
+	executionIds, err := GetRdsDbQueries().ListWaitingWorkflowExecutionEntityIds(ctx, rdsDbLib.ListWaitingWorkflowExecutionEntityIdsParams{
+		WaitTill: sql.NullTime{Time: time.Now(), Valid: true},
+		Limit:    waitTrackerBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	resumed := 0
+	for _, executionId := range executionIds {
+		_, err := ResumeWorkflowExecution(ctx, executionId)
+		if errors.Is(err, ErrExecutionNotWaiting) {
+			// Resumed by another pod.
+			continue
+		} else if err != nil {
+			executionWaitTracker.logger.Error("Failed to resume the waiting execution", "executionId", executionId, "err", err)
+			continue
+		}
+		resumed++
+	}
+	return resumed, nil
+}
+
+// track polls the due executions until the tracker is stopped.
+func (tracker *waitTracker) track() {
+	defer close(tracker.done)
+	ctx := context.Background()
+	for {
+		if _, err := ResumeDueExecutions(ctx); err != nil {
+			tracker.logger.Error("Failed to resume the waiting executions", "err", err)
+		}
+		select {
+		case <-tracker.stop:
+			return
+		case <-time.After(waitTrackerPollInterval):
+		}
+	}
+}
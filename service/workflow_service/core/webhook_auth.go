@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The requests of the Webhook and the Form Trigger nodes are checked before any execution is started:
+//  1. The client IP must match parameters.options.ipWhitelist if set, otherwise ErrWebhookForbidden.
+//  2. The request must carry the credential of parameters.authentication: the basic auth user and password,
+//     the header name and value, or the bearer JWT verified with the jwtAuth credential.
+//     ErrWebhookUnauthorized is returned if the request has no credential and ErrWebhookForbidden if it is wrong.
+//  3. The signature of the request must be valid for parameters.signatureVerification, see VerifyWebhookSignature.
+
+var (
+	ErrWebhookUnauthorized = errors.New("authorization is required")
+	ErrWebhookForbidden    = errors.New("authorization data is wrong")
+)
+
+// AuthenticateWebhookRequest checks the request of the webhook node is allowed.
+func AuthenticateWebhookRequest(
+	ctx context.Context,
+	orgId string,
+	node *structs.WorkflowNode,
+	request *fasthttp.Request,
+	clientIp string) error {
+	options, err := ParseWebhookNodeOptions(node)
+	if err != nil {
+		return err
+	}
+	if options.IpWhitelist != "" && !IsIpAllowed(clientIp, options.IpWhitelist) {
+		return fmt.Errorf("%w: IP %s is not allowed", ErrWebhookForbidden, clientIp)
+	}
+
+	if err := authenticateWebhookCredentials(ctx, orgId, node, request); err != nil {
+		return err
+	}
+	return VerifyWebhookSignature(ctx, orgId, node, request, time.Now())
+}
+
+// authenticateWebhookCredentials checks the request carries the credential of parameters.authentication.
+func authenticateWebhookCredentials(
+	ctx context.Context,
+	orgId string,
+	node *structs.WorkflowNode,
+	request *fasthttp.Request) error {
+	authentication, err := node.GetWebhookAuthentication()
+	if err != nil {
+		return err
+	}
+	switch authentication {
+	case structs.WebhookAuthentication_None, "":
+		return nil
+
+	case structs.WebhookAuthentication_BasicAuth:
+		credentials := HttpBasicAuthCredentials{}
+		if err := GetNodeCredentials(ctx, orgId, node, CredentialsType_HttpBasicAuth, &credentials); err != nil {
+			return err
+		}
+		authorization := string(request.Header.Peek(fasthttp.HeaderAuthorization))
+		if authorization == "" {
+			return ErrWebhookUnauthorized
+		}
+		user, password, ok := parseBasicAuth(authorization)
+		if !ok || !secureEqual(user, credentials.User) || !secureEqual(password, credentials.Password) {
+			return ErrWebhookForbidden
+		}
+		return nil
+
+	case structs.WebhookAuthentication_HeaderAuth:
+		credentials := HttpHeaderAuthCredentials{}
+		if err := GetNodeCredentials(ctx, orgId, node, CredentialsType_HttpHeaderAuth, &credentials); err != nil {
+			return err
+		}
+		if credentials.Name == "" {
+			return fmt.Errorf("the header name of the credential is empty")
+		}
+		value := request.Header.Peek(credentials.Name)
+		if value == nil {
+			return ErrWebhookUnauthorized
+		}
+		if !secureEqual(string(value), credentials.Value) {
+			return ErrWebhookForbidden
+		}
+		return nil
+
+	case structs.WebhookAuthentication_JwtAuth:
+		credentials := JwtAuthCredentials{}
+		if err := GetNodeCredentials(ctx, orgId, node, CredentialsType_JwtAuth, &credentials); err != nil {
+			return err
+		}
+		token, ok := strings.CutPrefix(string(request.Header.Peek(fasthttp.HeaderAuthorization)), "Bearer ")
+		if !ok || token == "" {
+			return ErrWebhookUnauthorized
+		}
+		_, err := VerifyWebhookJwt(token, &credentials)
+		return err
+
+	default:
+		return fmt.Errorf("unknown authentication: %s", authentication)
+	}
+}
+
+// VerifyWebhookJwt verifies the signature, the expiration and the required claims of the JWT.
+// Returns ErrWebhookForbidden if the JWT is invalid.
+func VerifyWebhookJwt(token string, credentials *JwtAuthCredentials) (jwt.MapClaims, error) {
+	algorithm := credentials.Algorithm
+	if algorithm == "" {
+		algorithm = jwt.SigningMethodHS256.Alg()
+	}
+	var key interface{}
+	switch jwt.GetSigningMethod(algorithm).(type) {
+	case *jwt.SigningMethodHMAC:
+		if credentials.Secret == "" {
+			return nil, fmt.Errorf("the secret of the credential is empty")
+		}
+		key = []byte(credentials.Secret)
+	case *jwt.SigningMethodRSA:
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(credentials.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of the credential: %w", err)
+		}
+		key = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", algorithm)
+	}
+
+	parserOptions := []jwt.ParserOption{jwt.WithValidMethods([]string{algorithm})}
+	if credentials.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(credentials.Issuer))
+	}
+	if credentials.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(credentials.Audience))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, parserOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebhookForbidden, err)
+	}
+	for name, value := range credentials.Claims {
+		if fmt.Sprint(claims[name]) != fmt.Sprint(value) {
+			return nil, fmt.Errorf("%w: claim %s is not %v", ErrWebhookForbidden, name, value)
+		}
+	}
+	return claims, nil
+}
+
+// SignWebhookJwt signs the claims with the secret or the private key of the credential.
+func SignWebhookJwt(claims jwt.MapClaims, credentials *JwtAuthCredentials) (string, error) {
+	algorithm := credentials.Algorithm
+	if algorithm == "" {
+		algorithm = jwt.SigningMethodHS256.Alg()
+	}
+	var key interface{}
+	method := jwt.GetSigningMethod(algorithm)
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if credentials.Secret == "" {
+			return "", fmt.Errorf("the secret of the credential is empty")
+		}
+		key = []byte(credentials.Secret)
+	case *jwt.SigningMethodRSA:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+		if err != nil {
+			return "", fmt.Errorf("invalid private key of the credential: %w", err)
+		}
+		key = privateKey
+	default:
+		return "", fmt.Errorf("unsupported JWT algorithm: %s", algorithm)
+	}
+	return jwt.NewWithClaims(method, claims).SignedString(key)
+}
+
+// IsIpAllowed returns whether the IP matches any of the comma separated IPs or CIDRs.
+// The invalid entries match no IP.
+func IsIpAllowed(ip string, whitelist string) bool {
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil {
+		return false
+	}
+	for _, entry := range strings.Split(whitelist, ",") {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if _, ipNet, err := net.ParseCIDR(entry); err == nil && ipNet.Contains(parsedIp) {
+				return true
+			}
+		} else if allowedIp := net.ParseIP(entry); allowedIp != nil && allowedIp.Equal(parsedIp) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseBasicAuth(authorization string) (string, string, bool) {
+	encoded, ok := strings.CutPrefix(authorization, "Basic ")
+	if !ok {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// secureEqual compares the secrets in constant time.
+func secureEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
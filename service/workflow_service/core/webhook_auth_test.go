@@ -0,0 +1,188 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_auth_test.go
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// Create the credential of the org and return its id.
+func createCredentials_Testing(assert *require.Assertions, orgId string, credentialsType string, data interface{}) string {
+	dataJson, err := json.Marshal(data)
+	assert.Nil(err)
+	credentials, err := rdsDbQueries.CreateCredentialsEntity(context.Background(), rdsDbLib.CreateCredentialsEntityParams{
+		Name:        credentialsType,
+		Data:        string(dataJson),
+		Type:        credentialsType,
+		NodesAccess: json.RawMessage("[]"),
+		ID:          uuid.NewString(),
+		SugerOrgId:  orgId,
+	})
+	assert.Nil(err)
+	return credentials.ID
+}
+
+func webhookRequest_Testing(headers map[string]string) *fasthttp.Request {
+	request := &fasthttp.Request{}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	return request
+}
+
+func TestWebhookAuth(t *testing.T) {
+
+	t.Run("IP allowlist", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		whitelist := "203.0.113.7, 10.0.0.0/8,2001:db8::/32,invalid"
+		assert.True(core.IsIpAllowed("203.0.113.7", whitelist))
+		assert.True(core.IsIpAllowed("10.1.2.3", whitelist))
+		assert.True(core.IsIpAllowed("2001:db8::1", whitelist))
+		assert.False(core.IsIpAllowed("203.0.113.8", whitelist))
+		assert.False(core.IsIpAllowed("", whitelist))
+
+		node := &structs.WorkflowNode{
+			Name:       "Webhook",
+			Parameters: map[string]interface{}{"options": map[string]interface{}{"ipWhitelist": "10.0.0.0/8"}},
+		}
+		request := webhookRequest_Testing(nil)
+		assert.Nil(core.AuthenticateWebhookRequest(context.Background(), "org", node, request, "10.1.2.3"))
+		err := core.AuthenticateWebhookRequest(context.Background(), "org", node, request, "192.168.1.1")
+		assert.ErrorIs(err, core.ErrWebhookForbidden)
+	})
+
+	t.Run("Basic auth and header auth", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		orgId := uuid.NewString()[:8]
+
+		basicAuthId := createCredentials_Testing(assert, orgId, core.CredentialsType_HttpBasicAuth,
+			core.HttpBasicAuthCredentials{User: "stripe", Password: "s3cret"})
+		node := &structs.WorkflowNode{
+			Name:        "Webhook",
+			Parameters:  map[string]interface{}{"authentication": "basicAuth"},
+			Credentials: map[string]structs.WorkflowNodeCredentialsDetails{"httpBasicAuth": {ID: basicAuthId, Name: "Basic"}},
+		}
+		basic := func(userPassword string) map[string]string {
+			return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(userPassword))}
+		}
+		assert.Nil(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(basic("stripe:s3cret")), ""))
+		assert.ErrorIs(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(nil), ""), core.ErrWebhookUnauthorized)
+		assert.ErrorIs(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(basic("stripe:wrong")), ""), core.ErrWebhookForbidden)
+
+		// The credential of another org can not be used.
+		err := core.AuthenticateWebhookRequest(ctx, "other-org", node, webhookRequest_Testing(basic("stripe:s3cret")), "")
+		assert.ErrorIs(err, core.ErrCredentialsNotFound)
+
+		headerAuthId := createCredentials_Testing(assert, orgId, core.CredentialsType_HttpHeaderAuth,
+			core.HttpHeaderAuthCredentials{Name: "X-Webhook-Token", Value: "t0ken"})
+		node = &structs.WorkflowNode{
+			Name:        "Webhook",
+			Parameters:  map[string]interface{}{"authentication": "headerAuth"},
+			Credentials: map[string]structs.WorkflowNodeCredentialsDetails{"httpHeaderAuth": {ID: headerAuthId, Name: "Header"}},
+		}
+		assert.Nil(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(map[string]string{"X-Webhook-Token": "t0ken"}), ""))
+		assert.ErrorIs(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(nil), ""), core.ErrWebhookUnauthorized)
+		assert.ErrorIs(core.AuthenticateWebhookRequest(ctx, orgId, node,
+			webhookRequest_Testing(map[string]string{"X-Webhook-Token": "wrong"}), ""), core.ErrWebhookForbidden)
+
+		// The node without the credential is misconfigured.
+		node.Credentials = nil
+		assert.ErrorIs(core.AuthenticateWebhookRequest(ctx, orgId, node, webhookRequest_Testing(nil), ""), core.ErrCredentialsNotFound)
+	})
+
+	t.Run("JWT auth", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		// HS256 with the issuer and the custom claims.
+		credentials := &core.JwtAuthCredentials{Secret: "s3cret", Issuer: "billing", Claims: map[string]interface{}{"tenant": "acme"}}
+		sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+			token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+			assert.Nil(err)
+			return token
+		}
+		exp := time.Now().Add(time.Hour).Unix()
+		claims, err := core.VerifyWebhookJwt(
+			sign(jwt.SigningMethodHS256, []byte("s3cret"), jwt.MapClaims{"iss": "billing", "tenant": "acme", "exp": exp}), credentials)
+		assert.Nil(err)
+		assert.Equal("acme", claims["tenant"])
+		for _, token := range []string{
+			sign(jwt.SigningMethodHS256, []byte("wrong"), jwt.MapClaims{"iss": "billing", "tenant": "acme"}),
+			sign(jwt.SigningMethodHS256, []byte("s3cret"), jwt.MapClaims{"iss": "other", "tenant": "acme"}),
+			sign(jwt.SigningMethodHS256, []byte("s3cret"), jwt.MapClaims{"iss": "billing", "tenant": "other"}),
+			sign(jwt.SigningMethodHS256, []byte("s3cret"), jwt.MapClaims{"iss": "billing", "tenant": "acme", "exp": time.Now().Add(-time.Hour).Unix()}),
+			sign(jwt.SigningMethodHS512, []byte("s3cret"), jwt.MapClaims{"iss": "billing", "tenant": "acme"}),
+			"not-a-jwt",
+		} {
+			_, err = core.VerifyWebhookJwt(token, credentials)
+			assert.ErrorIs(err, core.ErrWebhookForbidden, token)
+		}
+
+		// RS256 with the public key.
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(err)
+		publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		assert.Nil(err)
+		credentials = &core.JwtAuthCredentials{
+			Algorithm: "RS256",
+			PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		}
+		_, err = core.VerifyWebhookJwt(sign(jwt.SigningMethodRS256, privateKey, jwt.MapClaims{"sub": "partner"}), credentials)
+		assert.Nil(err)
+		// The HS256 token signed with the public key is rejected.
+		_, err = core.VerifyWebhookJwt(sign(jwt.SigningMethodHS256, []byte(credentials.PublicKey), jwt.MapClaims{"sub": "partner"}), credentials)
+		assert.ErrorIs(err, core.ErrWebhookForbidden)
+	})
+
+	t.Run("Sign JWT", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		credentials := &core.JwtAuthCredentials{Secret: "s3cret"}
+		token, err := core.SignWebhookJwt(jwt.MapClaims{"sub": "customer"}, credentials)
+		assert.Nil(err)
+		claims, err := core.VerifyWebhookJwt(token, credentials)
+		assert.Nil(err)
+		assert.Equal("customer", claims["sub"])
+
+		// RS256 with the private key.
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(err)
+		publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		assert.Nil(err)
+		credentials = &core.JwtAuthCredentials{
+			Algorithm:  "RS256",
+			PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})),
+		}
+		token, err = core.SignWebhookJwt(jwt.MapClaims{"sub": "partner"}, credentials)
+		assert.Nil(err)
+		_, err = core.VerifyWebhookJwt(token, credentials)
+		assert.Nil(err)
+
+		_, err = core.SignWebhookJwt(jwt.MapClaims{}, &core.JwtAuthCredentials{})
+		assert.EqualError(err, "the secret of the credential is empty")
+	})
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The body of the webhook request is parsed by its content type, the same as the n8n Webhook node:
+//   - application/json and */*+json: the JSON value, or the raw string if the JSON is invalid.
+//   - application/x-www-form-urlencoded: the fields, the repeated fields are arrays.
+//   - multipart/form-data: the fields as above, the files are put into the item binary keyed by the field name,
+//     or by options.binaryPropertyName with the index of the file if set.
+//   - XML: the object converted from the XML if options.parseXml, otherwise the raw string.
+//   - text/*: the raw string.
+//   - Others or options.binaryData except multipart: the body is put into the item binary of options.binaryPropertyName.
+// If options.rawBody, the raw body is also put into the item binary of options.binaryPropertyName.
+
+const defaultWebhookBinaryPropertyName = "data"
+
+// WebhookOutput is the json of the item output by the Webhook node.
+type WebhookOutput struct {
+	Headers  map[string]string `json:"headers"`
+	Params   map[string]string `json:"params"`
+	Query    map[string]string `json:"query"`
+	Body     interface{}       `json:"body"`
+	ClientIp string            `json:"clientIp"`
+}
+
+// GenerateWebhookOutput returns the json and the binary data of the item output by the Webhook node for the request.
+func GenerateWebhookOutput(
+	request *fasthttp.Request,
+	params map[string]string,
+	clientIp string,
+	options *WebhookNodeOptions) (WebhookOutput, map[string]structs.WorkflowBinaryData, error) {
+	headers := make(map[string]string)
+	request.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+
+	query := make(map[string]string)
+	request.URI().QueryArgs().VisitAll(func(key, value []byte) {
+		query[string(key)] = string(value)
+	})
+
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	body, binary, err := ParseWebhookRequestBody(request, options)
+	if err != nil {
+		return WebhookOutput{}, nil, err
+	}
+
+	return WebhookOutput{
+		Headers:  headers,
+		Params:   params,
+		Query:    query,
+		Body:     body,
+		ClientIp: clientIp,
+	}, binary, nil
+}
+
+// ParseWebhookRequestBody parses the body of the webhook request and returns the body and the binary data of the item.
+func ParseWebhookRequestBody(
+	request *fasthttp.Request,
+	options *WebhookNodeOptions) (interface{}, map[string]structs.WorkflowBinaryData, error) {
+	binaryPropertyName := options.BinaryPropertyName
+	if binaryPropertyName == "" {
+		binaryPropertyName = defaultWebhookBinaryPropertyName
+	}
+	binary := map[string]structs.WorkflowBinaryData{}
+	rawBody := request.Body()
+	contentType := string(request.Header.ContentType())
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+
+	if (options.BinaryData && mediaType != "multipart/form-data") ||
+		(len(rawBody) > 0 && !isParsableWebhookMediaType(mediaType)) {
+		binary[binaryPropertyName] = NewBinaryData(rawBody, contentType, webhookRequestFileName(request))
+		return map[string]interface{}{}, binary, nil
+	}
+	if options.RawBody {
+		binary[binaryPropertyName] = NewBinaryData(rawBody, contentType, webhookRequestFileName(request))
+	}
+
+	if mediaType == "multipart/form-data" {
+		form, err := request.MultipartForm()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		if err := addWebhookMultipartFiles(form, options.BinaryPropertyName, binary); err != nil {
+			return nil, nil, err
+		}
+		return webhookFormFields(form.Value), binary, nil
+	}
+	if len(rawBody) == 0 {
+		return map[string]interface{}{}, binary, nil
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var body interface{}
+		if err := json.Unmarshal(rawBody, &body); err != nil {
+			return string(rawBody), binary, nil
+		}
+		return body, binary, nil
+
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(rawBody))
+		if err != nil {
+			return string(rawBody), binary, nil
+		}
+		return webhookFormFields(values), binary, nil
+
+	case isXmlMediaType(mediaType) && options.ParseXml:
+		body, err := parseXmlBody(rawBody)
+		if err != nil {
+			return string(rawBody), binary, nil
+		}
+		return body, binary, nil
+
+	default:
+		return string(rawBody), binary, nil
+	}
+}
+
+// isParsableWebhookMediaType returns whether the body of the media type is not put into the item binary.
+// The body without the content type is parsed as a string.
+func isParsableWebhookMediaType(mediaType string) bool {
+	return mediaType == "" ||
+		mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/x-www-form-urlencoded" ||
+		mediaType == "multipart/form-data" ||
+		isXmlMediaType(mediaType) ||
+		strings.HasPrefix(mediaType, "text/")
+}
+
+func isXmlMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// webhookRequestFileName returns the file name in the Content-Disposition header of the request.
+func webhookRequestFileName(request *fasthttp.Request) string {
+	_, params, err := mime.ParseMediaType(string(request.Header.Peek(fasthttp.HeaderContentDisposition)))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
+
+// webhookFormFields converts the form values, the single values are strings and the repeated ones are arrays.
+func webhookFormFields(values map[string][]string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if len(value) == 1 {
+			fields[name] = value[0]
+		} else {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// addWebhookMultipartFiles puts the uploaded files into the binary. The files are keyed by the field name,
+// with the index if the field has multiple files, or by the binaryPropertyName prefix and the index of the file if set.
+func addWebhookMultipartFiles(
+	form *multipart.Form,
+	binaryPropertyName string,
+	binary map[string]structs.WorkflowBinaryData) error {
+	fieldNames := make([]string, 0, len(form.File))
+	for name := range form.File {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	count := 0
+	for _, fieldName := range fieldNames {
+		files := form.File[fieldName]
+		for index, fileHeader := range files {
+			content, err := readMultipartFile(fileHeader)
+			if err != nil {
+				return err
+			}
+			key := fieldName
+			if binaryPropertyName != "" {
+				key = fmt.Sprintf("%s%d", binaryPropertyName, count)
+			} else if len(files) > 1 {
+				key = fmt.Sprintf("%s%d", fieldName, index)
+			}
+			binary[key] = NewBinaryData(content, fileHeader.Header.Get("Content-Type"), fileHeader.Filename)
+			count++
+		}
+	}
+	return nil
+}
+
+func readMultipartFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the uploaded file %s: %w", fileHeader.Filename, err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// parseXmlBody converts the XML into an object keyed by the lower case root tag.
+// The attributes are merged into the element, the repeated child elements are arrays,
+// and the element with only text is the trimmed text, otherwise the text is in the "_" key.
+func parseXmlBody(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeXmlElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{strings.ToLower(start.Name.Local): value}, nil
+		}
+	}
+}
+
+func decodeXmlElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		element[attr.Name.Local] = attr.Value
+	}
+	text := strings.Builder{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXmlElement(decoder, token)
+			if err != nil {
+				return nil, err
+			}
+			name := strings.ToLower(token.Name.Local)
+			switch existing := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(existing, child)
+			default:
+				element[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				element["_"] = trimmed
+			}
+			return element, nil
+		}
+	}
+}
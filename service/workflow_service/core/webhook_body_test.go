@@ -0,0 +1,148 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_body_test.go
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+)
+
+func bodyRequest_Testing(contentType string, body []byte) *fasthttp.Request {
+	request := &fasthttp.Request{}
+	request.Header.SetMethod(fasthttp.MethodPost)
+	if contentType != "" {
+		request.Header.SetContentType(contentType)
+	}
+	request.SetBody(body)
+	return request
+}
+
+func TestWebhookBody(t *testing.T) {
+
+	t.Run("JSON, form and text bodies", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		options := &core.WebhookNodeOptions{}
+
+		body, binary, err := core.ParseWebhookRequestBody(
+			bodyRequest_Testing("application/json; charset=utf-8", []byte(`{"id":"evt_1","amount":12.5,"tags":["a"]}`)), options)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{"id": "evt_1", "amount": 12.5, "tags": []interface{}{"a"}}, body)
+		assert.Empty(binary)
+
+		// The invalid JSON is kept as the raw string.
+		body, _, err = core.ParseWebhookRequestBody(bodyRequest_Testing("application/json", []byte(`{"id":`)), options)
+		assert.Nil(err)
+		assert.Equal(`{"id":`, body)
+
+		body, _, err = core.ParseWebhookRequestBody(
+			bodyRequest_Testing("application/x-www-form-urlencoded", []byte("name=Suger&tag=a&tag=b")), options)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{"name": "Suger", "tag": []string{"a", "b"}}, body)
+
+		body, _, err = core.ParseWebhookRequestBody(bodyRequest_Testing("text/plain", []byte("hello")), options)
+		assert.Nil(err)
+		assert.Equal("hello", body)
+
+		body, binary, err = core.ParseWebhookRequestBody(bodyRequest_Testing("application/json", nil), options)
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{}, body)
+		assert.Empty(binary)
+	})
+
+	t.Run("XML body", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		xml := []byte(`<?xml version="1.0"?><Order id="42"><Item>a</Item><Item>b</Item><Note lang="en">urgent</Note></Order>`)
+
+		body, _, err := core.ParseWebhookRequestBody(bodyRequest_Testing("application/xml", xml), &core.WebhookNodeOptions{})
+		assert.Nil(err)
+		assert.Equal(string(xml), body)
+
+		body, _, err = core.ParseWebhookRequestBody(bodyRequest_Testing("application/xml", xml), &core.WebhookNodeOptions{ParseXml: true})
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{
+			"order": map[string]interface{}{
+				"id":   "42",
+				"item": []interface{}{"a", "b"},
+				"note": map[string]interface{}{"lang": "en", "_": "urgent"},
+			},
+		}, body)
+	})
+
+	t.Run("Multipart fields and files", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		content := &bytes.Buffer{}
+		writer := multipart.NewWriter(content)
+		assert.Nil(writer.WriteField("name", "Suger"))
+		invoice, err := writer.CreateFormFile("invoice", "invoice.pdf")
+		assert.Nil(err)
+		_, err = invoice.Write([]byte("%PDF-1.4"))
+		assert.Nil(err)
+		for _, fileName := range []string{"a.txt", "b.txt"} {
+			attachment, err := writer.CreateFormFile("attachments", fileName)
+			assert.Nil(err)
+			_, err = attachment.Write([]byte(fileName))
+			assert.Nil(err)
+		}
+		assert.Nil(writer.Close())
+
+		body, binary, err := core.ParseWebhookRequestBody(
+			bodyRequest_Testing(writer.FormDataContentType(), content.Bytes()), &core.WebhookNodeOptions{})
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{"name": "Suger"}, body)
+		assert.Len(binary, 3)
+		assert.Equal("invoice.pdf", binary["invoice"].FileName)
+		assert.Equal("pdf", binary["invoice"].FileExtension)
+		assert.Equal(base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")), binary["invoice"].Data)
+		assert.Equal("a.txt", binary["attachments0"].FileName)
+		assert.Equal("b.txt", binary["attachments1"].FileName)
+
+		// The files are numbered after the binary property name.
+		_, binary, err = core.ParseWebhookRequestBody(
+			bodyRequest_Testing(writer.FormDataContentType(), content.Bytes()), &core.WebhookNodeOptions{BinaryPropertyName: "file"})
+		assert.Nil(err)
+		assert.Equal("a.txt", binary["file0"].FileName)
+		assert.Equal("b.txt", binary["file1"].FileName)
+		assert.Equal("invoice.pdf", binary["file2"].FileName)
+	})
+
+	t.Run("Binary and raw bodies", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		request := bodyRequest_Testing("image/png", []byte{0x89, 'P', 'N', 'G'})
+		request.Header.Set(fasthttp.HeaderContentDisposition, `attachment; filename="logo.png"`)
+		body, binary, err := core.ParseWebhookRequestBody(request, &core.WebhookNodeOptions{})
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{}, body)
+		assert.Equal("image/png", binary["data"].MimeType)
+		assert.Equal("image", string(binary["data"].FileType))
+		assert.Equal("logo.png", binary["data"].FileName)
+		assert.Equal(base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'}), binary["data"].Data)
+
+		// The binary data option keeps the JSON body as the binary.
+		body, binary, err = core.ParseWebhookRequestBody(bodyRequest_Testing("application/json", []byte(`{"id":1}`)),
+			&core.WebhookNodeOptions{BinaryData: true, BinaryPropertyName: "payload"})
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{}, body)
+		assert.Equal(base64.StdEncoding.EncodeToString([]byte(`{"id":1}`)), binary["payload"].Data)
+
+		// The raw body option keeps both.
+		body, binary, err = core.ParseWebhookRequestBody(bodyRequest_Testing("application/json", []byte(`{"id":1}`)),
+			&core.WebhookNodeOptions{RawBody: true})
+		assert.Nil(err)
+		assert.Equal(map[string]interface{}{"id": float64(1)}, body)
+		assert.Equal(base64.StdEncoding.EncodeToString([]byte(`{"id":1}`)), binary["data"].Data)
+	})
+}
@@ -18,12 +18,22 @@ type (
 	WebhookNodeOptions struct {
 		NoResponseBody     bool                  `json:"noResponseBody,omitempty"`
 		RawBody            bool                  `json:"rawBody,omitempty"`
+		BinaryData         bool                  `json:"binaryData,omitempty"`
+		ParseXml           bool                  `json:"parseXml,omitempty"`
 		ResponseData       string                `json:"responseData,omitempty"`
 		BinaryPropertyName string                `json:"binaryPropertyName,omitempty"`
 		ResponseHeaders    ResponseHeadersOption `json:"responseHeaders,omitempty"`
 		// for responseMode:lastNode
 		ResponseContentType  string `json:"responseContentType,omitempty"`
 		ResponsePropertyName string `json:"responsePropertyName,omitempty"`
+		// The comma separated IPs or CIDRs allowed to call the webhook, all are allowed if empty.
+		IpWhitelist string `json:"ipWhitelist,omitempty"`
+		// The header name, or the expression over the output item, of the key to deduplicate the deliveries.
+		IdempotencyKey string `json:"idempotencyKey,omitempty"`
+		// The seconds to keep the idempotency key, default 86400
+		IdempotencyTtl int `json:"idempotencyTtl,omitempty"`
+		// for responseMode:lastNode and responseNode, the seconds to wait for the response, default 25
+		ResponseTimeout int `json:"responseTimeout,omitempty"`
 	}
 
 	ResponseHeadersOption struct {
@@ -60,16 +70,22 @@ func GetWorkflowWebhooks(workflowEntity *structs.WorkflowEntity, isTest bool) []
 				continue
 			}
 
+			// The webhook path is the webhookId by default, or the custom path of the Webhook node.
+			// The invalid custom path is rejected by CheckWebhookPaths.
+			path := getWebhookPath(node.WebhookId, isTest)
+			if nodePath, err := GetWebhookNodePath(&node); err == nil && nodePath != "" {
+				path = getOrgWebhookPath(workflowEntity.SugerOrgId, nodePath, isTest)
+			}
+
 			// If the node is a webhook, add it to the result.
 			results = append(
 				results,
 				structs.WebhookData{
-					HttpMethod: getWebhookMethod(&node),
-					Node:       node.Name,
-					NodeType:   node.Type,
-					NodeId:     node.ID,
-					// The webhook path is the webhookId by default
-					Path:                            getWebhookPath(node.WebhookId, isTest),
+					HttpMethod:                      getWebhookMethod(&node),
+					Node:                            node.Name,
+					NodeType:                        node.Type,
+					NodeId:                          node.ID,
+					Path:                            path,
 					WorkflowId:                      workflowEntity.ID,
 					WebhookId:                       node.WebhookId,
 					WorkflowExecutionAdditionalData: structs.WorkflowExecuteAdditionalData{},
@@ -160,12 +176,18 @@ func SaveWebhookEntity(
 		return rdsDbLib.WorkflowWebhookEntity{}, errors.New("webhookData is nil")
 	}
 
+	// Only the custom path has the pathLength, so that it is resolved by FindWebhookEntityByPath.
+	pathLength := sql.NullInt32{}
+	if webhookData.Path != getWebhookPath(webhookData.WebhookId, webhookData.IsTest) {
+		pathLength = sql.NullInt32{Int32: int32(webhookPathLength(webhookData.Path)), Valid: true}
+	}
 	return GetRdsDbQueries().CreateWebhookEntity(
 		ctx,
 		rdsDbLib.CreateWebhookEntityParams{
 			WebhookPath: webhookData.Path,
 			Method:      webhookData.HttpMethod,
 			WebhookId:   sql.NullString{String: webhookData.WebhookId, Valid: true},
+			PathLength:  pathLength,
 			Node:        webhookData.Node,
 			WorkflowId:  webhookData.WorkflowId,
 		})
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The Webhook node with options.idempotencyKey deduplicates the deliveries retried by the provider.
+// The key is the value of the header, or of the expression over the output item if it starts with "=",
+// e.g. ={{ $json.body.id }}. The first delivery claims the key in workflow.webhook_idempotency_key for
+// options.idempotencyTtl seconds, and its execution and response are stored with the key. A repeated delivery
+// does not start an execution: the stored response is returned, or 200 with the execution id if the first
+// delivery is not responded yet. The key of the delivery responded with 5xx, or without a started or queued
+// execution, e.g. rejected with 429, is released, so the retry runs again.
+
+const (
+	DefaultWebhookIdempotencyTtl = 24 * 60 * 60
+
+	// The execution metadata key of the idempotency key
+	ExecutionMetadataKey_IdempotencyKey = "idempotencyKey"
+
+	// The longer key is stored as its SHA-256 hash
+	webhookIdempotencyKeyMaxLength = 255
+)
+
+var ErrInvalidWebhookIdempotencyKey = errors.New("invalid webhook idempotency key")
+
+// WebhookIdempotencyKey is the idempotency key of a webhook delivery.
+type WebhookIdempotencyKey struct {
+	WorkflowId string
+	NodeId     string
+	Key        string
+	Ttl        time.Duration
+}
+
+// GetWebhookIdempotencyKey returns the idempotency key of the webhook request.
+// Returns nil if options.idempotencyKey is not set or its value is empty for the request.
+func GetWebhookIdempotencyKey(
+	workflowEntity *structs.WorkflowEntity,
+	webhookNode *structs.WorkflowNode,
+	request *fasthttp.Request,
+	params map[string]string,
+	clientIp string,
+	options *WebhookNodeOptions) (*WebhookIdempotencyKey, error) {
+	setting := strings.TrimSpace(options.IdempotencyKey)
+	if setting == "" {
+		return nil, nil
+	}
+
+	value := ""
+	if strings.HasPrefix(setting, "=") {
+		output, _, err := GenerateWebhookOutput(request, params, clientIp, options)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookIdempotencyKey, err)
+		}
+		evaluator := NewExpressionEvaluator(&SandboxContext{
+			Items:    structs.NodeData{{"json": output}},
+			Workflow: workflowEntity,
+		})
+		defer evaluator.Release()
+		result, err := evaluator.EvaluateExpression(setting, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookIdempotencyKey, err)
+		}
+		value = ExecutionCustomDataValue(result)
+	} else {
+		value = string(request.Header.Peek(setting))
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if len(value) > webhookIdempotencyKeyMaxLength {
+		hash := sha256.Sum256([]byte(value))
+		value = "sha256:" + hex.EncodeToString(hash[:])
+	}
+
+	ttl := options.IdempotencyTtl
+	if ttl <= 0 {
+		ttl = DefaultWebhookIdempotencyTtl
+	}
+	return &WebhookIdempotencyKey{
+		WorkflowId: workflowEntity.ID,
+		NodeId:     webhookNode.ID,
+		Key:        value,
+		Ttl:        time.Duration(ttl) * time.Second,
+	}, nil
+}
+
+// ClaimWebhookIdempotencyKey claims the key for its ttl, the expired keys of the workflow are deleted first.
+// Returns false with the stored key if it is claimed by a previous delivery.
+func ClaimWebhookIdempotencyKey(
+	ctx context.Context,
+	key *WebhookIdempotencyKey) (*rdsDbLib.WorkflowWebhookIdempotencyKey, bool, error) {
+	queries := GetRdsDbQueries()
+	if err := queries.DeleteExpiredWebhookIdempotencyKeys(ctx, key.WorkflowId); err != nil {
+		return nil, false, err
+	}
+
+	claimed, err := queries.ClaimWebhookIdempotencyKey(ctx, rdsDbLib.ClaimWebhookIdempotencyKeyParams{
+		WorkflowId: key.WorkflowId,
+		NodeId:     key.NodeId,
+		Key:        key.Key,
+		ExpiresAt:  time.Now().Add(key.Ttl),
+	})
+	if err == nil {
+		return &claimed, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	existing, err := queries.GetWebhookIdempotencyKey(ctx, rdsDbLib.GetWebhookIdempotencyKeyParams{
+		WorkflowId: key.WorkflowId,
+		NodeId:     key.NodeId,
+		Key:        key.Key,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// RecordWebhookIdempotencyKeyExecution saves the execution started by the delivery with the key,
+// and the key to the execution metadata for tracing.
+func RecordWebhookIdempotencyKeyExecution(ctx context.Context, key *WebhookIdempotencyKey, executionId string) error {
+	err := GetRdsDbQueries().UpdateWebhookIdempotencyKeyExecutionId(ctx, rdsDbLib.UpdateWebhookIdempotencyKeyExecutionIdParams{
+		WorkflowId:  key.WorkflowId,
+		NodeId:      key.NodeId,
+		Key:         key.Key,
+		ExecutionId: sql.NullString{String: executionId, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(executionId)
+	if err != nil {
+		return fmt.Errorf("invalid executionId %s: %w", executionId, err)
+	}
+	value := key.Key
+	if len(value) > ExecutionCustomDataValueMaxLength {
+		value = value[:ExecutionCustomDataValueMaxLength]
+	}
+	return SaveExecutionMetadata(ctx, int32(id), map[string]string{ExecutionMetadataKey_IdempotencyKey: value})
+}
+
+// SaveWebhookIdempotencyResponse saves the response of the delivery with the key to return it for the repeated ones.
+// The key is released if the response is 5xx, so that the retry of the delivery starts a new execution.
+func SaveWebhookIdempotencyResponse(
+	ctx context.Context,
+	key *WebhookIdempotencyKey,
+	statusCode int,
+	contentType string,
+	body []byte) error {
+	if statusCode >= fasthttp.StatusInternalServerError {
+		return ReleaseWebhookIdempotencyKey(ctx, key)
+	}
+	return GetRdsDbQueries().UpdateWebhookIdempotencyKeyResponse(ctx, rdsDbLib.UpdateWebhookIdempotencyKeyResponseParams{
+		WorkflowId:          key.WorkflowId,
+		NodeId:              key.NodeId,
+		Key:                 key.Key,
+		ResponseStatusCode:  sql.NullInt32{Int32: int32(statusCode), Valid: true},
+		ResponseContentType: sql.NullString{String: contentType, Valid: contentType != ""},
+		ResponseBody:        body,
+	})
+}
+
+// ReleaseWebhookIdempotencyKey deletes the claimed key, so that the next delivery with the key is not a repeated one.
+func ReleaseWebhookIdempotencyKey(ctx context.Context, key *WebhookIdempotencyKey) error {
+	return GetRdsDbQueries().DeleteWebhookIdempotencyKey(ctx, rdsDbLib.DeleteWebhookIdempotencyKeyParams{
+		WorkflowId: key.WorkflowId,
+		NodeId:     key.NodeId,
+		Key:        key.Key,
+	})
+}
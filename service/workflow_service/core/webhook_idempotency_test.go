@@ -0,0 +1,136 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_idempotency_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestWebhookIdempotency(t *testing.T) {
+
+	t.Run("Key of the header or the expression", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		workflowEntity := &structs.WorkflowEntity{ID: uuid.NewString()}
+		node := webhookNode_Testing("Webhook", http.MethodPost, "")
+		request := bodyRequest_Testing("application/json", []byte(`{"id":"evt_1","amount":12}`))
+		request.Header.Set("X-Request-Id", "req-1")
+
+		// Not set
+		key, err := core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "", &core.WebhookNodeOptions{})
+		assert.Nil(err)
+		assert.Nil(key)
+
+		key, err = core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "",
+			&core.WebhookNodeOptions{IdempotencyKey: "x-request-id"})
+		assert.Nil(err)
+		assert.Equal(&core.WebhookIdempotencyKey{
+			WorkflowId: workflowEntity.ID,
+			NodeId:     node.ID,
+			Key:        "req-1",
+			Ttl:        core.DefaultWebhookIdempotencyTtl * time.Second,
+		}, key)
+
+		key, err = core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "",
+			&core.WebhookNodeOptions{IdempotencyKey: "={{ $json.body.id }}", IdempotencyTtl: 60})
+		assert.Nil(err)
+		assert.Equal("evt_1", key.Key)
+		assert.Equal(time.Minute, key.Ttl)
+
+		// The request without the key is not deduplicated.
+		key, err = core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "",
+			&core.WebhookNodeOptions{IdempotencyKey: "={{ $json.body.eventId }}"})
+		assert.Nil(err)
+		assert.Nil(key)
+
+		// The long key is hashed.
+		request = bodyRequest_Testing("application/json", []byte(`{"id":"`+strings.Repeat("a", 300)+`"}`))
+		key, err = core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "",
+			&core.WebhookNodeOptions{IdempotencyKey: "={{ $json.body.id }}"})
+		assert.Nil(err)
+		assert.True(strings.HasPrefix(key.Key, "sha256:"))
+		assert.Len(key.Key, len("sha256:")+64)
+
+		_, err = core.GetWebhookIdempotencyKey(workflowEntity, &node, request, nil, "",
+			&core.WebhookNodeOptions{IdempotencyKey: "={{ $json.body.id. }}"})
+		assert.ErrorIs(err, core.ErrInvalidWebhookIdempotencyKey)
+	})
+
+	t.Run("Claim the key and save the response", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		workflowEntity, err := rdsDbQueries.CreateWorkflowEntity(ctx, rdsDbLib.CreateWorkflowEntityParams{
+			Name:        "idempotency",
+			Nodes:       json.RawMessage("[]"),
+			Connections: json.RawMessage("{}"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  uuid.NewString()[:8],
+		})
+		assert.Nil(err)
+		key := &core.WebhookIdempotencyKey{WorkflowId: workflowEntity.ID, NodeId: uuid.NewString(), Key: "evt_1", Ttl: time.Hour}
+
+		_, claimed, err := core.ClaimWebhookIdempotencyKey(ctx, key)
+		assert.Nil(err)
+		assert.True(claimed)
+
+		execution, err := rdsDbQueries.CreateWorkflowExecutionEntity(
+			ctx, rdsDbLib.CreateWorkflowExecutionEntityParams{WorkflowId: workflowEntity.ID})
+		assert.Nil(err)
+		executionId := strconv.Itoa(int(execution.ID))
+		assert.Nil(core.RecordWebhookIdempotencyKeyExecution(ctx, key, executionId))
+		metadata, err := core.GetExecutionMetadata(ctx, execution.ID)
+		assert.Nil(err)
+		assert.Equal("evt_1", metadata[core.ExecutionMetadataKey_IdempotencyKey])
+
+		// The repeated delivery gets the execution before the response is saved, then the response.
+		stored, claimed, err := core.ClaimWebhookIdempotencyKey(ctx, key)
+		assert.Nil(err)
+		assert.False(claimed)
+		assert.Equal(executionId, stored.ExecutionId.String)
+		assert.False(stored.ResponseStatusCode.Valid)
+
+		assert.Nil(core.SaveWebhookIdempotencyResponse(ctx, key, http.StatusCreated, "application/json", []byte(`{"ok":true}`)))
+		stored, claimed, err = core.ClaimWebhookIdempotencyKey(ctx, key)
+		assert.Nil(err)
+		assert.False(claimed)
+		assert.Equal(int32(http.StatusCreated), stored.ResponseStatusCode.Int32)
+		assert.Equal("application/json", stored.ResponseContentType.String)
+		assert.Equal([]byte(`{"ok":true}`), stored.ResponseBody)
+
+		// The key of the failed delivery is released.
+		assert.Nil(core.SaveWebhookIdempotencyResponse(ctx, key, http.StatusInternalServerError, "", nil))
+		_, claimed, err = core.ClaimWebhookIdempotencyKey(ctx, key)
+		assert.Nil(err)
+		assert.True(claimed)
+
+		// The key of the delivery which is not accepted, e.g. rejected with 429, is released.
+		assert.Nil(core.ReleaseWebhookIdempotencyKey(ctx, key))
+		_, claimed, err = core.ClaimWebhookIdempotencyKey(ctx, key)
+		assert.Nil(err)
+		assert.True(claimed)
+
+		// The expired key is claimed again.
+		expired := &core.WebhookIdempotencyKey{WorkflowId: workflowEntity.ID, NodeId: key.NodeId, Key: "evt_2", Ttl: -time.Second}
+		_, claimed, err = core.ClaimWebhookIdempotencyKey(ctx, expired)
+		assert.Nil(err)
+		assert.True(claimed)
+		_, claimed, err = core.ClaimWebhookIdempotencyKey(ctx, expired)
+		assert.Nil(err)
+		assert.True(claimed)
+	})
+}
@@ -26,6 +26,10 @@ func RegisterWebhook(ctx context.Context, workflowID string, isTest bool) error
 	if len(webhooks) == 0 {
 		return nil
 	}
+	// Register none of the webhooks if any custom path is invalid or conflicts.
+	if err := CheckWebhookPaths(ctx, workflowEntity, isTest); err != nil {
+		return err
+	}
 	for _, webhook := range webhooks {
 		// Create record in webhook_entity
 		_, err := SaveWebhookEntity(ctx, &webhook)
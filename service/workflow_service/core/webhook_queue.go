@@ -0,0 +1,393 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	sharedLog "github.com/sugerio/workflow-service-trial/shared/log"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The webhook requests of the onReceived mode are persisted in workflow.webhook_queue and answered with 202,
+// instead of starting an execution per request. The workers of every pod claim the queued requests in order
+// with FOR UPDATE SKIP LOCKED, skipping the workflows and orgs which already have the max running requests,
+// and delete the request when its execution ends. The claims of an org are serialized by the advisory lock
+// of the org, so the running requests counted by a claim include the ones just claimed by the other workers.
+// The running request keeps its heartbeat, the request of a crashed pod is claimed again once its heartbeat
+// is stale. The worker waits for the execution at most webhookQueueMaxExecutionWait. A new request is rejected
+// with ErrWebhookQueueFull if the org has MaxBacklog requests queued or running.
+
+const (
+	DefaultWebhookQueueWorkers                   = 10
+	DefaultWebhookQueueMaxBacklog                = 10000
+	DefaultWebhookQueueMaxConcurrencyPerWorkflow = 5
+	DefaultWebhookQueueMaxConcurrencyPerOrg      = 20
+
+	webhookQueuePollInterval = time.Second
+	webhookQueueStaleTimeout = 5 * time.Minute
+	// The worker stops waiting for the execution after the timeout, the execution keeps running
+	webhookQueueMaxExecutionWait = time.Hour
+	// The max number of orgs tried by a claim
+	webhookQueueMaxClaimOrgs = 100
+	// The request failed to start an execution is dropped after the attempts
+	webhookQueueMaxAttempts = 3
+)
+
+var ErrWebhookQueueFull = errors.New("too many webhook requests are queued")
+
+type (
+	WebhookQueueConfig struct {
+		Workers                   int
+		MaxBacklog                int
+		MaxConcurrencyPerWorkflow int
+		MaxConcurrencyPerOrg      int
+	}
+
+	webhookQueue struct {
+		config    WebhookQueueConfig
+		logger    sharedLog.Logger
+		notify    chan struct{}
+		stop      chan struct{}
+		waitGroup sync.WaitGroup
+		lock      sync.Mutex
+		started   bool
+	}
+)
+
+var (
+	webhookRequestQueue = &webhookQueue{
+		config: WebhookQueueConfig{}.withDefaults(),
+		logger: sharedLog.GetLogger(context.Background()),
+	}
+)
+
+// withDefaults returns the config with the default of the unset values.
+func (config WebhookQueueConfig) withDefaults() WebhookQueueConfig {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWebhookQueueWorkers
+	}
+	if config.MaxBacklog <= 0 {
+		config.MaxBacklog = DefaultWebhookQueueMaxBacklog
+	}
+	if config.MaxConcurrencyPerWorkflow <= 0 {
+		config.MaxConcurrencyPerWorkflow = DefaultWebhookQueueMaxConcurrencyPerWorkflow
+	}
+	if config.MaxConcurrencyPerOrg <= 0 {
+		config.MaxConcurrencyPerOrg = DefaultWebhookQueueMaxConcurrencyPerOrg
+	}
+	return config
+}
+
+// NewWebhookQueueConfig returns the webhook queue config of the environment.
+func NewWebhookQueueConfig(environment *structs.Environment) WebhookQueueConfig {
+	if environment == nil {
+		return WebhookQueueConfig{}.withDefaults()
+	}
+	return WebhookQueueConfig{
+		Workers:                   environment.WebhookQueue.Workers,
+		MaxBacklog:                environment.WebhookQueue.MaxBacklog,
+		MaxConcurrencyPerWorkflow: environment.WebhookQueue.MaxConcurrencyPerWorkflow,
+		MaxConcurrencyPerOrg:      environment.WebhookQueue.MaxConcurrencyPerOrg,
+	}.withDefaults()
+}
+
+// StartWebhookQueueWorkers starts the workers executing the queued webhook requests.
+func StartWebhookQueueWorkers(config WebhookQueueConfig) {
+	queue := webhookRequestQueue
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	if queue.started {
+		return
+	}
+	queue.config = config.withDefaults()
+	queue.notify = make(chan struct{}, queue.config.Workers)
+	queue.stop = make(chan struct{})
+	queue.started = true
+	for i := 0; i < queue.config.Workers; i++ {
+		queue.waitGroup.Add(1)
+		go queue.work()
+	}
+}
+
+// StopWebhookQueueWorkers stops claiming the queued requests and waits for the running ones,
+// at most 30 seconds. The requests not finished are claimed again after their heartbeat is stale.
+func StopWebhookQueueWorkers() {
+	queue := webhookRequestQueue
+	queue.lock.Lock()
+	if !queue.started {
+		queue.lock.Unlock()
+		return
+	}
+	queue.started = false
+	close(queue.stop)
+	queue.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		queue.waitGroup.Wait()
+	}()
+	select {
+	case <-done:
+		queue.logger.Info("Webhook queue workers are stopped.")
+	case <-time.After(30 * time.Second):
+		queue.logger.Info("Webhook queue workers are forced to stop after timeout.")
+	}
+}
+
+// EnqueueWebhookRequest persists the webhook request to be executed by the workers.
+// Returns ErrWebhookQueueFull if the org has the max backlog, which is counted with the index on sugerOrgId.
+// The backlog is counted under the lock of the org, so the concurrent requests never exceed it.
+func EnqueueWebhookRequest(
+	ctx context.Context,
+	workflowEntity *structs.WorkflowEntity,
+	webhookNode *structs.WorkflowNode,
+	request *fasthttp.Request,
+	params map[string]string,
+	clientIp string,
+	idempotencyKey *WebhookIdempotencyKey) (int32, error) {
+	// The request is serialized in the HTTP/1.1 format, which requires the Host header.
+	serialized := fasthttp.Request{}
+	request.CopyTo(&serialized)
+	if len(serialized.Host()) == 0 {
+		serialized.SetHost("localhost")
+	}
+	rawRequest := bytes.Buffer{}
+	if _, err := serialized.WriteTo(&rawRequest); err != nil {
+		return 0, fmt.Errorf("failed to serialize the webhook request: %w", err)
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	key := sql.NullString{}
+	if idempotencyKey != nil {
+		key = sql.NullString{String: idempotencyKey.Key, Valid: true}
+	}
+
+	queue := webhookRequestQueue
+	txQueries, tx, err := GetRdsDbQueries().BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if err := txQueries.LockWebhookQueueOrg(ctx, workflowEntity.SugerOrgId); err != nil {
+		return 0, err
+	}
+	id, err := txQueries.EnqueueWebhookRequest(ctx, rdsDbLib.EnqueueWebhookRequestParams{
+		WorkflowId:     workflowEntity.ID,
+		NodeId:         webhookNode.ID,
+		SugerOrgId:     workflowEntity.SugerOrgId,
+		Request:        rawRequest.Bytes(),
+		ClientIp:       clientIp,
+		Params:         rawParams,
+		IdempotencyKey: key,
+		MaxBacklog:     int32(queue.config.MaxBacklog),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrWebhookQueueFull
+	} else if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Wake up an idle worker of the pod.
+	select {
+	case queue.notify <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// ProcessWebhookRequest claims a queued webhook request and executes it until the execution ends.
+// Returns false if no request can be claimed.
+func ProcessWebhookRequest(ctx context.Context) (bool, error) {
+	queue := webhookRequestQueue
+	queued, err := claimWebhookRequest(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// Keep the heartbeat while the request is running.
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go func() {
+		ticker := time.NewTicker(webhookQueueStaleTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				if err := GetRdsDbQueries().UpdateWebhookRequestHeartbeat(context.Background(), queued.ID); err != nil {
+					queue.logger.Error("Failed to update the heartbeat of the webhook request", "id", queued.ID, "err", err)
+				}
+			}
+		}
+	}()
+
+	err = executeWebhookRequest(ctx, &queued)
+	if err != nil && queued.Attempts < webhookQueueMaxAttempts {
+		// Retry the request which did not start an execution.
+		if releaseErr := GetRdsDbQueries().ReleaseWebhookRequest(ctx, queued.ID); releaseErr != nil {
+			return true, releaseErr
+		}
+		return true, err
+	}
+	if deleteErr := GetRdsDbQueries().DeleteWebhookRequest(ctx, queued.ID); deleteErr != nil {
+		return true, deleteErr
+	}
+	return true, err
+}
+
+// claimWebhookRequest claims the first queued request of the orgs in order, under the advisory lock of the org.
+// Returns sql.ErrNoRows if no request can be claimed.
+func claimWebhookRequest(ctx context.Context) (rdsDbLib.WorkflowWebhookQueue, error) {
+	queue := webhookRequestQueue
+	staleBefore := sql.NullTime{Time: time.Now().Add(-webhookQueueStaleTimeout), Valid: true}
+	orgIds, err := GetRdsDbQueries().ListClaimableWebhookQueueOrgIds(ctx, rdsDbLib.ListClaimableWebhookQueueOrgIdsParams{
+		StaleBefore: staleBefore,
+		OrgLimit:    webhookQueueMaxClaimOrgs,
+	})
+	if err != nil {
+		return rdsDbLib.WorkflowWebhookQueue{}, err
+	}
+	for _, orgId := range orgIds {
+		queued, err := func() (rdsDbLib.WorkflowWebhookQueue, error) {
+			txQueries, tx, err := GetRdsDbQueries().BeginTx(ctx)
+			if err != nil {
+				return rdsDbLib.WorkflowWebhookQueue{}, err
+			}
+			defer tx.Rollback()
+			if err := txQueries.LockWebhookQueueOrg(ctx, orgId); err != nil {
+				return rdsDbLib.WorkflowWebhookQueue{}, err
+			}
+			queued, err := txQueries.ClaimWebhookRequest(ctx, rdsDbLib.ClaimWebhookRequestParams{
+				SugerOrgId:     orgId,
+				StaleBefore:    staleBefore,
+				MaxPerWorkflow: int32(queue.config.MaxConcurrencyPerWorkflow),
+				MaxPerOrg:      int32(queue.config.MaxConcurrencyPerOrg),
+			})
+			if err != nil {
+				return rdsDbLib.WorkflowWebhookQueue{}, err
+			}
+			return queued, tx.Commit()
+		}()
+		// The org has the max running requests, try the next one.
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		return queued, err
+	}
+	return rdsDbLib.WorkflowWebhookQueue{}, sql.ErrNoRows
+}
+
+// executeWebhookRequest runs the workflow from the webhook node with the queued request and waits for the end,
+// at most webhookQueueMaxExecutionWait. Returns error only if the execution is not started.
+func executeWebhookRequest(ctx context.Context, queued *rdsDbLib.WorkflowWebhookQueue) error {
+	workflowEntity, err := GetWorkflowEntityById(ctx, queued.WorkflowId)
+	if err != nil {
+		return err
+	}
+	webhookNode := workflowEntity.GetNodeById(queued.NodeId)
+	if webhookNode == nil {
+		return fmt.Errorf("no such webhook node %s in workflow %s", queued.NodeId, queued.WorkflowId)
+	}
+
+	request := &fasthttp.Request{}
+	if err := request.Read(bufio.NewReader(bytes.NewReader(queued.Request))); err != nil {
+		return fmt.Errorf("failed to read the webhook request: %w", err)
+	}
+	params := map[string]string{}
+	if err := json.Unmarshal(queued.Params, &params); err != nil {
+		return err
+	}
+
+	additionalData := GetBaseAdditionalData()
+	additionalData.HttpRequest = request
+	additionalData.HttpRequestClientIp = queued.ClientIp
+	additionalData.HttpRequestParams = params
+	executionId, executingWorkflowData, err := RunWorkflow(
+		ctx,
+		"",
+		workflowEntity,
+		additionalData,
+		structs.WorkflowExecutionMode_Webhook,
+		webhookNode)
+	if err != nil {
+		return err
+	}
+
+	logger := sharedLog.GetLogger(ctx)
+	if queued.IdempotencyKey.Valid {
+		idempotencyKey := &WebhookIdempotencyKey{
+			WorkflowId: queued.WorkflowId,
+			NodeId:     queued.NodeId,
+			Key:        queued.IdempotencyKey.String,
+		}
+		if err := RecordWebhookIdempotencyKeyExecution(ctx, idempotencyKey, executionId); err != nil {
+			logger.Error("Failed to record the idempotency key", "executionId", executionId, "err", err)
+		}
+	}
+
+	if executingWorkflowData == nil || executingWorkflowData.WorkflowExecutionRun == nil {
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, webhookQueueMaxExecutionWait)
+	defer cancel()
+	if _, err := executingWorkflowData.WorkflowExecutionRun.Wait(waitCtx, nil); errors.Is(err, context.DeadlineExceeded) {
+		logger.Info("Stop waiting for the execution of the webhook request",
+			"workflowId", queued.WorkflowId,
+			"executionId", executionId)
+	} else if err != nil {
+		logger.Error("Error in workflow",
+			"workflowId", queued.WorkflowId,
+			"executionId", executionId,
+			"err", err)
+	}
+	return nil
+}
+
+// work processes the queued requests until the queue is stopped, and polls the queue when it is idle.
+func (queue *webhookQueue) work() {
+	defer queue.waitGroup.Done()
+	ctx := context.Background()
+	for {
+		select {
+		case <-queue.stop:
+			return
+		default:
+		}
+
+		processed, err := ProcessWebhookRequest(ctx)
+		if err != nil {
+			queue.logger.Error("Failed to process the webhook request", "err", err)
+		}
+		if processed {
+			continue
+		}
+		select {
+		case <-queue.stop:
+			return
+		case <-queue.notify:
+		case <-time.After(webhookQueuePollInterval):
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_body_test.go service/workflow_service/core/webhook_queue_test.go
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func createWebhookWorkflow_Testing(assert *require.Assertions, orgId string) *structs.WorkflowEntity {
+	node := webhookNode_Testing("Webhook", http.MethodPost, "")
+	nodes, err := json.Marshal([]structs.WorkflowNode{node})
+	assert.Nil(err)
+	workflowEntity, err := rdsDbQueries.CreateWorkflowEntity(context.Background(), rdsDbLib.CreateWorkflowEntityParams{
+		Name:        "webhook queue",
+		Nodes:       nodes,
+		Connections: json.RawMessage("{}"),
+		ID:          uuid.NewString(),
+		SugerOrgId:  orgId,
+	})
+	assert.Nil(err)
+	workflow, err := structs.ToWorkflowEntity(workflowEntity)
+	assert.Nil(err)
+	return &workflow
+}
+
+func TestWebhookQueue(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	orgId := uuid.NewString()[:8]
+
+	t.Run("Backlog and concurrency caps", func(t *testing.T) {
+		first := createWebhookWorkflow_Testing(assert, orgId)
+		second := createWebhookWorkflow_Testing(assert, orgId)
+		enqueue := func(workflowEntity *structs.WorkflowEntity, maxBacklog int32) (int32, error) {
+			return rdsDbQueries.EnqueueWebhookRequest(ctx, rdsDbLib.EnqueueWebhookRequestParams{
+				WorkflowId: workflowEntity.ID,
+				NodeId:     workflowEntity.Nodes[0].ID,
+				SugerOrgId: workflowEntity.SugerOrgId,
+				Request:    []byte("POST / HTTP/1.1\r\n\r\n"),
+				Params:     json.RawMessage("{}"),
+				MaxBacklog: maxBacklog,
+			})
+		}
+		_, err := enqueue(first, 0)
+		assert.ErrorIs(err, sql.ErrNoRows)
+
+		ids := []int32{}
+		for _, workflowEntity := range []*structs.WorkflowEntity{first, first, second, second} {
+			id, err := enqueue(workflowEntity, core.DefaultWebhookQueueMaxBacklog)
+			assert.Nil(err)
+			ids = append(ids, id)
+		}
+		defer func() {
+			for _, id := range ids {
+				assert.Nil(rdsDbQueries.DeleteWebhookRequest(ctx, id))
+			}
+		}()
+
+		// The backlog is counted per org.
+		_, err = enqueue(first, int32(len(ids)))
+		assert.ErrorIs(err, sql.ErrNoRows)
+		other := createWebhookWorkflow_Testing(assert, uuid.NewString()[:8])
+		otherId, err := enqueue(other, int32(len(ids)))
+		assert.Nil(err)
+		assert.Nil(rdsDbQueries.DeleteWebhookRequest(ctx, otherId))
+
+		claim := func(staleBefore time.Time) (rdsDbLib.WorkflowWebhookQueue, error) {
+			return rdsDbQueries.ClaimWebhookRequest(ctx, rdsDbLib.ClaimWebhookRequestParams{
+				SugerOrgId:     orgId,
+				StaleBefore:    sql.NullTime{Time: staleBefore, Valid: true},
+				MaxPerWorkflow: 1,
+				MaxPerOrg:      2,
+			})
+		}
+		// One running request per workflow, the second request of the first workflow waits.
+		claimed, err := claim(time.Now().Add(-time.Minute))
+		assert.Nil(err)
+		assert.Equal(ids[0], claimed.ID)
+		assert.Equal(int32(1), claimed.Attempts)
+		claimed, err = claim(time.Now().Add(-time.Minute))
+		assert.Nil(err)
+		assert.Equal(ids[2], claimed.ID)
+		// Two running requests per org.
+		_, err = claim(time.Now().Add(-time.Minute))
+		assert.ErrorIs(err, sql.ErrNoRows)
+
+		// The released request is claimed again.
+		assert.Nil(rdsDbQueries.ReleaseWebhookRequest(ctx, ids[0]))
+		claimed, err = claim(time.Now().Add(-time.Minute))
+		assert.Nil(err)
+		assert.Equal(ids[0], claimed.ID)
+		assert.Equal(int32(2), claimed.Attempts)
+
+		// The request with the stale heartbeat is claimed again.
+		claimed, err = claim(time.Now().Add(time.Minute))
+		assert.Nil(err)
+		assert.Equal(ids[0], claimed.ID)
+	})
+
+	t.Run("Claims and enqueues of an org are serialized", func(t *testing.T) {
+		txQueries, tx, err := rdsDbQueries.BeginTx(ctx)
+		assert.Nil(err)
+		defer tx.Rollback()
+		assert.Nil(txQueries.LockWebhookQueueOrg(ctx, orgId))
+
+		// The other org is not blocked.
+		otherTxQueries, otherTx, err := rdsDbQueries.BeginTx(ctx)
+		assert.Nil(err)
+		assert.Nil(otherTxQueries.LockWebhookQueueOrg(ctx, uuid.NewString()[:8]))
+		assert.Nil(otherTx.Rollback())
+
+		// The claim of the org waits for the lock.
+		blockedTxQueries, blockedTx, err := rdsDbQueries.BeginTx(ctx)
+		assert.Nil(err)
+		defer blockedTx.Rollback()
+		timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		assert.NotNil(blockedTxQueries.LockWebhookQueueOrg(timeoutCtx, orgId))
+
+		// The enqueue of the org waits for the lock as well, so the backlog is counted by one at a time.
+		workflowEntity := createWebhookWorkflow_Testing(assert, orgId)
+		enqueueCtx, cancelEnqueue := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancelEnqueue()
+		_, err = core.EnqueueWebhookRequest(enqueueCtx, workflowEntity, &workflowEntity.Nodes[0],
+			bodyRequest_Testing("application/json", []byte(`{}`)), nil, "", nil)
+		assert.NotNil(err)
+	})
+
+	t.Run("Process the queued request", func(t *testing.T) {
+		workflowEntity := createWebhookWorkflow_Testing(assert, orgId)
+		webhookNode := &workflowEntity.Nodes[0]
+		request := bodyRequest_Testing("application/json", []byte(`{"id":"evt_1"}`))
+		request.SetRequestURI("/workflow/public/webhook/" + orgId + "/orders/42?source=test")
+		idempotencyKey := &core.WebhookIdempotencyKey{
+			WorkflowId: workflowEntity.ID, NodeId: webhookNode.ID, Key: "evt_1", Ttl: time.Hour}
+		_, claimed, err := core.ClaimWebhookIdempotencyKey(ctx, idempotencyKey)
+		assert.Nil(err)
+		assert.True(claimed)
+
+		_, err = core.EnqueueWebhookRequest(
+			ctx, workflowEntity, webhookNode, request, map[string]string{"orderId": "42"}, "10.0.0.1", idempotencyKey)
+		assert.Nil(err)
+		count, err := rdsDbQueries.CountWebhookRequestsByWorkflowId(ctx, workflowEntity.ID)
+		assert.Nil(err)
+		assert.Equal(int64(1), count)
+
+		for {
+			processed, err := core.ProcessWebhookRequest(ctx)
+			assert.Nil(err)
+			if !processed {
+				break
+			}
+		}
+		count, err = rdsDbQueries.CountWebhookRequestsByWorkflowId(ctx, workflowEntity.ID)
+		assert.Nil(err)
+		assert.Equal(int64(0), count)
+
+		executionEntities, err := rdsDbQueries.ListWorkflowExecutionEntitiesByWorkflowId(
+			ctx, rdsDbLib.ListWorkflowExecutionEntitiesByWorkflowIdParams{WorkflowId: workflowEntity.ID, Limit: 10})
+		assert.Nil(err)
+		assert.Len(executionEntities, 1)
+		execution, err := core.GetWorkflowExecution(ctx, executionEntities[0].ID)
+		assert.Nil(err)
+		output, err := json.Marshal(execution.Data.ResultData.RunData["Webhook"][0].Data["main"][0][0]["json"])
+		assert.Nil(err)
+		item := core.WebhookOutput{}
+		assert.Nil(json.Unmarshal(output, &item))
+		assert.Equal(map[string]interface{}{"id": "evt_1"}, item.Body)
+		assert.Equal(map[string]string{"orderId": "42"}, item.Params)
+		assert.Equal(map[string]string{"source": "test"}, item.Query)
+		assert.Equal("10.0.0.1", item.ClientIp)
+
+		metadata, err := core.GetExecutionMetadata(ctx, executionEntities[0].ID)
+		assert.Nil(err)
+		assert.Equal("evt_1", metadata[core.ExecutionMetadataKey_IdempotencyKey])
+		storedKey, claimed, err := core.ClaimWebhookIdempotencyKey(ctx, idempotencyKey)
+		assert.Nil(err)
+		assert.False(claimed)
+		assert.Equal(strconv.Itoa(int(executionEntities[0].ID)), storedKey.ExecutionId.String)
+	})
+}
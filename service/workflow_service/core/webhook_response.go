@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The webhook request of the lastNode or responseNode mode waits for the response at most
+// options.responseTimeout seconds. After that it is answered with 202, the execution id and the status URL,
+// and the execution goes on in the background. The status URL has the random token of the execution, which is
+// saved in the execution metadata. It returns 202 until the execution ends, then the webhook response:
+// the result of the last node, or the response of the Respond to Webhook node which is saved
+// in workflow.webhook_response when it is sent after the timeout.
+
+const (
+	DefaultWebhookResponseTimeout = 25
+
+	// The execution metadata key of the token of the status URL
+	ExecutionMetadataKey_WebhookResponseToken = "webhookResponseToken"
+
+	// The binary body larger than this is sent with the chunked transfer encoding.
+	webhookResponseStreamingThreshold = 1 << 20
+)
+
+// GetWebhookResponseTimeout returns the time to wait for the response of the webhook request.
+func GetWebhookResponseTimeout(options *WebhookNodeOptions) time.Duration {
+	if options == nil || options.ResponseTimeout <= 0 {
+		return DefaultWebhookResponseTimeout * time.Second
+	}
+	return time.Duration(options.ResponseTimeout) * time.Second
+}
+
+// IsWorkflowExecutionEnded returns true if the execution will not run any node.
+func IsWorkflowExecutionEnded(execution *structs.WorkflowExecution) bool {
+	switch execution.Status {
+	case "", structs.WorkflowExecutionStatus_New,
+		structs.WorkflowExecutionStatus_Running,
+		structs.WorkflowExecutionStatus_Waiting:
+		return false
+	default:
+		return true
+	}
+}
+
+// GetWorkflowExecutionError returns the error of the execution, or of the last executed node
+// which failed without the error of the execution. Returns empty if the execution has no error.
+func GetWorkflowExecutionError(data *structs.WorkflowRunExecutionData) string {
+	if data == nil || data.ResultData == nil {
+		return ""
+	}
+	if data.ResultData.Error != "" {
+		return data.ResultData.Error
+	}
+	runs := data.ResultData.RunData[data.ResultData.LastNodeExecuted]
+	if len(runs) == 0 || runs[len(runs)-1] == nil {
+		return ""
+	}
+	taskData := runs[len(runs)-1]
+	if taskData.Error != nil && taskData.Error.Message != "" {
+		return taskData.Error.Message
+	}
+	switch taskData.ExecutionStatus {
+	case structs.WorkflowExecutionStatus_Error,
+		structs.WorkflowExecutionStatus_Failed,
+		structs.WorkflowExecutionStatus_Crashed,
+		structs.WorkflowExecutionStatus_Canceled:
+		return fmt.Sprintf("node %s ended with status %s", data.ResultData.LastNodeExecuted, taskData.ExecutionStatus)
+	}
+	return ""
+}
+
+var ErrInvalidWebhookResponseToken = errors.New("invalid webhook response token")
+
+// CreateWebhookResponseToken returns a new random token of the status URL of the execution.
+func CreateWebhookResponseToken(ctx context.Context, executionId string) (string, error) {
+	id, err := strconv.Atoi(executionId)
+	if err != nil {
+		return "", err
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+	err = SaveExecutionMetadata(ctx, int32(id), map[string]string{ExecutionMetadataKey_WebhookResponseToken: token})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyWebhookResponseToken returns ErrInvalidWebhookResponseToken if the token is not the token of the execution.
+func VerifyWebhookResponseToken(ctx context.Context, executionId int32, token string) error {
+	metadata, err := GetExecutionMetadata(ctx, executionId)
+	if err != nil {
+		return err
+	}
+	expected := metadata[ExecutionMetadataKey_WebhookResponseToken]
+	if expected == "" || !hmac.Equal([]byte(expected), []byte(token)) {
+		return ErrInvalidWebhookResponseToken
+	}
+	return nil
+}
+
+// SaveWebhookResponse saves the response of the Respond to Webhook node sent after the webhook request
+// is answered. Only the first response of the execution is saved.
+func SaveWebhookResponse(ctx context.Context, executionId string, response *fasthttp.Response) error {
+	id, err := strconv.Atoi(executionId)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	response.Header.VisitAll(func(key, value []byte) {
+		// The length is set again when the body is sent.
+		if string(key) != fasthttp.HeaderContentLength {
+			headers[string(key)] = string(value)
+		}
+	})
+	rawHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	return GetRdsDbQueries().SaveWebhookResponse(ctx, rdsDbLib.SaveWebhookResponseParams{
+		ExecutionId: int32(id),
+		StatusCode:  int32(response.StatusCode()),
+		Headers:     rawHeaders,
+		Body:        response.Body(),
+	})
+}
+
+// GetWebhookResponse returns the saved response of the execution, or nil if there is none.
+func GetWebhookResponse(ctx context.Context, executionId int32) (*fasthttp.Response, error) {
+	saved, err := GetRdsDbQueries().GetWebhookResponse(ctx, executionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	if err := json.Unmarshal(saved.Headers, &headers); err != nil {
+		return nil, err
+	}
+	response := &fasthttp.Response{}
+	for key, value := range headers {
+		response.Header.Set(key, value)
+	}
+	response.SetStatusCode(int(saved.StatusCode))
+	response.SetBody(saved.Body)
+	return response, nil
+}
+
+// GetWebhookResponseBinaryData returns the binary data of the property of the item,
+// or the only binary data of the item if the property name is empty.
+func GetWebhookResponseBinaryData(item map[string]interface{}, propertyName string) (*structs.WorkflowBinaryData, error) {
+	binary, err := ConvertInterfaceToType[map[string]structs.WorkflowBinaryData](item["binary"])
+	if err != nil || len(*binary) == 0 {
+		return nil, errors.New("no binary data in the item")
+	}
+	if propertyName == "" {
+		if len(*binary) > 1 {
+			names := make([]string, 0, len(*binary))
+			for name := range *binary {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("the item has multiple binary data %s, set the one to respond with", strings.Join(names, ", "))
+		}
+		for _, binaryData := range *binary {
+			return &binaryData, nil
+		}
+	}
+	binaryData, ok := (*binary)[propertyName]
+	if !ok {
+		return nil, fmt.Errorf("no binary data %s in the item", propertyName)
+	}
+	return &binaryData, nil
+}
+
+// SetWebhookBinaryResponse sets the binary data as the body of the response, with its mime type as the content type
+// and the disposition, attachment or inline, with its file name. The large body is decoded while it is sent
+// with the chunked transfer encoding.
+func SetWebhookBinaryResponse(response *fasthttp.Response, binaryData *structs.WorkflowBinaryData, disposition string) error {
+	mimeType := binaryData.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	response.Header.SetContentType(mimeType)
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	contentDisposition := disposition
+	if binaryData.FileName != "" {
+		if formatted := mime.FormatMediaType(disposition, map[string]string{"filename": binaryData.FileName}); formatted != "" {
+			contentDisposition = formatted
+		}
+	}
+	response.Header.Set(fasthttp.HeaderContentDisposition, contentDisposition)
+
+	if !binaryData.Base64Encoded {
+		response.SetBodyString(binaryData.Data)
+		return nil
+	}
+	if base64.StdEncoding.DecodedLen(len(binaryData.Data)) > webhookResponseStreamingThreshold {
+		response.SetBodyStream(base64.NewDecoder(base64.StdEncoding, strings.NewReader(binaryData.Data)), -1)
+		return nil
+	}
+	content, err := base64.StdEncoding.DecodeString(binaryData.Data)
+	if err != nil {
+		return fmt.Errorf("invalid binary data: %w", err)
+	}
+	response.SetBody(content)
+	return nil
+}
@@ -0,0 +1,98 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_response_test.go
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestWebhookResponse(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	t.Run("Response timeout", func(t *testing.T) {
+		assert.Equal(core.DefaultWebhookResponseTimeout*time.Second, core.GetWebhookResponseTimeout(nil))
+		assert.Equal(core.DefaultWebhookResponseTimeout*time.Second,
+			core.GetWebhookResponseTimeout(&core.WebhookNodeOptions{}))
+		assert.Equal(5*time.Second, core.GetWebhookResponseTimeout(&core.WebhookNodeOptions{ResponseTimeout: 5}))
+	})
+
+	t.Run("Execution ended", func(t *testing.T) {
+		for status, ended := range map[structs.WorkflowExecutionStatus]bool{
+			"":                                      false,
+			structs.WorkflowExecutionStatus_New:     false,
+			structs.WorkflowExecutionStatus_Running: false,
+			structs.WorkflowExecutionStatus_Waiting: false,
+			structs.WorkflowExecutionStatus_Success: true,
+			structs.WorkflowExecutionStatus_Failed:  true,
+		} {
+			assert.Equal(ended, core.IsWorkflowExecutionEnded(&structs.WorkflowExecution{Status: status}), status)
+		}
+	})
+
+	t.Run("Execution error", func(t *testing.T) {
+		executionData := func(taskData *structs.WorkflowExecutionTaskData) *structs.WorkflowRunExecutionData {
+			return &structs.WorkflowRunExecutionData{
+				ResultData: &structs.WorkflowRunExecutionResultData{
+					LastNodeExecuted: "Code",
+					RunData:          map[string][]*structs.WorkflowExecutionTaskData{"Code": {taskData}},
+				},
+			}
+		}
+		assert.Equal("", core.GetWorkflowExecutionError(nil))
+		assert.Equal("", core.GetWorkflowExecutionError(&structs.WorkflowRunExecutionData{}))
+		assert.Equal("", core.GetWorkflowExecutionError(executionData(
+			&structs.WorkflowExecutionTaskData{ExecutionStatus: structs.WorkflowExecutionStatus_Success})))
+
+		data := executionData(&structs.WorkflowExecutionTaskData{ExecutionStatus: structs.WorkflowExecutionStatus_Success})
+		data.ResultData.Error = "workflow error"
+		assert.Equal("workflow error", core.GetWorkflowExecutionError(data))
+		assert.Equal("node error", core.GetWorkflowExecutionError(executionData(&structs.WorkflowExecutionTaskData{
+			ExecutionStatus: structs.WorkflowExecutionStatus_Failed,
+			Error:           &structs.WorkflowExecutionError{Message: "node error"},
+		})))
+		assert.Equal("node Code ended with status failed", core.GetWorkflowExecutionError(executionData(
+			&structs.WorkflowExecutionTaskData{ExecutionStatus: structs.WorkflowExecutionStatus_Failed})))
+	})
+
+	t.Run("Save the response", func(t *testing.T) {
+		workflowEntity := createWebhookWorkflow_Testing(assert, uuid.NewString()[:8])
+		execution, err := rdsDbQueries.CreateWorkflowExecutionEntity(
+			ctx, rdsDbLib.CreateWorkflowExecutionEntityParams{WorkflowId: workflowEntity.ID})
+		assert.Nil(err)
+		response, err := core.GetWebhookResponse(ctx, execution.ID)
+		assert.Nil(err)
+		assert.Nil(response)
+
+		sent := &fasthttp.Response{}
+		sent.SetStatusCode(201)
+		sent.Header.SetContentType("application/json")
+		sent.Header.Set("X-Order-Id", "42")
+		sent.SetBody([]byte(`{"id":"42"}`))
+		executionId := strconv.Itoa(int(execution.ID))
+		assert.Nil(core.SaveWebhookResponse(ctx, executionId, sent))
+		// Only the first response is saved.
+		second := &fasthttp.Response{}
+		second.SetStatusCode(200)
+		assert.Nil(core.SaveWebhookResponse(ctx, executionId, second))
+
+		response, err = core.GetWebhookResponse(ctx, execution.ID)
+		assert.Nil(err)
+		assert.Equal(201, response.StatusCode())
+		assert.Equal("application/json", string(response.Header.ContentType()))
+		assert.Equal("42", string(response.Header.Peek("X-Order-Id")))
+		assert.Equal(`{"id":"42"}`, string(response.Body()))
+	})
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The Webhook node with parameters.path is reachable at /workflow/public/webhook/{orgId}/{path}, or at
+// /workflow/public/webhook/{orgId}/{path}/test for the test webhook. The webhookPath of webhook_entity is
+// {orgId}/{path}[/test] and the pathLength is the number of its segments.
+//
+// The segments starting with ":" are the route parameters, e.g. orders/:orderId. The request path is resolved
+// to the most specific webhook of the org: from left to right, the static segment wins over the parameter.
+// Two webhooks of the same method conflict if their paths are the same regardless of the parameter names,
+// and the workflow with the conflicting webhook can not be activated.
+
+// The Form Trigger has its own route, only the path of the Webhook node is routed.
+const webhookNodeType = "n8n-nodes-base.webhook"
+
+var (
+	ErrInvalidWebhookPath  = errors.New("invalid webhook path")
+	ErrWebhookPathConflict = errors.New("the webhook path conflicts with another webhook")
+	ErrWebhookNotFound     = errors.New("no webhook is registered for the path")
+)
+
+// GetWebhookNodePath returns parameters.path of the Webhook node without the leading and trailing slashes.
+// Returns empty if the node has no custom path. The path of the node imported from n8n is its webhookId
+// by default, which is not a custom path.
+func GetWebhookNodePath(node *structs.WorkflowNode) (string, error) {
+	if node == nil || node.Type != webhookNodeType {
+		return "", nil
+	}
+	raw := node.Parameters["path"]
+	if raw == nil {
+		return "", nil
+	}
+	path, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: the path of node %s must be a string", ErrInvalidWebhookPath, node.Name)
+	}
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" || path == node.WebhookId {
+		return "", nil
+	}
+
+	paramNames := map[string]bool{}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || strings.ContainsAny(segment, "?#*% ") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidWebhookPath, path)
+		}
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			if name == "" || paramNames[name] {
+				return "", fmt.Errorf("%w: the parameter %q of %s is empty or repeated", ErrInvalidWebhookPath, name, path)
+			}
+			paramNames[name] = true
+		}
+	}
+	return path, nil
+}
+
+// getOrgWebhookPath returns the webhookPath of the custom path in webhook_entity.
+func getOrgWebhookPath(orgId string, path string, isTest bool) string {
+	return orgId + "/" + getWebhookPath(path, isTest)
+}
+
+func webhookPathLength(webhookPath string) int {
+	return len(strings.Split(webhookPath, "/"))
+}
+
+// FindWebhookEntityByPath resolves the request path {orgId}/{path} to the most specific webhook entity
+// and returns the matched route parameters.
+func FindWebhookEntityByPath(
+	ctx context.Context,
+	method string,
+	path string) (*rdsDbLib.WorkflowWebhookEntity, map[string]string, error) {
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || segments[0] == "" {
+		return nil, nil, ErrWebhookNotFound
+	}
+
+	webhookEntities, err := GetRdsDbQueries().ListWebhookEntitiesByOrgId_Method_PathLength(
+		ctx,
+		rdsDbLib.ListWebhookEntitiesByOrgId_Method_PathLengthParams{
+			OrgID:      segments[0],
+			Method:     method,
+			PathLength: sql.NullInt32{Int32: int32(len(segments)), Valid: true},
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matched *rdsDbLib.WorkflowWebhookEntity
+	var matchedParams map[string]string
+	for index := range webhookEntities {
+		webhookEntity := &webhookEntities[index]
+		params, ok := MatchWebhookPath(webhookEntity.WebhookPath, path)
+		if !ok {
+			continue
+		}
+		if matched == nil || compareWebhookPathSpecificity(webhookEntity.WebhookPath, matched.WebhookPath) > 0 {
+			matched = webhookEntity
+			matchedParams = params
+		}
+	}
+	if matched == nil {
+		return nil, nil, ErrWebhookNotFound
+	}
+	return matched, matchedParams, nil
+}
+
+// MatchWebhookPath matches the path against the pattern and returns the decoded route parameters.
+func MatchWebhookPath(pattern string, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for index, patternSegment := range patternSegments {
+		pathSegment := pathSegments[index]
+		if name, ok := strings.CutPrefix(patternSegment, ":"); ok {
+			value, err := url.PathUnescape(pathSegment)
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[name] = value
+		} else if patternSegment != pathSegment {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// compareWebhookPathSpecificity returns 1 if the pattern a is more specific than b, -1 if less, otherwise 0.
+func compareWebhookPathSpecificity(a string, b string) int {
+	aSegments := strings.Split(a, "/")
+	bSegments := strings.Split(b, "/")
+	for index := 0; index < len(aSegments) && index < len(bSegments); index++ {
+		aIsParam := strings.HasPrefix(aSegments[index], ":")
+		bIsParam := strings.HasPrefix(bSegments[index], ":")
+		if aIsParam != bIsParam {
+			if bIsParam {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// webhookPathsConflict returns whether the patterns match the same paths.
+func webhookPathsConflict(a string, b string) bool {
+	aSegments := strings.Split(a, "/")
+	bSegments := strings.Split(b, "/")
+	if len(aSegments) != len(bSegments) {
+		return false
+	}
+	for index := range aSegments {
+		aIsParam := strings.HasPrefix(aSegments[index], ":")
+		bIsParam := strings.HasPrefix(bSegments[index], ":")
+		if aIsParam != bIsParam || (!aIsParam && aSegments[index] != bSegments[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckWebhookPaths validates the custom paths of the workflow webhooks and checks they do not conflict
+// with each other or with the webhooks registered by the other workflows of the org.
+func CheckWebhookPaths(ctx context.Context, workflowEntity *structs.WorkflowEntity, isTest bool) error {
+	checked := []structs.WebhookData{}
+	for _, webhook := range GetWorkflowWebhooks(workflowEntity, isTest) {
+		path, err := GetWebhookNodePath(workflowEntity.GetNodeById(webhook.NodeId))
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			continue
+		}
+
+		for _, other := range checked {
+			if other.HttpMethod == webhook.HttpMethod && webhookPathsConflict(other.Path, webhook.Path) {
+				return fmt.Errorf("%w: %s %s of nodes %s and %s",
+					ErrWebhookPathConflict, webhook.HttpMethod, path, other.Node, webhook.Node)
+			}
+		}
+		checked = append(checked, webhook)
+
+		webhookEntities, err := GetRdsDbQueries().ListWebhookEntitiesByOrgId_Method_PathLength(
+			ctx,
+			rdsDbLib.ListWebhookEntitiesByOrgId_Method_PathLengthParams{
+				OrgID:      workflowEntity.SugerOrgId,
+				Method:     webhook.HttpMethod,
+				PathLength: sql.NullInt32{Int32: int32(webhookPathLength(webhook.Path)), Valid: true},
+			})
+		if err != nil {
+			return err
+		}
+		for _, webhookEntity := range webhookEntities {
+			// The webhooks of the workflow itself are replaced when it is registered again.
+			if webhookEntity.WorkflowId == workflowEntity.ID {
+				continue
+			}
+			if webhookPathsConflict(webhookEntity.WebhookPath, webhook.Path) {
+				return fmt.Errorf("%w: %s %s is used by workflow %s",
+					ErrWebhookPathConflict, webhook.HttpMethod, path, webhookEntity.WorkflowId)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_router_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	// Register the Webhook node.
+	_ "github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/webhook"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func webhookNode_Testing(name string, method string, path string) structs.WorkflowNode {
+	return structs.WorkflowNode{
+		ID:         uuid.NewString(),
+		Name:       name,
+		Type:       "n8n-nodes-base.webhook",
+		WebhookId:  uuid.NewString(),
+		Parameters: map[string]interface{}{"httpMethod": method, "path": path},
+	}
+}
+
+func TestWebhookRouter(t *testing.T) {
+
+	t.Run("Path of the webhook node", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		node := webhookNode_Testing("Webhook", http.MethodPost, "/orders/:orderId/")
+		path, err := core.GetWebhookNodePath(&node)
+		assert.Nil(err)
+		assert.Equal("orders/:orderId", path)
+
+		// The path imported from n8n is the webhookId, which is the default webhook URL.
+		node.Parameters["path"] = node.WebhookId
+		path, err = core.GetWebhookNodePath(&node)
+		assert.Nil(err)
+		assert.Equal("", path)
+
+		for _, invalid := range []interface{}{"orders//items", "orders/:", "orders/:id/:id", "orders/*", "orders?id=1", 1} {
+			node.Parameters["path"] = invalid
+			_, err = core.GetWebhookNodePath(&node)
+			assert.ErrorIs(err, core.ErrInvalidWebhookPath, invalid)
+		}
+
+		// The Form Trigger has its own route.
+		node = webhookNode_Testing("Form", http.MethodPost, "contact")
+		node.Type = "n8n-nodes-base.formTrigger"
+		path, err = core.GetWebhookNodePath(&node)
+		assert.Nil(err)
+		assert.Equal("", path)
+	})
+
+	t.Run("Match the path", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		params, ok := core.MatchWebhookPath("org/orders/:orderId/items/:itemId", "org/orders/o%201/items/42")
+		assert.True(ok)
+		assert.Equal(map[string]string{"orderId": "o 1", "itemId": "42"}, params)
+		_, ok = core.MatchWebhookPath("org/orders/:orderId", "org/invoices/42")
+		assert.False(ok)
+		_, ok = core.MatchWebhookPath("org/orders/:orderId", "org/orders/42/items")
+		assert.False(ok)
+		params, ok = core.MatchWebhookPath("org/orders/new", "org/orders/new")
+		assert.True(ok)
+		assert.Empty(params)
+	})
+
+	t.Run("Resolve the most specific webhook and reject conflicts", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		orgId := uuid.NewString()[:8]
+
+		// The workflow registered first, the webhook entities reference it.
+		workflowEntity, err := rdsDbQueries.CreateWorkflowEntity(ctx, rdsDbLib.CreateWorkflowEntityParams{
+			Name:        "router",
+			Nodes:       json.RawMessage("[]"),
+			Connections: json.RawMessage("{}"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  orgId,
+		})
+		assert.Nil(err)
+		registered := &structs.WorkflowEntity{
+			ID:         workflowEntity.ID,
+			SugerOrgId: orgId,
+			Nodes: []structs.WorkflowNode{
+				webhookNode_Testing("Order", http.MethodPost, "orders/:orderId"),
+				webhookNode_Testing("New order", http.MethodPost, "orders/new"),
+				webhookNode_Testing("Order item", http.MethodPost, ":kind/:id/items"),
+			},
+		}
+		assert.Nil(core.CheckWebhookPaths(ctx, registered, false))
+		for _, webhook := range core.GetWorkflowWebhooks(registered, false) {
+			_, err := core.SaveWebhookEntity(ctx, &webhook)
+			assert.Nil(err)
+		}
+
+		webhookEntity, params, err := core.FindWebhookEntityByPath(ctx, http.MethodPost, orgId+"/orders/new")
+		assert.Nil(err)
+		assert.Equal("New order", webhookEntity.Node)
+		assert.Empty(params)
+
+		webhookEntity, params, err = core.FindWebhookEntityByPath(ctx, http.MethodPost, "/"+orgId+"/orders/42/")
+		assert.Nil(err)
+		assert.Equal("Order", webhookEntity.Node)
+		assert.Equal(registered.ID, webhookEntity.WorkflowId)
+		assert.Equal(map[string]string{"orderId": "42"}, params)
+
+		webhookEntity, params, err = core.FindWebhookEntityByPath(ctx, http.MethodPost, orgId+"/invoices/7/items")
+		assert.Nil(err)
+		assert.Equal("Order item", webhookEntity.Node)
+		assert.Equal(map[string]string{"kind": "invoices", "id": "7"}, params)
+
+		for _, path := range []string{orgId + "/orders", "other-org/orders/42", orgId} {
+			_, _, err = core.FindWebhookEntityByPath(ctx, http.MethodPost, path)
+			assert.ErrorIs(err, core.ErrWebhookNotFound, path)
+		}
+		_, _, err = core.FindWebhookEntityByPath(ctx, http.MethodGet, orgId+"/orders/42")
+		assert.ErrorIs(err, core.ErrWebhookNotFound)
+
+		// The same path with another parameter name conflicts, another method or org does not.
+		other := &structs.WorkflowEntity{
+			ID:         uuid.NewString(),
+			SugerOrgId: orgId,
+			Nodes:      []structs.WorkflowNode{webhookNode_Testing("Order", http.MethodPost, "orders/:id")},
+		}
+		assert.ErrorIs(core.CheckWebhookPaths(ctx, other, false), core.ErrWebhookPathConflict)
+		other.Nodes[0].Parameters["httpMethod"] = http.MethodPut
+		assert.Nil(core.CheckWebhookPaths(ctx, other, false))
+		other.Nodes[0].Parameters["httpMethod"] = http.MethodPost
+		other.SugerOrgId = orgId + "x"
+		assert.Nil(core.CheckWebhookPaths(ctx, other, false))
+
+		// The registered workflow does not conflict with itself, but its nodes may conflict with each other.
+		assert.Nil(core.CheckWebhookPaths(ctx, registered, false))
+		registered.Nodes = append(registered.Nodes, webhookNode_Testing("Duplicate", http.MethodPost, "orders/:id"))
+		assert.ErrorIs(core.CheckWebhookPaths(ctx, registered, false), core.ErrWebhookPathConflict)
+
+		assert.Nil(rdsDbQueries.DeleteWebhookEntitiesByWorkflowId(ctx, registered.ID))
+		_, _, err = core.FindWebhookEntityByPath(ctx, http.MethodPost, orgId+"/orders/42")
+		assert.ErrorIs(err, core.ErrWebhookNotFound)
+	})
+}
@@ -0,0 +1,371 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The signature of the webhook request is verified against the raw body with the preset of
+// parameters.signatureVerification. The presets except awsSns use the secret of the webhookSigningSecret credential:
+//   - stripe:  Stripe-Signature "t=<timestamp>,v1=<hex>", HMAC-SHA256 of "<timestamp>.<body>".
+//   - github:  X-Hub-Signature-256 "sha256=<hex>", HMAC-SHA256 of the body.
+//   - slack:   X-Slack-Signature "v0=<hex>", HMAC-SHA256 of "v0:<X-Slack-Request-Timestamp>:<body>".
+//   - shopify: X-Shopify-Hmac-Sha256 "<base64>", HMAC-SHA256 of the body.
+//   - awsSns:  the RSA signature of the SNS message, verified with the certificate of SigningCertURL,
+//     the message must be from one of the topics of parameters.snsTopicArns.
+//   - hmac:    the generic HMAC of the body, or of "<timestamp>.<body>" if the timestamp header is set.
+//
+// A missing signature returns ErrWebhookUnauthorized and a wrong or expired signature returns ErrWebhookForbidden.
+
+const (
+	CredentialsType_WebhookSigningSecret = "webhookSigningSecret"
+
+	// The default max age in seconds of the signed timestamp
+	DefaultWebhookSignatureTolerance = 300
+)
+
+var snsSigningCertUrlHostRegexp = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+type (
+	WebhookSigningSecretCredentials struct {
+		Secret string `json:"secret"`
+	}
+
+	// The signature parameters of the webhook node.
+	WebhookSignatureOptions struct {
+		Verification structs.WebhookSignatureVerification `json:"signatureVerification,omitempty"`
+		// The max age in seconds of the signed timestamp, default 300
+		Tolerance int `json:"signatureTolerance,omitempty"`
+		// for signatureVerification:hmac
+		Header          string `json:"signatureHeader,omitempty"`
+		Algorithm       string `json:"signatureAlgorithm,omitempty"` // sha1, sha256 or sha512, default sha256
+		Encoding        string `json:"signatureEncoding,omitempty"`  // hex or base64, default hex
+		Prefix          string `json:"signaturePrefix,omitempty"`    // e.g. "sha256="
+		TimestampHeader string `json:"signatureTimestampHeader,omitempty"`
+		// for signatureVerification:awsSns, the comma separated ARNs of the allowed topics
+		SnsTopicArns string `json:"snsTopicArns,omitempty"`
+	}
+)
+
+// FetchSnsSigningCertificate downloads the certificate of the SNS signature, it is replaced in the tests.
+var FetchSnsSigningCertificate = fetchSnsSigningCertificate
+
+var snsSigningCertificates = sync.Map{}
+
+// ParseWebhookSignatureOptions parses the signature parameters of the webhook node.
+func ParseWebhookSignatureOptions(node *structs.WorkflowNode) (*WebhookSignatureOptions, error) {
+	options := &WebhookSignatureOptions{}
+	data, err := json.Marshal(node.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, options); err != nil {
+		return nil, err
+	}
+	if options.Verification == "" {
+		options.Verification = structs.WebhookSignatureVerification_None
+	}
+	if options.Tolerance <= 0 {
+		options.Tolerance = DefaultWebhookSignatureTolerance
+	}
+	return options, nil
+}
+
+// VerifyWebhookSignature verifies the signature of the request with the preset of the webhook node.
+func VerifyWebhookSignature(
+	ctx context.Context,
+	orgId string,
+	node *structs.WorkflowNode,
+	request *fasthttp.Request,
+	now time.Time) error {
+	options, err := ParseWebhookSignatureOptions(node)
+	if err != nil {
+		return err
+	}
+	body := request.Body()
+	header := func(name string) string {
+		return string(request.Header.Peek(name))
+	}
+
+	switch options.Verification {
+	case structs.WebhookSignatureVerification_None:
+		return nil
+	case structs.WebhookSignatureVerification_AwsSns:
+		return verifySnsSignature(ctx, body, options, now)
+	case structs.WebhookSignatureVerification_Stripe,
+		structs.WebhookSignatureVerification_Github,
+		structs.WebhookSignatureVerification_Slack,
+		structs.WebhookSignatureVerification_Shopify,
+		structs.WebhookSignatureVerification_Hmac:
+	default:
+		return fmt.Errorf("unknown signatureVerification: %s", options.Verification)
+	}
+
+	credentials := WebhookSigningSecretCredentials{}
+	if err := GetNodeCredentials(ctx, orgId, node, CredentialsType_WebhookSigningSecret, &credentials); err != nil {
+		return err
+	}
+	if credentials.Secret == "" {
+		return fmt.Errorf("the secret of the credential is empty")
+	}
+	secret := []byte(credentials.Secret)
+
+	switch options.Verification {
+	case structs.WebhookSignatureVerification_Stripe:
+		signatureHeader := header("Stripe-Signature")
+		if signatureHeader == "" {
+			return ErrWebhookUnauthorized
+		}
+		timestamp := ""
+		signatures := []string{}
+		for _, part := range strings.Split(signatureHeader, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if err := checkSignatureTimestamp(timestamp, options.Tolerance, now); err != nil {
+			return err
+		}
+		expected := hex.EncodeToString(computeHmac(sha256.New, secret, []byte(timestamp+"."), body))
+		for _, signature := range signatures {
+			if hmac.Equal([]byte(signature), []byte(expected)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: invalid Stripe signature", ErrWebhookForbidden)
+
+	case structs.WebhookSignatureVerification_Github:
+		return checkHmacSignature(header("X-Hub-Signature-256"), "sha256=",
+			hex.EncodeToString(computeHmac(sha256.New, secret, body)))
+
+	case structs.WebhookSignatureVerification_Slack:
+		signature := header("X-Slack-Signature")
+		if signature == "" {
+			return ErrWebhookUnauthorized
+		}
+		timestamp := header("X-Slack-Request-Timestamp")
+		if err := checkSignatureTimestamp(timestamp, options.Tolerance, now); err != nil {
+			return err
+		}
+		return checkHmacSignature(signature, "v0=",
+			hex.EncodeToString(computeHmac(sha256.New, secret, []byte("v0:"+timestamp+":"), body)))
+
+	case structs.WebhookSignatureVerification_Shopify:
+		return checkHmacSignature(header("X-Shopify-Hmac-Sha256"), "",
+			base64.StdEncoding.EncodeToString(computeHmac(sha256.New, secret, body)))
+
+	default:
+		if options.Header == "" {
+			return fmt.Errorf("signatureHeader is empty")
+		}
+		var newHash func() hash.Hash
+		switch options.Algorithm {
+		case "sha1":
+			newHash = sha1.New
+		case "sha256", "":
+			newHash = sha256.New
+		case "sha512":
+			newHash = sha512.New
+		default:
+			return fmt.Errorf("unsupported signatureAlgorithm: %s", options.Algorithm)
+		}
+		signedContent := [][]byte{body}
+		if options.TimestampHeader != "" {
+			timestamp := header(options.TimestampHeader)
+			if err := checkSignatureTimestamp(timestamp, options.Tolerance, now); err != nil {
+				return err
+			}
+			signedContent = [][]byte{[]byte(timestamp + "."), body}
+		}
+		mac := computeHmac(newHash, secret, signedContent...)
+		switch options.Encoding {
+		case "hex", "":
+			return checkHmacSignature(header(options.Header), options.Prefix, hex.EncodeToString(mac))
+		case "base64":
+			return checkHmacSignature(header(options.Header), options.Prefix, base64.StdEncoding.EncodeToString(mac))
+		default:
+			return fmt.Errorf("unsupported signatureEncoding: %s", options.Encoding)
+		}
+	}
+}
+
+func computeHmac(newHash func() hash.Hash, secret []byte, contents ...[]byte) []byte {
+	mac := hmac.New(newHash, secret)
+	for _, content := range contents {
+		mac.Write(content)
+	}
+	return mac.Sum(nil)
+}
+
+// checkHmacSignature compares the signature without the prefix to the expected one in constant time.
+func checkHmacSignature(signature string, prefix string, expected string) error {
+	if signature == "" {
+		return ErrWebhookUnauthorized
+	}
+	signature, ok := strings.CutPrefix(signature, prefix)
+	if !ok || !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("%w: invalid signature", ErrWebhookForbidden)
+	}
+	return nil
+}
+
+// checkSignatureTimestamp checks the unix timestamp in seconds is within the tolerance of now, against replays.
+func checkSignatureTimestamp(timestamp string, tolerance int, now time.Time) error {
+	if timestamp == "" {
+		return ErrWebhookUnauthorized
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature timestamp", ErrWebhookForbidden)
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > time.Duration(tolerance)*time.Second {
+		return fmt.Errorf("%w: the signature timestamp is out of tolerance", ErrWebhookForbidden)
+	}
+	return nil
+}
+
+// verifySnsSignature verifies the signature of the SNS message from the allowed topics,
+// the Timestamp of the message must be within the tolerance of now.
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func verifySnsSignature(ctx context.Context, body []byte, options *WebhookSignatureOptions, now time.Time) error {
+	topicArns := []string{}
+	for _, topicArn := range strings.Split(options.SnsTopicArns, ",") {
+		if topicArn = strings.TrimSpace(topicArn); topicArn != "" {
+			topicArns = append(topicArns, topicArn)
+		}
+	}
+	if len(topicArns) == 0 {
+		return fmt.Errorf("snsTopicArns is empty")
+	}
+	message := structs.AwsSnsSubscriptionConfirmationEvent{}
+	if err := json.Unmarshal(body, &message); err != nil || message.Signature == "" {
+		return ErrWebhookUnauthorized
+	}
+	if !slices.Contains(topicArns, message.TopicArn) {
+		return fmt.Errorf("%w: the SNS topic %s is not allowed", ErrWebhookForbidden, message.TopicArn)
+	}
+	timestamp, err := time.Parse(time.RFC3339, message.Timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: invalid SNS message timestamp", ErrWebhookForbidden)
+	}
+	if err := checkSignatureTimestamp(strconv.FormatInt(timestamp.Unix(), 10), options.Tolerance, now); err != nil {
+		return err
+	}
+
+	fields := []string{"Message", message.Message, "MessageId", message.MessageId}
+	switch message.Type {
+	case "Notification":
+		if message.Subject != "" {
+			fields = append(fields, "Subject", message.Subject)
+		}
+		fields = append(fields, "Timestamp", message.Timestamp, "TopicArn", message.TopicArn, "Type", message.Type)
+	case "SubscriptionConfirmation", "UnsubscribeConfirmation":
+		fields = append(fields, "SubscribeURL", message.SubscribeURL, "Timestamp", message.Timestamp,
+			"Token", message.Token, "TopicArn", message.TopicArn, "Type", message.Type)
+	default:
+		return fmt.Errorf("%w: unknown SNS message type %s", ErrWebhookForbidden, message.Type)
+	}
+	stringToSign := strings.Join(fields, "\n") + "\n"
+
+	var hashType crypto.Hash
+	switch message.SignatureVersion {
+	case "1":
+		hashType = crypto.SHA1
+	case "2":
+		hashType = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unknown SNS signature version %s", ErrWebhookForbidden, message.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: invalid SNS signature", ErrWebhookForbidden)
+	}
+
+	certUrl, err := url.Parse(message.SigningCertURL)
+	if err != nil || certUrl.Scheme != "https" || !snsSigningCertUrlHostRegexp.MatchString(certUrl.Host) ||
+		!strings.HasSuffix(certUrl.Path, ".pem") {
+		return fmt.Errorf("%w: invalid SNS SigningCertURL %s", ErrWebhookForbidden, message.SigningCertURL)
+	}
+	certificate, err := FetchSnsSigningCertificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("the SNS signing certificate has no RSA public key")
+	}
+	digest := hashType.New()
+	digest.Write([]byte(stringToSign))
+	if err := rsa.VerifyPKCS1v15(publicKey, hashType, digest.Sum(nil), signature); err != nil {
+		return fmt.Errorf("%w: invalid SNS signature", ErrWebhookForbidden)
+	}
+	return nil
+}
+
+// fetchSnsSigningCertificate downloads and caches the certificate.
+func fetchSnsSigningCertificate(ctx context.Context, certUrl string) (*x509.Certificate, error) {
+	if certificate, ok := snsSigningCertificates.Load(certUrl); ok {
+		return certificate.(*x509.Certificate), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, certUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the SNS signing certificate: %s", response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid SNS signing certificate")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	snsSigningCertificates.Store(certUrl, certificate)
+	return certificate, nil
+}
@@ -0,0 +1,199 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/webhook_auth_test.go service/workflow_service/core/webhook_signature_test.go
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func signedRequest_Testing(body string, headers map[string]string) *fasthttp.Request {
+	request := webhookRequest_Testing(headers)
+	request.SetBodyString(body)
+	return request
+}
+
+func hmacSha256_Testing(secret string, content string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
+
+func TestWebhookSignature(t *testing.T) {
+
+	t.Run("Provider presets and generic HMAC", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		orgId := uuid.NewString()[:8]
+		secretId := createCredentials_Testing(assert, orgId, core.CredentialsType_WebhookSigningSecret,
+			core.WebhookSigningSecretCredentials{Secret: "whsec_test"})
+		node := func(parameters map[string]interface{}) *structs.WorkflowNode {
+			return &structs.WorkflowNode{
+				Name:       "Webhook",
+				Parameters: parameters,
+				Credentials: map[string]structs.WorkflowNodeCredentialsDetails{
+					core.CredentialsType_WebhookSigningSecret: {ID: secretId, Name: "Signing secret"},
+				},
+			}
+		}
+		now := time.Now()
+		timestamp := fmt.Sprint(now.Unix())
+		staleTimestamp := fmt.Sprint(now.Add(-10 * time.Minute).Unix())
+		body := `{"id":"evt_1","type":"invoice.paid"}`
+		verify := func(node *structs.WorkflowNode, request *fasthttp.Request) error {
+			return core.VerifyWebhookSignature(ctx, orgId, node, request, now)
+		}
+
+		// Stripe
+		stripe := node(map[string]interface{}{"signatureVerification": "stripe"})
+		stripeSignature := func(timestamp string, secret string) string {
+			return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(hmacSha256_Testing(secret, timestamp+"."+body)))
+		}
+		assert.Nil(verify(stripe, signedRequest_Testing(body, map[string]string{"Stripe-Signature": stripeSignature(timestamp, "whsec_test")})))
+		assert.ErrorIs(verify(stripe, signedRequest_Testing(body, nil)), core.ErrWebhookUnauthorized)
+		assert.ErrorIs(verify(stripe, signedRequest_Testing(body, map[string]string{"Stripe-Signature": stripeSignature(timestamp, "wrong")})),
+			core.ErrWebhookForbidden)
+		assert.ErrorIs(verify(stripe, signedRequest_Testing(body, map[string]string{"Stripe-Signature": stripeSignature(staleTimestamp, "whsec_test")})),
+			core.ErrWebhookForbidden)
+		// The body is not the signed one.
+		assert.ErrorIs(verify(stripe, signedRequest_Testing(body+" ", map[string]string{"Stripe-Signature": stripeSignature(timestamp, "whsec_test")})),
+			core.ErrWebhookForbidden)
+
+		// GitHub
+		github := node(map[string]interface{}{"signatureVerification": "github"})
+		githubSignature := "sha256=" + hex.EncodeToString(hmacSha256_Testing("whsec_test", body))
+		assert.Nil(verify(github, signedRequest_Testing(body, map[string]string{"X-Hub-Signature-256": githubSignature})))
+		assert.ErrorIs(verify(github, signedRequest_Testing(body, map[string]string{"X-Hub-Signature-256": "sha256=00"})), core.ErrWebhookForbidden)
+
+		// Slack
+		slack := node(map[string]interface{}{"signatureVerification": "slack"})
+		slackSignature := "v0=" + hex.EncodeToString(hmacSha256_Testing("whsec_test", "v0:"+timestamp+":"+body))
+		assert.Nil(verify(slack, signedRequest_Testing(body, map[string]string{
+			"X-Slack-Signature": slackSignature, "X-Slack-Request-Timestamp": timestamp})))
+		assert.ErrorIs(verify(slack, signedRequest_Testing(body, map[string]string{"X-Slack-Signature": slackSignature})),
+			core.ErrWebhookUnauthorized)
+
+		// Shopify
+		shopify := node(map[string]interface{}{"signatureVerification": "shopify"})
+		shopifySignature := base64.StdEncoding.EncodeToString(hmacSha256_Testing("whsec_test", body))
+		assert.Nil(verify(shopify, signedRequest_Testing(body, map[string]string{"X-Shopify-Hmac-Sha256": shopifySignature})))
+		assert.ErrorIs(verify(shopify, signedRequest_Testing(body, map[string]string{"X-Shopify-Hmac-Sha256": githubSignature})),
+			core.ErrWebhookForbidden)
+
+		// Generic HMAC with the timestamp
+		generic := node(map[string]interface{}{
+			"signatureVerification":    "hmac",
+			"signatureHeader":          "X-Signature",
+			"signatureEncoding":        "base64",
+			"signaturePrefix":          "v1,",
+			"signatureTimestampHeader": "X-Timestamp",
+			"signatureTolerance":       60,
+		})
+		genericSignature := "v1," + base64.StdEncoding.EncodeToString(hmacSha256_Testing("whsec_test", timestamp+"."+body))
+		assert.Nil(verify(generic, signedRequest_Testing(body, map[string]string{"X-Signature": genericSignature, "X-Timestamp": timestamp})))
+		assert.ErrorIs(verify(generic, signedRequest_Testing(body, map[string]string{"X-Signature": genericSignature, "X-Timestamp": staleTimestamp})),
+			core.ErrWebhookForbidden)
+		assert.ErrorIs(verify(generic, signedRequest_Testing(body, map[string]string{"X-Timestamp": timestamp})), core.ErrWebhookUnauthorized)
+
+		// The misconfigured node
+		assert.NotNil(verify(node(map[string]interface{}{"signatureVerification": "hmac"}), signedRequest_Testing(body, nil)))
+		assert.Nil(verify(node(map[string]interface{}{}), signedRequest_Testing(body, nil)))
+	})
+
+	t.Run("AWS SNS signature", func(t *testing.T) {
+		assert := require.New(t)
+		ctx := context.Background()
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		certificateDer, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+		assert.Nil(err)
+		certificate, err := x509.ParseCertificate(certificateDer)
+		assert.Nil(err)
+		fetch := core.FetchSnsSigningCertificate
+		core.FetchSnsSigningCertificate = func(context.Context, string) (*x509.Certificate, error) {
+			return certificate, nil
+		}
+		defer func() { core.FetchSnsSigningCertificate = fetch }()
+
+		message := structs.AwsSnsSubscriptionConfirmationEvent{
+			Type:             "Notification",
+			MessageId:        uuid.NewString(),
+			TopicArn:         "arn:aws:sns:us-west-2:123456789012:suger",
+			Subject:          "invoice",
+			Message:          `{"event":"paid"}`,
+			Timestamp:        time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+			SignatureVersion: "2",
+			SigningCertURL:   "https://sns.us-west-2.amazonaws.com/SimpleNotificationService-1234.pem",
+		}
+		stringToSign := "Message\n" + message.Message + "\nMessageId\n" + message.MessageId + "\nSubject\n" + message.Subject +
+			"\nTimestamp\n" + message.Timestamp + "\nTopicArn\n" + message.TopicArn + "\nType\n" + message.Type + "\n"
+		digest := sha256.Sum256([]byte(stringToSign))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		assert.Nil(err)
+		message.Signature = base64.StdEncoding.EncodeToString(signature)
+
+		node := &structs.WorkflowNode{Name: "Webhook", Parameters: map[string]interface{}{
+			"signatureVerification": "awsSns",
+			"snsTopicArns":          "arn:aws:sns:us-west-2:123456789012:other, arn:aws:sns:us-west-2:123456789012:suger",
+		}}
+		verifyAt := func(message structs.AwsSnsSubscriptionConfirmationEvent, now time.Time) error {
+			body, err := json.Marshal(message)
+			assert.Nil(err)
+			return core.VerifyWebhookSignature(ctx, "org", node, signedRequest_Testing(string(body), nil), now)
+		}
+		verify := func(message structs.AwsSnsSubscriptionConfirmationEvent) error {
+			return verifyAt(message, time.Now())
+		}
+		assert.Nil(verify(message))
+		// The replayed message is rejected.
+		assert.ErrorIs(verifyAt(message, time.Now().Add(10*time.Minute)), core.ErrWebhookForbidden)
+
+		tampered := message
+		tampered.Message = `{"event":"refunded"}`
+		assert.ErrorIs(verify(tampered), core.ErrWebhookForbidden)
+		// The certificate must be downloaded from SNS.
+		tampered = message
+		tampered.SigningCertURL = "https://attacker.example.com/sns.us-west-2.amazonaws.com.pem"
+		assert.ErrorIs(verify(tampered), core.ErrWebhookForbidden)
+		tampered = message
+		tampered.Signature = ""
+		assert.ErrorIs(verify(tampered), core.ErrWebhookUnauthorized)
+
+		// Only the messages of the allowed topics are accepted, the topics must be set.
+		node.Parameters["snsTopicArns"] = "arn:aws:sns:us-west-2:123456789012:other"
+		assert.ErrorIs(verify(message), core.ErrWebhookForbidden)
+		delete(node.Parameters, "snsTopicArns")
+		err = verify(message)
+		assert.NotNil(err)
+		assert.NotErrorIs(err, core.ErrWebhookForbidden)
+	})
+}
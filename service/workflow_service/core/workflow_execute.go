@@ -149,6 +149,9 @@ func saveExecutionProgress(
 	}
 	runExecutionData.ExecutionData = executionData.ExecutionData
 	runExecutionData.ResultData.LastNodeExecuted = nodeName
+	if executionData.ResultData != nil {
+		runExecutionData.ResultData.MetaData = executionData.ResultData.MetaData
+	}
 
 	fullExecutionData.Status = "running"
 
@@ -211,6 +214,11 @@ func saveWorkflowAfterExecutionData(
 	fullExecutionData.StoppedAt = fullRunData.StoppedAt
 	fullExecutionData.WaitTill = fullRunData.WaitTill
 	fullExecutionData.Data.ResultData.Error = fullRunData.Data.ResultData.Error
+	fullExecutionData.Data.ResultData.MetaData = fullRunData.Data.ResultData.MetaData
+	// The waiting execution resumes from its node execution stack.
+	if fullRunData.WaitTill != nil {
+		fullExecutionData.Data.ExecutionData = fullRunData.Data.ExecutionData
+	}
 
 	if fullRunData.NeedDelete {
 		id, err := strconv.Atoi(hooks.ExecutionId)
@@ -227,6 +235,10 @@ func saveWorkflowAfterExecutionData(
 	if err != nil {
 		Errorf("failed to update workflow execution entity: %v", err)
 	}
+	err = SaveExecutionMetadata(ctx, int32(executionId), fullRunData.Data.ResultData.MetaData)
+	if err != nil {
+		Errorf("failed to save workflow execution metadata: %v", err)
+	}
 }
 
 func GetWorkflowHooksMain(executionId string) structs.WorkflowHooks {
@@ -253,6 +265,11 @@ func GetWorkflowHooksMain(executionId string) structs.WorkflowHooks {
 				func(ctx context.Context, hooks *structs.WorkflowHooks, fullRunData *structs.Run) {
 					saveWorkflowAfterExecutionData(ctx, hooks, hooks.WorkflowData, fullRunData)
 				},
+				func(ctx context.Context, hooks *structs.WorkflowHooks, fullRunData *structs.Run) {
+					if err := RecordWorkflowStatistics(ctx, hooks.WorkflowData, hooks.Mode, fullRunData); err != nil {
+						Errorf("failed to record workflow statistics: %v", err)
+					}
+				},
 			},
 			WorkflowExecuteBefore: []func(context.Context, *structs.WorkflowHooks, *structs.WorkflowEntity){
 				func(ctx context.Context, hooks *structs.WorkflowHooks, workflowEntity *structs.WorkflowEntity) {
@@ -383,10 +400,13 @@ func (w *WorkflowExecute) execute(
 		// gen nodeInput
 		nodeInput := &structs.NodeExecuteInput{
 			WorkflowID:       workflowEntity.ID,
+			Workflow:         workflowEntity,
 			Params:           curNodeStack.Node,
 			Data:             curNodeStack.RunResultList,
+			RunIndex:         int32(len(w.RunExecutionData.ResultData.RunData[curNodeStack.Node.Name])),
 			AdditionalData:   w.AdditionalData,
 			RunExecutionData: w.RunExecutionData,
+			Mode:             w.Mode,
 		}
 		// node execute
 		nodeObj := NewExecutor(curNodeStack.Node.Type).GetNode()
@@ -395,6 +415,13 @@ func (w *WorkflowExecute) execute(
 		w.AdditionalData.Hooks.ExecutionHookFunctionsNodeExecutionBefore(ctx, curNodeStack.Node.Name)
 
 		result := nodeObj.Execute(ctx, nodeInput)
+		// The node put the execution to wait, e.g. the Approval node. It runs again with the same input
+		// when the execution is resumed, so this run is not recorded.
+		if result.ExecutionStatus == structs.WorkflowExecutionStatus_Waiting && w.RunExecutionData.WaitTill != nil {
+			w.RunExecutionData.ExecutionData.NodeExecutionStack.PushFront(curNodeStack)
+			finished = false
+			break
+		}
 		// get next node and push to nodeExecutionStack
 		resultList := w.getResultData(result, nodeObj.Category())
 		// WaitingExecution saved the execution results for each node.
@@ -569,6 +596,7 @@ func (w *WorkflowExecute) getFullRunData(startAt time.Time) *structs.Run {
 	return &structs.Run{
 		Data:       w.RunExecutionData,
 		Mode:       w.Mode,
+		WaitTill:   w.RunExecutionData.WaitTill,
 		StartedAt:  &startAt,
 		StoppedAt:  &stopAt,
 		Status:     status,
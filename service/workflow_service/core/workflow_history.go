@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// Every save of the workflow creates a version in workflow.workflow_history with the nodes and connections,
+// the versionId of the version is the versionId of the workflow entity.
+
+var ErrWorkflowVersionNotFound = errors.New("no such workflow version")
+
+// SaveWorkflowHistory saves the nodes and connections of the workflow entity as the version entity.VersionId,
+// with the queries of the transaction which saves the workflow entity.
+func SaveWorkflowHistory(ctx context.Context, queries *rdsDbLib.Queries, workflowEntity *structs.WorkflowEntity, authors string) error {
+	_, err := queries.CreateWorkflowHistory(ctx, rdsDbLib.CreateWorkflowHistoryParams{
+		VersionId:   workflowEntity.VersionId,
+		WorkflowId:  workflowEntity.ID,
+		Authors:     authors,
+		Nodes:       json.RawMessage(JsonStr(workflowEntity.Nodes)),
+		Connections: json.RawMessage(JsonStr(workflowEntity.Connections)),
+	})
+	return err
+}
+
+// List the versions of the workflow without the nodes and connections, the latest first.
+// Returns the page of versions and the total number of versions.
+func ListWorkflowHistory(ctx context.Context, workflowId string, limit int32, offset int32) ([]structs.WorkflowHistoryVersion, int64, error) {
+	rows, err := rdsDbQueries.ListWorkflowHistory(ctx, rdsDbLib.ListWorkflowHistoryParams{
+		WorkflowId: workflowId,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := rdsDbQueries.CountWorkflowHistory(ctx, workflowId)
+	if err != nil {
+		return nil, 0, err
+	}
+	versions := make([]structs.WorkflowHistoryVersion, 0, len(rows))
+	for _, row := range rows {
+		version, err := structs.ToWorkflowHistoryVersion(rdsDbLib.WorkflowWorkflowHistory{
+			VersionId:  row.VersionId,
+			WorkflowId: row.WorkflowId,
+			Authors:    row.Authors,
+			CreatedAt:  row.CreatedAt,
+			UpdatedAt:  row.UpdatedAt,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, count, nil
+}
+
+// Get the version of the workflow with the nodes and connections.
+// If the version does not exist, return ErrWorkflowVersionNotFound.
+func GetWorkflowHistory(ctx context.Context, workflowId string, versionId string) (*structs.WorkflowHistoryVersion, error) {
+	history_RdsDbLib, err := rdsDbQueries.GetWorkflowHistory(ctx, rdsDbLib.GetWorkflowHistoryParams{
+		WorkflowId: workflowId,
+		VersionId:  versionId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkflowVersionNotFound
+		}
+		return nil, err
+	}
+	version, err := structs.ToWorkflowHistoryVersion(history_RdsDbLib)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// DiffWorkflowHistory compares the nodes of two versions of the workflow node by node.
+// The nodes are matched by id, or by name if the node has no id.
+// The added and changed nodes are listed in the order of the "to" version, followed by the removed nodes.
+func DiffWorkflowHistory(from *structs.WorkflowHistoryVersion, to *structs.WorkflowHistoryVersion) *structs.WorkflowHistoryDiff {
+	nodeKey := func(node *structs.WorkflowNode) string {
+		if node.ID != "" {
+			return node.ID
+		}
+		return "name:" + node.Name
+	}
+	fromNodes := make(map[string]*structs.WorkflowNode, len(from.Nodes))
+	for i := range from.Nodes {
+		fromNodes[nodeKey(&from.Nodes[i])] = &from.Nodes[i]
+	}
+
+	diff := &structs.WorkflowHistoryDiff{
+		FromVersionId:      from.VersionId,
+		ToVersionId:        to.VersionId,
+		Nodes:              []structs.WorkflowNodeDiff{},
+		ConnectionsChanged: !jsonEqual(from.Connections, to.Connections),
+	}
+	matched := make(map[string]bool, len(to.Nodes))
+	for i := range to.Nodes {
+		toNode := &to.Nodes[i]
+		key := nodeKey(toNode)
+		nodeDiff := structs.WorkflowNodeDiff{
+			NodeId: toNode.ID,
+			Name:   toNode.Name,
+			Type:   toNode.Type,
+		}
+		fromNode, ok := fromNodes[key]
+		if !ok {
+			nodeDiff.Status = structs.WorkflowNodeDiffStatus_Added
+			diff.Nodes = append(diff.Nodes, nodeDiff)
+			continue
+		}
+		matched[key] = true
+		if fields := diffWorkflowNode(fromNode, toNode); len(fields) > 0 {
+			nodeDiff.Status = structs.WorkflowNodeDiffStatus_Changed
+			nodeDiff.Fields = fields
+			diff.Nodes = append(diff.Nodes, nodeDiff)
+		}
+	}
+	for i := range from.Nodes {
+		fromNode := &from.Nodes[i]
+		if !matched[nodeKey(fromNode)] {
+			diff.Nodes = append(diff.Nodes, structs.WorkflowNodeDiff{
+				NodeId: fromNode.ID,
+				Name:   fromNode.Name,
+				Type:   fromNode.Type,
+				Status: structs.WorkflowNodeDiffStatus_Removed,
+			})
+		}
+	}
+	return diff
+}
+
+// diffWorkflowNode returns the names of the changed fields of the node, the node settings are compared as "settings".
+func diffWorkflowNode(from *structs.WorkflowNode, to *structs.WorkflowNode) []string {
+	fields := []string{}
+	if from.Name != to.Name {
+		fields = append(fields, "name")
+	}
+	if from.Type != to.Type || from.TypeVersion != to.TypeVersion {
+		fields = append(fields, "type")
+	}
+	if !jsonEqual(from.Parameters, to.Parameters) {
+		fields = append(fields, "parameters")
+	}
+	if !jsonEqual(from.Credentials, to.Credentials) {
+		fields = append(fields, "credentials")
+	}
+	if from.Disabled != to.Disabled {
+		fields = append(fields, "disabled")
+	}
+	if !reflect.DeepEqual(from.Position, to.Position) {
+		fields = append(fields, "position")
+	}
+	if from.Notes != to.Notes || from.NotesInFlow != to.NotesInFlow {
+		fields = append(fields, "notes")
+	}
+	if from.RetryOnFail != to.RetryOnFail || from.MaxTries != to.MaxTries || from.WaitBetweenTries != to.WaitBetweenTries ||
+		from.AlwaysOutputData != to.AlwaysOutputData || from.ExecutionOnce != to.ExecutionOnce ||
+		from.OnError != to.OnError || from.ContinueOnFail != to.ContinueOnFail {
+		fields = append(fields, "settings")
+	}
+	return fields
+}
+
+// jsonEqual compares the JSON of the values, so the empty and the missing values are equal.
+func jsonEqual(a interface{}, b interface{}) bool {
+	aJson, bJson := JsonStr(a), JsonStr(b)
+	emptyJson := func(s string) bool { return s == "null" || s == "{}" || s == "[]" }
+	if emptyJson(aJson) && emptyJson(bJson) {
+		return true
+	}
+	return aJson == bJson
+}
@@ -0,0 +1,55 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/workflow_history_test.go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestDiffWorkflowHistory(t *testing.T) {
+
+	t.Run("Diff nodes by id", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		from := &structs.WorkflowHistoryVersion{
+			VersionId: "v1",
+			Nodes: []structs.WorkflowNode{
+				{ID: "1", Name: "Webhook", Type: "n8n-nodes-base.webhook", Position: []int64{0, 0}},
+				{ID: "2", Name: "Code", Type: "n8n-nodes-base.code", Parameters: map[string]interface{}{"jsCode": "return items"}},
+				{ID: "3", Name: "Set", Type: "n8n-nodes-base.set"},
+			},
+			Connections: map[string]structs.WorkflowNodeConnections{},
+		}
+		to := &structs.WorkflowHistoryVersion{
+			VersionId: "v2",
+			Nodes: []structs.WorkflowNode{
+				{ID: "1", Name: "Webhook", Type: "n8n-nodes-base.webhook", Position: []int64{0, 0}},
+				{ID: "2", Name: "Transform", Type: "n8n-nodes-base.code", Parameters: map[string]interface{}{"jsCode": "return []"}, RetryOnFail: true},
+				{ID: "4", Name: "If", Type: "n8n-nodes-base.if"},
+			},
+		}
+
+		diff := core.DiffWorkflowHistory(from, to)
+		assert.Equal("v1", diff.FromVersionId)
+		assert.Equal("v2", diff.ToVersionId)
+		assert.False(diff.ConnectionsChanged)
+		assert.Equal([]structs.WorkflowNodeDiff{
+			{NodeId: "2", Name: "Transform", Type: "n8n-nodes-base.code", Status: structs.WorkflowNodeDiffStatus_Changed,
+				Fields: []string{"name", "parameters", "settings"}},
+			{NodeId: "4", Name: "If", Type: "n8n-nodes-base.if", Status: structs.WorkflowNodeDiffStatus_Added},
+			{NodeId: "3", Name: "Set", Type: "n8n-nodes-base.set", Status: structs.WorkflowNodeDiffStatus_Removed},
+		}, diff.Nodes)
+
+		to.Connections = map[string]structs.WorkflowNodeConnections{
+			"Webhook": {"main": structs.WorkflowNodeInputConnections{{{Node: "Transform", Type: "main"}}}},
+		}
+		assert.True(core.DiffWorkflowHistory(from, to).ConnectionsChanged)
+		assert.Empty(core.DiffWorkflowHistory(to, to).Nodes)
+	})
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+// The statistics of the workflows are maintained by the WorkflowExecuteAfter hook:
+// the all time counters in workflow.workflow_statistics like n8n, and the counts of the production runs by hour,
+// status and duration bucket in workflow.workflow_statistics_hourly for the success rate and the duration percentiles
+// over a window, so the metrics never scan workflow.execution_entity.
+
+const (
+	WorkflowRunStatus_Success = "success"
+	WorkflowRunStatus_Error   = "error"
+
+	// The upper bounds of the duration buckets grow by 2^(1/workflowDurationBucketsPerDoubling), about 19%.
+	workflowDurationBucketsPerDoubling = 4
+)
+
+// WorkflowDurationBucket returns the duration bucket of the run duration.
+func WorkflowDurationBucket(duration time.Duration) int16 {
+	milliseconds := duration.Milliseconds()
+	if milliseconds <= 1 {
+		return 0
+	}
+	return int16(math.Ceil(math.Log2(float64(milliseconds)) * workflowDurationBucketsPerDoubling))
+}
+
+// WorkflowDurationBucketUpperBound returns the upper bound of the duration bucket in milliseconds.
+func WorkflowDurationBucketUpperBound(bucket int16) int64 {
+	return int64(math.Round(math.Pow(2, float64(bucket)/workflowDurationBucketsPerDoubling)))
+}
+
+// workflowRunLoadedData returns whether any node of the run output an item.
+func workflowRunLoadedData(run *structs.Run) bool {
+	if run.Data == nil || run.Data.ResultData == nil {
+		return false
+	}
+	for _, taskDataList := range run.Data.ResultData.RunData {
+		for _, taskData := range taskDataList {
+			if taskData == nil {
+				continue
+			}
+			for _, outputs := range taskData.Data {
+				for _, items := range outputs {
+					if len(items) > 0 {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// RecordWorkflowStatistics updates the statistics of the workflow with the finished run.
+// The waiting and canceled runs are not counted.
+func RecordWorkflowStatistics(ctx context.Context, workflowEntity *structs.WorkflowEntity, mode structs.WorkflowExecutionMode, run *structs.Run) error {
+	var runStatus string
+	switch run.Status {
+	case structs.WorkflowExecutionStatus_Success:
+		runStatus = WorkflowRunStatus_Success
+	case structs.WorkflowExecutionStatus_Error, structs.WorkflowExecutionStatus_Failed, structs.WorkflowExecutionStatus_Crashed:
+		runStatus = WorkflowRunStatus_Error
+	default:
+		return nil
+	}
+	production := mode != structs.WorkflowExecutionMode_Manual
+	var name structs.WorkflowStatisticsName
+	switch {
+	case production && runStatus == WorkflowRunStatus_Success:
+		name = structs.WorkflowStatisticsName_ProductionSuccess
+	case production:
+		name = structs.WorkflowStatisticsName_ProductionError
+	case runStatus == WorkflowRunStatus_Success:
+		name = structs.WorkflowStatisticsName_ManualSuccess
+	default:
+		name = structs.WorkflowStatisticsName_ManualError
+	}
+	stoppedAt := time.Now()
+	if run.StoppedAt != nil {
+		stoppedAt = *run.StoppedAt
+	}
+
+	err := rdsDbQueries.IncrementWorkflowStatistics(ctx, rdsDbLib.IncrementWorkflowStatisticsParams{
+		Name:        string(name),
+		WorkflowId:  workflowEntity.ID,
+		LatestEvent: sql.NullTime{Time: stoppedAt, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if workflowRunLoadedData(run) {
+		err := rdsDbQueries.IncrementWorkflowStatistics(ctx, rdsDbLib.IncrementWorkflowStatisticsParams{
+			Name:        string(structs.WorkflowStatisticsName_DataLoaded),
+			WorkflowId:  workflowEntity.ID,
+			LatestEvent: sql.NullTime{Time: stoppedAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if production && run.StartedAt != nil {
+		return rdsDbQueries.IncrementWorkflowStatisticsHourly(ctx, rdsDbLib.IncrementWorkflowStatisticsHourlyParams{
+			WorkflowId:     workflowEntity.ID,
+			Hour:           stoppedAt.Truncate(time.Hour),
+			Status:         runStatus,
+			DurationBucket: WorkflowDurationBucket(stoppedAt.Sub(*run.StartedAt)),
+		})
+	}
+	return nil
+}
+
+// The number of the production runs with the status in the duration bucket.
+type WorkflowRunCount struct {
+	Status         string
+	DurationBucket int16
+	Count          int64
+}
+
+// SummarizeWorkflowRuns sets the runs, success rate and duration percentiles of the statistics from the run counts.
+func SummarizeWorkflowRuns(statistics *structs.WorkflowStatistics, runCounts []WorkflowRunCount) {
+	countByBucket := map[int16]int64{}
+	for _, runCount := range runCounts {
+		statistics.Runs += runCount.Count
+		if runCount.Status == WorkflowRunStatus_Success {
+			statistics.Successes += runCount.Count
+		} else {
+			statistics.Errors += runCount.Count
+		}
+		countByBucket[runCount.DurationBucket] += runCount.Count
+	}
+	if statistics.Runs == 0 {
+		return
+	}
+	statistics.SuccessRate = float64(statistics.Successes) / float64(statistics.Runs)
+
+	buckets := make([]int16, 0, len(countByBucket))
+	for bucket := range countByBucket {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	percentile := func(p float64) int64 {
+		rank := int64(math.Ceil(p * float64(statistics.Runs)))
+		cumulative := int64(0)
+		for _, bucket := range buckets {
+			cumulative += countByBucket[bucket]
+			if cumulative >= rank {
+				return WorkflowDurationBucketUpperBound(bucket)
+			}
+		}
+		return WorkflowDurationBucketUpperBound(buckets[len(buckets)-1])
+	}
+	statistics.DurationP50 = percentile(0.5)
+	statistics.DurationP95 = percentile(0.95)
+}
+
+// addWorkflowStatisticsCounter adds the counter to the statistics, the last run is the latest success or error.
+func addWorkflowStatisticsCounter(statistics *structs.WorkflowStatistics, counter rdsDbLib.WorkflowWorkflowStatistic) {
+	name := structs.WorkflowStatisticsName(counter.Name)
+	statistics.Counters[name] = int64(counter.Count.Int32)
+	if name == structs.WorkflowStatisticsName_DataLoaded || !counter.LatestEvent.Valid {
+		return
+	}
+	if statistics.LastRunAt == nil || counter.LatestEvent.Time.After(*statistics.LastRunAt) {
+		latestEvent := counter.LatestEvent.Time
+		statistics.LastRunAt = &latestEvent
+	}
+}
+
+// Get the statistics of the workflow, the runs are counted since the given time.
+func GetWorkflowStatistics(ctx context.Context, workflowEntity *structs.WorkflowEntity, since time.Time) (*structs.WorkflowStatistics, error) {
+	counters, err := rdsDbQueries.ListWorkflowStatistics(ctx, workflowEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := rdsDbQueries.SumWorkflowStatisticsHourly(ctx, rdsDbLib.SumWorkflowStatisticsHourlyParams{
+		WorkflowId: workflowEntity.ID,
+		Since:      since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := &structs.WorkflowStatistics{
+		WorkflowId:   workflowEntity.ID,
+		WorkflowName: workflowEntity.Name,
+		Counters:     map[structs.WorkflowStatisticsName]int64{},
+		Since:        &since,
+	}
+	for _, counter := range counters {
+		addWorkflowStatisticsCounter(statistics, counter)
+	}
+	runCounts := make([]WorkflowRunCount, 0, len(rows))
+	for _, row := range rows {
+		runCounts = append(runCounts, WorkflowRunCount{Status: row.Status, DurationBucket: row.DurationBucket, Count: row.Count})
+	}
+	SummarizeWorkflowRuns(statistics, runCounts)
+	return statistics, nil
+}
+
+// List the statistics of the workflows of the org with any run, the workflows with the most errors first.
+func ListWorkflowStatistics(ctx context.Context, orgId string, since time.Time) ([]structs.WorkflowStatistics, error) {
+	workflowEntities, err := ListWorkflowEntities(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	counters, err := rdsDbQueries.ListWorkflowStatisticsByOrg(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := rdsDbQueries.SumWorkflowStatisticsHourlyByOrg(ctx, rdsDbLib.SumWorkflowStatisticsHourlyByOrgParams{
+		SugerOrgId: orgId,
+		Since:      since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statisticsByWorkflowId := map[string]*structs.WorkflowStatistics{}
+	for _, workflowEntity := range workflowEntities {
+		statisticsByWorkflowId[workflowEntity.ID] = &structs.WorkflowStatistics{
+			WorkflowId:   workflowEntity.ID,
+			WorkflowName: workflowEntity.Name,
+			Counters:     map[structs.WorkflowStatisticsName]int64{},
+			Since:        &since,
+		}
+	}
+	for _, counter := range counters {
+		if statistics, ok := statisticsByWorkflowId[counter.WorkflowId]; ok {
+			addWorkflowStatisticsCounter(statistics, counter)
+		}
+	}
+	runCountsByWorkflowId := map[string][]WorkflowRunCount{}
+	for _, row := range rows {
+		runCountsByWorkflowId[row.WorkflowId] = append(runCountsByWorkflowId[row.WorkflowId],
+			WorkflowRunCount{Status: row.Status, DurationBucket: row.DurationBucket, Count: row.Count})
+	}
+
+	result := make([]structs.WorkflowStatistics, 0)
+	for _, workflowEntity := range workflowEntities {
+		statistics := statisticsByWorkflowId[workflowEntity.ID]
+		if statistics.LastRunAt == nil {
+			continue
+		}
+		SummarizeWorkflowRuns(statistics, runCountsByWorkflowId[workflowEntity.ID])
+		result = append(result, *statistics)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Errors != result[j].Errors {
+			return result[i].Errors > result[j].Errors
+		}
+		return result[i].SuccessRate < result[j].SuccessRate
+	})
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package core_test
+
+// Command to run this test file only.
+// go test -v service/workflow_service/core/init_test.go service/workflow_service/core/workflow_statistics_test.go
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func TestWorkflowStatistics(t *testing.T) {
+
+	t.Run("Duration buckets and percentiles", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+
+		assert.Equal(int16(0), core.WorkflowDurationBucket(0))
+		assert.Equal(int64(1024), core.WorkflowDurationBucketUpperBound(core.WorkflowDurationBucket(time.Second)))
+		for _, duration := range []time.Duration{3 * time.Millisecond, 250 * time.Millisecond, 42 * time.Second, time.Hour} {
+			upperBound := core.WorkflowDurationBucketUpperBound(core.WorkflowDurationBucket(duration))
+			assert.GreaterOrEqual(upperBound, duration.Milliseconds())
+			assert.LessOrEqual(float64(upperBound), float64(duration.Milliseconds())*1.2+1)
+		}
+
+		statistics := structs.WorkflowStatistics{}
+		core.SummarizeWorkflowRuns(&statistics, []core.WorkflowRunCount{
+			{Status: core.WorkflowRunStatus_Success, DurationBucket: core.WorkflowDurationBucket(100 * time.Millisecond), Count: 90},
+			{Status: core.WorkflowRunStatus_Error, DurationBucket: core.WorkflowDurationBucket(100 * time.Millisecond), Count: 4},
+			{Status: core.WorkflowRunStatus_Error, DurationBucket: core.WorkflowDurationBucket(10 * time.Second), Count: 6},
+		})
+		assert.Equal(int64(100), statistics.Runs)
+		assert.Equal(int64(90), statistics.Successes)
+		assert.Equal(int64(10), statistics.Errors)
+		assert.Equal(0.9, statistics.SuccessRate)
+		assert.Equal(core.WorkflowDurationBucketUpperBound(core.WorkflowDurationBucket(100*time.Millisecond)), statistics.DurationP50)
+		assert.Equal(core.WorkflowDurationBucketUpperBound(core.WorkflowDurationBucket(10*time.Second)), statistics.DurationP95)
+
+		empty := structs.WorkflowStatistics{}
+		core.SummarizeWorkflowRuns(&empty, nil)
+		assert.Equal(0.0, empty.SuccessRate)
+	})
+
+	t.Run("Record and get the statistics", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(t)
+		ctx := context.Background()
+		orgId := uuid.NewString()[:8]
+		workflowEntity_RdsDbLib, err := rdsDbQueries.CreateWorkflowEntity(ctx, rdsDbLib.CreateWorkflowEntityParams{
+			Name:        "statistics",
+			Nodes:       json.RawMessage("[]"),
+			Connections: json.RawMessage("{}"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  orgId,
+		})
+		assert.Nil(err)
+		workflowEntity, err := structs.ToWorkflowEntity(workflowEntity_RdsDbLib)
+		assert.Nil(err)
+
+		startedAt := time.Now().Add(-time.Second)
+		stoppedAt := time.Now()
+		runs := []struct {
+			mode   structs.WorkflowExecutionMode
+			status structs.WorkflowExecutionStatus
+		}{
+			{structs.WorkflowExecutionMode_Trigger, structs.WorkflowExecutionStatus_Success},
+			{structs.WorkflowExecutionMode_Webhook, structs.WorkflowExecutionStatus_Failed},
+			{structs.WorkflowExecutionMode_Trigger, structs.WorkflowExecutionStatus_Success},
+			{structs.WorkflowExecutionMode_Manual, structs.WorkflowExecutionStatus_Success},
+			{structs.WorkflowExecutionMode_Trigger, structs.WorkflowExecutionStatus_Waiting},
+		}
+		for _, run := range runs {
+			err := core.RecordWorkflowStatistics(ctx, &workflowEntity, run.mode, &structs.Run{
+				Status:    run.status,
+				StartedAt: &startedAt,
+				StoppedAt: &stoppedAt,
+			})
+			assert.Nil(err)
+		}
+
+		statistics, err := core.GetWorkflowStatistics(ctx, &workflowEntity, time.Now().Add(-time.Hour))
+		assert.Nil(err)
+		assert.Equal(map[structs.WorkflowStatisticsName]int64{
+			structs.WorkflowStatisticsName_ProductionSuccess: 2,
+			structs.WorkflowStatisticsName_ProductionError:   1,
+			structs.WorkflowStatisticsName_ManualSuccess:     1,
+		}, statistics.Counters)
+		assert.Equal(int64(3), statistics.Runs)
+		assert.InDelta(2.0/3, statistics.SuccessRate, 0.001)
+		assert.Equal(int64(1024), statistics.DurationP95)
+		assert.NotNil(statistics.LastRunAt)
+
+		list, err := core.ListWorkflowStatistics(ctx, orgId, time.Now().Add(-time.Hour))
+		assert.Nil(err)
+		assert.Len(list, 1)
+		assert.Equal("statistics", list[0].WorkflowName)
+	})
+}
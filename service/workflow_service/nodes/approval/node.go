@@ -0,0 +1,215 @@
+package approval
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	// Category is the category of ApprovalNode.
+	Category = structs.CategoryExecutor
+
+	// Name is the name of ApprovalNode.
+	Name = "n8n-nodes-base.approval"
+
+	// The outputs of the node.
+	OutputIndex_Approved = 0
+	OutputIndex_Rejected = 1
+	OutputIndex_Timeout  = 2
+
+	// MaxTimeout is the longest an approval can wait for the decision.
+	MaxTimeout = 365 * 24 * time.Hour
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	ApprovalExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	ParameterOptions struct {
+		RequireComment bool `json:"requireComment"`
+	}
+)
+
+func init() {
+	executor := &ApprovalExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	executor.spec.JsonConfig = rawJson
+	executor.spec.GenerateSpec()
+
+	core.Register(executor)
+}
+
+func (executor *ApprovalExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (executor *ApprovalExecutor) Name() string {
+	return Name
+}
+
+func (executor *ApprovalExecutor) DefaultSpec() interface{} {
+	return executor.spec
+}
+
+// Execute requests the approval and puts the execution to wait till it expires. When the execution is
+// resumed, the items are routed to the output of the decision, with the decision in the "approval" key.
+func (executor *ApprovalExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+	if input.AdditionalData == nil || input.RunExecutionData == nil {
+		return core.GenerateFailedResponse(Name, fmt.Errorf("the approval requires a saved execution"))
+	}
+	executionId, err := strconv.Atoi(input.AdditionalData.Hooks.ExecutionId)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, fmt.Errorf("the approval requires a saved execution"))
+	}
+
+	approval, err := core.GetApprovalOfNode(ctx, int32(executionId), input.Params.Name, input.RunIndex)
+	if errors.Is(err, core.ErrApprovalNotFound) {
+		return executor.requestApproval(ctx, input, int32(executionId))
+	} else if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+
+	if approval.Status == structs.WorkflowApprovalStatus_Pending {
+		approval, err = core.ExpireApproval(ctx, approval.ID)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		if approval.Status == structs.WorkflowApprovalStatus_Pending {
+			// Resumed before the approval is decided, keep waiting.
+			return core.GenerateWaitingResponse(input.RunExecutionData, *approval.ExpiresAt)
+		}
+	}
+
+	outputs := []structs.NodeData{{}, {}, {}}
+	outputIndex := OutputIndex_Timeout
+	switch approval.Status {
+	case structs.WorkflowApprovalStatus_Approved:
+		outputIndex = OutputIndex_Approved
+	case structs.WorkflowApprovalStatus_Rejected:
+		outputIndex = OutputIndex_Rejected
+	}
+	decision := map[string]interface{}{
+		"id":     approval.ID,
+		"status": string(approval.Status),
+	}
+	if approval.DecidedAt != nil {
+		decision["decidedAt"] = approval.DecidedAt.UTC().Format(time.RFC3339)
+	}
+	if approval.Comment != "" {
+		decision["comment"] = approval.Comment
+	}
+	for _, item := range items {
+		json := map[string]interface{}{}
+		if itemJson, ok := item["json"].(map[string]interface{}); ok {
+			for key, value := range itemJson {
+				json[key] = value
+			}
+		}
+		json["approval"] = decision
+		newItem := structs.NodeSingleData{}
+		for key, value := range item {
+			newItem[key] = value
+		}
+		newItem["json"] = json
+		outputs[outputIndex] = append(outputs[outputIndex], newItem)
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, outputs)
+}
+
+// requestApproval creates the approval of the node run, notifies it and puts the execution to wait.
+func (executor *ApprovalExecutor) requestApproval(
+	ctx context.Context, input *structs.NodeExecuteInput, executionId int32) *structs.NodeExecutionResult {
+	message, err := core.GetNodeParameterAsBasicType(Name, "message", "", input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	timeoutAmountValue, err := core.GetNodeParameter(Name, "timeoutAmount", 1, input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	timeoutAmount, err := core.ConvertToFloat(timeoutAmountValue)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, fmt.Errorf("timeoutAmount is not a number: %w", err))
+	}
+	timeoutUnit, err := core.GetNodeParameterAsBasicType(Name, "timeoutUnit", "days", input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	timeout, err := getTimeout(timeoutAmount, timeoutUnit)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	// The links are only sent to the notification URL, without it the approval could only time out.
+	notificationUrl, err := core.GetNodeParameterAsBasicType(Name, "notificationUrl", "", input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	if notificationUrl == "" {
+		return core.GenerateFailedResponse(Name, fmt.Errorf("notificationUrl is required"))
+	}
+	options, err := core.GetNodeParameterAsType(Name, "options", ParameterOptions{}, input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+
+	approval, err := core.CreateApproval(ctx, core.CreateApprovalParams{
+		ExecutionId:    executionId,
+		WorkflowId:     input.WorkflowID,
+		NodeName:       input.Params.Name,
+		RunIndex:       input.RunIndex,
+		Message:        message,
+		RequireComment: options.RequireComment,
+		ExpiresAt:      time.Now().Add(timeout),
+	})
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	if approval.Status == structs.WorkflowApprovalStatus_Pending {
+		if err := core.SetApprovalLinks(approval); err != nil {
+			return core.GenerateFailedResponse(Name, fmt.Errorf("failed to sign the approval links: %w", err))
+		}
+	}
+	if err := core.NotifyApproval(ctx, notificationUrl, approval); err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	return core.GenerateWaitingResponse(input.RunExecutionData, *approval.ExpiresAt)
+}
+
+// getTimeout returns the duration of the timeout amount in the unit, at most MaxTimeout.
+func getTimeout(amount float64, unit string) (time.Duration, error) {
+	if amount < 0 {
+		return 0, fmt.Errorf("timeoutAmount must not be negative")
+	}
+	var unitDuration time.Duration
+	switch unit {
+	case "minutes":
+		unitDuration = time.Minute
+	case "hours":
+		unitDuration = time.Hour
+	case "days":
+		unitDuration = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("unknown timeoutUnit %s", unit)
+	}
+	// Checked before the conversion, which overflows for a large amount.
+	timeout := amount * float64(unitDuration)
+	if !(timeout <= float64(MaxTimeout)) {
+		return 0, fmt.Errorf("the timeout must not be longer than %d days", MaxTimeout/(24*time.Hour))
+	}
+	return time.Duration(timeout), nil
+}
@@ -78,6 +78,7 @@ func getSandbox(
 		ItemIndex: itemIndex,
 		RunData:   runData,
 	}
+	context.SetupExecutionInfo(input)
 
 	sandbox := core.Sandbox{
 		Lang:    CodeLanguageJs,
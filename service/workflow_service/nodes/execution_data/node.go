@@ -0,0 +1,84 @@
+package execution_data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	// Category is the category of ExecutionDataNode.
+	Category = structs.CategoryExecutor
+
+	// Name is the name of ExecutionDataNode.
+	Name = "n8n-nodes-base.executionData"
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	ExecutionDataExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	// A key and value of the "dataToSave" parameter, the value may be a number or boolean from an expression.
+	ExecutionDataValue struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}
+)
+
+func init() {
+	executor := &ExecutionDataExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	executor.spec.JsonConfig = rawJson
+	executor.spec.GenerateSpec()
+
+	core.Register(executor)
+}
+
+func (executor *ExecutionDataExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (executor *ExecutionDataExecutor) Name() string {
+	return Name
+}
+
+func (executor *ExecutionDataExecutor) DefaultSpec() interface{} {
+	return executor.spec
+}
+
+// Execute saves the key and value pairs of every item as the custom data of the execution,
+// the later items overwrite the same keys. The items are passed through.
+func (executor *ExecutionDataExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+	if input.RunExecutionData == nil || input.RunExecutionData.ResultData == nil {
+		return core.GenerateFailedResponse(Name, fmt.Errorf("the execution has no result data"))
+	}
+	resultData := input.RunExecutionData.ResultData
+
+	for itemIndex := range items {
+		values, err := core.GetNodeParameterAsType(Name, "dataToSave.values", []ExecutionDataValue{}, input, itemIndex)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		for _, value := range *values {
+			if value.Key == "" {
+				continue
+			}
+			err := core.SetExecutionCustomData(resultData, value.Key, core.ExecutionCustomDataValue(value.Value))
+			if err != nil {
+				return core.GenerateFailedResponse(Name, err)
+			}
+		}
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, []structs.NodeData{items})
+}
@@ -1,9 +1,10 @@
 package form_trigger
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
+	"errors"
+	"time"
 
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
@@ -29,12 +30,6 @@ type FormTrigger struct {
 	spec *structs.WorkflowNodeSpec
 }
 
-type formTriggerOutput struct {
-	Body    string            `json:"body"`
-	Headers map[string]string `json:"headers"`
-	Query   map[string]string `json:"query"`
-}
-
 func init() {
 
 	trigger := &FormTrigger{
@@ -60,22 +55,36 @@ func (ft *FormTrigger) DefaultSpec() interface{} {
 }
 
 func (ft *FormTrigger) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
-
-	request := input.AdditionalData.HttpRequest
-
-	node := input.Params
-	if bytes.Equal(request.Header.Method(), []byte("GET")) {
-		return core.GenerateSuccessResponse(structs.NodeData{
-			{
-				"parameters": node.Parameters,
-			},
-		}, []structs.NodeData{})
+	if input.AdditionalData == nil {
+		return core.GenerateFailedResponse(Name, errors.New("no form submission"))
 	}
 
-	// POST
-	return core.GenerateSuccessResponse(structs.NodeData{
-		{
-			"parameters": node.Parameters,
-		},
-	}, []structs.NodeData{})
+	// The submitted values keyed by the field labels, and the time of the submission.
+	// The submission is parsed by the API when the form is submitted, else from the request.
+	returnItem, binary := map[string]interface{}{}, map[string]structs.WorkflowBinaryData{}
+	if submission := input.AdditionalData.FormSubmission; submission != nil {
+		for key, value := range submission["json"].(map[string]interface{}) {
+			returnItem[key] = value
+		}
+		binary, _ = submission["binary"].(map[string]structs.WorkflowBinaryData)
+	} else if input.AdditionalData.HttpRequest != nil {
+		form, err := core.GetFormTriggerParameters(input.Params)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		returnItem, binary, err = core.ParseFormTriggerSubmission(input.AdditionalData.HttpRequest, form)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+	} else {
+		return core.GenerateFailedResponse(Name, errors.New("no form submission"))
+	}
+	returnItem["submittedAt"] = time.Now().UTC().Format(time.RFC3339)
+	item := structs.NodeSingleData{
+		"json": returnItem,
+	}
+	if len(binary) > 0 {
+		item["binary"] = binary
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{item}, []structs.NodeData{})
 }
@@ -209,7 +209,9 @@ func (he *HtmlExecutor) Execute(ctx context.Context, input *structs.NodeExecuteI
 		Params:    input.Params.Parameters,
 		Functions: core.BuiltInFunctions,
 	}
+	sbc.SetupExecutionInfo(input)
 	eval := core.NewExpressionEvaluator(&sbc)
+	defer eval.Release()
 
 ItemLoop:
 	for itemIndex, item := range items {
@@ -7,12 +7,10 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net"
 	"net/http"
 	"net/url"
 	"path"
@@ -59,43 +57,8 @@ var (
 		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		"application/x-7z-compressed",
 	}
-
-	fileTypes = map[string]string{
-		"image/":                 "image",
-		"audio/":                 "audio",
-		"video/":                 "video",
-		"text/html":              "html",
-		"text/":                  "text",
-		"application/json":       "json",
-		"application/javascript": "text",
-		"application/pdf":        "pdf",
-	}
-
-	extensions = map[string]string{
-		"application/pdf":             ".pdf",
-		"application/zip":             ".zip",
-		"application/gzip":            ".gz",
-		"application/x-gzip":          ".gz",
-		"application/x-tar":           ".tar",
-		"application/x-7z-compressed": ".7z",
-		"audio/mpeg":                  ".mp3",
-		"audio/wav":                   ".wav",
-		"audio/ogg":                   ".ogg",
-		"image/jpeg":                  ".jpeg",
-		"image/png":                   ".png",
-		"image/gif":                   ".gif",
-		"image/bmp":                   ".bmp",
-		"image/webp":                  ".webp",
-		"video/mp4":                   ".mp4",
-		"video/mpeg":                  ".mpeg",
-		"video/quicktime":             ".mov",
-		"video/x-msvideo":             ".avi",
-		"text/plain":                  ".txt",
-		"text/html":                   ".html",
-		"text/css":                    ".css",
-		"application/json":            ".json",
-		"application/xml":             ".xml",
-	}
+	// publicHttpClient never dials the internal network, also after the redirects
+	publicHttpClient = core.NewPublicHttpClient(0)
 )
 
 type (
@@ -262,7 +225,7 @@ func (hre *HttpRequestExecutor) Execute(ctx context.Context, input *structs.Node
 
 	sendBodyHttpMethods := []string{"PATCH", "POST", "PUT", "GET"}
 	responseChannel := make(chan ResponseWithIndex)
-	client := &http.Client{}
+	client := publicHttpClient
 	// TODO: set total timeout here
 	parentCtx, cancelCtx := context.WithCancel(ctx)
 	defer cancelCtx()
@@ -373,7 +336,7 @@ func (hre *HttpRequestExecutor) Execute(ctx context.Context, input *structs.Node
 		if err != nil || url == "" {
 			return core.GenerateFailedResponse(Name, err)
 		}
-		err = validateUrl(url)
+		err = core.ValidateUrl(url)
 		if err != nil {
 			return core.GenerateFailedResponse(Name, err)
 		}
@@ -724,57 +687,20 @@ func prepareBinaryData(response *http.Response, body []byte) structs.WorkflowBin
 	contentType := response.Header.Get("Content-Type")
 	filePath := response.Request.URL.Path
 
-	if contentType == "" {
-		contentType = "text/plain"
+	if filePath == "" {
+		return core.NewBinaryData(body, contentType, "")
 	}
-
-	data := structs.WorkflowBinaryData{
-		MimeType:      contentType,
-		FileType:      structs.WorkflowBinaryFileType(fileTypeFromMimeType(contentType)),
-		FileExtension: fileExtensionFromMimeType(contentType),
+	if strings.Contains(filePath, "?") {
+		filePath = strings.Split(filePath, "?")[0]
 	}
-
-	if filePath != "" {
-		if strings.Contains(filePath, "?") {
-			filePath = strings.Split(filePath, "?")[0]
-		}
-		filePathParts := path.Base(filePath)
-		dir := path.Dir(filePath)
-		if dir != "." {
-			data.Directory = dir
-		}
-		data.FileName = filePathParts
-		fileExtension := strings.TrimPrefix(path.Ext(filePath), ".")
-		if fileExtension != "" {
-			data.FileExtension = fileExtension
-		}
+	data := core.NewBinaryData(body, contentType, path.Base(filePath))
+	dir := path.Dir(filePath)
+	if dir != "." {
+		data.Directory = dir
 	}
-
-	base64String := base64.StdEncoding.EncodeToString(body)
-	data.Base64Encoded = true
-	data.Data = base64String
-	data.FileSize = prettyBytes(float64(len(body)))
 	return data
 }
 
-func fileTypeFromMimeType(mimeType string) string {
-	for ct, fileType := range fileTypes {
-		if strings.HasPrefix(mimeType, ct) {
-			return fileType
-		}
-	}
-	return ""
-}
-
-func fileExtensionFromMimeType(mimeType string) string {
-	for ct, ext := range extensions {
-		if strings.HasPrefix(mimeType, ct) {
-			return ext
-		}
-	}
-	return ""
-}
-
 func toPagination(raw interface{}) (*Pagination, error) {
 	pagination := &Pagination{}
 	if raw == nil {
@@ -1088,37 +1014,3 @@ func checkBinaryContentType(contentType string) bool {
 	}
 	return false
 }
-
-// Validate the URL. It should not be an IP address or a local hostname.
-// Return an error if the URL is invalid. Otherwise, return nil.
-func validateUrl(urlStr string) error {
-	parsedURL, err := url.Parse(urlStr)
-	if err != nil {
-		return err
-	}
-	hostname := parsedURL.Hostname()
-	ip := net.ParseIP(hostname)
-	if ip != nil {
-		if strings.HasPrefix(hostname, "10.") || strings.HasPrefix(hostname, "192.168") || hostname == "127.0.0.1" {
-			return fmt.Errorf("%s forbidden ip address", urlStr)
-		}
-	} else {
-		if strings.HasSuffix(hostname, ".svc") || strings.Contains(hostname, "localhost") {
-			return fmt.Errorf("%s forbidden hostname", urlStr)
-		}
-	}
-
-	return nil
-}
-
-func prettyBytes(size float64) string {
-	units := []string{"B", "KB", "MB", "GB", "TB"}
-
-	unitIndex := 0
-	for size >= 1024 && unitIndex < len(units)-1 {
-		size /= 1024
-		unitIndex++
-	}
-
-	return fmt.Sprintf("%.2f %s", size, units[unitIndex])
-}
@@ -0,0 +1,164 @@
+package remove_duplicates
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	Category = structs.CategoryExecutor
+	Name     = "n8n-nodes-base.removeDuplicates"
+
+	Compare_AllFields       = "allFields"
+	Compare_AllFieldsExcept = "allFieldsExcept"
+	Compare_SelectedFields  = "selectedFields"
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	RemoveDuplicatesExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	ParameterOptions struct {
+		DisableDotNotation bool `json:"disableDotNotation"`
+		RemoveOtherFields  bool `json:"removeOtherFields"`
+	}
+)
+
+func init() {
+	re := &RemoveDuplicatesExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	re.spec.JsonConfig = rawJson
+	re.spec.GenerateSpec()
+
+	core.Register(re)
+}
+
+func (re *RemoveDuplicatesExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (re *RemoveDuplicatesExecutor) Name() string {
+	return Name
+}
+
+func (re *RemoveDuplicatesExecutor) DefaultSpec() interface{} {
+	return re.spec
+}
+
+// Execute keeps the first of the items with the same values of the compared fields.
+func (re *RemoveDuplicatesExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+
+	compare, err := core.GetNodeParameterAsBasicType(Name, "compare", Compare_AllFields, input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	options, err := core.GetNodeParameterAsType(Name, "options", ParameterOptions{}, input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	dotNotation := !options.DisableDotNotation
+
+	var getCompared func(itemJson map[string]interface{}) interface{}
+	switch compare {
+	case Compare_AllFields:
+		getCompared = func(itemJson map[string]interface{}) interface{} {
+			return itemJson
+		}
+	case Compare_AllFieldsExcept:
+		fieldsToExclude, err := core.GetNodeParameterAsBasicType(Name, "fieldsToExclude", "", input, 0)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		fields := core.SplitFieldNames(fieldsToExclude)
+		if len(fields) == 0 {
+			return core.GenerateFailedResponse(Name, fmt.Errorf("no fields specified, please add a field to exclude from comparison"))
+		}
+		getCompared = func(itemJson map[string]interface{}) interface{} {
+			compared := core.DeepCopyJson(itemJson).(map[string]interface{})
+			for _, field := range fields {
+				core.DeleteItemFieldValue(compared, field, dotNotation)
+			}
+			return compared
+		}
+	case Compare_SelectedFields:
+		fieldsToCompare, err := core.GetNodeParameterAsBasicType(Name, "fieldsToCompare", "", input, 0)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		fields := core.SplitFieldNames(fieldsToCompare)
+		if len(fields) == 0 {
+			return core.GenerateFailedResponse(Name, fmt.Errorf("no fields specified, please add a field to compare on"))
+		}
+		for _, field := range fields {
+			for _, item := range items {
+				if _, ok := core.GetItemFieldValue(getItemJson(item), field, dotNotation); !ok {
+					return core.GenerateFailedResponse(Name, fmt.Errorf("'%s' field is missing from some input items", field))
+				}
+			}
+		}
+		getCompared = func(itemJson map[string]interface{}) interface{} {
+			compared := make([]interface{}, 0, len(fields))
+			for _, field := range fields {
+				value, _ := core.GetItemFieldValue(itemJson, field, dotNotation)
+				compared = append(compared, value)
+			}
+			return compared
+		}
+		if options.RemoveOtherFields {
+			items = getItemsWithFields(items, fields, dotNotation)
+		}
+	default:
+		return core.GenerateFailedResponse(Name, fmt.Errorf("unknown compare %s", compare))
+	}
+
+	// The JSON of the compared values is the key, the numbers of the same value and the objects of the same
+	// fields in any order have the same JSON.
+	seen := map[string]bool{}
+	result := structs.NodeData{}
+	for _, item := range items {
+		key, err := json.Marshal(getCompared(getItemJson(item)))
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		result = append(result, item)
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, []structs.NodeData{result})
+}
+
+// getItemsWithFields returns the items with only the fields.
+func getItemsWithFields(items structs.NodeData, fields []string, dotNotation bool) structs.NodeData {
+	result := make(structs.NodeData, 0, len(items))
+	for _, item := range items {
+		itemJson := getItemJson(item)
+		newJson := map[string]interface{}{}
+		for _, field := range fields {
+			if value, ok := core.GetItemFieldValue(itemJson, field, dotNotation); ok {
+				core.SetItemFieldValue(newJson, field, core.DeepCopyJson(value), dotNotation)
+			}
+		}
+		result = append(result, structs.NodeSingleData{"json": newJson})
+	}
+	return result
+}
+
+func getItemJson(item structs.NodeSingleData) map[string]interface{} {
+	itemJson, _ := item["json"].(map[string]interface{})
+	return itemJson
+}
@@ -5,7 +5,11 @@ import (
 	_ "embed"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 	"github.com/valyala/fasthttp"
@@ -73,8 +77,10 @@ func (wh *RespondToWebhook) Execute(ctx context.Context, input *structs.NodeExec
 
 	responseCode := 0
 	responseKey := ""
+	contentDisposition := ""
 	headers := make(map[string]string)
 	if options != nil {
+		contentDisposition = options.ContentDisposition
 		if options.ResponseCode != 0 {
 			responseCode = options.ResponseCode
 		}
@@ -89,6 +95,7 @@ func (wh *RespondToWebhook) Execute(ctx context.Context, input *structs.NodeExec
 	}
 
 	inputData := core.GetInputData(input.Data)
+	var binaryData *structs.WorkflowBinaryData
 	switch respondWith {
 	case structs.WebhookRespondWith_FirstIncomingItem: // json field of first incoming item
 		if len(inputData) == 0 {
@@ -126,23 +133,58 @@ func (wh *RespondToWebhook) Execute(ctx context.Context, input *structs.NodeExec
 		// n8n did not set content type, and it automatically used text/html
 		setContentType(headers, "text/html; charset=utf-8")
 	case structs.WebhookRespondWith_Redirect:
-		if responseCode == 0 {
-			responseCode = 307
+		redirectUrl, err := core.GetNodeParameterAsBasicType(Name, "redirectURL", "", input, 0)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		if err := validateRedirectUrl(redirectUrl); err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		responseCode, err = getRedirectStatusCode(input, responseCode)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		headers["location"] = redirectUrl
+	case structs.WebhookRespondWith_Binary:
+		if len(inputData) == 0 {
+			return core.GenerateFailedResponse(Name, errors.New("empty incoming items"))
+		}
+		// The only binary data of the item if the input field is chosen automatically
+		propertyName := ""
+		if source, _ := node.Parameters["responseDataSource"].(string); source == "set" {
+			propertyName, err = core.GetNodeParameterAsBasicType(Name, "inputFieldName", "data", input, 0)
+			if err != nil {
+				return core.GenerateFailedResponse(Name, err)
+			}
 		}
-		url, err := core.GetNodeParameterAsBasicType(Name, "redirectURL", "", input, 0)
+		binaryData, err = core.GetWebhookResponseBinaryData(inputData[0], propertyName)
 		if err != nil {
 			return core.GenerateFailedResponse(Name, err)
 		}
-		headers["location"] = url
+	case structs.WebhookRespondWith_Jwt:
+		token, err := signJwt(ctx, input)
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		responseBytes, err = json.Marshal(map[string]string{"token": token})
+		if err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+		setContentType(headers, "application/json; charset=utf-8")
 	case structs.WebhookRespondWith_NoData:
 		// do nothing
-	// TODO binary type
 	default:
 		return core.GenerateFailedResponse(Name, errors.New("Unknown value of respondWith parameter: "+string(respondWith)))
 	}
 
 	response := &fasthttp.Response{}
-	response.SetBody(responseBytes)
+	if binaryData != nil {
+		if err := core.SetWebhookBinaryResponse(response, binaryData, contentDisposition); err != nil {
+			return core.GenerateFailedResponse(Name, err)
+		}
+	} else {
+		response.SetBody(responseBytes)
+	}
 	if responseCode == 0 {
 		responseCode = 200
 	}
@@ -183,6 +225,8 @@ type ResponseOptions struct {
 	ResponseCode    int                   `json:"responseCode"`
 	ResponseKey     string                `json:"responseKey"`
 	ResponseHeaders ResponseHeadersOption `json:"responseHeaders"`
+	// for respondWith:binary, attachment or inline
+	ContentDisposition string `json:"contentDisposition"`
 }
 
 type ResponseHeadersOption struct {
@@ -218,3 +262,68 @@ func getResponseOptions(node *structs.WorkflowNode) (*ResponseOptions, error) {
 func setContentType(headers map[string]string, contentType string) {
 	headers["Content-Type"] = contentType
 }
+
+// validateRedirectUrl rejects the URL which is not a relative or an http(s) URL, e.g. javascript:.
+func validateRedirectUrl(redirectUrl string) error {
+	if strings.TrimSpace(redirectUrl) == "" {
+		return errors.New("redirect URL is empty")
+	}
+	parsed, err := url.Parse(redirectUrl)
+	if err != nil {
+		return fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	if parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("redirect URL must be an http or https URL: %s", redirectUrl)
+	}
+	return nil
+}
+
+// getRedirectStatusCode returns the redirectStatusCode parameter, or the response code option, default 307.
+func getRedirectStatusCode(input *structs.NodeExecuteInput, responseCode int) (int, error) {
+	// The response code option is used by the nodes saved before the parameter.
+	if _, ok := input.Params.Parameters["redirectStatusCode"]; ok {
+		redirectStatusCode, err := core.GetNodeParameterAsType(Name, "redirectStatusCode", 0, input, 0)
+		if err != nil {
+			return 0, err
+		}
+		responseCode = *redirectStatusCode
+	}
+	switch responseCode {
+	case 0:
+		return 307, nil
+	case 301, 302, 303, 307, 308:
+		return responseCode, nil
+	default:
+		return 0, fmt.Errorf("invalid redirect status code: %d", responseCode)
+	}
+}
+
+// signJwt returns the payload parameter signed with the jwtAuth credential.
+func signJwt(ctx context.Context, input *structs.NodeExecuteInput) (string, error) {
+	if input.Workflow == nil {
+		return "", errors.New("workflow not found")
+	}
+	credentials := core.JwtAuthCredentials{}
+	err := core.GetNodeCredentials(ctx, input.Workflow.SugerOrgId, input.Params, core.CredentialsType_JwtAuth, &credentials)
+	if err != nil {
+		return "", err
+	}
+	payload, err := core.GetNodeParameter(Name, "payload", "{}", input, 0)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.MapClaims{}
+	switch value := payload.(type) {
+	case string:
+		if strings.TrimSpace(value) != "" {
+			if err := json.Unmarshal([]byte(value), &claims); err != nil {
+				return "", fmt.Errorf("payload must be a JSON object: %w", err)
+			}
+		}
+	case map[string]interface{}:
+		claims = value
+	default:
+		return "", errors.New("payload must be a JSON object")
+	}
+	return core.SignWebhookJwt(claims, &credentials)
+}
@@ -0,0 +1,489 @@
+package setnode
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	Category = structs.CategoryExecutor
+	Name     = "n8n-nodes-base.set"
+
+	Mode_Manual = "manual"
+	Mode_Raw    = "raw"
+
+	Include_All      = "all"
+	Include_None     = "none"
+	Include_Selected = "selected"
+	Include_Except   = "except"
+
+	FieldType_String  = "string"
+	FieldType_Number  = "number"
+	FieldType_Boolean = "boolean"
+	FieldType_Array   = "array"
+	FieldType_Object  = "object"
+
+	// The version replacing the "keepOnlySet" switch and the "values" by type with the modes.
+	versionModes = 3
+	// The version replacing the "include" options with the "includeOtherFields" switch, and the
+	// "fields" with the "assignments".
+	versionIncludeOtherFields = 3.3
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	SetExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	ParameterOptions struct {
+		DotNotation            bool `json:"dotNotation"`
+		IgnoreConversionErrors bool `json:"ignoreConversionErrors"`
+		IncludeBinary          bool `json:"includeBinary"`
+	}
+
+	// The field to set in the manual mapping, of the "assignmentCollection" parameter.
+	Assignment struct {
+		ID    string      `json:"id,omitempty"`
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+		Type  string      `json:"type"`
+	}
+)
+
+func init() {
+	se := &SetExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	se.spec.JsonConfig = rawJson
+	se.spec.GenerateSpec()
+
+	core.Register(se)
+}
+
+func (se *SetExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (se *SetExecutor) Name() string {
+	return Name
+}
+
+func (se *SetExecutor) DefaultSpec() interface{} {
+	return se.spec
+}
+
+// Execute sets the fields of the manual mapping or the JSON on each item, on top of the input fields
+// included, and passes the binary data through.
+func (se *SetExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+	returnData := structs.NodeData{}
+
+	for itemIndex, item := range items {
+		newItem, err := se.executeItem(input, item, itemIndex)
+		if err != nil {
+			if core.ContinueOnFail(input.Params) {
+				returnData = append(returnData, core.NewNodeSingleDataError(err, itemIndex))
+				continue
+			}
+			return core.GenerateFailedResponse(Name, err)
+		}
+		returnData = append(returnData, newItem)
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, []structs.NodeData{returnData})
+}
+
+func (se *SetExecutor) executeItem(
+	input *structs.NodeExecuteInput, item structs.NodeSingleData, itemIndex int) (structs.NodeSingleData, error) {
+	optionsRaw, err := core.GetNodeParameter(Name, "options", map[string]interface{}{}, input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+	options, err := toOptions(optionsRaw)
+	if err != nil {
+		return nil, fmt.Errorf(`property "options" is invalid: %v`, err)
+	}
+	if version := input.Params.TypeVersion; version != 0 && version < versionModes {
+		return executeValuesItem(input, item, itemIndex, options)
+	}
+	mode, err := core.GetNodeParameterAsBasicType(Name, "mode", Mode_Manual, input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var newFields []Assignment
+	switch mode {
+	case Mode_Manual:
+		newFields, err = getManualFields(input, itemIndex, options)
+	case Mode_Raw:
+		newFields, err = getRawFields(input, itemIndex)
+	default:
+		err = fmt.Errorf("unknown mode %s", mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	itemJson, _ := item["json"].(map[string]interface{})
+	resultJson, err := getIncludedFields(input, itemIndex, itemJson, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range newFields {
+		core.SetItemFieldValue(resultJson, field.Name, field.Value, options.DotNotation)
+	}
+
+	newItem := structs.NodeSingleData{"json": resultJson}
+	if binary, ok := item["binary"]; ok && binary != nil && options.IncludeBinary {
+		newItem["binary"] = binary
+	}
+	return newItem, nil
+}
+
+// executeValuesItem sets the "values" of the versions before 3 by type, the booleans first, then the numbers
+// and the strings. The input fields and the binary data are kept unless "keepOnlySet" is on.
+func executeValuesItem(
+	input *structs.NodeExecuteInput, item structs.NodeSingleData, itemIndex int, options *ParameterOptions,
+) (structs.NodeSingleData, error) {
+	keepOnlySet, err := core.GetNodeParameterAsBasicType(Name, "keepOnlySet", false, input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	newItem := structs.NodeSingleData{}
+	resultJson := map[string]interface{}{}
+	if !keepOnlySet {
+		if itemJson, ok := item["json"].(map[string]interface{}); ok {
+			resultJson = core.DeepCopyJson(itemJson).(map[string]interface{})
+		}
+		if binary, ok := item["binary"]; ok && binary != nil {
+			newItem["binary"] = binary
+		}
+	}
+	for _, valueType := range []string{FieldType_Boolean, FieldType_Number, FieldType_String} {
+		parameterName := "values." + valueType
+		raw, err := core.GetNodeParameter(Name, parameterName, []interface{}{}, input, itemIndex)
+		if err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			continue
+		}
+		values, err := core.ConvertToInterfaceArray(raw)
+		if err != nil {
+			return nil, fmt.Errorf(`property "%s" is invalid: %v`, parameterName, err)
+		}
+		for _, value := range values {
+			field, err := core.ConvertToInterfaceMap(value)
+			if err != nil {
+				return nil, fmt.Errorf(`property "%s" is invalid: %v`, parameterName, err)
+			}
+			name := core.GetValueFromMapWithDefault(field, "name", "propertyName")
+			fieldValue := field["value"]
+			if valueType == FieldType_Boolean {
+				fieldValue = isTruthy(fieldValue)
+			}
+			core.SetItemFieldValue(resultJson, name, fieldValue, options.DotNotation)
+		}
+	}
+	newItem["json"] = resultJson
+	return newItem, nil
+}
+
+// getManualFields returns the fields of the manual mapping in order, with the values converted to their types.
+func getManualFields(
+	input *structs.NodeExecuteInput, itemIndex int, options *ParameterOptions) ([]Assignment, error) {
+	parameterName, legacy := "assignments.assignments", false
+	if _, ok := input.Params.Parameters["assignments"]; !ok && hasLegacyFields(input) {
+		parameterName, legacy = "fields.values", true
+	}
+	// The values are read as resolved, the JSON conversion would fail on NaN of an invalid number.
+	raw, err := core.GetNodeParameter(Name, parameterName, []interface{}{}, input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := toAssignments(raw, legacy)
+	if err != nil {
+		return nil, fmt.Errorf(`property "%s" is invalid: %v`, parameterName, err)
+	}
+
+	fields := make([]Assignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.Name == "" {
+			return nil, errors.New("the name of the field to set is empty")
+		}
+		value, err := convertFieldValue(assignment.Name, assignment.Value, assignment.Type)
+		if err != nil {
+			if !options.IgnoreConversionErrors {
+				return nil, fmt.Errorf("%w [item %d]", err, itemIndex)
+			}
+			value = assignment.Value
+			if number, ok := value.(float64); ok && math.IsNaN(number) {
+				// NaN is not valid in JSON.
+				value = nil
+			}
+		}
+		fields = append(fields, Assignment{Name: assignment.Name, Value: value, Type: assignment.Type})
+	}
+	return fields, nil
+}
+
+// getRawFields returns the fields of the JSON, resolved per item.
+func getRawFields(input *structs.NodeExecuteInput, itemIndex int) ([]Assignment, error) {
+	jsonOutput, err := core.GetNodeParameter(Name, "jsonOutput", "{}", input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if jsonString, ok := jsonOutput.(string); ok {
+		if err := json.Unmarshal([]byte(jsonString), &values); err != nil {
+			return nil, fmt.Errorf("'JSON Output' in item %d does not contain a valid JSON object: %v", itemIndex, err)
+		}
+	} else if values, err = core.ConvertToInterfaceMap(jsonOutput); err != nil {
+		return nil, fmt.Errorf("'JSON Output' in item %d does not contain a valid JSON object", itemIndex)
+	}
+
+	// The nested fields are set after their parents.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]Assignment, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, Assignment{Name: name, Value: values[name]})
+	}
+	return fields, nil
+}
+
+// getIncludedFields returns a copy of the input fields to include in the output item.
+func getIncludedFields(
+	input *structs.NodeExecuteInput, itemIndex int, itemJson map[string]interface{}, options *ParameterOptions,
+) (map[string]interface{}, error) {
+	include, err := getInclude(input, itemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	resultJson := map[string]interface{}{}
+	switch include {
+	case Include_None:
+	case Include_All:
+		resultJson = core.DeepCopyJson(itemJson).(map[string]interface{})
+	case Include_Selected:
+		includeFields, err := core.GetNodeParameterAsBasicType(Name, "includeFields", "", input, itemIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range core.SplitFieldNames(includeFields) {
+			if value, ok := core.GetItemFieldValue(itemJson, field, options.DotNotation); ok {
+				core.SetItemFieldValue(resultJson, field, core.DeepCopyJson(value), options.DotNotation)
+			}
+		}
+	case Include_Except:
+		excludeFields, err := core.GetNodeParameterAsBasicType(Name, "excludeFields", "", input, itemIndex)
+		if err != nil {
+			return nil, err
+		}
+		resultJson = core.DeepCopyJson(itemJson).(map[string]interface{})
+		for _, field := range core.SplitFieldNames(excludeFields) {
+			core.DeleteItemFieldValue(resultJson, field, options.DotNotation)
+		}
+	default:
+		return nil, fmt.Errorf("unknown include %s", include)
+	}
+	return resultJson, nil
+}
+
+// getInclude returns which input fields to include. From version 3.3 no input field is included unless
+// "includeOtherFields" is on, before it all are included by default.
+func getInclude(input *structs.NodeExecuteInput, itemIndex int) (string, error) {
+	version := input.Params.TypeVersion
+	if version == 0 || version >= versionIncludeOtherFields {
+		includeOtherFields, err := core.GetNodeParameterAsBasicType(Name, "includeOtherFields", false, input, itemIndex)
+		if err != nil {
+			return "", err
+		}
+		if !includeOtherFields {
+			return Include_None, nil
+		}
+	}
+	return core.GetNodeParameterAsBasicType(Name, "include", Include_All, input, itemIndex)
+}
+
+// hasLegacyFields returns true if the manual mapping is in the "fields" parameter before version 3.3.
+func hasLegacyFields(input *structs.NodeExecuteInput) bool {
+	_, ok := input.Params.Parameters["fields"]
+	return ok
+}
+
+// toAssignments returns the assignments of the manual mapping. Before version 3.3 the field has the value
+// in the key of its type, e.g. "numberValue", and is a string by default.
+func toAssignments(raw interface{}, legacy bool) ([]Assignment, error) {
+	if raw == nil {
+		return []Assignment{}, nil
+	}
+	values, err := core.ConvertToInterfaceArray(raw)
+	if err != nil {
+		return nil, err
+	}
+	assignments := make([]Assignment, 0, len(values))
+	for _, value := range values {
+		field, err := core.ConvertToInterfaceMap(value)
+		if err != nil {
+			return nil, err
+		}
+		assignment := Assignment{
+			Name: core.GetValueFromMapWithDefault(field, "name", ""),
+			Type: core.GetValueFromMapWithDefault(field, "type", FieldType_String),
+		}
+		if legacy {
+			valueKey := assignment.Type
+			if !strings.HasSuffix(valueKey, "Value") {
+				valueKey = "stringValue"
+			}
+			assignment.Type = strings.TrimSuffix(valueKey, "Value")
+			assignment.Value = field[valueKey]
+		} else {
+			assignment.ID = core.GetValueFromMapWithDefault(field, "id", "")
+			assignment.Value = field["value"]
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
+
+// convertFieldValue converts the value to the type of the field. The empty value of a non-string field is null.
+func convertFieldValue(name string, value interface{}, fieldType string) (interface{}, error) {
+	if fieldType == "" || fieldType == FieldType_String {
+		return toString(value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	if valueString, ok := value.(string); ok {
+		if strings.TrimSpace(valueString) == "" {
+			return nil, nil
+		}
+		value = strings.TrimSpace(valueString)
+	}
+
+	var result interface{}
+	var err error
+	switch fieldType {
+	case FieldType_Number:
+		if _, isBool := value.(bool); isBool {
+			err = core.TypeError
+		} else if number, convertErr := core.ConvertToFloat(value); convertErr != nil || math.IsNaN(number) {
+			err = core.TypeError
+		} else {
+			result = number
+		}
+	case FieldType_Boolean:
+		result, err = toBool(value)
+	case FieldType_Array:
+		result, err = core.ConvertToArray(value)
+	case FieldType_Object:
+		result, err = toObject(value)
+	default:
+		return nil, fmt.Errorf("unknown type %s of the field '%s'", fieldType, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("'%s' expects a %s but we got '%s'", name, fieldType, core.JsonStr(value))
+	}
+	return result, nil
+}
+
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool, int, int32, int64:
+		return fmt.Sprint(v), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// isTruthy returns the boolean of the value as in JavaScript.
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	}
+	return true
+}
+
+func toBool(value interface{}) (bool, error) {
+	if number, err := core.ConvertToFloat(value); err == nil {
+		if number == 0 || number == 1 {
+			return number == 1, nil
+		}
+		return false, core.TypeError
+	}
+	return core.ConvertToBool(value)
+}
+
+func toObject(value interface{}) (map[string]interface{}, error) {
+	if valueString, ok := value.(string); ok {
+		result := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(valueString), &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if !core.IsMap(value) {
+		return nil, core.TypeError
+	}
+	return core.ConvertToInterfaceMap(value)
+}
+
+func toOptions(raw interface{}) (*ParameterOptions, error) {
+	options := &ParameterOptions{
+		DotNotation:   true,
+		IncludeBinary: true,
+	}
+	if raw == nil {
+		return options, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, options)
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
@@ -0,0 +1,273 @@
+package sortnode
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	Category = structs.CategoryExecutor
+	Name     = "n8n-nodes-base.sort"
+
+	Type_Simple = "simple"
+	Type_Random = "random"
+	Type_Code   = "code"
+
+	Order_Ascending  = "ascending"
+	Order_Descending = "descending"
+
+	// The code compares the items a and b, the items are sorted in JS and their indexes are returned.
+	sortCodeWrapperFmt = `const $compare = (a, b) => {
+%s
+};
+return Array.from($input.all())
+	.map((item, index) => ({ item, index }))
+	.sort((a, b) => $compare(a.item, b.item))
+	.map(({ index }) => ({ index }));`
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	SortExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	ParameterOptions struct {
+		DisableDotNotation bool `json:"disableDotNotation"`
+	}
+
+	SortFieldsUi struct {
+		SortField []SortField `json:"sortField"`
+	}
+
+	SortField struct {
+		FieldName string `json:"fieldName"`
+		Order     string `json:"order"`
+	}
+)
+
+func init() {
+	se := &SortExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	se.spec.JsonConfig = rawJson
+	se.spec.GenerateSpec()
+
+	core.Register(se)
+}
+
+func (se *SortExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (se *SortExecutor) Name() string {
+	return Name
+}
+
+func (se *SortExecutor) DefaultSpec() interface{} {
+	return se.spec
+}
+
+// Execute sorts the items by the fields, randomly, or by the JS code comparing two items.
+func (se *SortExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+	sortedItems := make(structs.NodeData, len(items))
+	copy(sortedItems, items)
+
+	sortType, err := core.GetNodeParameterAsBasicType(Name, "type", Type_Simple, input, 0)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	switch sortType {
+	case Type_Simple:
+		err = sortByFields(input, sortedItems)
+	case Type_Random:
+		rand.Shuffle(len(sortedItems), func(i, j int) {
+			sortedItems[i], sortedItems[j] = sortedItems[j], sortedItems[i]
+		})
+	case Type_Code:
+		sortedItems, err = sortByCode(input, items)
+	default:
+		err = fmt.Errorf("unknown sort type %s", sortType)
+	}
+	if err != nil {
+		if core.ContinueOnFail(input.Params) {
+			return core.GenerateSuccessResponse(structs.NodeData{},
+				[]structs.NodeData{{core.NewNodeSingleDataError(err, 0)}})
+		}
+		return core.GenerateFailedResponse(Name, err)
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, []structs.NodeData{sortedItems})
+}
+
+// sortByFields sorts the items by the fields in order, the items of the same values keep their order.
+func sortByFields(input *structs.NodeExecuteInput, items structs.NodeData) error {
+	sortFieldsUi, err := core.GetNodeParameterAsType(Name, "sortFieldsUi", SortFieldsUi{}, input, 0)
+	if err != nil {
+		return err
+	}
+	options, err := core.GetNodeParameterAsType(Name, "options", ParameterOptions{}, input, 0)
+	if err != nil {
+		return err
+	}
+	dotNotation := !options.DisableDotNotation
+
+	sortFields := sortFieldsUi.SortField
+	for _, sortField := range sortFields {
+		if sortField.FieldName == "" {
+			return fmt.Errorf("the field to sort by is empty")
+		}
+		found := false
+		for _, item := range items {
+			if _, ok := core.GetItemFieldValue(getItemJson(item), sortField.FieldName, dotNotation); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("couldn't find the field '%s' in the input data", sortField.FieldName)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, sortField := range sortFields {
+			a, aOk := core.GetItemFieldValue(getItemJson(items[i]), sortField.FieldName, dotNotation)
+			b, bOk := core.GetItemFieldValue(getItemJson(items[j]), sortField.FieldName, dotNotation)
+			// The missing values go last in both orders.
+			aPresent, bPresent := aOk && a != nil, bOk && b != nil
+			if !aPresent || !bPresent {
+				if aPresent != bPresent {
+					return aPresent
+				}
+				continue
+			}
+			result := compareValues(a, b)
+			if result == 0 {
+				continue
+			}
+			if sortField.Order == Order_Descending {
+				return result > 0
+			}
+			return result < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// sortByCode sorts the items by the JS code returning -1, 0 or 1 for the items a and b.
+func sortByCode(input *structs.NodeExecuteInput, items structs.NodeData) (structs.NodeData, error) {
+	code, err := core.GetNodeParameter(Name, "code", "", input, 0)
+	if err != nil {
+		return nil, err
+	}
+	codeStr, ok := code.(string)
+	if !ok {
+		return nil, fmt.Errorf("code is not a string")
+	}
+
+	runData := make(map[string][]*structs.WorkflowExecutionTaskData)
+	if input.RunExecutionData != nil && input.RunExecutionData.ResultData != nil && input.RunExecutionData.ResultData.RunData != nil {
+		runData = input.RunExecutionData.ResultData.RunData
+	}
+	sandboxContext := core.SandboxContext{
+		Items:   items,
+		Params:  input.Params.Parameters,
+		RunData: runData,
+	}
+	sandboxContext.SetupExecutionInfo(input)
+	sandbox := core.Sandbox{
+		Lang:    core.CodeLanguage,
+		JsCode:  fmt.Sprintf(sortCodeWrapperFmt, codeStr),
+		Context: &sandboxContext,
+	}
+	sandbox.Initialize()
+
+	indexes, err := sandbox.RunCodeAllItems()
+	if err != nil {
+		return nil, err
+	}
+	if len(indexes) != len(items) {
+		return nil, fmt.Errorf("the sort code returns %d items instead of %d", len(indexes), len(items))
+	}
+	sortedItems := make(structs.NodeData, 0, len(items))
+	for _, index := range indexes {
+		itemIndex, err := core.ConvertToInt(index["index"])
+		if err != nil || itemIndex < 0 || int(itemIndex) >= len(items) {
+			return nil, fmt.Errorf("the sort code returns an invalid item")
+		}
+		sortedItems = append(sortedItems, items[itemIndex])
+	}
+	return sortedItems, nil
+}
+
+// compareValues compares the field values by their types, it returns -1 if a is before b, 1 if after,
+// and 0 if they are equal. The numbers, including the numeric strings, and the dates are compared by
+// their values, false is before true, and the others are compared as strings.
+func compareValues(a interface{}, b interface{}) int {
+	if aNumber, ok := toNumber(a); ok {
+		if bNumber, ok := toNumber(b); ok {
+			return compareOrdered(aNumber, bNumber)
+		}
+	}
+	if aBool, ok := a.(bool); ok {
+		if bBool, ok := b.(bool); ok {
+			if aBool == bBool {
+				return 0
+			} else if aBool {
+				return 1
+			}
+			return -1
+		}
+	}
+	if aDate, err := core.ConvertToDate(a); err == nil {
+		if bDate, err := core.ConvertToDate(b); err == nil {
+			return aDate.Compare(bDate)
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	if _, ok := value.(bool); ok {
+		return 0, false
+	}
+	number, err := core.ConvertToFloat(value)
+	if err != nil || math.IsNaN(number) {
+		return 0, false
+	}
+	return number, true
+}
+
+func toString(value interface{}) string {
+	if valueString, ok := value.(string); ok {
+		return valueString
+	}
+	return core.JsonStr(value)
+}
+
+func compareOrdered(a float64, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func getItemJson(item structs.NodeSingleData) map[string]interface{} {
+	itemJson, _ := item["json"].(map[string]interface{})
+	return itemJson
+}
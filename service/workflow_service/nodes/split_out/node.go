@@ -0,0 +1,226 @@
+package split_out
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+const (
+	Category = structs.CategoryExecutor
+	Name     = "n8n-nodes-base.splitOut"
+
+	Include_NoOtherFields       = "noOtherFields"
+	Include_AllOtherFields      = "allOtherFields"
+	Include_SelectedOtherFields = "selectedOtherFields"
+)
+
+var (
+	//go:embed node.json
+	rawJson []byte
+)
+
+type (
+	SplitOutExecutor struct {
+		spec *structs.WorkflowNodeSpec
+	}
+
+	ParameterOptions struct {
+		DisableDotNotation   bool   `json:"disableDotNotation"`
+		DestinationFieldName string `json:"destinationFieldName"`
+		IncludeBinary        bool   `json:"includeBinary"`
+	}
+)
+
+func init() {
+	se := &SplitOutExecutor{
+		spec: &structs.WorkflowNodeSpec{},
+	}
+	se.spec.JsonConfig = rawJson
+	se.spec.GenerateSpec()
+
+	core.Register(se)
+}
+
+func (se *SplitOutExecutor) Category() structs.NodeObjectCategory {
+	return Category
+}
+
+func (se *SplitOutExecutor) Name() string {
+	return Name
+}
+
+func (se *SplitOutExecutor) DefaultSpec() interface{} {
+	return se.spec
+}
+
+// Execute turns the elements of the list fields of each item into separate items. The elements at the same
+// index of multiple fields go to the same item, and the other fields of the item are copied as included.
+func (se *SplitOutExecutor) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
+	items := core.GetInputData(input.Data)
+	returnData := structs.NodeData{}
+	notFoundFields := map[string]int{}
+
+	for itemIndex, item := range items {
+		newItems, notFound, err := splitOutItem(input, item, itemIndex)
+		if err != nil {
+			if core.ContinueOnFail(input.Params) {
+				returnData = append(returnData, core.NewNodeSingleDataError(err, itemIndex))
+				continue
+			}
+			return core.GenerateFailedResponse(Name, err)
+		}
+		for _, field := range notFound {
+			notFoundFields[field]++
+		}
+		returnData = append(returnData, newItems...)
+	}
+
+	// The field missing from some items is split out as an empty list, but not from all.
+	missingFields := []string{}
+	for field, count := range notFoundFields {
+		if count == len(items) {
+			missingFields = append(missingFields, field)
+		}
+	}
+	if len(missingFields) > 0 && !core.ContinueOnFail(input.Params) {
+		sort.Strings(missingFields)
+		return core.GenerateFailedResponse(Name, fmt.Errorf(
+			"the field '%s' wasn't found in any input item", strings.Join(missingFields, "', '")))
+	}
+	return core.GenerateSuccessResponse(structs.NodeData{}, []structs.NodeData{returnData})
+}
+
+// splitOutItem returns the items split out of the item, and the fields to split out not found in the item.
+func splitOutItem(
+	input *structs.NodeExecuteInput, item structs.NodeSingleData, itemIndex int,
+) (structs.NodeData, []string, error) {
+	fieldToSplitOut, err := core.GetNodeParameterAsBasicType(Name, "fieldToSplitOut", "", input, itemIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldsToSplitOut := core.SplitFieldNames(fieldToSplitOut)
+	if len(fieldsToSplitOut) == 0 {
+		return nil, nil, fmt.Errorf("no fields specified, please add a field to split out")
+	}
+	include, err := core.GetNodeParameterAsBasicType(Name, "include", Include_NoOtherFields, input, itemIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	options, err := core.GetNodeParameterAsType(Name, "options", ParameterOptions{}, input, itemIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	dotNotation := !options.DisableDotNotation
+	destinationFields := core.SplitFieldNames(options.DestinationFieldName)
+	if len(destinationFields) > 0 && len(destinationFields) != len(fieldsToSplitOut) {
+		return nil, nil, fmt.Errorf("if multiple fields to split out are given, the same number of destination fields must be given")
+	}
+	multiSplit := len(fieldsToSplitOut) > 1
+
+	itemJson, _ := item["json"].(map[string]interface{})
+	splitFieldContents := []map[string]interface{}{}
+	notFound := []string{}
+	for fieldIndex, field := range fieldsToSplitOut {
+		destinationField := field
+		if len(destinationFields) > 0 {
+			destinationField = destinationFields[fieldIndex]
+		}
+		value, ok := core.GetItemFieldValue(itemJson, field, dotNotation)
+		if !ok {
+			notFound = append(notFound, field)
+			continue
+		}
+		elements, err := toElements(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("the value of the field '%s' can not be split out [item %d]", field, itemIndex)
+		}
+		for elementIndex, element := range elements {
+			if elementIndex >= len(splitFieldContents) {
+				splitFieldContents = append(splitFieldContents, map[string]interface{}{})
+			}
+			// The object element is the new item if it is the only field split out without other fields.
+			elementJson, isObject := element.(map[string]interface{})
+			if isObject && include == Include_NoOtherFields && !multiSplit && len(destinationFields) == 0 {
+				for key, value := range elementJson {
+					splitFieldContents[elementIndex][key] = core.DeepCopyJson(value)
+				}
+			} else {
+				splitFieldContents[elementIndex][destinationField] = core.DeepCopyJson(element)
+			}
+		}
+	}
+
+	var fieldsToInclude []string
+	if include == Include_SelectedOtherFields {
+		fieldsToIncludeRaw, err := core.GetNodeParameterAsBasicType(Name, "fieldsToInclude", "", input, itemIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		fieldsToInclude = core.SplitFieldNames(fieldsToIncludeRaw)
+	}
+
+	newItems := structs.NodeData{}
+	for _, splitFieldContent := range splitFieldContents {
+		newJson := map[string]interface{}{}
+		switch include {
+		case Include_NoOtherFields:
+		case Include_AllOtherFields:
+			newJson = core.DeepCopyJson(itemJson).(map[string]interface{})
+			for _, field := range fieldsToSplitOut {
+				core.DeleteItemFieldValue(newJson, field, dotNotation)
+			}
+		case Include_SelectedOtherFields:
+			for _, field := range fieldsToInclude {
+				if value, ok := core.GetItemFieldValue(itemJson, field, dotNotation); ok {
+					core.SetItemFieldValue(newJson, field, core.DeepCopyJson(value), dotNotation)
+				}
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown include %s", include)
+		}
+		for key, value := range splitFieldContent {
+			newJson[key] = value
+		}
+
+		newItem := structs.NodeSingleData{"json": newJson}
+		if binary, ok := item["binary"]; ok && binary != nil && options.IncludeBinary {
+			newItem["binary"] = binary
+		}
+		newItems = append(newItems, newItem)
+	}
+	return newItems, notFound, nil
+}
+
+// toElements returns the elements of the list, the values of the object, or the value itself.
+func toElements(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return []interface{}{nil}, nil
+	}
+	if core.IsArray(value) {
+		return core.ConvertToInterfaceArray(value)
+	}
+	if core.IsMap(value) {
+		object, err := core.ConvertToInterfaceMap(value)
+		if err != nil {
+			return nil, err
+		}
+		// The values are in the order of the keys.
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		elements := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			elements = append(elements, object[key])
+		}
+		return elements, nil
+	}
+	return []interface{}{value}, nil
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
-	"github.com/valyala/fasthttp"
 )
 
 const (
@@ -29,12 +28,6 @@ type Webhook struct {
 	spec *structs.WorkflowNodeSpec
 }
 
-type webhookOutput struct {
-	Body    string            `json:"body"`
-	Headers map[string]string `json:"headers"`
-	Query   map[string]string `json:"query"`
-}
-
 func init() {
 	wh := &Webhook{
 		spec: &structs.WorkflowNodeSpec{},
@@ -58,29 +51,21 @@ func (wh *Webhook) DefaultSpec() interface{} {
 }
 
 func (wh *Webhook) Execute(ctx context.Context, input *structs.NodeExecuteInput) *structs.NodeExecutionResult {
-	request := input.AdditionalData.HttpRequest
-	returnItem := wh.generateOutput(request)
-	return core.GenerateSuccessResponse(structs.NodeData{
-		{
-			"json": returnItem,
-		},
-	}, []structs.NodeData{})
-}
-
-func (wh *Webhook) generateOutput(request *fasthttp.Request) webhookOutput {
-	headers := make(map[string]string)
-	request.Header.VisitAll(func(key, value []byte) {
-		headers[string(key)] = string(value)
-	})
-
-	params := make(map[string]string)
-	request.URI().QueryArgs().VisitAll(func(key, value []byte) {
-		params[string(key)] = string(value)
-	})
-
-	return webhookOutput{
-		Body:    string(request.Body()),
-		Headers: headers,
-		Query:   params,
+	options, err := core.ParseWebhookNodeOptions(input.Params)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	additionalData := input.AdditionalData
+	returnItem, binary, err := core.GenerateWebhookOutput(
+		additionalData.HttpRequest, additionalData.HttpRequestParams, additionalData.HttpRequestClientIp, options)
+	if err != nil {
+		return core.GenerateFailedResponse(Name, err)
+	}
+	item := structs.NodeSingleData{
+		"json": returnItem,
+	}
+	if len(binary) > 0 {
+		item["binary"] = binary
 	}
+	return core.GenerateSuccessResponse(structs.NodeData{item}, []structs.NodeData{})
 }
@@ -0,0 +1,41 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/execution_data"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func (s *NodeTestSuite) TestExecutionData() {
+	s.T().Run("TestExecutionDataExecute", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := &structs.WorkflowNode{}
+		testFile, err := os.ReadFile("./test_files/execution-data-params.json")
+		assert.Nil(err)
+		err = json.Unmarshal(testFile, &np)
+		assert.Nil(err)
+
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"orderId": 12345}},
+			{"json": map[string]interface{}{"orderId": 67890}},
+		}
+		resultData := &structs.WorkflowRunExecutionResultData{}
+		executor := &execution_data.ExecutionDataExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params:           np,
+			Data:             []structs.NodeData{items},
+			RunExecutionData: &structs.WorkflowRunExecutionData{ResultData: resultData},
+		})
+		assert.Empty(result.Errors)
+		// the items are passed through and the last item wins
+		assert.Equal(items, result.ExecutorData[0])
+		assert.Equal(map[string]string{"orderId": "67890", "source": "shop"}, resultData.MetaData)
+	})
+}
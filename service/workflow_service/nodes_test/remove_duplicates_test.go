@@ -0,0 +1,81 @@
+package nodes_test
+
+// Command to run this test only
+// go test -v service/workflow_service/nodes_test/init_test.go service/workflow_service/nodes_test/spec_test.go service/workflow_service/nodes_test/util_test.go service/workflow_service/nodes_test/remove_duplicates_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/remove_duplicates"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func (s *NodeTestSuite) TestRemoveDuplicates() {
+	items := structs.NodeData{
+		{"json": map[string]interface{}{"id": 1, "email": "a@example.com", "address": map[string]interface{}{"country": "US"}}},
+		{"json": map[string]interface{}{"id": 2, "email": "a@example.com", "address": map[string]interface{}{"country": "US"}}},
+		{"json": map[string]interface{}{"id": 3, "email": "a@example.com", "address": map[string]interface{}{"country": "CA"}}},
+		{"json": map[string]interface{}{"id": 1, "email": "a@example.com", "address": map[string]interface{}{"country": "US"}}},
+	}
+
+	s.T().Run("TestRemoveDuplicatesExecute selected fields", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/remove-duplicates-params.json")
+		executor := &remove_duplicates.RemoveDuplicatesExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		// the first of the duplicates is kept
+		assert.Equal(structs.NodeData{items[0], items[2]}, result.ExecutorData[0])
+
+		np.Parameters["options"] = map[string]interface{}{"removeOtherFields": true}
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(structs.NodeData{
+			{"json": map[string]interface{}{"email": "a@example.com", "address": map[string]interface{}{"country": "US"}}},
+			{"json": map[string]interface{}{"email": "a@example.com", "address": map[string]interface{}{"country": "CA"}}},
+		}, result.ExecutorData[0])
+
+		np.Parameters["fieldsToCompare"] = "phone"
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.NotEmpty(result.Errors)
+		assert.Equal("'phone' field is missing from some input items", result.Errors[0].Message)
+	})
+
+	s.T().Run("TestRemoveDuplicatesExecute all fields", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/remove-duplicates-params.json")
+		np.Parameters["compare"] = "allFields"
+		executor := &remove_duplicates.RemoveDuplicatesExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(structs.NodeData{items[0], items[1], items[2]}, result.ExecutorData[0])
+
+		np.Parameters["compare"] = "allFieldsExcept"
+		np.Parameters["fieldsToExclude"] = "id, address.country"
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(structs.NodeData{items[0]}, result.ExecutorData[0])
+		// the input items are not changed
+		assert.Equal(map[string]interface{}{"country": "CA"}, items[2]["json"].(map[string]interface{})["address"])
+	})
+}
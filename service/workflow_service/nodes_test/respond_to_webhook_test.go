@@ -7,8 +7,11 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	rdsDbLib "github.com/sugerio/workflow-service-trial/rds-db/lib"
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/respond_to_webhook"
 	"github.com/sugerio/workflow-service-trial/shared/structs"
 	"github.com/valyala/fasthttp"
@@ -19,6 +22,28 @@ import (
 	"github.com/sugerio/workflow-service-trial/service/workflow_service/core"
 )
 
+// respondToWebhookInput_Testing returns the input of the node with the items, recording the sent response.
+func respondToWebhookInput_Testing(
+	items structs.NodeData,
+	parameters map[string]interface{},
+	recorded **fasthttp.Response) *structs.NodeExecuteInput {
+	return &structs.NodeExecuteInput{
+		Data:   []structs.NodeData{items},
+		Params: &structs.WorkflowNode{Name: "Respond to Webhook", Parameters: parameters},
+		AdditionalData: &structs.WorkflowExecuteAdditionalData{
+			Hooks: structs.WorkflowHooks{
+				HookFunctions: structs.WorkflowExecuteHooks{
+					SendResponse: []func(ctx context.Context, hooks *structs.WorkflowHooks, response *fasthttp.Response){
+						func(ctx context.Context, hooks *structs.WorkflowHooks, response *fasthttp.Response) {
+							*recorded = response
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 type RespondToWebhookTestSuite struct {
 	suite.Suite
 }
@@ -276,6 +301,138 @@ func (s *RespondToWebhookTestSuite) Test() {
 		assert.Equal("localhost", string(recorded.Header.Peek("location")))
 	})
 
+	s.T().Run("TestRespondToWebhookExecute respondWith redirect status code", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+
+		var recorded *fasthttp.Response
+		node := respond_to_webhook.RespondToWebhook{}
+		node.Execute(context.Background(), respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{}},
+			map[string]interface{}{
+				"respondWith":        "redirect",
+				"redirectURL":        "https://example.com/done",
+				"redirectStatusCode": 303,
+			},
+			&recorded))
+		assert.Equal(303, recorded.StatusCode())
+		assert.Equal("https://example.com/done", string(recorded.Header.Peek("location")))
+
+		// Only the redirect status codes and the http(s) URLs
+		result := node.Execute(context.Background(), respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{}},
+			map[string]interface{}{
+				"respondWith": "redirect",
+				"redirectURL": "https://example.com/done",
+				"options":     map[string]interface{}{"responseCode": 200},
+			},
+			&recorded))
+		assert.Equal("invalid redirect status code: 200", result.Errors[0].Message)
+		result = node.Execute(context.Background(), respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{}},
+			map[string]interface{}{
+				"respondWith": "redirect",
+				"redirectURL": "javascript:alert(1)",
+			},
+			&recorded))
+		assert.Equal("redirect URL must be an http or https URL: javascript:alert(1)", result.Errors[0].Message)
+	})
+
+	s.T().Run("TestRespondToWebhookExecute respondWith binary", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+
+		report := core.NewBinaryData([]byte("id,amount\n1,12\n"), "text/csv", "report.csv")
+		logo := core.NewBinaryData([]byte("PNG"), "image/png", "logo.png")
+		var recorded *fasthttp.Response
+		node := respond_to_webhook.RespondToWebhook{}
+		node.Execute(context.Background(), respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{
+				"json":   map[string]interface{}{},
+				"binary": map[string]interface{}{"report": report},
+			}},
+			map[string]interface{}{"respondWith": "binary"},
+			&recorded))
+		assert.Equal(200, recorded.StatusCode())
+		assert.Equal("id,amount\n1,12\n", string(recorded.Body()))
+		assert.Equal("text/csv", string(recorded.Header.ContentType()))
+		assert.Equal(`attachment; filename=report.csv`, string(recorded.Header.Peek("Content-Disposition")))
+
+		// The input field of the item with multiple binary data
+		items := structs.NodeData{structs.NodeSingleData{
+			"json":   map[string]interface{}{},
+			"binary": map[string]interface{}{"report": report, "logo": logo},
+		}}
+		result := node.Execute(context.Background(), respondToWebhookInput_Testing(
+			items, map[string]interface{}{"respondWith": "binary"}, &recorded))
+		assert.Equal("the item has multiple binary data logo, report, set the one to respond with", result.Errors[0].Message)
+		node.Execute(context.Background(), respondToWebhookInput_Testing(
+			items,
+			map[string]interface{}{
+				"respondWith":        "binary",
+				"responseDataSource": "set",
+				"inputFieldName":     "logo",
+				"options":            map[string]interface{}{"contentDisposition": "inline"},
+			},
+			&recorded))
+		assert.Equal("PNG", string(recorded.Body()))
+		assert.Equal("image/png", string(recorded.Header.ContentType()))
+		assert.Equal(`inline; filename=logo.png`, string(recorded.Header.Peek("Content-Disposition")))
+
+		// The large file is streamed.
+		content := []byte(strings.Repeat("0123456789", 200*1024))
+		node.Execute(context.Background(), respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{
+				"json":   map[string]interface{}{},
+				"binary": map[string]interface{}{"data": core.NewBinaryData(content, "application/zip", "export.zip")},
+			}},
+			map[string]interface{}{"respondWith": "binary"},
+			&recorded))
+		assert.True(recorded.IsBodyStream())
+		assert.Equal(content, recorded.Body())
+	})
+
+	s.T().Run("TestRespondToWebhookExecute respondWith jwt", func(t *testing.T) {
+		t.Parallel()
+		assert := require.New(s.T())
+
+		orgId := sid.MustGenerate()
+		credentials := core.JwtAuthCredentials{Secret: "jwt-secret"}
+		data, err := json.Marshal(credentials)
+		assert.Nil(err)
+		credentialsEntity, err := rdsDbQueries.CreateCredentialsEntity(context.Background(), rdsDbLib.CreateCredentialsEntityParams{
+			Name:        core.CredentialsType_JwtAuth,
+			Data:        string(data),
+			Type:        core.CredentialsType_JwtAuth,
+			NodesAccess: json.RawMessage("[]"),
+			ID:          uuid.NewString(),
+			SugerOrgId:  orgId,
+		})
+		assert.Nil(err)
+
+		var recorded *fasthttp.Response
+		input := respondToWebhookInput_Testing(
+			structs.NodeData{structs.NodeSingleData{"json": map[string]interface{}{}}},
+			map[string]interface{}{
+				"respondWith": "jwt",
+				"payload":     `{"sub":"customer-1"}`,
+			},
+			&recorded)
+		input.Workflow = &structs.WorkflowEntity{SugerOrgId: orgId}
+		input.Params.Credentials = map[string]structs.WorkflowNodeCredentialsDetails{
+			core.CredentialsType_JwtAuth: {ID: credentialsEntity.ID},
+		}
+		node := respond_to_webhook.RespondToWebhook{}
+		result := node.Execute(context.Background(), input)
+		assert.Empty(result.Errors)
+		assert.Equal("application/json; charset=utf-8", string(recorded.Header.ContentType()))
+		body := map[string]string{}
+		assert.Nil(json.Unmarshal(recorded.Body(), &body))
+		claims, err := core.VerifyWebhookJwt(body["token"], &credentials)
+		assert.Nil(err)
+		assert.Equal("customer-1", claims["sub"])
+	})
+
 	s.T().Run("TestRespondToWebhookExecute respondWith noData", func(t *testing.T) {
 		t.Parallel()
 		assert := require.New(s.T())
@@ -0,0 +1,158 @@
+package nodes_test
+
+// Command to run this test only
+// go test -v service/workflow_service/nodes_test/init_test.go service/workflow_service/nodes_test/spec_test.go service/workflow_service/nodes_test/util_test.go service/workflow_service/nodes_test/set_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	setnode "github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/set"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func (s *NodeTestSuite) TestSet() {
+	s.T().Run("TestSetExecute manual mapping", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/set-params.json")
+		binary := map[string]interface{}{
+			"data": map[string]interface{}{"data": "aGVsbG8=", "base64Encoded": true, "mimeType": "text/plain"},
+		}
+		items := structs.NodeData{
+			{
+				"json": map[string]interface{}{
+					"firstName": "Ada",
+					"lastName":  "Lovelace",
+					"price":     2.5,
+					"quantity":  4,
+					"customer":  map[string]interface{}{"id": "c1"},
+				},
+				"binary": binary,
+			},
+		}
+		executor := &setnode.SetExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Len(result.ExecutorData[0], 1)
+		item := result.ExecutorData[0][0]
+		assert.Equal(map[string]interface{}{
+			// the dot notation sets the field in the existing object
+			"customer": map[string]interface{}{"id": "c1", "name": "Ada Lovelace"},
+			"price":    2.5,
+			"quantity": 4,
+			"total":    float64(10),
+			"paid":     true,
+			"tags":     []interface{}{"new", "vip"},
+		}, item["json"])
+		assert.Equal(binary, item["binary"])
+		// the input item is not changed
+		assert.Equal(map[string]interface{}{"id": "c1"}, items[0]["json"].(map[string]interface{})["customer"])
+	})
+
+	s.T().Run("TestSetExecute type conversion error", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/set-params.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"firstName": "Ada", "lastName": "Lovelace", "price": "free", "quantity": 1}},
+		}
+		executor := &setnode.SetExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.NotEmpty(result.Errors)
+		assert.Contains(result.Errors[0].Message, "'total' expects a number")
+
+		np.Parameters["options"] = map[string]interface{}{"ignoreConversionErrors": true}
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Len(result.ExecutorData[0], 1)
+	})
+
+	s.T().Run("TestSetExecute JSON", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/set-params-json.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"orderId": 12345, "status": "paid", "customer.name": "Ada"}},
+			{"json": map[string]interface{}{"orderId": 67890, "status": "refunded"}},
+		}
+		executor := &setnode.SetExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		// the other input fields are not included by default
+		assert.Equal(structs.NodeData{
+			{"json": map[string]interface{}{"orderId": float64(12345), "status": "PAID"}},
+			{"json": map[string]interface{}{"orderId": float64(67890), "status": "REFUNDED"}},
+		}, result.ExecutorData[0])
+	})
+
+	s.T().Run("TestSetExecute fields before version 3.3", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/set-params-fields.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a", "quantity": 1}},
+		}
+		executor := &setnode.SetExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		// all the input fields are included by default before version 3.3
+		assert.Equal(map[string]interface{}{
+			"name":     "a",
+			"quantity": float64(2),
+			"note":     "updated",
+		}, result.ExecutorData[0][0]["json"])
+	})
+
+	s.T().Run("TestSetExecute values before version 3", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/set-params-values.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a", "quantity": 1}},
+		}
+		executor := &setnode.SetExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(map[string]interface{}{
+			"name":     "a",
+			"quantity": 1,
+			"checked":  true,
+			"order":    map[string]interface{}{"quantity": int64(2)},
+			"note":     "a updated",
+		}, result.ExecutorData[0][0]["json"])
+
+		// only the values set are kept
+		np.Parameters["keepOnlySet"] = true
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(map[string]interface{}{
+			"checked": true,
+			"order":   map[string]interface{}{"quantity": int64(2)},
+			"note":    "a updated",
+		}, result.ExecutorData[0][0]["json"])
+	})
+}
@@ -0,0 +1,118 @@
+package nodes_test
+
+// Command to run this test only
+// go test -v service/workflow_service/nodes_test/init_test.go service/workflow_service/nodes_test/spec_test.go service/workflow_service/nodes_test/util_test.go service/workflow_service/nodes_test/sort_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sortnode "github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/sort"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func getItemNames_Testing(items structs.NodeData) []interface{} {
+	names := []interface{}{}
+	for _, item := range items {
+		names = append(names, item["json"].(map[string]interface{})["name"])
+	}
+	return names
+}
+
+func (s *NodeTestSuite) TestSort() {
+	s.T().Run("TestSortExecute simple", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/sort-params.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a", "amount": "10", "customer": map[string]interface{}{"tier": 1}}},
+			{"json": map[string]interface{}{"name": "b", "amount": 9, "customer": map[string]interface{}{"tier": 1}}},
+			{"json": map[string]interface{}{"name": "c", "amount": 100}},
+			{"json": map[string]interface{}{"name": "d", "amount": 1.5, "customer": map[string]interface{}{"tier": 2}}},
+			{"json": map[string]interface{}{"name": "e", "amount": 9, "customer": map[string]interface{}{"tier": 1}}},
+		}
+		executor := &sortnode.SortExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		// the numeric strings are compared as numbers, the missing values go last,
+		// and the items of the same values keep their order
+		assert.Equal([]interface{}{"d", "b", "e", "a", "c"}, getItemNames_Testing(result.ExecutorData[0]))
+		// the input items are not sorted in place
+		assert.Equal([]interface{}{"a", "b", "c", "d", "e"}, getItemNames_Testing(items))
+	})
+
+	s.T().Run("TestSortExecute simple dates and missing field", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/sort-params.json")
+		np.Parameters["sortFieldsUi"] = map[string]interface{}{
+			"sortField": []interface{}{map[string]interface{}{"fieldName": "createdAt"}},
+		}
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a", "createdAt": "2024-03-01T10:00:00Z"}},
+			{"json": map[string]interface{}{"name": "b", "createdAt": "2024-01-15"}},
+			{"json": map[string]interface{}{"name": "c", "createdAt": "2024-02-01 08:30:00"}},
+		}
+		executor := &sortnode.SortExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal([]interface{}{"b", "c", "a"}, getItemNames_Testing(result.ExecutorData[0]))
+
+		np.Parameters["sortFieldsUi"] = map[string]interface{}{
+			"sortField": []interface{}{map[string]interface{}{"fieldName": "updatedAt"}},
+		}
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.NotEmpty(result.Errors)
+		assert.Equal("couldn't find the field 'updatedAt' in the input data", result.Errors[0].Message)
+	})
+
+	s.T().Run("TestSortExecute code", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/sort-params-code.json")
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a", "createdAt": "2024-01-01T00:00:00Z"}},
+			{"json": map[string]interface{}{"name": "b", "createdAt": "2024-03-01T00:00:00Z"}},
+			{"json": map[string]interface{}{"name": "c", "createdAt": "2024-02-01T00:00:00Z"}},
+		}
+		executor := &sortnode.SortExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal([]interface{}{"b", "c", "a"}, getItemNames_Testing(result.ExecutorData[0]))
+		// the items are returned as they are
+		assert.Equal(items[1], result.ExecutorData[0][0])
+	})
+
+	s.T().Run("TestSortExecute random", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/sort-params.json")
+		np.Parameters["type"] = "random"
+		items := structs.NodeData{
+			{"json": map[string]interface{}{"name": "a"}},
+			{"json": map[string]interface{}{"name": "b"}},
+			{"json": map[string]interface{}{"name": "c"}},
+		}
+		executor := &sortnode.SortExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.ElementsMatch(items, result.ExecutorData[0])
+	})
+}
@@ -0,0 +1,111 @@
+package nodes_test
+
+// Command to run this test only
+// go test -v service/workflow_service/nodes_test/init_test.go service/workflow_service/nodes_test/spec_test.go service/workflow_service/nodes_test/util_test.go service/workflow_service/nodes_test/split_out_test.go
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/service/workflow_service/nodes/split_out"
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func (s *NodeTestSuite) TestSplitOut() {
+	binary := map[string]interface{}{
+		"data": map[string]interface{}{"data": "aGVsbG8=", "base64Encoded": true, "mimeType": "text/plain"},
+	}
+	items := structs.NodeData{
+		{
+			"json": map[string]interface{}{
+				"order": map[string]interface{}{
+					"id": "o1",
+					"lines": []interface{}{
+						map[string]interface{}{"sku": "A", "quantity": 1},
+						map[string]interface{}{"sku": "B", "quantity": 2},
+					},
+				},
+				"customer": "c1",
+			},
+			"binary": binary,
+		},
+		{
+			"json": map[string]interface{}{
+				"order": map[string]interface{}{
+					"id":    "o2",
+					"lines": []interface{}{map[string]interface{}{"sku": "C", "quantity": 3}},
+				},
+				"customer": "c2",
+			},
+		},
+	}
+
+	s.T().Run("TestSplitOutExecute selected other fields", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/split-out-params.json")
+		executor := &split_out.SplitOutExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(structs.NodeData{
+			{"json": map[string]interface{}{"order": map[string]interface{}{"id": "o1"}, "line": map[string]interface{}{"sku": "A", "quantity": 1}}},
+			{"json": map[string]interface{}{"order": map[string]interface{}{"id": "o1"}, "line": map[string]interface{}{"sku": "B", "quantity": 2}}},
+			{"json": map[string]interface{}{"order": map[string]interface{}{"id": "o2"}, "line": map[string]interface{}{"sku": "C", "quantity": 3}}},
+		}, result.ExecutorData[0])
+	})
+
+	s.T().Run("TestSplitOutExecute no other fields", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/split-out-params.json")
+		np.Parameters["include"] = "noOtherFields"
+		np.Parameters["options"] = map[string]interface{}{"includeBinary": true}
+		executor := &split_out.SplitOutExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.Empty(result.Errors)
+		// the object elements become the items
+		assert.Equal(structs.NodeData{
+			{"json": map[string]interface{}{"sku": "A", "quantity": 1}, "binary": binary},
+			{"json": map[string]interface{}{"sku": "B", "quantity": 2}, "binary": binary},
+			{"json": map[string]interface{}{"sku": "C", "quantity": 3}},
+		}, result.ExecutorData[0])
+	})
+
+	s.T().Run("TestSplitOutExecute all other fields", func(t *testing.T) {
+		assert := require.New(s.T())
+
+		np := readNodeParams_Testing(assert, "./test_files/split-out-params.json")
+		np.Parameters["include"] = "allOtherFields"
+		executor := &split_out.SplitOutExecutor{}
+		result := executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items[1:]},
+		})
+		assert.Empty(result.Errors)
+		assert.Equal(structs.NodeData{
+			{"json": map[string]interface{}{
+				"order":    map[string]interface{}{"id": "o2"},
+				"customer": "c2",
+				"line":     map[string]interface{}{"sku": "C", "quantity": 3},
+			}},
+		}, result.ExecutorData[0])
+		// the input items are not changed
+		assert.Contains(items[1]["json"].(map[string]interface{})["order"], "lines")
+
+		np.Parameters["fieldToSplitOut"] = "order.refunds"
+		result = executor.Execute(context.Background(), &structs.NodeExecuteInput{
+			Params: np,
+			Data:   []structs.NodeData{items},
+		})
+		assert.NotEmpty(result.Errors)
+		assert.Equal("the field 'order.refunds' wasn't found in any input item", result.Errors[0].Message)
+	})
+}
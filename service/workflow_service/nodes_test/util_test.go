@@ -0,0 +1,19 @@
+package nodes_test
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sugerio/workflow-service-trial/shared/structs"
+)
+
+func readNodeParams_Testing(assert *require.Assertions, fileName string) *structs.WorkflowNode {
+	np := &structs.WorkflowNode{}
+	testFile, err := os.ReadFile(fileName)
+	assert.Nil(err)
+	err = json.Unmarshal(testFile, &np)
+	assert.Nil(err)
+	return np
+}
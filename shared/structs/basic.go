@@ -131,6 +131,8 @@ type OrganizationConfigInfo struct {
 	EnforceCustomLogin bool `json:"enforceCustomLogin,omitempty"`
 	// Whether to use new filler filed mapping
 	EnableSalesforceAwsFieldMappingV2 bool `json:"enableSalesforceAwsFieldMappingV2,omitempty"`
+	// The limits of the workflow Code node and expressions, the defaults are used if not set.
+	WorkflowSandboxLimits *WorkflowSandboxLimits `json:"workflowSandboxLimits,omitempty"`
 } //@name OrganizationConfigInfo
 
 type NotificationConfigInfo struct {
@@ -335,8 +337,10 @@ type AwsSnsSubscriptionConfirmationEvent struct {
 	MessageId        string `json:"MessageId,omitempty"`
 	Token            string `json:"Token,omitempty"`
 	TopicArn         string `json:"TopicArn,omitempty"`
+	Subject          string `json:"Subject,omitempty"`
 	Message          string `json:"Message,omitempty"`
 	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	UnsubscribeURL   string `json:"UnsubscribeURL,omitempty"`
 	SignatureVersion string `json:"SignatureVersion,omitempty"`
 	Signature        string `json:"Signature,omitempty"`
 	SigningCertURL   string `json:"SigningCertURL,omitempty"`
@@ -29,6 +29,22 @@ type Environment struct {
 	Temporal struct {
 		HostPort string `env:"TEMPORAL_HOST_PORT"`
 	}
+	Auth struct {
+		JwtSecret                 string `env:"WORKFLOW_JWT_SECRET"`                   // The HS256 secret to verify the bearer JWTs.
+		TrustApiGatewayAuthorizer bool   `env:"WORKFLOW_TRUST_API_GATEWAY_AUTHORIZER"` // Only set when the requests come through API Gateway.
+	}
+	// The webhook requests of the onReceived mode are queued and executed by the workers. For workflow-service only.
+	WebhookQueue struct {
+		Workers                   int `env:"WEBHOOK_QUEUE_WORKERS,default=10"`
+		MaxBacklog                int `env:"WEBHOOK_QUEUE_MAX_BACKLOG,default=10000"` // per org
+		MaxConcurrencyPerWorkflow int `env:"WEBHOOK_QUEUE_MAX_CONCURRENCY_PER_WORKFLOW,default=5"`
+		MaxConcurrencyPerOrg      int `env:"WEBHOOK_QUEUE_MAX_CONCURRENCY_PER_ORG,default=20"`
+	}
+	// The signed approve and reject links of the Approval node. For workflow-service only.
+	Approval struct {
+		PublicUrl string `env:"WORKFLOW_PUBLIC_URL"`      // The base URL of the public routes, e.g. https://api.example.com
+		Secret    string `env:"WORKFLOW_APPROVAL_SECRET"` // The HMAC secret to sign the approval links.
+	}
 	AllowOrigins                 string `env:"CORS_ALLOW_ORIGINS,default=*"`     // For marketplace-service only
 	NotificationEventSqsQueueUrl string `env:"NOTIFICATION_EVENT_SQS_QUEUE_URL"` // sqs queue url for notification events.
 	SugerApiEndpoint             string `env:"SUGER_API_ENDPOINT"`
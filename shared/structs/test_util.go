@@ -12,11 +12,13 @@ import (
 )
 
 const (
-	TEST_POSTGRES_DB_NAME       = "postgres"
-	TEST_POSTGRES_USERNAME      = "rds_db_admin"
-	TEST_POSTGRES_PASSWORD      = "password"
-	TEST_POSTGRES_PORT          = "5432"
-	TEST_POSTGRES_DB_URL_FORMAT = "postgres://%s:%s@localhost:%s/%s?sslmode=disable"
+	TEST_POSTGRES_DB_NAME         = "postgres"
+	TEST_POSTGRES_USERNAME        = "rds_db_admin"
+	TEST_POSTGRES_PASSWORD        = "password"
+	TEST_POSTGRES_PORT            = "5432"
+	TEST_POSTGRES_DB_URL_FORMAT   = "postgres://%s:%s@localhost:%s/%s?sslmode=disable"
+	TEST_WORKFLOW_JWT_SECRET      = "workflow-jwt-secret-for-testing"
+	TEST_WORKFLOW_APPROVAL_SECRET = "workflow-approval-secret-for-testing"
 
 	AWS_PROFILE_TEST = "workload-dev" // Here we use the workload-dev as our unit testing profile.
 )
@@ -167,6 +169,10 @@ func SetupEnvironmentVariables() {
 	os.Setenv("RDS_DB_USER", TEST_POSTGRES_USERNAME)
 	os.Setenv("RDS_DB_PASSWORD", TEST_POSTGRES_PASSWORD)
 	os.Setenv("RDS_DB_PASSWORD_SECRET_ID", "rds-private-postgres-db-dev-password")
+	// The test requests are sent with the API Gateway authorizer context.
+	os.Setenv("WORKFLOW_TRUST_API_GATEWAY_AUTHORIZER", "true")
+	os.Setenv("WORKFLOW_JWT_SECRET", TEST_WORKFLOW_JWT_SECRET)
+	os.Setenv("WORKFLOW_APPROVAL_SECRET", TEST_WORKFLOW_APPROVAL_SECRET)
 }
 
 func CleanupEnvironmentVariables() {
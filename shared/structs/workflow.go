@@ -97,6 +97,15 @@ const (
 	WorkflowExecutionStatus_Warning  WorkflowExecutionStatus = "warning"
 )
 
+type WorkflowApprovalStatus string //@name WorkflowApprovalStatus
+
+const (
+	WorkflowApprovalStatus_Approved WorkflowApprovalStatus = "approved"
+	WorkflowApprovalStatus_Pending  WorkflowApprovalStatus = "pending"
+	WorkflowApprovalStatus_Rejected WorkflowApprovalStatus = "rejected"
+	WorkflowApprovalStatus_Timeout  WorkflowApprovalStatus = "timeout"
+)
+
 type WorkflowBinaryFileType string //@name WorkflowBinaryFileType
 
 const (
@@ -197,6 +206,23 @@ type WorkflowAllowedModules struct {
 	External []string `json:"external,omitempty"`
 } //@name WorkflowAllowedModules
 
+// WorkflowSandboxLimits are the limits of the Code node and the expressions of an organization.
+// The zero fields fall back to the defaults of the workflow service.
+type WorkflowSandboxLimits struct {
+	// The modules can be loaded by require(), e.g. "console" or "util".
+	AllowedModules *WorkflowAllowedModules `json:"allowedModules,omitempty"`
+	// The max run time of a single code run, in milliseconds.
+	Timeout int `json:"timeout,omitempty"`
+	// The max depth of the function calls.
+	MaxCallStackSize int `json:"maxCallStackSize,omitempty"`
+	// The max growth of the heap during a code run, in bytes. It is an approximation of the memory used by the code.
+	MaxMemory int64 `json:"maxMemory,omitempty"`
+	// The max size of the JSON encoded output of a Code node run, in bytes.
+	MaxOutputSize int `json:"maxOutputSize,omitempty"`
+	// The globals which are not available to the code, e.g. "eval".
+	BlockedGlobals []string `json:"blockedGlobals,omitempty"`
+} //@name WorkflowSandboxLimits
+
 type WorkflowLicense struct {
 	Environment string `json:"environment"`
 } //@name WorkflowLicense
@@ -438,7 +464,7 @@ type WorkflowEntity struct {
 	PinData      interface{}                        `json:"pinData,omitempty"`
 	Settings     *WorkflowSettings                  `json:"settings,omitempty"`
 	StaticData   map[string]interface{}             `json:"staticData,omitempty"`
-	Tags         []interface{}                      `json:"tags,omitempty"` //
+	Tags         []interface{}                      `json:"tags,omitempty"` // The tag ids or {"id": ...} in the requests, WorkflowTag in the responses.
 	TriggerCount int                                `json:"triggerCount,omitempty"`
 	VersionId    string                             `json:"versionId,omitempty"`
 	CreatedAt    *time.Time                         `json:"createdAt,omitempty"`
@@ -491,6 +517,196 @@ type DeleteWorkflowResponse struct {
 	Data bool `json:"data"`
 } //@name DeleteWorkflowResponse
 
+type WorkflowVariableType string //@name WorkflowVariableType
+
+const (
+	WorkflowVariableType_String  WorkflowVariableType = "string"
+	WorkflowVariableType_Number  WorkflowVariableType = "number"
+	WorkflowVariableType_Boolean WorkflowVariableType = "boolean"
+	// The value of a secret variable is never returned by the API, it can only be read by $vars.
+	WorkflowVariableType_Secret WorkflowVariableType = "secret"
+)
+
+// n8n Variables, the org scoped variables available as $vars in the expressions and the Code node.
+type WorkflowVariable struct {
+	ID    string               `json:"id,omitempty"`
+	Key   string               `json:"key"`
+	Type  WorkflowVariableType `json:"type,omitempty"`
+	Value string               `json:"value"`
+} //@name WorkflowVariable
+
+type ListVariablesResponse struct {
+	Count int64              `json:"count,omitempty"`
+	Data  []WorkflowVariable `json:"data"`
+} //@name ListVariablesResponse
+
+type GetVariableResponse struct {
+	Data *WorkflowVariable `json:"data,omitempty"`
+} //@name GetVariableResponse
+
+type DeleteVariableResponse struct {
+	Data bool `json:"data"`
+} //@name DeleteVariableResponse
+
+// n8n WorkflowHistory entity, the nodes and connections of a saved version of the workflow.
+type WorkflowHistoryVersion struct {
+	VersionId   string                             `json:"versionId"`
+	WorkflowId  string                             `json:"workflowId"`
+	Authors     string                             `json:"authors"`
+	Nodes       []WorkflowNode                     `json:"nodes,omitempty"`       // Not set when listing versions.
+	Connections map[string]WorkflowNodeConnections `json:"connections,omitempty"` // Not set when listing versions.
+	CreatedAt   *time.Time                         `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time                         `json:"updatedAt,omitempty"`
+} //@name WorkflowHistoryVersion
+
+type ListWorkflowHistoryResponse struct {
+	Count int64                    `json:"count,omitempty"`
+	Data  []WorkflowHistoryVersion `json:"data"`
+} //@name ListWorkflowHistoryResponse
+
+type GetWorkflowHistoryResponse struct {
+	Data *WorkflowHistoryVersion `json:"data,omitempty"`
+} //@name GetWorkflowHistoryResponse
+
+type WorkflowNodeDiffStatus string //@name WorkflowNodeDiffStatus
+
+const (
+	WorkflowNodeDiffStatus_Added   WorkflowNodeDiffStatus = "added"
+	WorkflowNodeDiffStatus_Removed WorkflowNodeDiffStatus = "removed"
+	WorkflowNodeDiffStatus_Changed WorkflowNodeDiffStatus = "changed"
+)
+
+// The difference of a node between two versions of the workflow.
+type WorkflowNodeDiff struct {
+	NodeId string                 `json:"nodeId"`
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Status WorkflowNodeDiffStatus `json:"status"`
+	Fields []string               `json:"fields,omitempty"` // The changed fields of a changed node.
+} //@name WorkflowNodeDiff
+
+// The differences between two versions of the workflow, the unchanged nodes are not listed.
+type WorkflowHistoryDiff struct {
+	FromVersionId      string             `json:"fromVersionId"`
+	ToVersionId        string             `json:"toVersionId"`
+	Nodes              []WorkflowNodeDiff `json:"nodes"`
+	ConnectionsChanged bool               `json:"connectionsChanged"`
+} //@name WorkflowHistoryDiff
+
+type DiffWorkflowHistoryResponse struct {
+	Data *WorkflowHistoryDiff `json:"data,omitempty"`
+} //@name DiffWorkflowHistoryResponse
+
+// n8n StatisticsNames, the counters of workflow.workflow_statistics.
+type WorkflowStatisticsName string //@name WorkflowStatisticsName
+
+const (
+	WorkflowStatisticsName_ProductionSuccess WorkflowStatisticsName = "production_success"
+	WorkflowStatisticsName_ProductionError   WorkflowStatisticsName = "production_error"
+	WorkflowStatisticsName_ManualSuccess     WorkflowStatisticsName = "manual_success"
+	WorkflowStatisticsName_ManualError       WorkflowStatisticsName = "manual_error"
+	WorkflowStatisticsName_DataLoaded        WorkflowStatisticsName = "data_loaded"
+)
+
+// The execution metrics of a workflow, the runs and durations are of the production executions since Since.
+type WorkflowStatistics struct {
+	WorkflowId   string                           `json:"workflowId"`
+	WorkflowName string                           `json:"workflowName,omitempty"`
+	Counters     map[WorkflowStatisticsName]int64 `json:"counters"` // The counters of all time.
+	Since        *time.Time                       `json:"since,omitempty"`
+	Runs         int64                            `json:"runs"`
+	Successes    int64                            `json:"successes"`
+	Errors       int64                            `json:"errors"`
+	SuccessRate  float64                          `json:"successRate"`         // Successes / Runs, 0 if there is no run.
+	DurationP50  int64                            `json:"durationP50"`         // The median duration in milliseconds, approximated.
+	DurationP95  int64                            `json:"durationP95"`         // The 95th percentile duration in milliseconds, approximated.
+	LastRunAt    *time.Time                       `json:"lastRunAt,omitempty"` // The last run of all time.
+} //@name WorkflowStatistics
+
+type GetWorkflowStatisticsResponse struct {
+	Data *WorkflowStatistics `json:"data,omitempty"`
+} //@name GetWorkflowStatisticsResponse
+
+type ListWorkflowStatisticsResponse struct {
+	Count int64                `json:"count,omitempty"`
+	Data  []WorkflowStatistics `json:"data"`
+} //@name ListWorkflowStatisticsResponse
+
+// n8n TagEntity, the org scoped tags of the workflows.
+type WorkflowTag struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UsageCount *int64     `json:"usageCount,omitempty"` // The number of workflows with the tag, only set when listing tags.
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+} //@name WorkflowTag
+
+type ListTagsResponse struct {
+	Count int64         `json:"count,omitempty"`
+	Data  []WorkflowTag `json:"data"`
+} //@name ListTagsResponse
+
+type GetTagResponse struct {
+	Data *WorkflowTag `json:"data,omitempty"`
+} //@name GetTagResponse
+
+type DeleteTagResponse struct {
+	Data bool `json:"data"`
+} //@name DeleteTagResponse
+
+// The permissions checked by the workflow APIs.
+type WorkflowPermission string //@name WorkflowPermission
+
+const (
+	WorkflowPermission_WorkflowRead    WorkflowPermission = "workflow:read"
+	WorkflowPermission_WorkflowUpdate  WorkflowPermission = "workflow:update"
+	WorkflowPermission_WorkflowExecute WorkflowPermission = "workflow:execute"
+	WorkflowPermission_WorkflowDelete  WorkflowPermission = "workflow:delete"
+	WorkflowPermission_WorkflowShare   WorkflowPermission = "workflow:share"
+	WorkflowPermission_CredentialUse   WorkflowPermission = "credential:use"
+	WorkflowPermission_CredentialShare WorkflowPermission = "credential:share"
+	WorkflowPermission_ExecutionRead   WorkflowPermission = "execution:read"
+	WorkflowPermission_ExecutionDelete WorkflowPermission = "execution:delete"
+)
+
+type WorkflowShareGranteeType string //@name WorkflowShareGranteeType
+
+const (
+	WorkflowShareGranteeType_User WorkflowShareGranteeType = "user" // A user of the org.
+	WorkflowShareGranteeType_Role WorkflowShareGranteeType = "role" // All the users with the org role.
+)
+
+// The role of the grantee on the shared workflow or credential.
+type WorkflowShareRole string //@name WorkflowShareRole
+
+const (
+	WorkflowShareRole_Viewer WorkflowShareRole = "viewer" // Read the workflow and its executions.
+	WorkflowShareRole_Editor WorkflowShareRole = "editor" // Also update and run the workflow, and delete its executions.
+	WorkflowShareRole_User   WorkflowShareRole = "user"   // Use the credential in the workflows.
+)
+
+// The access to a workflow or a credential granted to a user or an org role.
+type WorkflowShare struct {
+	ResourceId  string                   `json:"resourceId,omitempty"` // The id of the workflow or the credential.
+	GranteeType WorkflowShareGranteeType `json:"granteeType"`
+	GranteeId   string                   `json:"granteeId"` // The user id or the org role.
+	Role        WorkflowShareRole        `json:"role"`
+	CreatedAt   *time.Time               `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time               `json:"updatedAt,omitempty"`
+} //@name WorkflowShare
+
+type ListWorkflowSharesResponse struct {
+	Data []WorkflowShare `json:"data"`
+} //@name ListWorkflowSharesResponse
+
+type GetWorkflowShareResponse struct {
+	Data *WorkflowShare `json:"data,omitempty"`
+} //@name GetWorkflowShareResponse
+
+type DeleteWorkflowShareResponse struct {
+	Data bool `json:"data"`
+} //@name DeleteWorkflowShareResponse
+
 type WorkflowNodeCredentialsDetails struct {
 	ID   string `json:"id,omitempty"`
 	Name string `json:"name"`
@@ -562,6 +778,8 @@ type WorkflowExecutionSummary struct {
 	WaitTill            *time.Time                             `json:"waitTill,omitempty"`
 	WorkflowId          string                                 `json:"workflowId,omitempty"`
 	WorkflowName        string                                 `json:"workflowName,omitempty"`
+	WorkflowVersionId   string                                 `json:"workflowVersionId,omitempty"` // The version of the workflow that ran.
+	StartedBy           string                                 `json:"startedBy,omitempty"`         // The user or API client that started the run.
 } //@name WorkflowExecutionSummary
 
 type ListWorkflowExecutionsResponse struct {
@@ -731,6 +949,12 @@ type WorkflowExecution struct {
 	WaitTill       *time.Time                `json:"waitTill,omitempty"`
 	WorkflowData   *WorkflowEntity           `json:"workflowData,omitempty"`
 	WorkflowId     string                    `json:"workflowId,omitempty"`
+	// The version of the workflow that ran.
+	WorkflowVersionId string `json:"workflowVersionId,omitempty"`
+	// The id of the user or API client that started the run, empty for the triggered runs.
+	StartedBy string `json:"startedBy,omitempty"`
+	// The custom data set by the Execution Data node or $execution.customData.
+	CustomData map[string]string `json:"customData,omitempty"`
 } //@name WorkflowExecution
 
 type GetWorkflowExecutionResponse struct {
@@ -763,6 +987,30 @@ type StopWorkflowExecutionResponse struct {
 	Data *WorkflowExecutionStopData `json:"data"`
 } //@name StopWorkflowExecutionResponse
 
+// The approval requested by the Approval node of an execution, with its decision for the audit.
+type WorkflowApproval struct {
+	ID             string                 `json:"id"`
+	ExecutionId    string                 `json:"executionId"`
+	WorkflowId     string                 `json:"workflowId"`
+	NodeName       string                 `json:"nodeName"`
+	RunIndex       int32                  `json:"runIndex"`
+	Message        string                 `json:"message,omitempty"`
+	RequireComment bool                   `json:"requireComment,omitempty"` // Whether the decision requires a comment.
+	Status         WorkflowApprovalStatus `json:"status"`
+	ExpiresAt      *time.Time             `json:"expiresAt,omitempty"`
+	DecidedAt      *time.Time             `json:"decidedAt,omitempty"`
+	Comment        string                 `json:"comment,omitempty"`
+	ClientIp       string                 `json:"clientIp,omitempty"` // The IP address of the decision.
+	UserAgent      string                 `json:"userAgent,omitempty"`
+	CreatedAt      *time.Time             `json:"createdAt,omitempty"`
+	ApproveUrl     string                 `json:"approveUrl,omitempty"` // The signed links, only set in the notification of the pending approval.
+	RejectUrl      string                 `json:"rejectUrl,omitempty"`
+} //@name WorkflowApproval
+
+type ListWorkflowApprovalsResponse struct {
+	Data []WorkflowApproval `json:"data"`
+} //@name ListWorkflowApprovalsResponse
+
 type DeleteWorkflowExecutionsRequest struct {
 	DeleteBefore *time.Time             `json:"deleteBefore,omitempty"`
 	Filters      map[string]interface{} `json:"filters,omitempty"`
@@ -874,6 +1122,7 @@ const (
 	WebhookRespondWith_AllIncomingItems  WebhookRespondWith = "allIncomingItems"
 	WebhookRespondWith_Binary            WebhookRespondWith = "binary"
 	WebhookRespondWith_Json              WebhookRespondWith = "json"
+	WebhookRespondWith_Jwt               WebhookRespondWith = "jwt"
 	WebhookRespondWith_NoData            WebhookRespondWith = "noData"
 	WebhookRespondWith_Redirect          WebhookRespondWith = "redirect"
 	WebhookRespondWith_Text              WebhookRespondWith = "text"
@@ -889,6 +1138,29 @@ const (
 	WebhookResponseData_NoData           WebhookResponseData = "noData"
 )
 
+type WebhookAuthentication string //@name WebhookAuthentication
+
+const (
+	// WebhookAuthentication_None is default
+	WebhookAuthentication_None       WebhookAuthentication = "none"
+	WebhookAuthentication_BasicAuth  WebhookAuthentication = "basicAuth"
+	WebhookAuthentication_HeaderAuth WebhookAuthentication = "headerAuth"
+	WebhookAuthentication_JwtAuth    WebhookAuthentication = "jwtAuth"
+)
+
+type WebhookSignatureVerification string //@name WebhookSignatureVerification
+
+const (
+	// WebhookSignatureVerification_None is default
+	WebhookSignatureVerification_None    WebhookSignatureVerification = "none"
+	WebhookSignatureVerification_Stripe  WebhookSignatureVerification = "stripe"
+	WebhookSignatureVerification_Github  WebhookSignatureVerification = "github"
+	WebhookSignatureVerification_Slack   WebhookSignatureVerification = "slack"
+	WebhookSignatureVerification_Shopify WebhookSignatureVerification = "shopify"
+	WebhookSignatureVerification_AwsSns  WebhookSignatureVerification = "awsSns"
+	WebhookSignatureVerification_Hmac    WebhookSignatureVerification = "hmac"
+)
+
 type (
 	// Spec is the universal spec for all nodes.
 	WorkflowNodeSpec struct {
@@ -1004,7 +1276,8 @@ type (
 	}
 
 	DescriptionCredentialsShow struct {
-		Authentication []string `json:"authentication"`
+		Authentication        []string `json:"authentication"`
+		SignatureVerification []string `json:"signatureVerification,omitempty"`
 	}
 
 	DescriptionModes struct {
@@ -1201,6 +1474,9 @@ type (
 		RestartExecutionId        string
 		HttpResponse              *fasthttp.Response
 		HttpRequest               *fasthttp.Request
+		HttpRequestClientIp       string             // the client IP of HttpRequest
+		HttpRequestParams         map[string]string  // the path params of HttpRequest
+		FormSubmission            NodeSingleData     // the form submission of HttpRequest parsed for the Form Trigger node
 		RestApiUrl                string             // const from os.env
 		InstanceBaseUrl           string             // const from os.env
 		CbSetExecutionStatus      SetExecutionStatus // CBFunc
@@ -1238,6 +1514,7 @@ type (
 
 	NodeExecuteInput struct {
 		WorkflowID           string
+		Workflow             *WorkflowEntity
 		Params               *WorkflowNode
 		Data                 []NodeData
 		ExecutionData        *WorkflowExecutionData
@@ -1426,6 +1703,9 @@ type WorkflowFromFields struct {
 			Option string `json:"option"`
 		} `json:"values,omitempty"`
 	} `json:"fieldOptions,omitempty"`
+	Multiselect     bool   `json:"multiselect,omitempty"`
+	MultipleFiles   bool   `json:"multipleFiles,omitempty"`
+	AcceptFileTypes string `json:"acceptFileTypes,omitempty"`
 } //@name WorkflowFromFields
 type GetWorkflowFromResponse struct {
 	Parameters *WorkflowFrom `json:"parameters"`
@@ -23,6 +23,19 @@ func (workflowEntity *WorkflowEntity) GetNodeById(nodeId string) *WorkflowNode {
 	return nil
 }
 
+func (workflowEntity *WorkflowEntity) GetNodeByName(name string) *WorkflowNode {
+	if workflowEntity == nil {
+		return nil
+	}
+
+	for _, node := range workflowEntity.Nodes {
+		if node.Name == name {
+			return &node
+		}
+	}
+	return nil
+}
+
 func (node *WorkflowNode) GetWebhookResponseMode() (WebhookResponseMode, error) {
 	if node == nil {
 		return "", errors.New("node is nil")
@@ -53,6 +66,21 @@ func (node *WorkflowNode) GetWebhookResponseData() (WebhookResponseData, error)
 	}
 }
 
+func (node *WorkflowNode) GetWebhookAuthentication() (WebhookAuthentication, error) {
+	if node == nil {
+		return "", errors.New("node is nil")
+	}
+	value := node.Parameters["authentication"]
+	if value == nil {
+		// If not specified, default to none
+		return WebhookAuthentication_None, nil
+	} else if str, ok := value.(string); ok {
+		return WebhookAuthentication(str), nil
+	} else {
+		return "", errors.New("authentication must be a string")
+	}
+}
+
 // ToWorkflowEntity converts a rdsDbLib.WorkflowWorkflowEntity to a WorkflowEntity.
 func ToWorkflowEntity(entity rdsDbLib.WorkflowWorkflowEntity) (WorkflowEntity, error) {
 	workflowEntity := WorkflowEntity{
@@ -88,6 +116,104 @@ func ToWorkflowEntity(entity rdsDbLib.WorkflowWorkflowEntity) (WorkflowEntity, e
 	return workflowEntity, combinedErr
 }
 
+// ToWorkflowHistoryVersion converts a rdsDbLib.WorkflowWorkflowHistory to a WorkflowHistoryVersion.
+func ToWorkflowHistoryVersion(history rdsDbLib.WorkflowWorkflowHistory) (WorkflowHistoryVersion, error) {
+	workflowHistory := WorkflowHistoryVersion{
+		VersionId:  history.VersionId,
+		WorkflowId: history.WorkflowId,
+		Authors:    history.Authors,
+		CreatedAt:  &history.CreatedAt,
+		UpdatedAt:  &history.UpdatedAt,
+	}
+	var combinedErr error
+	if err := UnmarshalOmitEmpty(history.Nodes, &workflowHistory.Nodes); err != nil {
+		combinedErr = multierror.Append(combinedErr, err)
+	}
+	if err := UnmarshalOmitEmpty(history.Connections, &workflowHistory.Connections); err != nil {
+		combinedErr = multierror.Append(combinedErr, err)
+	}
+	return workflowHistory, combinedErr
+}
+
+// ToWorkflowTag converts a rdsDbLib.WorkflowTagEntity to a WorkflowTag.
+func ToWorkflowTag(tag rdsDbLib.WorkflowTagEntity) WorkflowTag {
+	return WorkflowTag{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: &tag.CreatedAt,
+		UpdatedAt: &tag.UpdatedAt,
+	}
+}
+
+// ToWorkflowShare converts a rdsDbLib.WorkflowSharedWorkflow to a WorkflowShare.
+func ToWorkflowShare(share rdsDbLib.WorkflowSharedWorkflow) WorkflowShare {
+	return WorkflowShare{
+		ResourceId:  share.WorkflowId,
+		GranteeType: WorkflowShareGranteeType(share.GranteeType),
+		GranteeId:   share.GranteeId,
+		Role:        WorkflowShareRole(share.Role),
+		CreatedAt:   &share.CreatedAt,
+		UpdatedAt:   &share.UpdatedAt,
+	}
+}
+
+// ToCredentialShare converts a rdsDbLib.WorkflowSharedCredential to a WorkflowShare.
+func ToCredentialShare(share rdsDbLib.WorkflowSharedCredential) WorkflowShare {
+	return WorkflowShare{
+		ResourceId:  share.CredentialsId,
+		GranteeType: WorkflowShareGranteeType(share.GranteeType),
+		GranteeId:   share.GranteeId,
+		Role:        WorkflowShareRole(share.Role),
+		CreatedAt:   &share.CreatedAt,
+		UpdatedAt:   &share.UpdatedAt,
+	}
+}
+
+// WorkflowTagIds returns the ids of the tags in the request, a tag is either the id or an object with the id.
+// Returns nil if the tags are not set.
+func WorkflowTagIds(tags []interface{}) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	tagIds := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		switch t := tag.(type) {
+		case string:
+			tagIds = append(tagIds, t)
+		case map[string]interface{}:
+			id, ok := t["id"].(string)
+			if !ok || id == "" {
+				return nil, fmt.Errorf("invalid tag %v", tag)
+			}
+			tagIds = append(tagIds, id)
+		default:
+			return nil, fmt.Errorf("invalid tag %v", tag)
+		}
+	}
+	return tagIds, nil
+}
+
+// ToWorkflowVariable converts a rdsDbLib.WorkflowVariable to a WorkflowVariable.
+func ToWorkflowVariable(variable rdsDbLib.WorkflowVariable) WorkflowVariable {
+	return WorkflowVariable{
+		ID:    variable.ID,
+		Key:   variable.Key,
+		Type:  WorkflowVariableType(variable.Type),
+		Value: variable.Value.String,
+	}
+}
+
+// WorkflowVariableSecretMask replaces the value of a secret variable in the API responses.
+const WorkflowVariableSecretMask = "********"
+
+// Masked returns the variable with the value of a secret masked.
+func (v WorkflowVariable) Masked() WorkflowVariable {
+	if v.Type == WorkflowVariableType_Secret {
+		v.Value = WorkflowVariableSecretMask
+	}
+	return v
+}
+
 func UnmarshalOmitEmpty(from []byte, to interface{}) error {
 	if from == nil || len(from) == 0 {
 		return nil
@@ -215,6 +215,11 @@ func IsDuplicateKeyError(err error) bool {
 	return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
 }
 
+// Check if the error is pq.Error of Code=23503 foreign key violation error.
+func IsForeignKeyViolationError(err error) bool {
+	return strings.Contains(err.Error(), "violates foreign key constraint")
+}
+
 // Compare two objects by converting them to json string and compare the json string.
 func EqualObjects(a interface{}, b interface{}) bool {
 	aJson, _ := json.Marshal(a)